@@ -4,10 +4,11 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 
 	"github.com/spf13/cobra"
 
-	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/orchestrator"
 	"github.com/anthropics/ultra-engineer/internal/state"
 )
 
@@ -20,7 +21,8 @@ func abortCmd() *cobra.Command {
 		Short: "Abort processing of an issue",
 		Long: `Abort processing of an issue by adding the abort label.
 
-This will stop any ongoing processing and mark the issue as failed.
+This marks the issue as aborted. A running daemon cancels any worker
+still processing the issue on its next poll.
 
 Example:
   ultra-engineer abort --repo owner/repo --issue 123`,
@@ -46,9 +48,9 @@ Example:
 
 func abortIssue(repo string, issueNum int) error {
 	// Load config
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
 	// Create provider
@@ -60,7 +62,7 @@ func abortIssue(repo string, issueNum int) error {
 	ctx := context.Background()
 
 	// Add abort label
-	if err := provider.AddLabel(ctx, repo, issueNum, "abort"); err != nil {
+	if err := provider.AddLabel(ctx, repo, issueNum, orchestrator.AbortLabel); err != nil {
 		return fmt.Errorf("failed to add abort label: %w", err)
 	}
 
@@ -71,14 +73,22 @@ func abortIssue(repo string, issueNum int) error {
 	}
 
 	// Update phase label
-	if err := provider.AddLabel(ctx, repo, issueNum, state.PhaseFailed.Label()); err != nil {
-		return fmt.Errorf("failed to add failed label: %w", err)
+	if err := provider.AddLabel(ctx, repo, issueNum, state.PhaseAborted.Label()); err != nil {
+		return fmt.Errorf("failed to add aborted label: %w", err)
 	}
 
-	// Remove trigger label (best-effort, don't fail if it doesn't exist)
-	if err := provider.RemoveLabel(ctx, repo, issueNum, cfg.TriggerLabel); err != nil {
-		// Log but don't fail - the abort was still successful
-		fmt.Fprintf(os.Stderr, "Warning: failed to remove trigger label: %v\n", err)
+	// Remove trigger and other phase labels (best-effort, don't fail if they don't exist)
+	if issue, err := provider.GetIssue(ctx, repo, issueNum); err == nil {
+		otherPhases := state.NewLabels().GetPhaseLabelsToRemove(state.PhaseAborted)
+		for _, label := range issue.Labels {
+			if !cfg.IsTriggerLabel(label) && !slices.Contains(otherPhases, label) {
+				continue
+			}
+			if err := provider.RemoveLabel(ctx, repo, issueNum, label); err != nil {
+				// Log but don't fail - the abort was still successful
+				fmt.Fprintf(os.Stderr, "Warning: failed to remove label %s: %v\n", label, err)
+			}
+		}
 	}
 
 	fmt.Printf("Aborted processing of issue #%d\n", issueNum)
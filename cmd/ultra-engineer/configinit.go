@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+)
+
+func configCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the configuration file",
+	}
+	cmd.AddCommand(configInitCmd())
+	return cmd
+}
+
+func configInitCmd() *cobra.Command {
+	var provider string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Write a documented default config file",
+		Long: `Write a config file with every section explained and set to its default.
+Tokens are read from environment variables, so the file can be committed.
+
+The file is written to the --config path (default: config.yaml). An existing
+file is never overwritten unless --force is given.
+
+Example:
+  ultra-engineer config init
+  ultra-engineer config init --provider gitea -c /etc/ultra-engineer/config.yaml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := initConfig(configPath, provider, force); err != nil {
+				return err
+			}
+			fmt.Printf("Wrote %s\n", configPath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&provider, "provider", "github", "Git provider: github or gitea")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing config file")
+
+	return cmd
+}
+
+// initConfig writes the documented default config for provider to path
+func initConfig(path, provider string, force bool) error {
+	if provider != "github" && provider != "gitea" {
+		return fmt.Errorf("unsupported provider: %s (use github or gitea)", provider)
+	}
+
+	content, err := renderDefaultConfig(provider)
+	if err != nil {
+		return err
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !force {
+		flags |= os.O_EXCL
+	}
+	f, err := os.OpenFile(path, flags, 0600)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create config file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(content); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// renderDefaultConfig fills defaultConfigTemplate with the values from config.DefaultConfig
+func renderDefaultConfig(provider string) ([]byte, error) {
+	cfg := config.DefaultConfig()
+	cfg.Provider = provider
+
+	var buf bytes.Buffer
+	if err := defaultConfigTemplate.Execute(&buf, cfg); err != nil {
+		return nil, fmt.Errorf("failed to render config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// formatDuration prints d without zero trailing units (30m rather than 30m0s)
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+var defaultConfigTemplate = template.Must(template.New("config").Funcs(template.FuncMap{
+	"dur": formatDuration,
+}).Parse(`# Ultra Engineer configuration
+# Generated by "ultra-engineer config init". Every value below is the default.
+# Any setting can also be overridden with a UE_ environment variable, e.g.
+# claude.timeout -> UE_CLAUDE_TIMEOUT. See docs/configuration.md for details.
+
+# Git provider: github or gitea
+provider: {{.Provider}}
+
+# How often to poll for new issues
+poll_interval: {{dur .PollInterval}}
+
+# Label that triggers processing
+trigger_label: {{.TriggerLabel}}
+# trigger_labels: [ai-implement, ai-fix]  # Several labels (replaces trigger_label)
+# trigger_label_prefix: ai-  # Any label with this prefix triggers processing
+
+# Users allowed to trigger the bot (empty = repository collaborators)
+allowed_users: []
+
+# Repositories the daemon monitors (or pass --repo)
+repos:
+  - owner/repo
+{{if eq .Provider "github"}}
+# GitHub: the token is optional when the gh CLI is already logged in
+github:
+  token: ${GITHUB_TOKEN}
+  # host: github.example.com  # GitHub Enterprise
+{{else}}
+# Gitea: server URL and an API token with repository and issue write access
+gitea:
+  url: https://gitea.example.com
+  token: ${GITEA_TOKEN}
+  # ca_cert: /etc/ssl/internal-ca.pem  # Trust an internal CA
+{{end}}
+# Log output
+# log_file: /var/log/ultra-engineer.log
+log_format: {{.LogFormat}}  # text or json
+
+# Claude CLI
+claude:
+  command: {{.Claude.Command}}  # Path to the claude CLI
+  timeout: {{dur .Claude.Timeout}}  # Limit for a single Claude run
+  review_cycles: {{.Claude.ReviewCycles}}  # Review iterations for plans and code
+  # plan_review_cycles: 3  # Override review_cycles for plan reviews
+  # code_review_cycles: 5  # Override review_cycles for code reviews
+  max_qa_rounds: {{.Claude.MaxQARounds}}  # Question rounds before planning anyway (0 = unlimited)
+  max_consecutive_failures: {{.Claude.MaxConsecutiveFailures}}  # Failed runs in a row before the issue fails (0 = unlimited)
+  # max_concurrent: 2  # Claude runs at once across all issues (default: unlimited)
+  # max_cost_per_issue: 10  # US dollars per issue before it fails (default: unlimited)
+  # max_cost_per_day: 50  # US dollars per day before new issues wait for the next day (default: unlimited)
+  # model: sonnet  # Model for every phase (default: CLI default)
+
+# Retries for transient provider and Claude errors
+retry:
+  max_attempts: {{.Retry.MaxAttempts}}
+  backoff_base: {{dur .Retry.BackoffBase}}
+  rate_limit_retry: {{dur .Retry.RateLimitRetry}}
+  backoff_max: {{dur .Retry.BackoffMax}}
+  # jitter: full  # Spread out retries of many workers: none | equal | full (default: up to 25% added)
+
+# Pull requests
+defaults:
+  base_branch: {{.Defaults.BaseBranch}}
+  auto_merge: {{.Defaults.AutoMerge}}  # Merge once the provider reports the PR mergeable
+  draft_pr: {{.Defaults.DraftPR}}  # Open the PR as a draft until CI passes
+  # pr_labels: [ai-generated]  # Added to the PR when it is created
+  # merge_method: squash  # merge, squash or rebase (default: provider default)
+  merge_wait_timeout: {{dur .Defaults.MergeWaitTimeout}}
+  merge_poll_interval: {{dur .Defaults.MergePollInterval}}
+  # issue_timeout: 2h  # Fail an issue whose processing pass takes longer
+  max_retries: {{.Defaults.MaxRetries}}  # /retry commands accepted per issue (0 = no limit)
+  retry_interval: {{dur .Defaults.RetryInterval}}  # Minimum time between retries
+  # close_issue_on_merge: true  # Close the issue after its PR merges
+  # bot_username: ultra-bot  # Assigned to issues while the bot works on them
+
+# Parallel processing
+concurrency:
+  max_per_repo: {{.Concurrency.MaxPerRepo}}
+  max_total: {{.Concurrency.MaxTotal}}
+  dependency_detection: {{.Concurrency.DependencyDetection}}  # auto, manual or disabled
+  max_intake_per_poll: {{.Concurrency.MaxIntakePerPoll}}  # Oldest unprocessed issues considered per poll (0 = no limit)
+  # Scheduling weight per label; higher weights get free worker slots first
+  priority_labels:{{range $label, $weight := .Concurrency.PriorityLabels}}
+    {{printf "%q" $label}}: {{$weight}}{{end}}
+
+# Progress comment on each issue
+progress:
+  enabled: {{.Progress.Enabled}}
+  debounce_interval: {{dur .Progress.DebounceInterval}}
+  # comment_footer: "_ultra-engineer {version} (run {run_id})_"
+  single_question_comment: {{.Progress.SingleQuestionComment}}  # Edit one questions comment each round
+  ack_reaction: {{printf "%q" .Progress.AckReaction}}  # Reaction on comments the bot acts on; "" for none
+  # working_reaction: eyes  # While Claude works on an answer or PR feedback
+  # done_reaction: rocket   # When Claude is done with it
+
+# CI monitoring after the PR is created
+ci:
+  wait_for_ci: {{.CI.WaitForCI}}
+  poll_interval: {{dur .CI.PollInterval}}
+  max_poll_interval: {{dur .CI.MaxPollInterval}}
+  timeout: {{dur .CI.Timeout}}
+  max_fix_attempts: {{.CI.MaxFixAttempts}}
+  required_only: {{.CI.RequiredOnly}}
+  max_log_bytes: {{.CI.MaxLogBytes}}
+
+# Comments that approve a plan
+approval:
+  phrases:{{range .Approval.Phrases}}
+    - {{printf "%q" .}}{{end}}
+  require_exact_match: {{.Approval.RequireExactMatch}}
+
+# Where issue state is kept: comment or file
+state:
+  backend: {{.State.Backend}}
+  # dir: ~/.ultra-engineer/state
+
+# Per-issue working directories
+sandbox:
+  # base_dir: /data/ultra-engineer
+  reuse: {{.Sandbox.Reuse}}
+  max_clone_attempts: {{.Sandbox.MaxCloneAttempts}}
+
+# Identity and signing of the bot's commits (default: the host's git config), and branch names
+# git:
+#   author_name: Ultra Engineer
+#   author_email: ultra-engineer@example.com
+#   sign_commits: true  # For branches that require verified commits
+#   signing_key: /etc/ultra-engineer/signing.pub  # GPG key ID or SSH public key
+#   branch_template: ue/{issue}-{slug}  # Default: Claude names the branch
+
+# Commands that must pass in the sandbox before the PR is created; Claude fixes failures
+# hooks:
+#   pre_pr:
+#     - go test ./...
+#   max_fix_attempts: 3
+#   timeout: 10m
+`))
@@ -3,15 +3,18 @@ package main
 import (
 	"context"
 	"fmt"
+	"log"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/spf13/cobra"
 
+	"github.com/anthropics/ultra-engineer/internal/claude"
 	"github.com/anthropics/ultra-engineer/internal/config"
 	"github.com/anthropics/ultra-engineer/internal/orchestrator"
 	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/state"
 )
 
 func daemonCmd() *cobra.Command {
@@ -35,17 +38,20 @@ Example:
 	}
 
 	cmd.Flags().StringArrayVar(&repos, "repo", nil, "Repository to monitor (owner/repo), can be specified multiple times")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Run Q&A and planning without writing to the provider or pushing code")
 
 	return cmd
 }
 
 func runDaemon(cliRepos []string) error {
 	// Load config
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
+	applyCommentFooter(cfg)
+
 	// CLI flags take precedence; fall back to config repos
 	repos := cliRepos
 	if len(repos) == 0 {
@@ -61,19 +67,32 @@ func runDaemon(cliRepos []string) error {
 		logFilePath = cfg.LogFile
 	}
 
+	// Determine log format (CLI flag takes precedence over config)
+	format := logFormat
+	if format == "" {
+		format = cfg.LogFormat
+	}
+
 	// Create logger
-	logger, cleanup, err := setupLogger(logFilePath, verbose)
+	logger, cleanup, err := setupLogger(logFilePath, format, verbose)
 	if err != nil {
 		return fmt.Errorf("failed to setup logger: %w", err)
 	}
 	defer cleanup()
 
+	// Fail fast if the Claude CLI is missing or too old
+	if err := preflightClaude(cfg, logger); err != nil {
+		return err
+	}
+
 	// Create provider
 	provider, err := createProvider(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to create provider: %w", err)
 	}
 
+	provider = applyDryRun(cfg, provider, logger)
+
 	// Create daemon
 	daemon := orchestrator.NewDaemon(cfg, provider, logger)
 
@@ -82,15 +101,23 @@ func runDaemon(cliRepos []string) error {
 	defer cancel()
 
 	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
 	go func() {
-		select {
-		case <-sigCh:
-			logger.Println("Received shutdown signal")
-			cancel()
-		case <-ctx.Done():
-			// Context cancelled, exit goroutine
+		for {
+			select {
+			case sig := <-sigCh:
+				if sig == syscall.SIGHUP {
+					reloadConfig(daemon, logger)
+					continue
+				}
+				logger.Println("Received shutdown signal")
+				cancel()
+				return
+			case <-ctx.Done():
+				// Context cancelled, exit goroutine
+				return
+			}
 		}
 	}()
 
@@ -98,13 +125,60 @@ func runDaemon(cliRepos []string) error {
 	return daemon.Run(ctx, repos)
 }
 
+// reloadConfig re-reads and validates the config file and hands it to the daemon.
+// An invalid file is reported and the running config is kept.
+func reloadConfig(daemon *orchestrator.Daemon, logger *log.Logger) {
+	logger.Println("Received SIGHUP, reloading config")
+	cfg, err := loadConfig()
+	if err != nil {
+		logger.Printf("Config reload failed, keeping current config: %v", err)
+		return
+	}
+	daemon.Reload(cfg)
+}
+
+// preflightClaude checks the Claude CLI is installed and meets claude.min_version
+// before any issue is touched
+func preflightClaude(cfg *config.Config, logger *log.Logger) error {
+	client := claude.NewClient(cfg.Claude.Command, cfg.Claude.Timeout)
+	version, err := client.CheckVersion(context.Background(), cfg.Claude.MinVersion)
+	if err != nil {
+		return fmt.Errorf("claude preflight failed: %w", err)
+	}
+	logger.Printf("Using claude CLI %s", version)
+	return nil
+}
+
+// applyDryRun wraps the provider so mutating calls are only logged when --dry-run is set
+func applyDryRun(cfg *config.Config, provider providers.Provider, logger *log.Logger) providers.Provider {
+	if !dryRun {
+		return provider
+	}
+
+	cfg.DryRun = true
+	logger.Printf("Dry run: comments, labels, reactions, issue edits, PR creation and merges are logged but not sent")
+	logger.Printf("Dry run: processing stops once a plan is ready; no code is implemented, committed or pushed")
+	return providers.NewDryRunProvider(provider, logger)
+}
+
 func createProvider(cfg *config.Config) (providers.Provider, error) {
+	var provider providers.Provider
 	switch cfg.Provider {
 	case "gitea":
-		return providers.NewGiteaProvider(cfg.Gitea.URL, cfg.Gitea.Token), nil
+		gitea, err := providers.NewGiteaProvider(cfg.Gitea)
+		if err != nil {
+			return nil, err
+		}
+		provider = gitea
 	case "github":
-		return providers.NewGitHubProvider(cfg.GitHub.Token), nil
+		provider = providers.NewGitHubProvider(cfg.GitHub)
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", cfg.Provider)
 	}
+
+	// Labels the repository doesn't have yet are created in their phase's color
+	if lsProvider, ok := provider.(providers.LabelStyleProvider); ok {
+		lsProvider.SetLabelSpecs(state.LabelSpecs(cfg.Labels.Colors))
+	}
+	return provider, nil
 }
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/ultra-engineer/internal/orchestrator"
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+func depsCmd() *cobra.Command {
+	var repo string
+
+	cmd := &cobra.Command{
+		Use:   "deps",
+		Short: "Show the dependency graph of triggered issues",
+		Long: `Show which triggered issues depend on which, which are blocked and
+whether the dependencies form a cycle. Dependencies are read from the state
+the daemon stores on each issue.
+
+Example:
+  ultra-engineer deps --repo owner/repo`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if repo == "" {
+				return fmt.Errorf("--repo is required")
+			}
+			return showDeps(repo)
+		},
+	}
+
+	cmd.Flags().StringVar(&repo, "repo", "", "Repository (owner/repo)")
+	cmd.MarkFlagRequired("repo")
+
+	return cmd
+}
+
+func showDeps(repo string) error {
+	// Load config
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Create provider
+	provider, err := createProvider(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create provider: %w", err)
+	}
+
+	graph, err := orchestrator.BuildDependencyGraph(context.Background(), provider, cfg, repo)
+	if err != nil {
+		return fmt.Errorf("failed to build dependency graph: %w", err)
+	}
+
+	printDependencyGraph(os.Stdout, graph)
+	return nil
+}
+
+// printDependencyGraph writes graph as a table followed by its cycle check
+func printDependencyGraph(out io.Writer, graph *orchestrator.DependencyGraph) {
+	if len(graph.Nodes) == 0 {
+		fmt.Fprintf(out, "No triggered issues in %s\n", graph.Repo)
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ISSUE\tPHASE\tDEPENDS ON\tBLOCKED BY\tTITLE")
+	fmt.Fprintln(w, "-----\t-----\t----------\t----------\t-----")
+
+	for _, node := range graph.Nodes {
+		title := node.Title
+		if len(title) > 50 {
+			title = title[:47] + "..."
+		}
+		fmt.Fprintf(w, "#%d\t%s\t%s\t%s\t%s\n", node.Issue, node.Phase, formatIssueRefs(node.DependsOn), formatIssueRefs(node.BlockedBy), title)
+	}
+	w.Flush()
+
+	fmt.Fprintln(out)
+	if len(graph.Cycles) == 0 {
+		fmt.Fprintln(out, "Cycles: none")
+		return
+	}
+	fmt.Fprintln(out, "Cycles:")
+	for _, cycle := range graph.Cycles {
+		fmt.Fprintf(out, "  %s\n", cycle)
+	}
+}
+
+// formatIssueRefs formats issue references as "#1, owner/other#2", or "-" when there are none
+func formatIssueRefs(refs []state.IssueRef) string {
+	if len(refs) == 0 {
+		return "-"
+	}
+	parts := make([]string, len(refs))
+	for i, ref := range refs {
+		parts[i] = ref.String()
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/ultra-engineer/internal/providers"
+)
+
+// healthCheckTimeout bounds each check, so a hanging CLI or API fails the probe
+const healthCheckTimeout = 10 * time.Second
+
+func healthcheckCmd() *cobra.Command {
+	var repos []string
+
+	cmd := &cobra.Command{
+		Use:   "healthcheck",
+		Short: "Check that the daemon can run with the current config",
+		Long: `Check that the config validates, the Claude CLI is runnable and the
+provider credentials authenticate, by fetching the default branch of the
+first repo. Pass the same --repo flags as the daemon; without them the
+"repos" field in config.yaml is used.
+
+Prints one line per check and exits with status 1 if any check fails,
+so it can be used as a container liveness or readiness probe.
+
+Example:
+  ultra-engineer healthcheck --config /etc/ultra-engineer/config.yaml
+  ultra-engineer healthcheck --repo owner/repo`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !runHealthChecks(cmd.Context(), os.Stdout, repos) {
+				return fmt.Errorf("health check failed")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&repos, "repo", nil, "Repository the daemon monitors (owner/repo), can be specified multiple times")
+
+	return cmd
+}
+
+// healthCheck is the outcome of one check; Err is nil when it passed
+type healthCheck struct {
+	Name   string
+	Detail string // Shown when the check passed
+	Err    error
+}
+
+// runHealthChecks runs every check, writes the report to out and returns whether all passed.
+// Like the daemon, cliRepos take precedence over the config's repos.
+func runHealthChecks(ctx context.Context, out io.Writer, cliRepos []string) bool {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var checks []healthCheck
+	cfg, err := loadConfig()
+	if err != nil {
+		checks = append(checks, healthCheck{Name: "config", Err: err})
+	} else {
+		checks = append(checks, healthCheck{Name: "config", Detail: "valid"})
+		checks = append(checks, checkClaudeCLI(ctx, cfg.Claude.Command))
+
+		provider, err := createProvider(cfg)
+		if err != nil {
+			checks = append(checks, healthCheck{Name: "provider", Err: err})
+		} else {
+			repos := cliRepos
+			if len(repos) == 0 {
+				repos = cfg.Repos
+			}
+			checks = append(checks, checkProvider(ctx, provider, repos))
+		}
+	}
+
+	return writeHealthReport(out, checks)
+}
+
+// checkClaudeCLI checks that the Claude CLI starts and reports its version
+func checkClaudeCLI(ctx context.Context, command string) healthCheck {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, command, "--version").CombinedOutput()
+	if err != nil {
+		return healthCheck{Name: "claude", Err: fmt.Errorf("%s --version failed: %w", command, err)}
+	}
+	return healthCheck{Name: "claude", Detail: firstLine(string(output))}
+}
+
+// checkProvider checks the provider credentials with a cheap authenticated call
+func checkProvider(ctx context.Context, provider providers.Provider, repos []string) healthCheck {
+	name := "provider " + provider.Name()
+	if len(repos) == 0 {
+		return healthCheck{Name: name, Err: fmt.Errorf("no repos to check credentials against (use --repo flag or \"repos\" in config.yaml)")}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	branch, err := provider.GetDefaultBranch(ctx, repos[0])
+	if err != nil {
+		return healthCheck{Name: name, Err: fmt.Errorf("failed to access %s: %w", repos[0], err)}
+	}
+	return healthCheck{Name: name, Detail: fmt.Sprintf("%s reachable (default branch %s)", repos[0], branch)}
+}
+
+// writeHealthReport prints one line per check and returns whether all passed
+func writeHealthReport(out io.Writer, checks []healthCheck) bool {
+	healthy := true
+	for _, c := range checks {
+		if c.Err != nil {
+			healthy = false
+			fmt.Fprintf(out, "FAIL  %s: %v\n", c.Name, c.Err)
+			continue
+		}
+		fmt.Fprintf(out, "ok    %s: %s\n", c.Name, c.Detail)
+	}
+	return healthy
+}
+
+// firstLine returns the first non-empty line of s, trimmed
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
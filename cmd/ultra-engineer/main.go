@@ -1,19 +1,37 @@
 package main
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/logging"
+	"github.com/anthropics/ultra-engineer/internal/state"
 )
 
+// defaultConfigPath is used when --config is not given; it may be absent
+const defaultConfigPath = "config.yaml"
+
+// version is the release version; override with -ldflags "-X main.version=..."
+var version = "v0.1.0"
+
 var (
-	configPath string
-	verbose    bool
-	logFile    string
+	configPath   string
+	verbose      bool
+	logFile      string
+	logFormat    string
+	dryRun       bool
+	strictConfig bool
 )
 
 func main() {
@@ -30,14 +48,21 @@ It handles the full workflow:
 - PR: Create PRs, fix CI failures, and auto-merge`,
 	}
 
-	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "config.yaml", "Path to config file")
+	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", defaultConfigPath, "Path to config file (optional; UE_* environment variables are used without one)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose logging")
 	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Path to log file (logs to both stdout and file)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "", "Log format: text or json (overrides config)")
+	rootCmd.PersistentFlags().BoolVar(&strictConfig, "strict-config", false, "Fail on unknown keys in the config file instead of warning")
 
 	rootCmd.AddCommand(daemonCmd())
 	rootCmd.AddCommand(runCmd())
 	rootCmd.AddCommand(statusCmd())
+	rootCmd.AddCommand(depsCmd())
 	rootCmd.AddCommand(abortCmd())
+	rootCmd.AddCommand(pauseCmd())
+	rootCmd.AddCommand(resumeCmd())
+	rootCmd.AddCommand(configCmd())
+	rootCmd.AddCommand(healthcheckCmd())
 	rootCmd.AddCommand(versionCmd())
 
 	if err := rootCmd.Execute(); err != nil {
@@ -51,24 +76,59 @@ func versionCmd() *cobra.Command {
 		Use:   "version",
 		Short: "Print version information",
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println("ultra-engineer v0.1.0")
+			fmt.Println("ultra-engineer " + version)
 		},
 	}
 }
 
+// loadConfig loads the config file and validates it. Unknown keys are printed as
+// warnings, or fail the load with --strict-config. Without a config.yaml (and no
+// --config), settings come from the defaults and UE_* environment variables.
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load(configPath)
+	if errors.Is(err, fs.ErrNotExist) && configPath == defaultConfigPath {
+		cfg, err = config.LoadFromEnv()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if len(cfg.UnknownFields) > 0 {
+		if strictConfig {
+			return nil, fmt.Errorf("unknown keys in config %s:\n%s", configPath, strings.Join(cfg.UnknownFields, "\n"))
+		}
+		for _, field := range cfg.UnknownFields {
+			fmt.Fprintf(os.Stderr, "Warning: unknown key in config %s: %s\n", configPath, field)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s:\n%w", configPath, err)
+	}
+	return cfg, nil
+}
+
+// applyCommentFooter renders progress.comment_footer for this process and sets it on bot comments
+func applyCommentFooter(cfg *config.Config) {
+	state.SetCommentFooter(cfg.Progress.Footer(newRunID(), version))
+}
+
+// newRunID returns a short random ID identifying this process in comment footers
+func newRunID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
 // setupLogger creates a logger that writes to stdout and optionally to a file.
 // It returns the logger, a cleanup function to close the file handle, and any error.
 // If logFilePath is empty, the logger writes to stdout only.
 // If the file cannot be opened, it logs a warning to stderr and returns a stdout-only logger.
-func setupLogger(logFilePath string, verbose bool) (*log.Logger, func(), error) {
-	flags := log.LstdFlags
-	if verbose {
-		flags |= log.Lshortfile
-	}
-
+// logFormat selects "text" (default) or "json" output.
+func setupLogger(logFilePath string, logFormat string, verbose bool) (*log.Logger, func(), error) {
 	// If no log file specified, return stdout-only logger
 	if logFilePath == "" {
-		logger := log.New(os.Stdout, "[ultra-engineer] ", flags)
+		logger := logging.New(os.Stdout, logFormat, verbose)
 		return logger, func() {}, nil
 	}
 
@@ -76,7 +136,7 @@ func setupLogger(logFilePath string, verbose bool) (*log.Logger, func(), error)
 	dir := filepath.Dir(logFilePath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to create log directory %s: %v, logging to stdout only\n", dir, err)
-		logger := log.New(os.Stdout, "[ultra-engineer] ", flags)
+		logger := logging.New(os.Stdout, logFormat, verbose)
 		return logger, func() {}, nil
 	}
 
@@ -84,13 +144,13 @@ func setupLogger(logFilePath string, verbose bool) (*log.Logger, func(), error)
 	file, err := os.OpenFile(logFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to open log file %s: %v, logging to stdout only\n", logFilePath, err)
-		logger := log.New(os.Stdout, "[ultra-engineer] ", flags)
+		logger := logging.New(os.Stdout, logFormat, verbose)
 		return logger, func() {}, nil
 	}
 
 	// Create multi-writer for both stdout and file
 	multiWriter := io.MultiWriter(os.Stdout, file)
-	logger := log.New(multiWriter, "[ultra-engineer] ", flags)
+	logger := logging.New(multiWriter, logFormat, verbose)
 
 	cleanup := func() {
 		file.Sync()
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/ultra-engineer/internal/orchestrator"
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+func pauseCmd() *cobra.Command {
+	var repo string
+	var issueNum int
+
+	cmd := &cobra.Command{
+		Use:   "pause",
+		Short: "Pause processing of an issue",
+		Long: `Pause processing of an issue by adding the paused label.
+
+The daemon skips paused issues until they are resumed. Unlike abort,
+the issue is not marked failed and the trigger label is kept.
+
+Example:
+  ultra-engineer pause --repo owner/repo --issue 123`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if repo == "" {
+				return fmt.Errorf("--repo is required")
+			}
+			if issueNum == 0 {
+				return fmt.Errorf("--issue is required")
+			}
+
+			return pauseIssue(repo, issueNum)
+		},
+	}
+
+	cmd.Flags().StringVar(&repo, "repo", "", "Repository (owner/repo)")
+	cmd.Flags().IntVar(&issueNum, "issue", 0, "Issue number")
+	cmd.MarkFlagRequired("repo")
+	cmd.MarkFlagRequired("issue")
+
+	return cmd
+}
+
+func resumeCmd() *cobra.Command {
+	var repo string
+	var issueNum int
+
+	cmd := &cobra.Command{
+		Use:   "resume",
+		Short: "Resume processing of a paused issue",
+		Long: `Resume processing of a paused issue by removing the paused label.
+
+Processing continues from the persisted state on the next daemon poll.
+
+Example:
+  ultra-engineer resume --repo owner/repo --issue 123`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if repo == "" {
+				return fmt.Errorf("--repo is required")
+			}
+			if issueNum == 0 {
+				return fmt.Errorf("--issue is required")
+			}
+
+			return resumeIssue(repo, issueNum)
+		},
+	}
+
+	cmd.Flags().StringVar(&repo, "repo", "", "Repository (owner/repo)")
+	cmd.Flags().IntVar(&issueNum, "issue", 0, "Issue number")
+	cmd.MarkFlagRequired("repo")
+	cmd.MarkFlagRequired("issue")
+
+	return cmd
+}
+
+func pauseIssue(repo string, issueNum int) error {
+	// Load config
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	applyCommentFooter(cfg)
+
+	// Create provider
+	provider, err := createProvider(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create provider: %w", err)
+	}
+
+	ctx := context.Background()
+
+	if err := provider.AddLabel(ctx, repo, issueNum, orchestrator.PausedLabel); err != nil {
+		return fmt.Errorf("failed to add paused label: %w", err)
+	}
+
+	comment := state.AddBotMarker("**Processing paused** via CLI command. Run `ultra-engineer resume` to continue.")
+	if _, err := provider.CreateComment(ctx, repo, issueNum, comment); err != nil {
+		return fmt.Errorf("failed to post pause comment: %w", err)
+	}
+
+	fmt.Printf("Paused processing of issue #%d\n", issueNum)
+	return nil
+}
+
+func resumeIssue(repo string, issueNum int) error {
+	// Load config
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	applyCommentFooter(cfg)
+
+	// Create provider
+	provider, err := createProvider(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create provider: %w", err)
+	}
+
+	ctx := context.Background()
+
+	if err := provider.RemoveLabel(ctx, repo, issueNum, orchestrator.PausedLabel); err != nil {
+		return fmt.Errorf("failed to remove paused label: %w", err)
+	}
+
+	comment := state.AddBotMarker("**Processing resumed** via CLI command.")
+	if _, err := provider.CreateComment(ctx, repo, issueNum, comment); err != nil {
+		return fmt.Errorf("failed to post resume comment: %w", err)
+	}
+
+	fmt.Printf("Resumed processing of issue #%d\n", issueNum)
+	return nil
+}
@@ -9,7 +9,6 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"github.com/anthropics/ultra-engineer/internal/config"
 	"github.com/anthropics/ultra-engineer/internal/orchestrator"
 )
 
@@ -42,6 +41,7 @@ Example:
 
 	cmd.Flags().StringVar(&repo, "repo", "", "Repository (owner/repo)")
 	cmd.Flags().IntVar(&issueNum, "issue", 0, "Issue number")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Run Q&A and planning without writing to the provider or pushing code")
 	cmd.MarkFlagRequired("repo")
 	cmd.MarkFlagRequired("issue")
 
@@ -50,30 +50,45 @@ Example:
 
 func runSingle(repo string, issueNum int) error {
 	// Load config
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
+	applyCommentFooter(cfg)
+
 	// Determine log file path (CLI flag takes precedence over config)
 	logFilePath := logFile
 	if logFilePath == "" {
 		logFilePath = cfg.LogFile
 	}
 
+	// Determine log format (CLI flag takes precedence over config)
+	format := logFormat
+	if format == "" {
+		format = cfg.LogFormat
+	}
+
 	// Create logger
-	logger, cleanup, err := setupLogger(logFilePath, verbose)
+	logger, cleanup, err := setupLogger(logFilePath, format, verbose)
 	if err != nil {
 		return fmt.Errorf("failed to setup logger: %w", err)
 	}
 	defer cleanup()
 
+	// Fail fast if the Claude CLI is missing or too old
+	if err := preflightClaude(cfg, logger); err != nil {
+		return err
+	}
+
 	// Create provider
 	provider, err := createProvider(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to create provider: %w", err)
 	}
 
+	provider = applyDryRun(cfg, provider, logger)
+
 	// Create daemon (reuse for single run)
 	daemon := orchestrator.NewDaemon(cfg, provider, logger)
 
@@ -2,19 +2,28 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/orchestrator"
+	"github.com/anthropics/ultra-engineer/internal/providers"
 	"github.com/anthropics/ultra-engineer/internal/state"
 )
 
 func statusCmd() *cobra.Command {
 	var repo string
 	var issueNum int
+	var all bool
+	var jsonOutput bool
 
 	cmd := &cobra.Command{
 		Use:   "status",
@@ -24,16 +33,33 @@ func statusCmd() *cobra.Command {
 If --issue is specified, shows detailed status for that issue.
 Otherwise, lists all issues with the trigger label.
 
+With --all, lists triggered issues across every repo in the config,
+failed and blocked issues first.
+
+With --json, prints the status of --issue as JSON: the issue's metadata
+and its parsed processing state, for use in scripts and CI.
+
 Example:
   ultra-engineer status --repo owner/repo
-  ultra-engineer status --repo owner/repo --issue 123`,
+  ultra-engineer status --repo owner/repo --issue 123
+  ultra-engineer status --repo owner/repo --issue 123 --json
+  ultra-engineer status --all`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if jsonOutput && (all || issueNum <= 0) {
+				return fmt.Errorf("--json requires --repo and --issue")
+			}
+			if all {
+				if repo != "" || issueNum > 0 {
+					return fmt.Errorf("--all cannot be combined with --repo or --issue")
+				}
+				return listAllIssues()
+			}
 			if repo == "" {
-				return fmt.Errorf("--repo is required")
+				return fmt.Errorf("--repo is required (or use --all)")
 			}
 
 			if issueNum > 0 {
-				return showIssueStatus(repo, issueNum)
+				return showIssueStatus(repo, issueNum, jsonOutput)
 			}
 			return listIssues(repo)
 		},
@@ -41,16 +67,17 @@ Example:
 
 	cmd.Flags().StringVar(&repo, "repo", "", "Repository (owner/repo)")
 	cmd.Flags().IntVar(&issueNum, "issue", 0, "Specific issue number (optional)")
-	cmd.MarkFlagRequired("repo")
+	cmd.Flags().BoolVar(&all, "all", false, "List issues across all repos in the config")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the status of --issue as JSON")
 
 	return cmd
 }
 
 func listIssues(repo string) error {
 	// Load config
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
 	// Create provider
@@ -61,14 +88,14 @@ func listIssues(repo string) error {
 
 	ctx := context.Background()
 
-	// Get issues with trigger label
-	issues, err := provider.ListIssuesWithLabel(ctx, repo, cfg.TriggerLabel)
+	// Get issues with a trigger label
+	issues, err := orchestrator.ListTriggeredIssues(ctx, provider, cfg, repo)
 	if err != nil {
 		return fmt.Errorf("failed to list issues: %w", err)
 	}
 
 	if len(issues) == 0 {
-		fmt.Printf("No issues found with label '%s'\n", cfg.TriggerLabel)
+		fmt.Printf("No issues found with trigger labels '%s'\n", strings.Join(cfg.TriggerLabelList(), ", "))
 		return nil
 	}
 
@@ -89,11 +116,140 @@ func listIssues(repo string) error {
 	return nil
 }
 
-func showIssueStatus(repo string, issueNum int) error {
+// statusRow is one issue in the `status --all` table
+type statusRow struct {
+	Repo          string
+	Issue         int
+	Title         string
+	Phase         state.Phase
+	PRNumber      int
+	BlockedBy     []state.IssueRef
+	CIFixAttempts int
+}
+
+// phaseOrder ranks phases for `status --all`: issues needing attention first,
+// then the rest in pipeline order, and aborted ones, stopped on purpose, last
+var phaseOrder = map[state.Phase]int{
+	state.PhaseFailed:       0,
+	state.PhaseQuestions:    2,
+	state.PhaseApproval:     3,
+	state.PhaseNew:          4,
+	state.PhasePlanning:     5,
+	state.PhaseImplementing: 6,
+	state.PhaseReview:       7,
+	state.PhaseCompleted:    8,
+	state.PhaseAborted:      9,
+}
+
+// rank returns the sort key of a row; blocked issues sort right after failed ones
+func (r statusRow) rank() int {
+	if !r.Phase.Stopped() && len(r.BlockedBy) > 0 {
+		return 1
+	}
+	if rank, ok := phaseOrder[r.Phase]; ok {
+		return rank
+	}
+	return len(phaseOrder) + 1
+}
+
+// sortStatusRows orders rows by phase rank, then repo and issue number
+func sortStatusRows(rows []statusRow) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		if ri, rj := rows[i].rank(), rows[j].rank(); ri != rj {
+			return ri < rj
+		}
+		if rows[i].Repo != rows[j].Repo {
+			return rows[i].Repo < rows[j].Repo
+		}
+		return rows[i].Issue < rows[j].Issue
+	})
+}
+
+func listAllIssues() error {
 	// Load config
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
+	}
+	if len(cfg.Repos) == 0 {
+		return fmt.Errorf("no repositories configured (\"repos\" in config.yaml)")
+	}
+
+	// Create provider
+	provider, err := createProvider(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create provider: %w", err)
+	}
+
+	ctx := context.Background()
+
+	var rows []statusRow
+	for _, repo := range cfg.Repos {
+		issues, err := orchestrator.ListTriggeredIssues(ctx, provider, cfg, repo)
+		if err != nil {
+			return fmt.Errorf("failed to list issues for %s: %w", repo, err)
+		}
+
+		for _, issue := range issues {
+			row := statusRow{
+				Repo:  repo,
+				Issue: issue.Number,
+				Title: issue.Title,
+				Phase: state.ParsePhaseFromLabels(issue.Labels),
+			}
+
+			// Prefer the richer state stored in comments over label-derived phase
+			comments, err := provider.GetComments(ctx, repo, issue.Number)
+			if err != nil {
+				return fmt.Errorf("failed to get comments for %s#%d: %w", repo, issue.Number, err)
+			}
+			var commentBodies []string
+			for _, c := range comments {
+				commentBodies = append(commentBodies, c.Body)
+			}
+			if st, err := state.ParseFromComments(commentBodies); err == nil {
+				row.Phase = st.CurrentPhase
+				row.PRNumber = st.PRNumber
+				row.BlockedBy = st.BlockedBy
+				row.CIFixAttempts = st.CIFixAttempts
+			}
+
+			rows = append(rows, row)
+		}
+	}
+
+	if len(rows) == 0 {
+		fmt.Printf("No issues found with trigger labels '%s'\n", strings.Join(cfg.TriggerLabelList(), ", "))
+		return nil
+	}
+
+	sortStatusRows(rows)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REPO\tISSUE\tTITLE\tPHASE\tPR\tBLOCKED BY\tCI FIXES")
+	fmt.Fprintln(w, "----\t-----\t-----\t-----\t--\t----------\t--------")
+
+	for _, row := range rows {
+		title := row.Title
+		if len(title) > 50 {
+			title = title[:47] + "..."
+		}
+		pr := "-"
+		if row.PRNumber > 0 {
+			pr = fmt.Sprintf("#%d", row.PRNumber)
+		}
+		fmt.Fprintf(w, "%s\t#%d\t%s\t%s\t%s\t%s\t%d\n", row.Repo, row.Issue, title, row.Phase, pr, formatIssueRefs(row.BlockedBy), row.CIFixAttempts)
+	}
+
+	w.Flush()
+	return nil
+}
+
+func showIssueStatus(repo string, issueNum int, jsonOutput bool) error {
+	// Load config
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
 	}
 
 	// Create provider
@@ -123,6 +279,10 @@ func showIssueStatus(repo string, issueNum int) error {
 	}
 	st, _ := state.ParseFromComments(commentBodies)
 
+	if jsonOutput {
+		return writeIssueStatusJSON(os.Stdout, newIssueStatus(repo, issue, st), configSecrets(cfg))
+	}
+
 	// Display status
 	fmt.Printf("Issue #%d: %s\n", issue.Number, issue.Title)
 	fmt.Printf("Author: %s\n", issue.Author)
@@ -145,6 +305,13 @@ func showIssueStatus(repo string, issueNum int) error {
 		if st.Error != "" {
 			fmt.Printf("Error: %s\n", st.Error)
 		}
+		if st.FailureReason != "" {
+			fmt.Printf("Failure Reason: %s\n", st.FailureReason)
+		}
+		if st.TokensIn > 0 || st.TokensOut > 0 || st.CostUSD > 0 {
+			fmt.Printf("Claude Tokens: %d in, %d out\n", st.TokensIn, st.TokensOut)
+			fmt.Printf("Claude Cost: $%.2f\n", st.CostUSD)
+		}
 		fmt.Printf("Last Updated: %s\n", st.LastUpdated.Format("2006-01-02 15:04:05"))
 	} else {
 		fmt.Println("(No processing state found)")
@@ -152,3 +319,63 @@ func showIssueStatus(repo string, issueNum int) error {
 
 	return nil
 }
+
+// issueStatus is the `status --issue --json` output
+type issueStatus struct {
+	Repo       string       `json:"repo"`
+	Issue      int          `json:"issue"`
+	Title      string       `json:"title"`
+	Author     string       `json:"author"`
+	State      string       `json:"state"` // Issue state, e.g. "open" or "closed"
+	Labels     []string     `json:"labels"`
+	Phase      state.Phase  `json:"phase"` // Phase according to the issue's labels
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+	Processing *state.State `json:"processing"` // Parsed processing state, null if there is none
+}
+
+func newIssueStatus(repo string, issue *providers.Issue, st *state.State) *issueStatus {
+	labels := issue.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+	return &issueStatus{
+		Repo:       repo,
+		Issue:      issue.Number,
+		Title:      issue.Title,
+		Author:     issue.Author,
+		State:      issue.State,
+		Labels:     labels,
+		Phase:      state.ParsePhaseFromLabels(issue.Labels),
+		CreatedAt:  issue.CreatedAt,
+		UpdatedAt:  issue.UpdatedAt,
+		Processing: st,
+	}
+}
+
+// writeIssueStatusJSON writes status as indented JSON. The processing state uses the
+// same encoding as the state stored on the issue; any of secrets that ended up in it,
+// e.g. in an error message, is redacted.
+func writeIssueStatusJSON(out io.Writer, status *issueStatus, secrets []string) error {
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode status: %w", err)
+	}
+	output := string(data)
+	for _, secret := range secrets {
+		output = strings.ReplaceAll(output, secret, "[REDACTED]")
+	}
+	_, err = fmt.Fprintln(out, output)
+	return err
+}
+
+// configSecrets returns the credentials set in cfg
+func configSecrets(cfg *config.Config) []string {
+	var secrets []string
+	for _, token := range []string{cfg.GitHub.Token, cfg.Gitea.Token, cfg.GitLab.Token} {
+		if token != "" {
+			secrets = append(secrets, token)
+		}
+	}
+	return secrets
+}
@@ -1,10 +1,14 @@
 package claude
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"strings"
 	"time"
@@ -13,11 +17,22 @@ import (
 	"github.com/anthropics/ultra-engineer/internal/retry"
 )
 
+// Runner runs Claude with tools enabled and returns its output and session ID.
+// *Client is the production implementation; tests substitute fakes.
+type Runner interface {
+	RunInteractive(ctx context.Context, opts RunOptions) (output, sessionID string, err error)
+}
+
 // Client wraps the Claude Code CLI
 type Client struct {
 	command   string
 	timeout   time.Duration
+	model     string // Default model, used when RunOptions.Model is empty
 	retryOpts *retry.Options
+
+	systemPromptFile string // File whose contents are passed with --append-system-prompt
+
+	slots chan struct{} // Bounds concurrent Claude processes; nil means no limit
 }
 
 // NewClient creates a new Claude Code client
@@ -28,7 +43,9 @@ func NewClient(command string, timeout time.Duration) *Client {
 	}
 }
 
-// NewClientWithRetry creates a new Claude Code client with retry support
+// NewClientWithRetry creates a new Claude Code client with retry support. Runs that fail
+// transiently, such as timeouts, overload or server errors, are retried per retryConfig;
+// errors retry.ClassifyClaude considers permanent are returned right away.
 func NewClientWithRetry(command string, timeout time.Duration, retryConfig config.RetryConfig) *Client {
 	opts := retry.DefaultOptions(retryConfig)
 	opts.Classifier = retry.ClassifyClaude
@@ -39,21 +56,185 @@ func NewClientWithRetry(command string, timeout time.Duration, retryConfig confi
 	}
 }
 
+// SetModel sets the model used for runs that don't specify one in RunOptions
+func (c *Client) SetModel(model string) {
+	c.model = model
+}
+
+// SetAppendSystemPromptFile sets a file whose contents are appended to Claude's
+// system prompt on every run. The file is read per run so edits apply without a restart.
+func (c *Client) SetAppendSystemPromptFile(path string) {
+	c.systemPromptFile = path
+}
+
+// SetMaxConcurrent limits how many Claude processes the client runs at once, across all
+// callers; further runs wait for a free slot. n <= 0 removes the limit. Call it before
+// the client is shared.
+func (c *Client) SetMaxConcurrent(n int) {
+	if n <= 0 {
+		c.slots = nil
+		return
+	}
+	c.slots = make(chan struct{}, n)
+}
+
+// acquireSlot waits for a free concurrency slot and returns its release function
+func (c *Client) acquireSlot(ctx context.Context) (release func(), err error) {
+	if c.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.slots <- struct{}{}:
+		return func() { <-c.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 // JSONResponse represents the JSON output from Claude Code
 type JSONResponse struct {
-	Type      string  `json:"type"`
-	SessionID string  `json:"session_id"`
-	Result    string  `json:"result"`
-	Error     string  `json:"error,omitempty"`
-	CostUSD   float64 `json:"cost_usd"`
+	Type      string `json:"type"`
+	SessionID string `json:"session_id"`
+	Result    string `json:"result"`
+	Error     string `json:"error,omitempty"`
+	resultUsage
+}
+
+// resultUsage holds the usage fields of Claude Code's result output. Older CLI versions
+// report the cost as cost_usd, newer ones as total_cost_usd.
+type resultUsage struct {
+	CostUSD      float64 `json:"cost_usd"`
+	TotalCostUSD float64 `json:"total_cost_usd"`
+	Usage        struct {
+		InputTokens              int64 `json:"input_tokens"`
+		OutputTokens             int64 `json:"output_tokens"`
+		CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+		CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+	} `json:"usage"`
+}
+
+// Usage is the token usage and cost Claude reports for a run
+type Usage struct {
+	InputTokens  int64   // Includes tokens written to and read from the prompt cache
+	OutputTokens int64   // Tokens Claude generated
+	CostUSD      float64 // Cost of the run in US dollars
+}
+
+// IsZero reports whether no usage was reported
+func (u Usage) IsZero() bool {
+	return u == Usage{}
 }
 
+// usage converts the reported fields to a Usage
+func (r resultUsage) usage() Usage {
+	cost := r.TotalCostUSD
+	if cost == 0 {
+		cost = r.CostUSD
+	}
+	return Usage{
+		InputTokens:  r.Usage.InputTokens + r.Usage.CacheCreationInputTokens + r.Usage.CacheReadInputTokens,
+		OutputTokens: r.Usage.OutputTokens,
+		CostUSD:      cost,
+	}
+}
+
+// Stream event types passed to RunOptions.OnEvent
+const (
+	EventAssistant = "assistant" // Assistant text output; a message's text blocks are joined
+	EventToolUse   = "tool_use"  // Tool invocation; content is the tool name
+	EventResult    = "result"    // Final result text
+)
+
 // RunOptions configures a Claude Code run
 type RunOptions struct {
 	WorkDir      string
-	SessionID    string
+	SessionID    string // Session to resume (empty starts a new session)
 	Prompt       string
 	AllowedTools []string // Tools to allow without prompting
+	Model        string   // Model override for this run (empty = client default)
+
+	// OnEvent is called for each streamed event while Claude runs.
+	// When nil, output is buffered and only the final result is returned.
+	OnEvent func(eventType, content string)
+}
+
+// ErrTooManyFailures is returned when AttemptHooks.OnFailure gives up on further attempts
+var ErrTooManyFailures = errors.New("too many consecutive claude failures")
+
+// ErrCostLimit is returned when AttemptHooks.BeforeAttempt refuses to run Claude over budget
+var ErrCostLimit = errors.New("claude cost limit reached")
+
+// AttemptHooks observe every Claude invocation made with a context, including retries
+type AttemptHooks struct {
+	// BeforeAttempt is called before each attempt. A non-nil return is returned to the
+	// caller without running Claude or retrying; it should wrap ErrCostLimit.
+	BeforeAttempt func() error
+	OnSuccess     func()
+	// OnUsage is called with the usage of each attempt that reported any, failed or not
+	OnUsage func(usage Usage)
+	// OnFailure is called with each failed attempt. A non-nil return stops
+	// retrying and is returned to the caller; it should wrap ErrTooManyFailures.
+	OnFailure func(err error) error
+}
+
+type attemptHooksKey struct{}
+
+// WithAttemptHooks returns a context whose Claude invocations report to hooks
+func WithAttemptHooks(ctx context.Context, hooks AttemptHooks) context.Context {
+	return context.WithValue(ctx, attemptHooksKey{}, hooks)
+}
+
+// streamEvent represents one line of stream-json output from Claude Code
+type streamEvent struct {
+	Type      string `json:"type"`
+	SessionID string `json:"session_id"`
+	Result    string `json:"result"`
+	IsError   bool   `json:"is_error"`
+	resultUsage
+	Message struct {
+		Content messageContent `json:"content"`
+	} `json:"message"`
+}
+
+// contentBlock is one typed block of a stream-json message, e.g. text or tool_use
+type contentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+	Name string `json:"name"`
+}
+
+// messageContent is the content of a stream-json message, which Claude Code emits
+// either as a plain string or as an array of typed blocks
+type messageContent []contentBlock
+
+// UnmarshalJSON accepts both shapes, turning a plain string into a single text block
+func (c *messageContent) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = nil
+		if text != "" {
+			*c = messageContent{{Type: "text", Text: text}}
+		}
+		return nil
+	}
+
+	var blocks []contentBlock
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return fmt.Errorf("message content is neither a string nor an array of blocks: %w", err)
+	}
+	*c = blocks
+	return nil
+}
+
+// text concatenates the text blocks, one per line
+func (c messageContent) text() string {
+	var parts []string
+	for _, block := range c {
+		if block.Type == "text" && block.Text != "" {
+			parts = append(parts, block.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
 }
 
 // Run executes Claude Code with the given prompt
@@ -65,11 +246,52 @@ func (c *Client) Run(ctx context.Context, opts RunOptions) (string, error) {
 // RunInteractive runs Claude in a way that allows it to use tools
 // and waits for it to complete its task
 func (c *Client) RunInteractive(ctx context.Context, opts RunOptions) (string, string, error) {
+	output, sessionID, err := c.run(ctx, opts)
+
+	// A session can disappear (e.g. the sandbox moved after a restart); start fresh rather than failing
+	if err != nil && opts.SessionID != "" && isSessionNotFound(err) {
+		opts.SessionID = ""
+		return c.run(ctx, opts)
+	}
+	return output, sessionID, err
+}
+
+func (c *Client) run(ctx context.Context, opts RunOptions) (string, string, error) {
 	// If retry is configured, use retry logic
 	if c.retryOpts != nil {
 		return c.runInteractiveWithRetry(ctx, opts)
 	}
-	return c.runInteractiveOnce(ctx, opts)
+	return c.runAttempt(ctx, opts)
+}
+
+// runAttempt runs Claude once and reports the outcome to the context's AttemptHooks
+func (c *Client) runAttempt(ctx context.Context, opts RunOptions) (string, string, error) {
+	hooks, _ := ctx.Value(attemptHooksKey{}).(AttemptHooks)
+	if hooks.BeforeAttempt != nil {
+		if err := hooks.BeforeAttempt(); err != nil {
+			return "", "", err
+		}
+	}
+
+	output, sessionID, usage, err := c.runInteractiveOnce(ctx, opts)
+
+	if hooks.OnUsage != nil && !usage.IsZero() {
+		hooks.OnUsage(usage)
+	}
+	switch {
+	case err == nil && hooks.OnSuccess != nil:
+		hooks.OnSuccess()
+	case err != nil && hooks.OnFailure != nil && ctx.Err() == nil:
+		if stopErr := hooks.OnFailure(err); stopErr != nil {
+			return output, sessionID, stopErr
+		}
+	}
+	return output, sessionID, err
+}
+
+// isSessionNotFound reports whether Claude rejected --resume because the session doesn't exist
+func isSessionNotFound(err error) bool {
+	return strings.Contains(err.Error(), "No conversation found")
 }
 
 // runInteractiveWithRetry wraps runInteractiveOnce with retry logic
@@ -79,8 +301,18 @@ func (c *Client) runInteractiveWithRetry(ctx context.Context, opts RunOptions) (
 		sessionID string
 	}
 
-	r, err := retry.DoWithResult(ctx, *c.retryOpts, func() (result, error) {
-		output, sessionID, err := c.runInteractiveOnce(ctx, opts)
+	// Hooks that give up end retrying, whatever the underlying error was
+	retryOpts := *c.retryOpts
+	classify := retryOpts.Classifier
+	retryOpts.Classifier = func(err error) retry.ErrorType {
+		if errors.Is(err, ErrTooManyFailures) || errors.Is(err, ErrCostLimit) || classify == nil {
+			return retry.Permanent
+		}
+		return classify(err)
+	}
+
+	r, err := retry.DoWithResult(ctx, retryOpts, func() (result, error) {
+		output, sessionID, err := c.runAttempt(ctx, opts)
 		return result{output: output, sessionID: sessionID}, err
 	})
 
@@ -88,16 +320,52 @@ func (c *Client) runInteractiveWithRetry(ctx context.Context, opts RunOptions) (
 }
 
 // runInteractiveOnce executes a single Claude invocation
-func (c *Client) runInteractiveOnce(ctx context.Context, opts RunOptions) (string, string, error) {
+func (c *Client) runInteractiveOnce(ctx context.Context, opts RunOptions) (string, string, Usage, error) {
+	// Hold a slot per attempt, so waiting out a retry backoff doesn't block other runs
+	release, err := c.acquireSlot(ctx)
+	if err != nil {
+		return "", "", Usage{}, err
+	}
+	defer release()
+
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
 
 	// Build args: claude -p "prompt" --dangerously-skip-permissions --output-format json
 	// Prompt immediately follows -p
+	outputFormat := "json"
+	if opts.OnEvent != nil {
+		outputFormat = "stream-json"
+	}
 	args := []string{
 		"-p", opts.Prompt,
 		"--dangerously-skip-permissions",
-		"--output-format", "json",
+		"--output-format", outputFormat,
+	}
+	if opts.OnEvent != nil {
+		// stream-json requires --verbose in print mode
+		args = append(args, "--verbose")
+	}
+	if opts.SessionID != "" {
+		args = append(args, "--resume", opts.SessionID)
+	}
+
+	model := opts.Model
+	if model == "" {
+		model = c.model
+	}
+	if model != "" {
+		args = append(args, "--model", model)
+	}
+
+	if c.systemPromptFile != "" {
+		data, err := os.ReadFile(c.systemPromptFile)
+		if err != nil {
+			return "", "", Usage{}, fmt.Errorf("failed to read append_system_prompt file: %w", err)
+		}
+		if systemPrompt := strings.TrimSpace(string(data)); systemPrompt != "" {
+			args = append(args, "--append-system-prompt", systemPrompt)
+		}
 	}
 
 	for _, tool := range opts.AllowedTools {
@@ -109,22 +377,27 @@ func (c *Client) runInteractiveOnce(ctx context.Context, opts RunOptions) (strin
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get stdout pipe: %w", err)
+		return "", "", Usage{}, fmt.Errorf("failed to get stdout pipe: %w", err)
 	}
 
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get stderr pipe: %w", err)
+		return "", "", Usage{}, fmt.Errorf("failed to get stderr pipe: %w", err)
 	}
 
 	if err := cmd.Start(); err != nil {
-		return "", "", fmt.Errorf("failed to start claude: %w", err)
+		return "", "", Usage{}, fmt.Errorf("failed to start claude: %w", err)
 	}
 
-	// Read all stdout
-	stdoutBytes, err := io.ReadAll(stdout)
+	// Read all stdout, dispatching stream events as they arrive
+	var stdoutBytes []byte
+	if opts.OnEvent != nil {
+		stdoutBytes, err = readStream(stdout, opts.OnEvent)
+	} else {
+		stdoutBytes, err = io.ReadAll(stdout)
+	}
 	if err != nil {
-		return "", "", fmt.Errorf("failed to read stdout: %w", err)
+		return "", "", Usage{}, fmt.Errorf("failed to read stdout: %w", err)
 	}
 
 	// Read stderr for any errors
@@ -132,23 +405,95 @@ func (c *Client) runInteractiveOnce(ctx context.Context, opts RunOptions) (strin
 
 	if err := cmd.Wait(); err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return "", "", fmt.Errorf("claude timed out after %v", c.timeout)
+			return "", "", Usage{}, fmt.Errorf("claude timed out after %v", c.timeout)
 		}
-		return "", "", fmt.Errorf("claude failed: %w: %s", err, string(stderrBytes))
+		return "", "", Usage{}, fmt.Errorf("claude failed: %w: %s", err, string(stderrBytes))
+	}
+
+	if opts.OnEvent != nil {
+		return parseStreamResult(stdoutBytes)
 	}
 
 	// Parse JSON response
 	var resp JSONResponse
 	if err := json.Unmarshal(stdoutBytes, &resp); err != nil {
 		// If not valid JSON, return raw output
-		return string(stdoutBytes), "", nil
+		return string(stdoutBytes), "", Usage{}, nil
 	}
 
 	if resp.Error != "" {
-		return "", resp.SessionID, fmt.Errorf("claude error: %s", resp.Error)
+		return "", resp.SessionID, resp.usage(), fmt.Errorf("claude error: %s", resp.Error)
+	}
+
+	return resp.Result, resp.SessionID, resp.usage(), nil
+}
+
+// readStream reads stream-json output line by line, calling onEvent for each event
+// Returns the raw output so the final result can be parsed after the process exits
+func readStream(r io.Reader, onEvent func(eventType, content string)) ([]byte, error) {
+	var buf bytes.Buffer
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		buf.Write(line)
+		buf.WriteByte('\n')
+
+		var ev streamEvent
+		if err := json.Unmarshal(line, &ev); err != nil {
+			continue
+		}
+		dispatchEvent(ev, onEvent)
+	}
+
+	return buf.Bytes(), scanner.Err()
+}
+
+// dispatchEvent invokes onEvent for the parts of an event callers care about
+func dispatchEvent(ev streamEvent, onEvent func(eventType, content string)) {
+	switch ev.Type {
+	case "assistant":
+		if text := ev.Message.Content.text(); text != "" {
+			onEvent(EventAssistant, text)
+		}
+		for _, block := range ev.Message.Content {
+			if block.Type == "tool_use" {
+				onEvent(EventToolUse, block.Name)
+			}
+		}
+	case "result":
+		onEvent(EventResult, ev.Result)
+	}
+}
+
+// parseStreamResult extracts the final result, session ID and usage from stream-json output
+func parseStreamResult(output []byte) (string, string, Usage, error) {
+	var sessionID string
+	var result *streamEvent
+
+	for _, line := range bytes.Split(output, []byte("\n")) {
+		var ev streamEvent
+		if err := json.Unmarshal(line, &ev); err != nil {
+			continue
+		}
+		if ev.SessionID != "" {
+			sessionID = ev.SessionID
+		}
+		if ev.Type == "result" {
+			result = &ev
+		}
+	}
+
+	if result == nil {
+		// No result event, return raw output like the buffered path does
+		return string(output), sessionID, Usage{}, nil
+	}
+	if result.IsError {
+		return "", sessionID, result.usage(), fmt.Errorf("claude error: %s", result.Result)
 	}
 
-	return resp.Result, resp.SessionID, nil
+	return result.Result, sessionID, result.usage(), nil
 }
 
 // IsRateLimited checks if an error indicates rate limiting
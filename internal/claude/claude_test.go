@@ -0,0 +1,483 @@
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+)
+
+// writeFakeClaude writes an executable script that prints output and records its args
+func writeFakeClaude(t *testing.T, output string) (command string, argsFile string) {
+	t.Helper()
+	dir := t.TempDir()
+	argsFile = filepath.Join(dir, "args")
+	outFile := filepath.Join(dir, "out")
+	if err := os.WriteFile(outFile, []byte(output), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\ncat " + outFile + "\n"
+	command = filepath.Join(dir, "claude")
+	if err := os.WriteFile(command, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return command, argsFile
+}
+
+func TestRunInteractive_BufferedWithoutOnEvent(t *testing.T) {
+	command, argsFile := writeFakeClaude(t, `{"type":"result","session_id":"sess-1","result":"done"}`)
+	client := NewClient(command, time.Minute)
+
+	output, sessionID, err := client.RunInteractive(context.Background(), RunOptions{Prompt: "hi"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output != "done" || sessionID != "sess-1" {
+		t.Errorf("got output=%q session=%q", output, sessionID)
+	}
+
+	args, _ := os.ReadFile(argsFile)
+	if !strings.Contains(string(args), "--output-format json") {
+		t.Errorf("expected json output format, got args: %s", args)
+	}
+}
+
+func TestRunInteractive_StreamsEvents(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"type":"system","subtype":"init","session_id":"sess-2"}`,
+		`{"type":"assistant","session_id":"sess-2","message":{"content":[{"type":"text","text":"Looking at the code"},{"type":"tool_use","name":"Read","input":{}}]}}`,
+		`{"type":"user","session_id":"sess-2","message":{"content":[{"type":"tool_result","content":"file"}]}}`,
+		`{"type":"result","subtype":"success","session_id":"sess-2","result":"All done","is_error":false}`,
+	}, "\n")
+	command, argsFile := writeFakeClaude(t, stream)
+	client := NewClient(command, time.Minute)
+
+	var events []string
+	output, sessionID, err := client.RunInteractive(context.Background(), RunOptions{
+		Prompt: "hi",
+		OnEvent: func(eventType, content string) {
+			events = append(events, eventType+":"+content)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output != "All done" || sessionID != "sess-2" {
+		t.Errorf("got output=%q session=%q", output, sessionID)
+	}
+
+	expected := []string{"assistant:Looking at the code", "tool_use:Read", "result:All done"}
+	if strings.Join(events, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected events %v, got %v", expected, events)
+	}
+
+	args, _ := os.ReadFile(argsFile)
+	if !strings.Contains(string(args), "--output-format stream-json") || !strings.Contains(string(args), "--verbose") {
+		t.Errorf("expected stream-json args, got: %s", args)
+	}
+}
+
+func TestRunInteractive_StreamContentShapes(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"type":"assistant","session_id":"sess-4","message":{"content":"NO_QUESTIONS_NEEDED"}}`,
+		`{"type":"assistant","session_id":"sess-4","message":{"content":[{"type":"text","text":"First"},{"type":"tool_use","name":"Edit","input":{}},{"type":"text","text":"Second"}]}}`,
+		`{"type":"assistant","session_id":"sess-4","message":{"content":""}}`,
+		`{"type":"result","subtype":"success","session_id":"sess-4","result":"done","is_error":false}`,
+	}, "\n")
+	command, _ := writeFakeClaude(t, stream)
+	client := NewClient(command, time.Minute)
+
+	var events []string
+	output, _, err := client.RunInteractive(context.Background(), RunOptions{
+		Prompt: "hi",
+		OnEvent: func(eventType, content string) {
+			events = append(events, eventType+":"+content)
+		},
+	})
+	if err != nil || output != "done" {
+		t.Fatalf("got output=%q err=%v", output, err)
+	}
+
+	expected := []string{"assistant:NO_QUESTIONS_NEEDED", "assistant:First\nSecond", "tool_use:Edit", "result:done"}
+	if strings.Join(events, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected events %q, got %q", expected, events)
+	}
+}
+
+func TestMessageContent_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    string
+		wantErr bool
+	}{
+		{"string", `"plain text"`, "plain text", false},
+		{"blocks", `[{"type":"text","text":"a"},{"type":"tool_use","name":"Read"},{"type":"text","text":"b"}]`, "a\nb", false},
+		{"null", `null`, "", false},
+		{"number", `42`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var content messageContent
+			err := json.Unmarshal([]byte(tt.json), &content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := content.text(); got != tt.want {
+				t.Errorf("expected text %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRunInteractive_StreamError(t *testing.T) {
+	command, _ := writeFakeClaude(t, `{"type":"result","session_id":"sess-3","result":"boom","is_error":true}`)
+	client := NewClient(command, time.Minute)
+
+	_, _, err := client.RunInteractive(context.Background(), RunOptions{
+		Prompt:  "hi",
+		OnEvent: func(eventType, content string) {},
+	})
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected stream error, got %v", err)
+	}
+}
+
+func TestRunInteractive_ModelArg(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientModel  string
+		runModel     string
+		wantModelArg string
+	}{
+		{"no model", "", "", ""},
+		{"client default", "sonnet", "", "--model sonnet"},
+		{"run override", "sonnet", "opus", "--model opus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, argsFile := writeFakeClaude(t, `{"type":"result","result":"done"}`)
+			client := NewClient(command, time.Minute)
+			client.SetModel(tt.clientModel)
+
+			if _, _, err := client.RunInteractive(context.Background(), RunOptions{Prompt: "hi", Model: tt.runModel}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			args, _ := os.ReadFile(argsFile)
+			if tt.wantModelArg == "" {
+				if strings.Contains(string(args), "--model") {
+					t.Errorf("expected no --model arg, got args: %s", args)
+				}
+				return
+			}
+			if strings.Count(string(args), "--model") != 1 || !strings.Contains(string(args), tt.wantModelArg) {
+				t.Errorf("expected %q once, got args: %s", tt.wantModelArg, args)
+			}
+		})
+	}
+}
+
+func TestRunInteractive_AppendSystemPrompt(t *testing.T) {
+	command, argsFile := writeFakeClaude(t, `{"type":"result","result":"done"}`)
+	promptFile := filepath.Join(t.TempDir(), "system.md")
+	if err := os.WriteFile(promptFile, []byte("Use conventional commits.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient(command, time.Minute)
+	client.SetAppendSystemPromptFile(promptFile)
+
+	if _, _, err := client.RunInteractive(context.Background(), RunOptions{Prompt: "hi"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	args, _ := os.ReadFile(argsFile)
+	if !strings.Contains(string(args), "--append-system-prompt Use conventional commits.") {
+		t.Errorf("expected system prompt in args, got: %s", args)
+	}
+}
+
+func TestRunInteractive_AppendSystemPromptMissingFile(t *testing.T) {
+	command, _ := writeFakeClaude(t, `{"type":"result","result":"done"}`)
+	client := NewClient(command, time.Minute)
+	client.SetAppendSystemPromptFile(filepath.Join(t.TempDir(), "missing.md"))
+
+	_, _, err := client.RunInteractive(context.Background(), RunOptions{Prompt: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "append_system_prompt") {
+		t.Fatalf("expected error about the missing file, got %v", err)
+	}
+}
+
+func TestRunInteractive_ResumesSession(t *testing.T) {
+	command, argsFile := writeFakeClaude(t, `{"type":"result","session_id":"sess-1","result":"done"}`)
+	client := NewClient(command, time.Minute)
+
+	if _, _, err := client.RunInteractive(context.Background(), RunOptions{Prompt: "hi", SessionID: "sess-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	args, _ := os.ReadFile(argsFile)
+	if !strings.Contains(string(args), "--resume sess-1") {
+		t.Errorf("expected --resume in args, got: %s", args)
+	}
+}
+
+func TestRunInteractive_MissingSessionStartsFresh(t *testing.T) {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+for arg in "$@"; do
+  if [ "$arg" = "--resume" ]; then
+    echo "No conversation found with session ID: gone" >&2
+    exit 1
+  fi
+done
+echo '{"type":"result","session_id":"sess-2","result":"done"}'
+`
+	command := filepath.Join(dir, "claude")
+	if err := os.WriteFile(command, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(command, time.Minute)
+
+	output, sessionID, err := client.RunInteractive(context.Background(), RunOptions{Prompt: "hi", SessionID: "gone"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output != "done" || sessionID != "sess-2" {
+		t.Errorf("got output=%q sessionID=%q", output, sessionID)
+	}
+}
+
+func TestRunInteractive_AttemptHooksStopRetrying(t *testing.T) {
+	dir := t.TempDir()
+	command := filepath.Join(dir, "claude")
+	if err := os.WriteFile(command, []byte("#!/bin/sh\necho '503 service unavailable' >&2\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	client := NewClientWithRetry(command, time.Minute, config.RetryConfig{BackoffBase: time.Millisecond})
+
+	failures := 0
+	ctx := WithAttemptHooks(context.Background(), AttemptHooks{
+		OnFailure: func(err error) error {
+			failures++
+			if failures == 2 {
+				return fmt.Errorf("%w: %v", ErrTooManyFailures, err)
+			}
+			return nil
+		},
+	})
+
+	_, _, err := client.RunInteractive(ctx, RunOptions{Prompt: "hi"})
+	if !errors.Is(err, ErrTooManyFailures) {
+		t.Fatalf("expected ErrTooManyFailures, got %v", err)
+	}
+	if failures != 2 {
+		t.Errorf("expected retries to stop after 2 failures, got %d", failures)
+	}
+}
+
+func TestRunInteractive_ReportsUsage(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		onEvent func(eventType, content string)
+	}{
+		{
+			name:   "buffered",
+			output: `{"type":"result","result":"done","cost_usd":0.25,"usage":{"input_tokens":100,"output_tokens":40,"cache_creation_input_tokens":10,"cache_read_input_tokens":5}}`,
+		},
+		{
+			name:    "stream",
+			output:  `{"type":"result","result":"done","total_cost_usd":0.25,"usage":{"input_tokens":100,"output_tokens":40,"cache_creation_input_tokens":10,"cache_read_input_tokens":5}}`,
+			onEvent: func(eventType, content string) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, _ := writeFakeClaude(t, tt.output)
+			client := NewClient(command, time.Minute)
+
+			var got []Usage
+			ctx := WithAttemptHooks(context.Background(), AttemptHooks{
+				OnUsage: func(usage Usage) { got = append(got, usage) },
+			})
+			if _, _, err := client.RunInteractive(ctx, RunOptions{Prompt: "hi", OnEvent: tt.onEvent}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want := Usage{InputTokens: 115, OutputTokens: 40, CostUSD: 0.25}
+			if len(got) != 1 || got[0] != want {
+				t.Errorf("expected usage %+v, got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestRunInteractive_NoUsageReported(t *testing.T) {
+	command, _ := writeFakeClaude(t, `{"type":"result","result":"done"}`)
+	client := NewClient(command, time.Minute)
+
+	called := false
+	ctx := WithAttemptHooks(context.Background(), AttemptHooks{
+		OnUsage: func(Usage) { called = true },
+	})
+	if _, _, err := client.RunInteractive(ctx, RunOptions{Prompt: "hi"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if called {
+		t.Error("expected OnUsage not to be called without usage")
+	}
+}
+
+func TestRunInteractive_MaxConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	running := filepath.Join(dir, "running")
+	counts := filepath.Join(dir, "counts")
+	if err := os.Mkdir(running, 0755); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\ntouch " + running + "/$$\nls " + running + " | wc -l >> " + counts +
+		"\nsleep 0.2\nrm " + running + "/$$\necho '{\"type\":\"result\",\"result\":\"done\"}'\n"
+	command := filepath.Join(dir, "claude")
+	if err := os.WriteFile(command, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(command, time.Minute)
+	client.SetMaxConcurrent(2)
+
+	errs := make(chan error, 6)
+	for i := 0; i < 6; i++ {
+		go func() {
+			_, _, err := client.RunInteractive(context.Background(), RunOptions{Prompt: "hi"})
+			errs <- err
+		}()
+	}
+	for i := 0; i < 6; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	data, _ := os.ReadFile(counts)
+	seen := strings.Fields(string(data))
+	if len(seen) != 6 {
+		t.Fatalf("expected 6 runs, got %d", len(seen))
+	}
+	for _, n := range seen {
+		if n != "1" && n != "2" {
+			t.Errorf("expected at most 2 concurrent runs, saw %s", n)
+		}
+	}
+}
+
+func TestRunInteractive_MaxConcurrentWaitHonoursContext(t *testing.T) {
+	client := NewClient("true", time.Minute)
+	client.SetMaxConcurrent(1)
+	release, err := client.acquireSlot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, _, err := client.RunInteractive(ctx, RunOptions{Prompt: "hi"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the wait for a slot to end with the context, got %v", err)
+	}
+}
+
+// writeFlakyClaude writes a script that fails with failure on its first failures runs,
+// then succeeds; it returns the command and a file with one line per run
+func writeFlakyClaude(t *testing.T, failures int, failure string) (command string, runsFile string) {
+	t.Helper()
+	dir := t.TempDir()
+	runsFile = filepath.Join(dir, "runs")
+	script := fmt.Sprintf(`#!/bin/sh
+echo run >> %s
+if [ "$(wc -l < %s)" -le %d ]; then
+%s
+fi
+echo '{"type":"result","session_id":"sess-1","result":"done"}'
+`, runsFile, runsFile, failures, failure)
+	command = filepath.Join(dir, "claude")
+	if err := os.WriteFile(command, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return command, runsFile
+}
+
+func countRuns(t *testing.T, runsFile string) int {
+	t.Helper()
+	data, _ := os.ReadFile(runsFile)
+	return strings.Count(string(data), "\n")
+}
+
+func TestNewClientWithRetry_RetriesTransientFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		failure string
+	}{
+		{"overloaded", time.Minute, "echo 'API error: Overloaded' >&2; exit 1"},
+		{"server error", time.Minute, "echo '503 service unavailable' >&2; exit 1"},
+		{"timeout", 500 * time.Millisecond, "exec sleep 5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, runsFile := writeFlakyClaude(t, 2, tt.failure)
+			client := NewClientWithRetry(command, tt.timeout, config.RetryConfig{
+				MaxAttempts:    5,
+				BackoffBase:    time.Millisecond,
+				RateLimitRetry: time.Millisecond,
+			})
+
+			output, sessionID, err := client.RunInteractive(context.Background(), RunOptions{Prompt: "hi"})
+			if err != nil {
+				t.Fatalf("expected success after transient failures, got %v", err)
+			}
+			if output != "done" || sessionID != "sess-1" {
+				t.Errorf("got output=%q session=%q", output, sessionID)
+			}
+			if runs := countRuns(t, runsFile); runs != 3 {
+				t.Errorf("expected 3 runs, got %d", runs)
+			}
+		})
+	}
+}
+
+func TestNewClientWithRetry_StopsOnPermanentFailure(t *testing.T) {
+	command, runsFile := writeFlakyClaude(t, 2, "echo 'invalid API key' >&2; exit 1")
+	client := NewClientWithRetry(command, time.Minute, config.RetryConfig{MaxAttempts: 5, BackoffBase: time.Millisecond})
+
+	_, _, err := client.RunInteractive(context.Background(), RunOptions{Prompt: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "invalid API key") {
+		t.Fatalf("expected the permanent error, got %v", err)
+	}
+	if runs := countRuns(t, runsFile); runs != 1 {
+		t.Errorf("expected no retries, got %d runs", runs)
+	}
+}
+
+func TestNewClientWithRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	command, runsFile := writeFlakyClaude(t, 10, "echo '502 bad gateway' >&2; exit 1")
+	client := NewClientWithRetry(command, time.Minute, config.RetryConfig{MaxAttempts: 3, BackoffBase: time.Millisecond})
+
+	if _, _, err := client.RunInteractive(context.Background(), RunOptions{Prompt: "hi"}); err == nil {
+		t.Fatal("expected an error once attempts run out")
+	}
+	if runs := countRuns(t, runsFile); runs != 3 {
+		t.Errorf("expected 3 attempts, got %d runs", runs)
+	}
+}
@@ -8,16 +8,23 @@ import (
 // Prompts contains all the prompt templates used by the orchestrator
 var Prompts = struct {
 	AnalyzeIssue     string
+	FollowUp         string // Follow-up questions after the user answered a round
+	CreatePlan       string // Plan from the issue and Q&A when no plan file exists yet
 	ReviewPlan       string
 	ReviewCode       string
 	Implement        string
 	ImplementGit     string // Implementation with git commit/push to branch
+	ChooseBranch     string // Branch step of ImplementGit when Claude picks the name
+	UseBranch        string // Branch step of ImplementGit when git.branch_template set the name
 	FixCI            string
+	FixHook          string // A pre_pr hook command failed before the PR was created
 	SummarizeChanges string
 }{
-	AnalyzeIssue: `Analyze this issue and decide if you need clarifying questions.
+	AnalyzeIssue: UntrustedNotice + `Analyze this issue and decide if you need clarifying questions.
+
+Issue Title:
+%s
 
-Issue Title: %s
 Issue Body:
 %s
 
@@ -52,22 +59,60 @@ Then write your implementation plan to .ultra-engineer/plan.md with:
 - Step-by-step approach
 - Testing approach`,
 
-	ReviewPlan: `/review the plan at .ultra-engineer/plan.md and fix all issues`,
+	FollowUp: UntrustedNotice + `Review the answers to your clarifying questions for this issue.
+
+Issue Title:
+%s
+
+Issue Body:
+%s
+
+Questions and answers so far:
+%s
+
+Update the implementation plan at .ultra-engineer/plan.md to reflect the answers.
+
+If the answers leave ambiguities that would block a good implementation, write follow-up questions
+to .ultra-engineer/questions.md using the same format as before: numbered questions with lettered
+options, the recommended option marked "(Recommended)", and blank lines between options.
+Do not repeat questions that have already been answered.
+
+If no further questions are needed, write "NO_QUESTIONS_NEEDED" to .ultra-engineer/questions.md`,
+
+	CreatePlan: UntrustedNotice + `Write an implementation plan for this issue.
+
+Issue Title:
+%s
+
+Issue Body:
+%s
+
+Questions and answers so far:
+%s
+
+Write the plan to .ultra-engineer/plan.md with:
+- Overview
+- Files to create/modify
+- Step-by-step approach
+- Testing approach`,
+
+	ReviewPlan: `/review the plan at .ultra-engineer/plan.md and fix all issues (review %d/%d)`,
 
-	ReviewCode: `/review the code and fix all issues`,
+	ReviewCode: `/review the code and fix all issues (review %d/%d)`,
 
 	Implement: `Implement the plan from .ultra-engineer/plan.md`,
 
-	ImplementGit: `Implement the plan from .ultra-engineer/plan.md
+	ImplementGit: UntrustedNotice + `Implement the plan from .ultra-engineer/plan.md
+
+Issue #%d:
+%s
 
-Issue #%d: %s
 Base branch: %s
 
 After implementing the code changes:
 
 ## 1. Create a branch
-Choose a descriptive branch name based on the issue (e.g., feat/add-user-auth, fix/login-timeout).
-- git checkout -b <your-branch-name>
+%s
 
 ## 2. Commit your changes
 Write meaningful commit messages:
@@ -100,12 +145,17 @@ Example:
   MERGE_CONFLICT_UNRESOLVED: <comma-separated list of files>
 
 ## 4. Push the branch
-- git push -u origin <your-branch-name>
+- git push -u origin %s
 - If push fails due to remote changes, fetch/rebase and retry
 
-Output "IMPLEMENTATION_COMPLETE <branch-name>" when done.`,
+Output "IMPLEMENTATION_COMPLETE %s" when done.`,
+
+	ChooseBranch: `Choose a descriptive branch name based on the issue (e.g., feat/add-user-auth, fix/login-timeout).
+- git checkout -b <your-branch-name>`,
+	UseBranch: `Use the branch name %s; do not choose another one.
+- git checkout -b %s (or git checkout %s if it already exists)`,
 
-	FixCI: `CI has failed. Analyze the failure and fix the code.
+	FixCI: UntrustedNotice + `CI has failed. Analyze the failure and fix the code.
 
 ## CI Failure Details
 
@@ -123,6 +173,25 @@ Output "IMPLEMENTATION_COMPLETE <branch-name>" when done.`,
 6. Commit with message describing the fix
 7. Push to branch: git push origin %s
 
+Output "FIX_COMPLETE" when done, or "FIX_FAILED: <reason>" if unable to fix.`,
+
+	FixHook: UntrustedNotice + `A check that must pass before the pull request is opened has failed. Analyze the failure and fix the code.
+
+## Check Failure Details
+
+**Command:** %s
+**Output:**
+%s
+
+## Instructions
+
+1. Analyze the output carefully
+2. Identify the root cause (test failure, lint error, build error, etc.)
+3. Make the necessary code changes
+4. Do NOT skip or delete tests, and do not change the check itself - fix the underlying issue
+5. Stage changes: git add -A
+6. Commit with message describing the fix
+
 Output "FIX_COMPLETE" when done, or "FIX_FAILED: <reason>" if unable to fix.`,
 
 	SummarizeChanges: `Summarize the code changes for a PR description.
@@ -158,7 +227,7 @@ func FormatQAHistory(qa []QAEntry) string {
 	for i, entry := range qa {
 		sb.WriteString(fmt.Sprintf("Round %d:\n", i+1))
 		sb.WriteString(fmt.Sprintf("Questions:\n%s\n", entry.Questions))
-		sb.WriteString(fmt.Sprintf("Answers:\n%s\n\n", entry.Answers))
+		sb.WriteString(fmt.Sprintf("Answers:\n%s\n\n", WrapUntrusted("user answers", entry.Answers)))
 	}
 	return sb.String()
 }
@@ -176,13 +245,60 @@ func FormatQuestionsForComment(questions string, roundNum int) string {
 	return sb.String()
 }
 
+// FormatQuestionRoundsForComment formats the current round of questions like
+// FormatQuestionsForComment, followed by the earlier rounds and their answers in a
+// collapsed section, for a questions comment that is edited each round
+func FormatQuestionRoundsForComment(questions string, roundNum int, history []QAEntry) string {
+	body := FormatQuestionsForComment(questions, roundNum)
+	if len(history) == 0 {
+		return body
+	}
+
+	var sb strings.Builder
+	sb.WriteString(body)
+	sb.WriteString(fmt.Sprintf("\n<details>\n<summary>Earlier rounds (%d)</summary>\n\n", len(history)))
+	for i, entry := range history {
+		sb.WriteString(fmt.Sprintf("### Round %d\n\n%s\n\n", i+1, strings.TrimSpace(entry.Questions)))
+		sb.WriteString(fmt.Sprintf("**Answers:**\n\n%s\n\n", strings.TrimSpace(entry.Answers)))
+	}
+	sb.WriteString("</details>\n")
+	return sb.String()
+}
+
+// Delimiters of the plan in a comment written by FormatPlanForComment
+const (
+	planCommentHeader = "## Implementation Plan\n\n"
+	planCommentFooter = "\n\n---\nReply `/approve`"
+)
+
 // FormatPlanForComment formats the plan for posting as an issue comment
 func FormatPlanForComment(plan string, reviewCount int) string {
 	var sb strings.Builder
-	sb.WriteString("## Implementation Plan\n\n")
+	sb.WriteString(planCommentHeader)
 	sb.WriteString(fmt.Sprintf("*Reviewed %d times*\n\n", reviewCount))
 	sb.WriteString(plan)
-	sb.WriteString("\n\n---\n")
-	sb.WriteString("Reply `/approve` to proceed with implementation, or provide feedback to request changes.\n")
+	sb.WriteString(planCommentFooter)
+	sb.WriteString(" to proceed with implementation, or provide feedback to request changes.\n")
 	return sb.String()
 }
+
+// ParsePlanFromComment extracts the plan from a comment written by FormatPlanForComment
+func ParsePlanFromComment(body string) (string, bool) {
+	rest, ok := strings.CutPrefix(body, planCommentHeader)
+	if !ok {
+		return "", false
+	}
+	end := strings.LastIndex(rest, planCommentFooter)
+	if end < 0 {
+		return "", false
+	}
+	rest = rest[:end]
+
+	// Drop the "*Reviewed N times*" line
+	if strings.HasPrefix(rest, "*Reviewed ") {
+		if _, after, found := strings.Cut(rest, "\n\n"); found {
+			rest = after
+		}
+	}
+	return strings.TrimSpace(rest), true
+}
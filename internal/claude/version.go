@@ -0,0 +1,78 @@
+package claude
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// versionTimeout bounds `claude --version`, which should return almost immediately
+const versionTimeout = 30 * time.Second
+
+var versionRegex = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// Version runs `claude --version` and returns the version number it reports
+func (c *Client) Version(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, versionTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, c.command, "--version").Output()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) || errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("claude CLI not found (command %q): install Claude Code or set claude.command", c.command)
+		}
+		return "", fmt.Errorf("failed to run %s --version: %w", c.command, err)
+	}
+
+	version := parseVersion(string(out))
+	if version == "" {
+		return "", fmt.Errorf("could not parse claude version from %q", strings.TrimSpace(string(out)))
+	}
+	return version, nil
+}
+
+// CheckVersion verifies the CLI is installed and at least minVersion (if set)
+func (c *Client) CheckVersion(ctx context.Context, minVersion string) (string, error) {
+	version, err := c.Version(ctx)
+	if err != nil {
+		return "", err
+	}
+	if minVersion != "" && compareVersions(version, minVersion) < 0 {
+		return version, fmt.Errorf("claude CLI version %s is older than required claude.min_version %s", version, minVersion)
+	}
+	return version, nil
+}
+
+// parseVersion extracts the first dotted version number from output like "1.0.33 (Claude Code)"
+func parseVersion(output string) string {
+	return versionRegex.FindString(output)
+}
+
+// compareVersions compares dotted numeric versions, returning -1, 0 or 1.
+// Missing components count as zero, so "1.2" equals "1.2.0".
+func compareVersions(a, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,71 @@
+package claude
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"1.0.33 (Claude Code)\n", "1.0.33"},
+		{"claude version 2.1.0", "2.1.0"},
+		{"v1.2", "1.2"},
+		{"unknown", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			if got := parseVersion(tt.output); got != tt.want {
+				t.Errorf("parseVersion(%q) = %q, want %q", tt.output, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.33", "1.0.33", 0},
+		{"1.0.33", "1.0.4", 1},
+		{"1.0.4", "1.0.33", -1},
+		{"2.0", "1.9.9", 1},
+		{"1.2", "1.2.0", 0},
+	}
+
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	command, _ := writeFakeClaude(t, "1.0.33 (Claude Code)")
+	client := NewClient(command, time.Minute)
+
+	version, err := client.CheckVersion(context.Background(), "1.0.0")
+	if err != nil || version != "1.0.33" {
+		t.Fatalf("expected version 1.0.33, got %q, %v", version, err)
+	}
+
+	if _, err := client.CheckVersion(context.Background(), "2.0.0"); err == nil || !strings.Contains(err.Error(), "min_version") {
+		t.Errorf("expected min_version error, got %v", err)
+	}
+}
+
+func TestCheckVersion_NotInstalled(t *testing.T) {
+	client := NewClient(filepath.Join(t.TempDir(), "missing-claude"), time.Minute)
+
+	_, err := client.CheckVersion(context.Background(), "")
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
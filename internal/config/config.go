@@ -1,20 +1,29 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"net/url"
 	"os"
 	"regexp"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Provider     string        `yaml:"provider"`
-	PollInterval time.Duration `yaml:"poll_interval"`
-	TriggerLabel string        `yaml:"trigger_label"`
-	LogFile      string        `yaml:"log_file"`
-	Repos        []string      `yaml:"repos"`
-	AllowedUsers []string      `yaml:"allowed_users"`
+	Provider           string        `yaml:"provider"`
+	PollInterval       time.Duration `yaml:"poll_interval"`
+	TriggerLabel       string        `yaml:"trigger_label"`
+	TriggerLabels      []string      `yaml:"trigger_labels"`       // Replaces trigger_label when set
+	TriggerLabelPrefix string        `yaml:"trigger_label_prefix"` // Any label with this prefix triggers processing
+	LogFile            string        `yaml:"log_file"`
+	LogFormat          string        `yaml:"log_format"` // "text" | "json" (default: "text")
+	Repos              []string      `yaml:"repos"`
+	AllowedUsers       []string      `yaml:"allowed_users"`
+	DryRun             bool          `yaml:"-"` // Set by --dry-run; never read from the config file
+	UnknownFields      []string      `yaml:"-"` // Keys in the config file that match no setting; filled in by Load
 
 	Gitea  GiteaConfig  `yaml:"gitea"`
 	GitHub GitHubConfig `yaml:"github"`
@@ -26,15 +35,46 @@ type Config struct {
 	Concurrency ConcurrencyConfig `yaml:"concurrency"`
 	Progress    ProgressConfig    `yaml:"progress"`
 	CI          CIConfig          `yaml:"ci"`
+	Approval    ApprovalConfig    `yaml:"approval"`
+	State       StateConfig       `yaml:"state"`
+	Sandbox     SandboxConfig     `yaml:"sandbox"`
+	Git         GitConfig         `yaml:"git"`
+	Hooks       HooksConfig       `yaml:"hooks"`
+	Labels      LabelsConfig      `yaml:"labels"`
 }
 
 type GiteaConfig struct {
-	URL   string `yaml:"url"`
-	Token string `yaml:"token"`
+	URL         string        `yaml:"url"`
+	Token       string        `yaml:"token"`
+	HTTPTimeout time.Duration `yaml:"http_timeout"` // Timeout of a single API request (default: 30s)
+	CACert      string        `yaml:"ca_cert"`      // PEM file of CA certificates; API calls trust them in addition to the system ones, git trusts only them
+	Proxy       string        `yaml:"proxy"`        // Proxy URL (default: HTTPS_PROXY/HTTP_PROXY/NO_PROXY from the environment)
 }
 
 type GitHubConfig struct {
-	Token string `yaml:"token"`
+	Token  string `yaml:"token"`
+	Host   string `yaml:"host"`    // GitHub Enterprise hostname, e.g. github.example.com (default: github.com)
+	APIURL string `yaml:"api_url"` // GitHub Enterprise API URL, e.g. https://github.example.com/api/v3; sets the host when host is empty
+}
+
+// Hostname returns the GitHub Enterprise host the gh CLI should talk to, or "" for github.com
+func (c GitHubConfig) Hostname() string {
+	host := c.Host
+	if host == "" && c.APIURL != "" {
+		if u, err := url.Parse(c.APIURL); err == nil {
+			host = u.Hostname()
+		}
+	}
+	host = strings.ToLower(host)
+	if host == "github.com" || host == "api.github.com" {
+		return ""
+	}
+	return host
+}
+
+// LabelsConfig styles the labels the bot creates when a repository doesn't have them yet
+type LabelsConfig struct {
+	Colors map[string]string `yaml:"colors"` // Label name -> hex color, e.g. "phase:failed": "b60205" (default: a color per phase)
 }
 
 type GitLabConfig struct {
@@ -46,17 +86,89 @@ type ClaudeConfig struct {
 	Command      string        `yaml:"command"`
 	Timeout      time.Duration `yaml:"timeout"`
 	ReviewCycles int           `yaml:"review_cycles"`
+	MaxQARounds  int           `yaml:"max_qa_rounds"` // Max question rounds before planning anyway (default: 3, 0 = unlimited)
+
+	PlanReviewCycles int `yaml:"plan_review_cycles"` // Plan review iterations (default: review_cycles)
+	CodeReviewCycles int `yaml:"code_review_cycles"` // Code review iterations (default: review_cycles)
+
+	MaxConsecutiveFailures int `yaml:"max_consecutive_failures"` // Failed invocations in a row before the issue fails (default: 10, 0 = unlimited)
+	MaxConcurrent          int `yaml:"max_concurrent"`           // Claude processes running at once across all issues (default: 0 = unlimited)
+
+	MaxCostPerIssue float64 `yaml:"max_cost_per_issue"` // US dollars Claude may spend on one issue before it fails (default: 0 = unlimited)
+	MaxCostPerDay   float64 `yaml:"max_cost_per_day"`   // US dollars Claude may spend per day before new issues wait (default: 0 = unlimited)
+
+	AllowedTools AllowedToolsConfig `yaml:"allowed_tools"`
+
+	Model  string            `yaml:"model"`  // Model for every phase (default: Claude CLI default)
+	Models PhaseModelsConfig `yaml:"models"` // Per-phase model overrides
+
+	AppendSystemPrompt string `yaml:"append_system_prompt"` // Path to a file appended to Claude's system prompt on every run
+	MinVersion         string `yaml:"min_version"`          // Minimum Claude CLI version checked at startup (default: any)
+}
+
+// PlanReviews returns the number of plan review iterations
+func (c ClaudeConfig) PlanReviews() int {
+	if c.PlanReviewCycles > 0 {
+		return c.PlanReviewCycles
+	}
+	return c.ReviewCycles
+}
+
+// CodeReviews returns the number of code review iterations
+func (c ClaudeConfig) CodeReviews() int {
+	if c.CodeReviewCycles > 0 {
+		return c.CodeReviewCycles
+	}
+	return c.ReviewCycles
+}
+
+// PhaseModelsConfig overrides claude.model for individual phases.
+// Empty values fall back to claude.model.
+type PhaseModelsConfig struct {
+	QA             string `yaml:"qa"`
+	Planning       string `yaml:"planning"`
+	Implementation string `yaml:"implementation"`
+	Review         string `yaml:"review"` // Code review and PR feedback
+	FixCI          string `yaml:"fix_ci"`
+}
+
+// AllowedToolsConfig overrides the tools Claude may use in each phase.
+// A phase left unset keeps its built-in default; an empty list allows no tools.
+type AllowedToolsConfig struct {
+	QA             []string `yaml:"qa"`             // Default: Read, Write, Glob, Grep
+	Planning       []string `yaml:"planning"`       // Default: Read, Write, Edit
+	Implementation []string `yaml:"implementation"` // Default: Read, Write, Edit, Bash, Glob, Grep
+	Review         []string `yaml:"review"`         // Code review and PR feedback (default: same as implementation)
+	FixCI          []string `yaml:"fix_ci"`         // Default: same as implementation
 }
 
 type RetryConfig struct {
 	MaxAttempts    int           `yaml:"max_attempts"`
 	BackoffBase    time.Duration `yaml:"backoff_base"`
 	RateLimitRetry time.Duration `yaml:"rate_limit_retry"`
+	BackoffMax     time.Duration `yaml:"backoff_max"`     // Longest backoff between retries, before jitter (default: 5m)
+	Jitter         string        `yaml:"jitter"`          // "" | "none" | "equal" | "full" (default: "" = up to jitter_fraction added to each delay)
+	JitterFraction float64       `yaml:"jitter_fraction"` // Most jitter the default mode adds, as a share of the delay (default: 0.25)
 }
 
 type DefaultsConfig struct {
-	BaseBranch string `yaml:"base_branch"`
-	AutoMerge  bool   `yaml:"auto_merge"`
+	BaseBranch  string `yaml:"base_branch"`
+	AutoMerge   bool   `yaml:"auto_merge"`
+	DraftPR     bool   `yaml:"draft_pr"`     // Open the PR as a draft and mark it ready once CI passes (default: false)
+	MergeMethod string `yaml:"merge_method"` // "merge" | "squash" | "rebase" (default: provider default)
+
+	PRLabels []string `yaml:"pr_labels"` // Labels added to the PR when it is created (default: none)
+
+	MergeWaitTimeout  time.Duration `yaml:"merge_wait_timeout"`  // Max time to wait for a PR to become mergeable per poll (default: 10m, 0 = don't wait)
+	MergePollInterval time.Duration `yaml:"merge_poll_interval"` // How often to check mergeability while waiting (default: 30s)
+
+	IssueTimeout time.Duration `yaml:"issue_timeout"` // Max time one processing pass may take before the issue fails (default: 0 = no limit)
+
+	MaxRetries    int           `yaml:"max_retries"`    // /retry commands accepted per issue (default: 5, 0 = no limit)
+	RetryInterval time.Duration `yaml:"retry_interval"` // Minimum time between accepted /retry commands (default: 5m, 0 = none)
+
+	CloseIssueOnMerge bool   `yaml:"close_issue_on_merge"` // Close the issue after its PR is merged (default: false, rely on "Closes #N")
+	BotUsername       string `yaml:"bot_username"`         // Account assigned to issues while the bot works on them (default: none)
 }
 
 // ConcurrencyConfig controls concurrent issue processing
@@ -64,20 +176,80 @@ type ConcurrencyConfig struct {
 	MaxPerRepo          int    `yaml:"max_per_repo"`         // Maximum concurrent issues per repository (default: 1)
 	MaxTotal            int    `yaml:"max_total"`            // Maximum total concurrent issues (default: 5)
 	DependencyDetection string `yaml:"dependency_detection"` // "auto" | "manual" | "disabled" (default: "auto")
+	MaxIntakePerPoll    int    `yaml:"max_intake_per_poll"`  // Oldest unprocessed issues considered per poll (default: 0 = no limit)
+
+	PriorityLabels map[string]int `yaml:"priority_labels"` // Label -> weight; higher weights are scheduled first (default: priority:high/medium/low)
 }
 
 // ProgressConfig controls progress reporting
 type ProgressConfig struct {
 	Enabled          bool          `yaml:"enabled"`           // Enable progress comments (default: true)
 	DebounceInterval time.Duration `yaml:"debounce_interval"` // Minimum time between updates (default: 60s)
+	HistoryFile      string        `yaml:"history_file"`      // File to persist phase durations for ETA estimates (default: in-memory)
+	CommentFooter    string        `yaml:"comment_footer"`    // Footer added to every bot comment; supports {run_id} and {version} (default: none)
+
+	SingleQuestionComment bool `yaml:"single_question_comment"` // Edit one questions comment each Q&A round instead of posting a new one (default: false)
+
+	// Reactions on user comments; "" turns one off
+	AckReaction     string `yaml:"ack_reaction"`     // Added to every comment the bot acts on (default: "+1")
+	WorkingReaction string `yaml:"working_reaction"` // Added to answers and PR feedback when Claude starts on them (default: none)
+	DoneReaction    string `yaml:"done_reaction"`    // Added to answers and PR feedback when Claude is done with them (default: none)
+}
+
+// Footer renders CommentFooter with the given run ID and version
+func (p ProgressConfig) Footer(runID, version string) string {
+	r := strings.NewReplacer("{run_id}", runID, "{version}", version)
+	return strings.TrimSpace(r.Replace(p.CommentFooter))
 }
 
 // CIConfig controls CI status monitoring
 type CIConfig struct {
-	PollInterval   time.Duration `yaml:"poll_interval"`    // How often to poll CI status (default: 30s)
-	Timeout        time.Duration `yaml:"timeout"`          // Max time to wait for CI (default: 30m)
-	MaxFixAttempts int           `yaml:"max_fix_attempts"` // Max attempts to fix CI failures (default: 3)
-	WaitForCI      bool          `yaml:"wait_for_ci"`      // Whether to wait for CI (default: false, opt-in)
+	PollInterval    time.Duration `yaml:"poll_interval"`     // How often to poll CI status (default: 30s)
+	MaxPollInterval time.Duration `yaml:"max_poll_interval"` // Upper bound the poll interval grows to while CI stays pending (default: 5m)
+	Timeout         time.Duration `yaml:"timeout"`           // Max time to wait for CI (default: 30m)
+	MaxFixAttempts  int           `yaml:"max_fix_attempts"`  // Max attempts to fix CI failures (default: 3)
+	WaitForCI       bool          `yaml:"wait_for_ci"`       // Whether to wait for CI (default: false, opt-in)
+	RequiredOnly    bool          `yaml:"required_only"`     // Only checks required by branch protection block (default: false)
+	MaxLogBytes     int           `yaml:"max_log_bytes"`     // Size the failed check logs sent to Claude are summarized to (default: 32768)
+}
+
+// ApprovalConfig controls which comments count as plan approval
+type ApprovalConfig struct {
+	Phrases           []string `yaml:"phrases"`             // Phrases that approve a plan (default: ["/approve"])
+	RequireExactMatch bool     `yaml:"require_exact_match"` // Whole trimmed comment must equal a phrase (default: true)
+}
+
+// StateConfig controls where issue state is persisted
+type StateConfig struct {
+	Backend string `yaml:"backend"` // "comment" | "file" (default: "comment")
+	Dir     string `yaml:"dir"`     // Directory for the file backend and the daily Claude spend (default: ~/.ultra-engineer/state)
+}
+
+// SandboxConfig controls the per-issue working directories
+type SandboxConfig struct {
+	BaseDir       string        `yaml:"base_dir"`        // Parent directory for sandboxes (default: system temp dir)
+	Reuse         bool          `yaml:"reuse"`           // Fetch from origin when resuming in an existing sandbox (default: use it as-is)
+	MaxAge        time.Duration `yaml:"max_age"`         // Remove sandboxes unused for this long (default: 0 = keep)
+	MaxTotalBytes int64         `yaml:"max_total_bytes"` // Remove least recently used sandboxes above this total size (default: 0 = no limit)
+
+	MaxCloneAttempts int `yaml:"max_clone_attempts"` // Transient clone failures before the issue fails (default: 5, 0 = unlimited)
+}
+
+// GitConfig sets the identity and signing of commits made in sandboxes, and the branch they go to
+type GitConfig struct {
+	AuthorName  string `yaml:"author_name"`  // Commit author and committer name (default: the host's git config)
+	AuthorEmail string `yaml:"author_email"` // Commit author and committer email (default: the host's git config)
+	SignCommits bool   `yaml:"sign_commits"` // Sign commits, for branches that require verified commits (default: false)
+	SigningKey  string `yaml:"signing_key"`  // GPG key ID, or SSH public key (file ending in .pub, or "ssh-ed25519 ...") (default: git's user.signingkey)
+
+	BranchTemplate string `yaml:"branch_template"` // Name of the branch for an issue, e.g. "ue/{issue}-{slug}" (default: "" = Claude chooses)
+}
+
+// HooksConfig sets commands run in the sandbox during the workflow
+type HooksConfig struct {
+	PrePR          []string      `yaml:"pre_pr"`           // Shell commands that must pass before the PR is created, e.g. "go test ./..." (default: none)
+	MaxFixAttempts int           `yaml:"max_fix_attempts"` // Times Claude may fix a failing pre_pr command before the issue fails (default: 3)
+	Timeout        time.Duration `yaml:"timeout"`          // Max run time of each command (default: 10m)
 }
 
 // Default configuration values
@@ -86,39 +258,109 @@ func DefaultConfig() *Config {
 		Provider:     "gitea",
 		PollInterval: 60 * time.Second,
 		TriggerLabel: "ai-implement",
+		LogFormat:    "text",
+		Gitea: GiteaConfig{
+			HTTPTimeout: 30 * time.Second,
+		},
 		Claude: ClaudeConfig{
 			Command:      "claude",
 			Timeout:      30 * time.Minute,
 			ReviewCycles: 5,
+			MaxQARounds:  3,
+
+			MaxConsecutiveFailures: 10,
 		},
 		Retry: RetryConfig{
 			MaxAttempts:    3,
 			BackoffBase:    10 * time.Second,
 			RateLimitRetry: 5 * time.Minute,
+			BackoffMax:     5 * time.Minute,
+			JitterFraction: 0.25,
 		},
 		Defaults: DefaultsConfig{
-			BaseBranch: "main",
-			AutoMerge:  true,
+			BaseBranch:        "main",
+			AutoMerge:         true,
+			MergeWaitTimeout:  10 * time.Minute,
+			MergePollInterval: 30 * time.Second,
+			MaxRetries:        5,
+			RetryInterval:     5 * time.Minute,
 		},
 		Concurrency: ConcurrencyConfig{
 			MaxPerRepo:          5,
 			MaxTotal:            5,
 			DependencyDetection: "auto",
+			PriorityLabels: map[string]int{
+				"priority:high":   10,
+				"priority:medium": 0,
+				"priority:low":    -10,
+			},
 		},
 		Progress: ProgressConfig{
 			Enabled:          true,
 			DebounceInterval: 60 * time.Second,
+			AckReaction:      "+1",
 		},
 		CI: CIConfig{
-			PollInterval:   30 * time.Second,
-			Timeout:        30 * time.Minute,
+			PollInterval:    30 * time.Second,
+			MaxPollInterval: 5 * time.Minute,
+			MaxLogBytes:     32 * 1024,
+			Timeout:         30 * time.Minute,
+			MaxFixAttempts:  3,
+			WaitForCI:       false,
+		},
+		Approval: ApprovalConfig{
+			Phrases:           []string{"/approve"},
+			RequireExactMatch: true,
+		},
+		State: StateConfig{
+			Backend: "comment",
+		},
+		Sandbox: SandboxConfig{
+			MaxCloneAttempts: 5,
+		},
+		Hooks: HooksConfig{
 			MaxFixAttempts: 3,
-			WaitForCI:      false,
+			Timeout:        10 * time.Minute,
 		},
 	}
 }
 
-// Load reads configuration from a YAML file
+// TriggerLabelList returns the labels that trigger processing.
+// trigger_labels takes precedence over the single trigger_label.
+func (c *Config) TriggerLabelList() []string {
+	if len(c.TriggerLabels) > 0 {
+		return c.TriggerLabels
+	}
+	return []string{c.TriggerLabel}
+}
+
+// IsTriggerLabel reports whether label triggers processing, by exact match or prefix
+func (c *Config) IsTriggerLabel(label string) bool {
+	if c.TriggerLabelPrefix != "" && strings.HasPrefix(label, c.TriggerLabelPrefix) {
+		return true
+	}
+	for _, l := range c.TriggerLabelList() {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// Priority returns the scheduling weight of an issue with labels: the highest weight
+// among its priority labels, or 0 if it has none
+func (c ConcurrencyConfig) Priority(labels []string) int {
+	priority, found := 0, false
+	for _, l := range labels {
+		if w, ok := c.PriorityLabels[l]; ok && (!found || w > priority) {
+			priority, found = w, true
+		}
+	}
+	return priority
+}
+
+// Load reads configuration from a YAML file. UE_* environment variables override
+// values from the file (see LoadFromEnv).
 func Load(path string) (*Config, error) {
 	cfg := DefaultConfig()
 
@@ -134,9 +376,36 @@ func Load(path string) (*Config, error) {
 		return nil, err
 	}
 
+	// yaml.Unmarshal ignores unknown keys; a second, strict pass reports them
+	cfg.UnknownFields = unknownFields(data)
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
+// unknownFields returns one message per key in data that matches no setting,
+// e.g. "line 5: field reviewcycles not found in type config.ClaudeConfig"
+func unknownFields(data []byte) []string {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var typeErr *yaml.TypeError
+	if err := dec.Decode(DefaultConfig()); !errors.As(err, &typeErr) {
+		return nil
+	}
+
+	var fields []string
+	for _, msg := range typeErr.Errors {
+		if strings.Contains(msg, "not found in type") {
+			fields = append(fields, msg)
+		}
+	}
+	return fields
+}
+
 // expandEnvVars replaces ${VAR} patterns with environment variable values
 func expandEnvVars(data []byte) []byte {
 	re := regexp.MustCompile(`\$\{([^}]+)\}`)
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad_ReportsUnknownFields(t *testing.T) {
+	path := writeConfig(t, `provider: github
+claude:
+  reviewcycles: 2
+  timeout: 10m
+bogus_setting: true
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if len(cfg.UnknownFields) != 2 {
+		t.Fatalf("expected 2 unknown fields, got %v", cfg.UnknownFields)
+	}
+	all := strings.Join(cfg.UnknownFields, "\n")
+	for _, key := range []string{"reviewcycles", "bogus_setting"} {
+		if !strings.Contains(all, key) {
+			t.Errorf("expected %q to be reported, got %v", key, cfg.UnknownFields)
+		}
+	}
+
+	// Known keys are still applied
+	if cfg.Claude.Timeout.Minutes() != 10 {
+		t.Errorf("expected claude.timeout 10m, got %v", cfg.Claude.Timeout)
+	}
+}
+
+func TestLoad_NoUnknownFields(t *testing.T) {
+	path := writeConfig(t, "provider: github\nclaude:\n  review_cycles: 2\n")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(cfg.UnknownFields) != 0 {
+		t.Errorf("expected no unknown fields, got %v", cfg.UnknownFields)
+	}
+}
+
+func TestLoadFromEnv(t *testing.T) {
+	t.Setenv("UE_PROVIDER", "github")
+	t.Setenv("UE_GITHUB_TOKEN", "ghp_test")
+	t.Setenv("UE_TRIGGER_LABEL", "bot")
+	t.Setenv("UE_REPOS", "owner/one, owner/two")
+	t.Setenv("UE_CLAUDE_TIMEOUT", "15m")
+	t.Setenv("UE_CLAUDE_REVIEW_CYCLES", "2")
+	t.Setenv("UE_CI_WAIT_FOR_CI", "true")
+	t.Setenv("UE_SANDBOX_MAX_TOTAL_BYTES", "1073741824")
+
+	cfg, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+
+	if cfg.Provider != "github" || cfg.GitHub.Token != "ghp_test" || cfg.TriggerLabel != "bot" {
+		t.Errorf("string settings not applied: provider=%q token=%q label=%q", cfg.Provider, cfg.GitHub.Token, cfg.TriggerLabel)
+	}
+	if len(cfg.Repos) != 2 || cfg.Repos[0] != "owner/one" || cfg.Repos[1] != "owner/two" {
+		t.Errorf("expected two repos, got %v", cfg.Repos)
+	}
+	if cfg.Claude.Timeout != 15*time.Minute || cfg.Claude.ReviewCycles != 2 {
+		t.Errorf("claude settings not applied: timeout=%v cycles=%d", cfg.Claude.Timeout, cfg.Claude.ReviewCycles)
+	}
+	if !cfg.CI.WaitForCI || cfg.Sandbox.MaxTotalBytes != 1<<30 {
+		t.Errorf("bool/int64 settings not applied: wait=%v bytes=%d", cfg.CI.WaitForCI, cfg.Sandbox.MaxTotalBytes)
+	}
+
+	// Unset settings keep their defaults
+	if cfg.PollInterval != DefaultConfig().PollInterval {
+		t.Errorf("expected default poll interval, got %v", cfg.PollInterval)
+	}
+}
+
+func TestLoadFromEnv_InvalidValue(t *testing.T) {
+	t.Setenv("UE_CLAUDE_TIMEOUT", "soon")
+
+	_, err := LoadFromEnv()
+	if err == nil || !strings.Contains(err.Error(), "UE_CLAUDE_TIMEOUT") {
+		t.Errorf("expected error naming UE_CLAUDE_TIMEOUT, got: %v", err)
+	}
+}
+
+func TestLoadFromEnv_Maps(t *testing.T) {
+	t.Setenv("UE_CONCURRENCY_PRIORITY_LABELS", "urgent=10, later=-10")
+	t.Setenv("UE_LABELS_COLORS", "phase:failed=b60205")
+
+	cfg, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if want := map[string]int{"urgent": 10, "later": -10}; !reflect.DeepEqual(cfg.Concurrency.PriorityLabels, want) {
+		t.Errorf("expected priority labels %v, got %v", want, cfg.Concurrency.PriorityLabels)
+	}
+	if want := map[string]string{"phase:failed": "b60205"}; !reflect.DeepEqual(cfg.Labels.Colors, want) {
+		t.Errorf("expected label colors %v, got %v", want, cfg.Labels.Colors)
+	}
+
+	t.Setenv("UE_CONCURRENCY_PRIORITY_LABELS", "urgent=high")
+	if _, err := LoadFromEnv(); err == nil || !strings.Contains(err.Error(), "UE_CONCURRENCY_PRIORITY_LABELS") {
+		t.Errorf("expected error naming UE_CONCURRENCY_PRIORITY_LABELS, got: %v", err)
+	}
+	t.Setenv("UE_CONCURRENCY_PRIORITY_LABELS", "urgent")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Error("expected error for a pair without a value")
+	}
+}
+
+func TestLoad_EnvOverridesFile(t *testing.T) {
+	path := writeConfig(t, "provider: gitea\ntrigger_label: from-file\npoll_interval: 30s\n")
+	t.Setenv("UE_TRIGGER_LABEL", "from-env")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.TriggerLabel != "from-env" {
+		t.Errorf("expected env to override file, got %q", cfg.TriggerLabel)
+	}
+	if cfg.PollInterval != 30*time.Second {
+		t.Errorf("expected file value kept where env is unset, got %v", cfg.PollInterval)
+	}
+}
+
+func TestConcurrencyConfig_Priority(t *testing.T) {
+	cfg := DefaultConfig().Concurrency
+	tests := []struct {
+		labels []string
+		want   int
+	}{
+		{nil, 0},
+		{[]string{"bug"}, 0},
+		{[]string{"priority:high"}, 10},
+		{[]string{"priority:low"}, -10},
+		{[]string{"priority:low", "priority:medium"}, 0},
+	}
+	for _, tt := range tests {
+		if got := cfg.Priority(tt.labels); got != tt.want {
+			t.Errorf("Priority(%v) = %d, want %d", tt.labels, got, tt.want)
+		}
+	}
+}
+
+func TestLoad_PriorityLabelsMergeWithDefaults(t *testing.T) {
+	path := writeConfig(t, "concurrency:\n  priority_labels:\n    urgent: 20\n")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := cfg.Concurrency.Priority([]string{"urgent"}); got != 20 {
+		t.Errorf("expected urgent weight 20, got %d", got)
+	}
+	if got := cfg.Concurrency.Priority([]string{"priority:high"}); got != 10 {
+		t.Errorf("expected default priority:high weight to remain, got %d", got)
+	}
+}
+
+func TestGitHubConfig_Hostname(t *testing.T) {
+	tests := []struct {
+		cfg  GitHubConfig
+		want string
+	}{
+		{GitHubConfig{}, ""},
+		{GitHubConfig{Host: "GitHub.example.com"}, "github.example.com"},
+		{GitHubConfig{APIURL: "https://github.example.com/api/v3"}, "github.example.com"},
+		{GitHubConfig{Host: "ghe.internal", APIURL: "https://github.example.com/api/v3"}, "ghe.internal"},
+		{GitHubConfig{APIURL: "https://api.github.com"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := tt.cfg.Hostname(); got != tt.want {
+			t.Errorf("%+v: expected %q, got %q", tt.cfg, tt.want, got)
+		}
+	}
+}
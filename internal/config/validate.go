@@ -0,0 +1,196 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Providers that can be selected with the provider setting
+var supportedProviders = []string{"github", "gitea"}
+
+// hexColorPattern matches a label color: six hex digits, optionally prefixed with "#"
+var hexColorPattern = regexp.MustCompile(`^#?[0-9a-fA-F]{6}$`)
+
+// branchNameChars matches what a git.branch_template may contain besides its placeholders
+var branchNameChars = regexp.MustCompile(`^[A-Za-z0-9._/-]*$`)
+
+// Reactions both GitHub and Gitea accept on comments; "" turns a reaction off
+var supportedReactions = []string{"", "+1", "-1", "laugh", "confused", "heart", "hooray", "rocket", "eyes"}
+
+// Validate checks the configuration for mistakes that would otherwise surface late
+// or silently fall back to defaults. All problems are reported together, each
+// prefixed with the setting it concerns.
+func (c *Config) Validate() error {
+	var errs []error
+	add := func(field, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+	oneOf := func(field, value string, allowed ...string) {
+		if !slices.Contains(allowed, value) {
+			add(field, "must be one of %q, got %q", allowed, value)
+		}
+	}
+	positive := func(field string, d time.Duration) {
+		if d <= 0 {
+			add(field, "must be positive, got %s", d)
+		}
+	}
+	notNegative := func(field string, n int64) {
+		if n < 0 {
+			add(field, "must not be negative, got %d", n)
+		}
+	}
+	notNegativeDuration := func(field string, d time.Duration) {
+		if d < 0 {
+			add(field, "must not be negative, got %s", d)
+		}
+	}
+	notNegativeCost := func(field string, usd float64) {
+		if usd < 0 {
+			add(field, "must not be negative, got %g", usd)
+		}
+	}
+
+	// Provider and its credentials
+	oneOf("provider", c.Provider, supportedProviders...)
+	if c.Provider == "gitea" {
+		if c.Gitea.URL == "" {
+			add("gitea.url", "is required when provider is gitea")
+		} else if u, err := url.Parse(c.Gitea.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("gitea.url", "must be an http(s) URL, got %q", c.Gitea.URL)
+		}
+		if c.Gitea.Token == "" {
+			add("gitea.token", "is required when provider is gitea")
+		}
+		notNegativeDuration("gitea.http_timeout", c.Gitea.HTTPTimeout)
+		if c.Gitea.Proxy != "" {
+			if u, err := url.Parse(c.Gitea.Proxy); err != nil || u.Scheme == "" || u.Host == "" {
+				add("gitea.proxy", "must be a URL like http://proxy.example.com:3128, got %q", c.Gitea.Proxy)
+			}
+		}
+	}
+	if c.GitHub.Host != "" && strings.ContainsAny(c.GitHub.Host, ":/") {
+		add("github.host", "must be a hostname like github.example.com, got %q", c.GitHub.Host)
+	}
+	if c.GitHub.APIURL != "" {
+		if u, err := url.Parse(c.GitHub.APIURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("github.api_url", "must be an http(s) URL, got %q", c.GitHub.APIURL)
+		}
+	}
+
+	// Core settings
+	positive("poll_interval", c.PollInterval)
+	if c.TriggerLabel == "" && len(c.TriggerLabels) == 0 && c.TriggerLabelPrefix == "" {
+		add("trigger_label", "is required unless trigger_labels or trigger_label_prefix is set")
+	}
+	oneOf("log_format", c.LogFormat, "", "text", "json")
+
+	// Claude
+	if c.Claude.Command == "" {
+		add("claude.command", "is required")
+	}
+	positive("claude.timeout", c.Claude.Timeout)
+	notNegative("claude.review_cycles", int64(c.Claude.ReviewCycles))
+	notNegative("claude.plan_review_cycles", int64(c.Claude.PlanReviewCycles))
+	notNegative("claude.code_review_cycles", int64(c.Claude.CodeReviewCycles))
+	notNegative("claude.max_qa_rounds", int64(c.Claude.MaxQARounds))
+	notNegative("claude.max_consecutive_failures", int64(c.Claude.MaxConsecutiveFailures))
+	notNegative("claude.max_concurrent", int64(c.Claude.MaxConcurrent))
+	notNegativeCost("claude.max_cost_per_issue", c.Claude.MaxCostPerIssue)
+	notNegativeCost("claude.max_cost_per_day", c.Claude.MaxCostPerDay)
+
+	// Retry
+	notNegative("retry.max_attempts", int64(c.Retry.MaxAttempts))
+	positive("retry.backoff_base", c.Retry.BackoffBase)
+	notNegativeDuration("retry.rate_limit_retry", c.Retry.RateLimitRetry)
+	positive("retry.backoff_max", c.Retry.BackoffMax)
+	oneOf("retry.jitter", c.Retry.Jitter, "", "none", "equal", "full")
+	if c.Retry.JitterFraction < 0 || c.Retry.JitterFraction > 1 {
+		add("retry.jitter_fraction", "must be between 0 and 1, got %g", c.Retry.JitterFraction)
+	}
+
+	// Defaults
+	oneOf("defaults.merge_method", c.Defaults.MergeMethod, "", "merge", "squash", "rebase")
+	notNegativeDuration("defaults.merge_wait_timeout", c.Defaults.MergeWaitTimeout)
+	positive("defaults.merge_poll_interval", c.Defaults.MergePollInterval)
+	notNegativeDuration("defaults.issue_timeout", c.Defaults.IssueTimeout)
+	notNegative("defaults.max_retries", int64(c.Defaults.MaxRetries))
+	notNegativeDuration("defaults.retry_interval", c.Defaults.RetryInterval)
+	for i, label := range c.Defaults.PRLabels {
+		if strings.TrimSpace(label) == "" {
+			add(fmt.Sprintf("defaults.pr_labels[%d]", i), "must not be empty")
+		}
+	}
+
+	// Concurrency
+	if c.Concurrency.MaxPerRepo < 1 {
+		add("concurrency.max_per_repo", "must be at least 1, got %d", c.Concurrency.MaxPerRepo)
+	}
+	if c.Concurrency.MaxTotal < 1 {
+		add("concurrency.max_total", "must be at least 1, got %d", c.Concurrency.MaxTotal)
+	}
+	if c.Concurrency.MaxPerRepo > c.Concurrency.MaxTotal {
+		add("concurrency.max_per_repo", "must not exceed concurrency.max_total (%d), got %d", c.Concurrency.MaxTotal, c.Concurrency.MaxPerRepo)
+	}
+	notNegative("concurrency.max_intake_per_poll", int64(c.Concurrency.MaxIntakePerPoll))
+	oneOf("concurrency.dependency_detection", c.Concurrency.DependencyDetection, "", "auto", "manual", "disabled")
+
+	// Progress
+	notNegativeDuration("progress.debounce_interval", c.Progress.DebounceInterval)
+	oneOf("progress.ack_reaction", c.Progress.AckReaction, supportedReactions...)
+	oneOf("progress.working_reaction", c.Progress.WorkingReaction, supportedReactions...)
+	oneOf("progress.done_reaction", c.Progress.DoneReaction, supportedReactions...)
+
+	// CI
+	positive("ci.poll_interval", c.CI.PollInterval)
+	notNegativeDuration("ci.max_poll_interval", c.CI.MaxPollInterval)
+	positive("ci.timeout", c.CI.Timeout)
+	notNegative("ci.max_fix_attempts", int64(c.CI.MaxFixAttempts))
+	notNegative("ci.max_log_bytes", int64(c.CI.MaxLogBytes))
+
+	// State
+	oneOf("state.backend", c.State.Backend, "", "comment", "file")
+
+	// Sandbox
+	notNegativeDuration("sandbox.max_age", c.Sandbox.MaxAge)
+	notNegative("sandbox.max_total_bytes", c.Sandbox.MaxTotalBytes)
+	notNegative("sandbox.max_clone_attempts", int64(c.Sandbox.MaxCloneAttempts))
+
+	// Git
+	if c.Git.AuthorEmail != "" && !strings.Contains(c.Git.AuthorEmail, "@") {
+		add("git.author_email", "must be an email address, got %q", c.Git.AuthorEmail)
+	}
+
+	if t := c.Git.BranchTemplate; t != "" {
+		literal := strings.NewReplacer("{issue}", "", "{slug}", "").Replace(t)
+		switch {
+		case literal == t:
+			add("git.branch_template", "must contain {issue} or {slug}, got %q", t)
+		case !branchNameChars.MatchString(literal), strings.HasPrefix(t, "-"), strings.Contains(t, ".."):
+			add("git.branch_template", "must be a branch name of letters, digits, '.', '_', '/' and '-' around {issue} and {slug}, got %q", t)
+		}
+	}
+
+	// Hooks
+	for i, command := range c.Hooks.PrePR {
+		if strings.TrimSpace(command) == "" {
+			add(fmt.Sprintf("hooks.pre_pr[%d]", i), "must not be empty")
+		}
+	}
+	notNegative("hooks.max_fix_attempts", int64(c.Hooks.MaxFixAttempts))
+	positive("hooks.timeout", c.Hooks.Timeout)
+
+	// Labels
+	for name, color := range c.Labels.Colors {
+		if !hexColorPattern.MatchString(color) {
+			add("labels.colors."+name, "must be a hex color like b60205, got %q", color)
+		}
+	}
+
+	return errors.Join(errs...)
+}
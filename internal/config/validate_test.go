@@ -0,0 +1,115 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func validGitHubConfig() *Config {
+	cfg := DefaultConfig()
+	cfg.Provider = "github"
+	return cfg
+}
+
+func TestValidate_DefaultsAreValid(t *testing.T) {
+	if err := validGitHubConfig().Validate(); err != nil {
+		t.Errorf("expected default github config to be valid, got: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Gitea = GiteaConfig{URL: "https://gitea.example.com", Token: "secret"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected gitea config with URL and token to be valid, got: %v", err)
+	}
+}
+
+func TestValidate_ReportsAllProblemsByField(t *testing.T) {
+	cfg := DefaultConfig() // provider gitea without URL or token
+	cfg.PollInterval = 0
+	cfg.CI.Timeout = -time.Minute
+	cfg.Concurrency.MaxPerRepo = 10
+	cfg.Defaults.MergeMethod = "fast-forward"
+	cfg.Progress.DoneReaction = ":tada:"
+	cfg.Claude.MaxCostPerDay = -5
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, field := range []string{
+		"gitea.url:",
+		"gitea.token:",
+		"poll_interval:",
+		"ci.timeout:",
+		"concurrency.max_per_repo:",
+		"defaults.merge_method:",
+		"progress.done_reaction:",
+		"claude.max_cost_per_day:",
+	} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("expected error to name %s, got:\n%v", field, err)
+		}
+	}
+}
+
+func TestValidate_UnsupportedProvider(t *testing.T) {
+	cfg := validGitHubConfig()
+	cfg.Provider = "gitlab"
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "provider:") {
+		t.Errorf("expected provider error, got: %v", err)
+	}
+}
+
+func TestValidate_GitHubEnterprise(t *testing.T) {
+	cfg := validGitHubConfig()
+	cfg.GitHub = GitHubConfig{Host: "https://github.example.com", APIURL: "github.example.com/api/v3"}
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "github.host: must be a hostname") || !strings.Contains(err.Error(), "github.api_url: must be an http(s) URL") {
+		t.Errorf("expected github.host and github.api_url errors, got: %v", err)
+	}
+}
+
+func TestValidate_LabelColors(t *testing.T) {
+	cfg := validGitHubConfig()
+	cfg.Labels.Colors = map[string]string{"phase:failed": "#b60205", "phase:completed": "green"}
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), `labels.colors.phase:completed: must be a hex color like b60205, got "green"`) || strings.Contains(err.Error(), "phase:failed") {
+		t.Errorf("expected only the named color to be rejected, got: %v", err)
+	}
+}
+
+func TestValidate_GiteaURLMustBeHTTP(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Gitea = GiteaConfig{URL: "gitea.example.com", Token: "secret"}
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "gitea.url: must be an http(s) URL") {
+		t.Errorf("expected gitea.url error, got: %v", err)
+	}
+}
+
+func TestValidate_BranchTemplate(t *testing.T) {
+	for template, valid := range map[string]bool{
+		"ue/{issue}-{slug}":  true,
+		"fix-{issue}":        true,
+		"ue/main":            false, // Same branch for every issue
+		"ue/{issue} {slug}":  false,
+		"ue/{title}-{issue}": false,
+		"-{issue}":           false,
+	} {
+		cfg := validGitHubConfig()
+		cfg.Git.BranchTemplate = template
+		err := cfg.Validate()
+		if valid && err != nil {
+			t.Errorf("expected %q to be valid, got: %v", template, err)
+		}
+		if !valid && (err == nil || !strings.Contains(err.Error(), "git.branch_template:")) {
+			t.Errorf("expected %q to be rejected, got: %v", template, err)
+		}
+	}
+}
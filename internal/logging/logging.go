@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+)
+
+const (
+	// FormatText is the default human-readable log format
+	FormatText = "text"
+	// FormatJSON emits one JSON object per log line
+	FormatJSON = "json"
+
+	textPrefix = "[ultra-engineer] "
+)
+
+// New creates a logger writing to w in the given format
+// Unknown formats fall back to text
+func New(w io.Writer, format string, verbose bool) *log.Logger {
+	if format == FormatJSON {
+		return log.New(newJSONWriter(w), "", 0)
+	}
+
+	flags := log.LstdFlags
+	if verbose {
+		flags |= log.Lshortfile
+	}
+	return log.New(w, textPrefix, flags)
+}
+
+// With returns a logger that attaches key/value fields to every line
+// Text loggers are returned unchanged so human-readable output stays as-is
+func With(logger *log.Logger, args ...any) *log.Logger {
+	jw, ok := logger.Writer().(*jsonWriter)
+	if !ok {
+		return logger
+	}
+	return log.New(&jsonWriter{logger: jw.logger.With(args...)}, "", 0)
+}
+
+// jsonWriter converts each log line written by a log.Logger into a JSON record
+type jsonWriter struct {
+	logger *slog.Logger
+}
+
+func newJSONWriter(w io.Writer) *jsonWriter {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.LevelKey && len(groups) == 0 {
+				return slog.String(slog.LevelKey, strings.ToLower(a.Value.String()))
+			}
+			return a
+		},
+	})
+	return &jsonWriter{logger: slog.New(handler)}
+}
+
+// Write implements io.Writer; log.Logger calls it once per line
+func (j *jsonWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSuffix(string(p), "\n")
+	j.logger.Log(context.Background(), levelFor(msg), msg)
+	return len(p), nil
+}
+
+// levelFor infers a level from the message, matching existing log wording
+func levelFor(msg string) slog.Level {
+	lower := strings.ToLower(msg)
+	switch {
+	case strings.HasPrefix(lower, "error"), strings.HasPrefix(lower, "failed"):
+		return slog.LevelError
+	case strings.HasPrefix(lower, "warning"):
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
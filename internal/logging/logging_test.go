@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNew_TextFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, FormatText, false)
+
+	With(logger, "repo", "owner/repo").Printf("Processing issue #%d", 1)
+
+	out := buf.String()
+	if !strings.HasPrefix(out, textPrefix) {
+		t.Errorf("expected text prefix, got %q", out)
+	}
+	if strings.Contains(out, "owner/repo") {
+		t.Errorf("expected fields to be omitted in text mode, got %q", out)
+	}
+}
+
+func TestNew_JSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, FormatJSON, false)
+
+	issueLogger := With(logger, "repo", "owner/repo", "issue", 12)
+	With(issueLogger, "phase", "planning").Printf("Failed to post plan: %s", "boom")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("output is not JSON: %v (%q)", err, buf.String())
+	}
+
+	if record["level"] != "error" {
+		t.Errorf("expected level error, got %v", record["level"])
+	}
+	if record["msg"] != "Failed to post plan: boom" {
+		t.Errorf("unexpected msg: %v", record["msg"])
+	}
+	if record["repo"] != "owner/repo" || record["issue"] != float64(12) || record["phase"] != "planning" {
+		t.Errorf("missing fields: %v", record)
+	}
+}
+
+func TestLevelFor(t *testing.T) {
+	tests := []struct {
+		msg   string
+		level string
+	}{
+		{"Processing issue #1", "INFO"},
+		{"Error fetching issues", "ERROR"},
+		{"Failed to clone", "ERROR"},
+		{"Warning: rate limited", "WARN"},
+	}
+
+	for _, tt := range tests {
+		if got := levelFor(tt.msg).String(); got != tt.level {
+			t.Errorf("levelFor(%q) = %s, want %s", tt.msg, got, tt.level)
+		}
+	}
+}
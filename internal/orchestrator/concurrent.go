@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/anthropics/ultra-engineer/internal/providers"
 	"github.com/anthropics/ultra-engineer/internal/state"
@@ -47,6 +48,10 @@ type WorkerPool struct {
 	// State tracking for graceful shutdown (protected by mu)
 	activeStates map[string]*state.State // jobID -> current state for persistence
 
+	// Per-job cancellation (protected by mu)
+	jobCancels       map[string]context.CancelCauseFunc // jobID -> cancels that job's context
+	jobCommentsSince map[string]time.Time               // jobID -> state's LastCommentTime when the job started
+
 	// Worker function - set by caller
 	workerFunc func(ctx context.Context, job *Job) error
 }
@@ -56,15 +61,17 @@ func NewWorkerPool(ctx context.Context, maxPerRepo, maxTotal int) *WorkerPool {
 	ctx, cancel := context.WithCancel(ctx)
 
 	return &WorkerPool{
-		maxPerRepo:   maxPerRepo,
-		maxTotal:     maxTotal,
-		jobQueue:     make(chan *Job, maxTotal),
-		results:      make(chan *JobResult, maxTotal),
-		ctx:          ctx,
-		cancel:       cancel,
-		activeJobs:   make(map[string]int),
-		activeStates: make(map[string]*state.State),
-		accepting:    true,
+		maxPerRepo:       maxPerRepo,
+		maxTotal:         maxTotal,
+		jobQueue:         make(chan *Job, maxTotal),
+		results:          make(chan *JobResult, maxTotal),
+		ctx:              ctx,
+		cancel:           cancel,
+		activeJobs:       make(map[string]int),
+		activeStates:     make(map[string]*state.State),
+		jobCancels:       make(map[string]context.CancelCauseFunc),
+		jobCommentsSince: make(map[string]time.Time),
+		accepting:        true,
 	}
 }
 
@@ -105,9 +112,25 @@ func (wp *WorkerPool) processJob(job *Job) {
 	wp.RegisterState(job.JobID(), job.State)
 	defer wp.UnregisterState(job.JobID())
 
+	// Each job gets its own context so it can be cancelled without stopping the others
+	jobCtx, cancel := context.WithCancelCause(wp.ctx)
+	wp.mu.Lock()
+	wp.jobCancels[job.JobID()] = cancel
+	if job.State != nil {
+		wp.jobCommentsSince[job.JobID()] = job.State.LastCommentTime
+	}
+	wp.mu.Unlock()
+	defer func() {
+		wp.mu.Lock()
+		delete(wp.jobCancels, job.JobID())
+		delete(wp.jobCommentsSince, job.JobID())
+		wp.mu.Unlock()
+		cancel(nil)
+	}()
+
 	var err error
 	if wp.workerFunc != nil {
-		err = wp.workerFunc(wp.ctx, job)
+		err = wp.workerFunc(jobCtx, job)
 	}
 
 	// Send result
@@ -218,7 +241,32 @@ func (wp *WorkerPool) Wait() {
 	wp.wg.Wait()
 }
 
+// CancelJob cancels the context of a single in-progress job, with cause as its
+// context.Cause (nil for context.Canceled)
+// Returns false if no worker is currently running the job
+func (wp *WorkerPool) CancelJob(jobID string, cause error) bool {
+	wp.mu.Lock()
+	cancel, ok := wp.jobCancels[jobID]
+	wp.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+	cancel(cause)
+	return true
+}
+
+// CommentsSince returns the job state's LastCommentTime from when the job started.
+// Comments after it arrived while the job was running. The worker updates the state
+// itself, so callers on other goroutines use this instead of reading it.
+func (wp *WorkerPool) CommentsSince(jobID string) time.Time {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	return wp.jobCommentsSince[jobID]
+}
+
 // Cancel cancels all in-progress jobs via context
+// Per-job contexts derive from the pool context, so this also stops every job
 func (wp *WorkerPool) Cancel() {
 	wp.cancel()
 }
@@ -2,6 +2,7 @@ package orchestrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync/atomic"
 	"testing"
@@ -169,6 +170,68 @@ func TestWorkerPoolShutdown(t *testing.T) {
 	}
 }
 
+func TestWorkerPoolCancelJob(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	wp := NewWorkerPool(ctx, 2, 2)
+
+	started := make(chan struct{}, 2)
+	wp.SetWorkerFunc(func(ctx context.Context, job *Job) error {
+		started <- struct{}{}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(300 * time.Millisecond):
+			return nil
+		}
+	})
+
+	wp.Start()
+
+	jobs := []*Job{
+		{Issue: &providers.Issue{Number: 1}, Repository: "repo-a", State: state.NewState()},
+		{Issue: &providers.Issue{Number: 2}, Repository: "repo-a", State: state.NewState()},
+	}
+	for _, job := range jobs {
+		if !wp.TrySubmit(job) {
+			t.Fatalf("failed to submit job %s", job.JobID())
+		}
+	}
+	<-started
+	<-started
+
+	if !wp.CancelJob(jobs[0].JobID(), nil) {
+		t.Fatal("expected CancelJob to find the running job")
+	}
+	if wp.CancelJob("repo-a-999", nil) {
+		t.Error("expected CancelJob to report unknown job")
+	}
+
+	results := make(map[int]error)
+	for i := 0; i < len(jobs); i++ {
+		select {
+		case result := <-wp.Results():
+			wp.OnJobComplete(result.Job.Repository)
+			results[result.Job.Issue.Number] = result.Error
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for result")
+		}
+	}
+
+	if !errors.Is(results[1], context.Canceled) {
+		t.Errorf("expected cancelled job to return context.Canceled, got %v", results[1])
+	}
+	if results[2] != nil {
+		t.Errorf("expected other job to finish normally, got %v", results[2])
+	}
+	if wp.CancelJob(jobs[0].JobID(), nil) {
+		t.Error("expected CancelJob to return false once the job finished")
+	}
+
+	wp.Shutdown()
+}
+
 func TestWorkerPoolStateTracking(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -0,0 +1,146 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/anthropics/ultra-engineer/internal/security"
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+// Comment commands that declare an issue's dependencies by hand
+const (
+	DependsOnCommand = "/depends-on"
+	NoDepsCommand    = "/no-deps"
+)
+
+// dependencyArgPattern matches one /depends-on argument: "#123" or "owner/repo#123"
+var dependencyArgPattern = regexp.MustCompile(`^` + issueRefPattern + `$`)
+
+// parseDependencyCommand parses a "/depends-on #1 owner/repo#2" or "/no-deps" comment.
+// isCommand is false if body is neither; /no-deps returns no dependencies.
+func parseDependencyCommand(body string) (deps []state.IssueRef, isCommand bool, err error) {
+	line, _, _ := strings.Cut(strings.TrimSpace(body), "\n")
+	fields := strings.Fields(strings.ReplaceAll(line, ",", " "))
+	if len(fields) == 0 {
+		return nil, false, nil
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case NoDepsCommand:
+		return nil, true, nil
+	case DependsOnCommand:
+	default:
+		return nil, false, nil
+	}
+
+	for _, arg := range fields[1:] {
+		match := dependencyArgPattern.FindStringSubmatch(arg)
+		if match == nil {
+			return nil, true, fmt.Errorf("%q is not an issue reference like #123 or owner/repo#123", arg)
+		}
+		num, err := strconv.Atoi(match[2])
+		if err != nil {
+			return nil, true, fmt.Errorf("%q is not an issue reference: %w", arg, err)
+		}
+		deps = append(deps, state.IssueRef{Repo: match[1], Number: num})
+	}
+	if len(deps) == 0 {
+		return nil, true, fmt.Errorf("%s needs at least one issue, e.g. `%s #123`; use `%s` to clear dependencies", DependsOnCommand, DependsOnCommand, NoDepsCommand)
+	}
+	return deps, true, nil
+}
+
+// applyDependencyCommands applies /depends-on and /no-deps comments to issues that
+// aren't being processed, before dependencies are detected and resolved. Issues with
+// an active worker keep their commands until the worker is done with them.
+func (d *Daemon) applyDependencyCommands(ctx context.Context, issues []issueInfo) {
+	if d.depDetector.mode == "disabled" {
+		return
+	}
+
+	activeStates := d.workerPool.GetActiveStates()
+	for _, info := range issues {
+		if _, active := activeStates[fmt.Sprintf("%s-%d", info.repo, info.issue.Number)]; active {
+			continue
+		}
+		d.applyDependencyCommand(ctx, info)
+	}
+}
+
+// applyDependencyCommand applies the latest dependency command an authorized user posted
+// on the issue since the last one was applied. Declared dependencies replace detected
+// ones and turn off detection for the issue.
+func (d *Daemon) applyDependencyCommand(ctx context.Context, info issueInfo) {
+	repo, issueNum, st := info.repo, info.issue.Number, info.state
+
+	// Reuse the comments filterPendingIssues fetched this poll
+	comments := info.comments
+	if comments == nil {
+		var err error
+		comments, err = d.provider.GetComments(ctx, repo, issueNum)
+		if err != nil {
+			d.logger.Printf("Error checking dependency commands for #%d: %v", issueNum, err)
+			return
+		}
+	}
+
+	for i := len(comments) - 1; i >= 0; i-- {
+		c := comments[i]
+		if !c.CreatedAt.After(st.DependencyCommandTime) || state.IsBotComment(c.Body) {
+			continue
+		}
+		deps, isCommand, parseErr := parseDependencyCommand(c.Body)
+		if !isCommand {
+			continue
+		}
+		if !security.IsAuthorized(d.config.AllowedUsers, c.Author, d.logger) {
+			// Skip unauthorized commands (already logged by IsAuthorized)
+			continue
+		}
+
+		st.DependencyCommandTime = c.CreatedAt
+		if c.CreatedAt.After(st.LastCommentTime) {
+			// Keep waiting phases from reading the command as an answer
+			st.LastCommentTime = c.CreatedAt
+		}
+		if parseErr == nil {
+			deps = d.depDetector.deduplicateDeps(deps, repo, issueNum)
+		}
+
+		var reply string
+		switch {
+		case parseErr != nil:
+			d.provider.ReactToComment(ctx, repo, c.ID, "confused")
+			reply = fmt.Sprintf("**Dependencies unchanged:** %v", parseErr)
+		case len(deps) == 0:
+			d.logger.Printf("Dependencies of issue #%d cleared by %s", issueNum, c.Author)
+			st.DependsOn = nil
+			st.BlockedBy = nil
+			d.orchestrator.react(ctx, repo, c, d.config.Progress.AckReaction)
+			reply = "Dependencies cleared. This issue no longer waits for other issues."
+		default:
+			st.DependsOn = deps
+			st.BlockedBy = nil
+			d.logger.Printf("Dependencies of issue #%d set by %s: %v", issueNum, c.Author, st.DependsOn)
+			d.orchestrator.react(ctx, repo, c, d.config.Progress.AckReaction)
+			reply = fmt.Sprintf("Dependencies set to %s. This issue waits until they are completed.", formatIssueRefs(st.DependsOn))
+		}
+
+		// Carry the state in the reply so the command isn't applied again after a restart
+		d.orchestrator.postWithState(ctx, repo, issueNum, st, reply)
+		return
+	}
+}
+
+// formatIssueRefs formats references as "#1, owner/repo#2"
+func formatIssueRefs(refs []state.IssueRef) string {
+	parts := make([]string, len(refs))
+	for i, ref := range refs {
+		parts[i] = ref.String()
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,188 @@
+package orchestrator
+
+import (
+	"context"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+func TestParseDependencyCommand(t *testing.T) {
+	tests := []struct {
+		body      string
+		want      []state.IssueRef
+		isCommand bool
+		wantErr   bool
+	}{
+		{body: "/depends-on #3 #4", want: refs(3, 4), isCommand: true},
+		{body: "/Depends-On #3, owner/lib#4\nthanks", want: []state.IssueRef{{Number: 3}, {Repo: "owner/lib", Number: 4}}, isCommand: true},
+		{body: "/no-deps", isCommand: true},
+		{body: "/depends-on", isCommand: true, wantErr: true},
+		{body: "/depends-on 3", isCommand: true, wantErr: true},
+		{body: "this depends on #3"},
+		{body: "/retry"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			deps, isCommand, err := parseDependencyCommand(tt.body)
+			if isCommand != tt.isCommand || (err != nil) != tt.wantErr {
+				t.Fatalf("expected command=%v err=%v, got command=%v err=%v", tt.isCommand, tt.wantErr, isCommand, err)
+			}
+			if !slices.Equal(deps, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, deps)
+			}
+		})
+	}
+}
+
+func TestApplyDependencyCommands_DependsOnAndNoDeps(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	mock := providers.NewMockProvider()
+	d := NewDaemon(cfg, mock, log.New(io.Discard, "", 0))
+	d.depDetector = NewDependencyDetector(mock, nil, cfg.Concurrency.DependencyDetection)
+	d.workerPool = NewWorkerPool(ctx, 1, 1)
+	defer d.workerPool.Cancel()
+
+	issue := &providers.Issue{Number: 1, Body: "This depends on #9", Labels: []string{cfg.TriggerLabel}}
+	mock.AddIssue("owner/repo", issue)
+	info := issueInfo{repo: "owner/repo", issue: issue, state: state.NewState()}
+	issues := []issueInfo{info}
+	base := time.Now()
+
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 10, Body: "/depends-on #3 owner/lib#4 #1 #3", Author: "alice", CreatedAt: base})
+	d.applyDependencyCommands(ctx, issues)
+	d.detectDependencies(ctx, issues)
+
+	want := []state.IssueRef{{Number: 3}, {Repo: "owner/lib", Number: 4}}
+	if !slices.Equal(info.state.DependsOn, want) {
+		t.Fatalf("expected declared dependencies %v, got %v", want, info.state.DependsOn)
+	}
+	if len(mock.Reactions) != 1 || mock.Reactions[0].CommentID != 10 || mock.Reactions[0].Reaction != "+1" {
+		t.Errorf("expected +1 on the command, got %+v", mock.Reactions)
+	}
+	comments, _ := mock.GetComments(ctx, "owner/repo", 1)
+	if len(comments) != 2 || !strings.Contains(comments[1].Body, "Dependencies set to #3, owner/lib#4") {
+		t.Fatalf("expected a confirmation comment, got %v", comments)
+	}
+
+	// A restarted daemon loads the state from the confirmation and doesn't apply it again
+	loaded, err := d.orchestrator.loadState(ctx, "owner/repo", 1)
+	if err != nil {
+		t.Fatalf("expected state in the confirmation comment: %v", err)
+	}
+	reloaded := []issueInfo{{repo: "owner/repo", issue: info.issue, state: loaded}}
+	d.applyDependencyCommands(ctx, reloaded)
+	if comments, _ := mock.GetComments(ctx, "owner/repo", 1); len(comments) != 2 {
+		t.Errorf("expected the command to be applied once, got %d comments", len(comments))
+	}
+
+	// /no-deps clears them, and the body's "depends on #9" isn't detected again
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 11, Body: "/no-deps", Author: "alice", CreatedAt: base.Add(time.Minute)})
+	d.applyDependencyCommands(ctx, issues)
+	d.detectDependencies(ctx, issues)
+
+	if len(info.state.DependsOn) != 0 {
+		t.Errorf("expected dependencies to be cleared, got %v", info.state.DependsOn)
+	}
+	if ready := d.resolveReadyIssues(ctx, issues); len(ready) != 1 {
+		t.Errorf("expected #1 to be ready after /no-deps, got %d ready", len(ready))
+	}
+}
+
+func TestApplyDependencyCommands_IgnoresUnauthorizedUsers(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.AllowedUsers = []string{"alice"}
+	mock := providers.NewMockProvider()
+	d := NewDaemon(cfg, mock, log.New(io.Discard, "", 0))
+	d.depDetector = NewDependencyDetector(mock, nil, cfg.Concurrency.DependencyDetection)
+	d.workerPool = NewWorkerPool(context.Background(), 1, 1)
+	defer d.workerPool.Cancel()
+
+	issue := &providers.Issue{Number: 1, Body: "This depends on #9", Labels: []string{cfg.TriggerLabel}}
+	mock.AddIssue("owner/repo", issue)
+	info := issueInfo{repo: "owner/repo", issue: issue, state: state.NewState()}
+
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 10, Body: "/no-deps", Author: "mallory", CreatedAt: time.Now()})
+	d.applyDependencyCommands(context.Background(), []issueInfo{info})
+	d.detectDependencies(context.Background(), []issueInfo{info})
+
+	if !slices.Equal(info.state.DependsOn, refs(9)) {
+		t.Errorf("expected detected dependency on #9, got %v", info.state.DependsOn)
+	}
+	if len(mock.Reactions) != 0 {
+		t.Errorf("expected no reaction to an unauthorized command, got %+v", mock.Reactions)
+	}
+}
+
+func TestApplyDependencyCommands_RejectsInvalidArguments(t *testing.T) {
+	cfg := config.DefaultConfig()
+	mock := providers.NewMockProvider()
+	d := NewDaemon(cfg, mock, log.New(io.Discard, "", 0))
+	d.depDetector = NewDependencyDetector(mock, nil, cfg.Concurrency.DependencyDetection)
+	d.workerPool = NewWorkerPool(context.Background(), 1, 1)
+	defer d.workerPool.Cancel()
+
+	issue := &providers.Issue{Number: 1, Body: "This depends on #9", Labels: []string{cfg.TriggerLabel}}
+	mock.AddIssue("owner/repo", issue)
+	info := issueInfo{repo: "owner/repo", issue: issue, state: state.NewState()}
+	info.state.DependsOn = refs(9)
+
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 10, Body: "/depends-on 3", Author: "alice", CreatedAt: time.Now()})
+	d.applyDependencyCommands(context.Background(), []issueInfo{info})
+
+	if !slices.Equal(info.state.DependsOn, refs(9)) {
+		t.Errorf("expected dependencies to be unchanged, got %v", info.state.DependsOn)
+	}
+	if len(mock.Reactions) != 1 || mock.Reactions[0].Reaction != "confused" {
+		t.Errorf("expected a confused reaction, got %+v", mock.Reactions)
+	}
+	comments, _ := mock.GetComments(context.Background(), "owner/repo", 1)
+	if len(comments) != 2 || !strings.Contains(comments[1].Body, "Dependencies unchanged") {
+		t.Errorf("expected an explanation, got %v", comments)
+	}
+}
+
+// commentCountingProvider counts GetComments calls on the mock provider
+type commentCountingProvider struct {
+	*providers.MockProvider
+	getComments int
+}
+
+func (p *commentCountingProvider) GetComments(ctx context.Context, repo string, number int) ([]*providers.Comment, error) {
+	p.getComments++
+	return p.MockProvider.GetComments(ctx, repo, number)
+}
+
+func TestApplyDependencyCommands_ReusesPolledComments(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	mock := providers.NewMockProvider()
+	provider := &commentCountingProvider{MockProvider: mock}
+	d := NewDaemon(cfg, provider, log.New(io.Discard, "", 0))
+	d.depDetector = NewDependencyDetector(provider, nil, cfg.Concurrency.DependencyDetection)
+	d.workerPool = NewWorkerPool(ctx, 1, 1)
+	t.Cleanup(d.workerPool.Cancel)
+
+	issue := &providers.Issue{Number: 1, Labels: []string{cfg.TriggerLabel}}
+	mock.AddIssue("owner/repo", issue)
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 10, Body: "/depends-on #3", Author: "alice", CreatedAt: time.Now()})
+
+	pending := d.filterPendingIssues(ctx, []issueInfo{{repo: "owner/repo", issue: issue}})
+	d.applyDependencyCommands(ctx, pending)
+
+	if provider.getComments != 1 {
+		t.Errorf("expected the comments to be fetched once per poll, got %d fetches", provider.getComments)
+	}
+	if len(pending) != 1 || !slices.Equal(pending[0].state.DependsOn, refs(3)) {
+		t.Errorf("expected the command to be applied from the polled comments, got %+v", pending)
+	}
+}
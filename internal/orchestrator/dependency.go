@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/anthropics/ultra-engineer/internal/claude"
 	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/state"
 )
 
 // DependencyDetector detects dependencies between issues
@@ -31,7 +34,8 @@ func NewDependencyDetector(provider providers.Provider, claudeClient *claude.Cli
 }
 
 // DetectDependencies detects dependencies for an issue based on the configured mode
-func (d *DependencyDetector) DetectDependencies(ctx context.Context, repo string, issue *providers.Issue) ([]int, error) {
+// References into another repository keep that repository; references into repo don't.
+func (d *DependencyDetector) DetectDependencies(ctx context.Context, repo string, issue *providers.Issue) ([]state.IssueRef, error) {
 	if d.mode == "disabled" {
 		return nil, nil
 	}
@@ -41,8 +45,16 @@ func (d *DependencyDetector) DetectDependencies(ctx context.Context, repo string
 		return nil, nil
 	}
 
+	// Prefer structured issue links when the provider supports them
+	var deps []state.IssueRef
+	if depProvider, ok := d.provider.(providers.IssueDependencyProvider); ok {
+		if linked, err := depProvider.GetIssueDependencies(ctx, repo, issue.Number); err == nil {
+			deps = append(deps, state.SameRepoRefs(linked)...)
+		}
+	}
+
 	// Parse explicit references from issue content
-	deps := d.ParseIssueReferences(issue.Body)
+	deps = append(deps, d.ParseIssueReferences(issue.Body)...)
 
 	// Also check comments for dependency declarations
 	if comments, err := d.provider.GetComments(ctx, repo, issue.Number); err == nil {
@@ -53,32 +65,36 @@ func (d *DependencyDetector) DetectDependencies(ctx context.Context, repo string
 	}
 
 	// Remove duplicates and self-references
-	deps = d.deduplicateDeps(deps, issue.Number)
+	deps = d.deduplicateDeps(deps, repo, issue.Number)
 
 	return deps, nil
 }
 
+// issueRefPattern matches "#123" or "owner/repo#123"
+const issueRefPattern = `((?:[\w.-]+/[\w.-]+)?)#(\d+)`
+
 // ParseIssueReferences finds issue references in text
-// Supports: #123, "depends on #456", "after #789", "requires #123", "blocked by #456"
-func (d *DependencyDetector) ParseIssueReferences(text string) []int {
-	var deps []int
+// Supports: #123, "depends on #456", "after #789", "requires #123", "blocked by #456",
+// and owner/repo#123 for issues in another repository
+func (d *DependencyDetector) ParseIssueReferences(text string) []state.IssueRef {
+	var deps []state.IssueRef
 
 	// Pattern for explicit dependency declarations
 	dependencyPatterns := []string{
-		`(?i)depends?\s+on\s+#(\d+)`,
-		`(?i)after\s+#(\d+)`,
-		`(?i)requires?\s+#(\d+)`,
-		`(?i)blocked\s+by\s+#(\d+)`,
-		`(?i)waiting\s+(?:for|on)\s+#(\d+)`,
+		`(?i)depends?\s+on\s+` + issueRefPattern,
+		`(?i)after\s+` + issueRefPattern,
+		`(?i)requires?\s+` + issueRefPattern,
+		`(?i)blocked\s+by\s+` + issueRefPattern,
+		`(?i)waiting\s+(?:for|on)\s+` + issueRefPattern,
 	}
 
 	for _, pattern := range dependencyPatterns {
 		re := regexp.MustCompile(pattern)
 		matches := re.FindAllStringSubmatch(text, -1)
 		for _, match := range matches {
-			if len(match) > 1 {
-				if num, err := strconv.Atoi(match[1]); err == nil {
-					deps = append(deps, num)
+			if len(match) > 2 {
+				if num, err := strconv.Atoi(match[2]); err == nil {
+					deps = append(deps, state.IssueRef{Repo: match[1], Number: num})
 				}
 			}
 		}
@@ -87,59 +103,142 @@ func (d *DependencyDetector) ParseIssueReferences(text string) []int {
 	return deps
 }
 
+// DependencyCycle is a group of issues that depend on each other
+type DependencyCycle struct {
+	Issues []state.IssueRef // Every issue in the group, sorted
+	Path   []state.IssueRef // One closed path through the group, e.g. #1, #2, #1
+}
+
+// String formats the cycle's path, e.g. "#1 -> #2 -> #1"
+func (c DependencyCycle) String() string {
+	return formatCycle(c.Path)
+}
+
+// Format formats the cycle's path as seen from repo, leaving out repo itself
+func (c DependencyCycle) Format(repo string) string {
+	path := make([]state.IssueRef, len(c.Path))
+	for i, ref := range c.Path {
+		path[i] = ref.RelativeTo(repo)
+	}
+	return formatCycle(path)
+}
+
 // CheckForCycles detects cycles in the dependency graph
-// issues is a map of issueNumber -> list of issue numbers it depends on
-func (d *DependencyDetector) CheckForCycles(issues map[int][]int) error {
-	// Track visited and currently in recursion stack
-	visited := make(map[int]bool)
-	recStack := make(map[int]bool)
-	var cyclePath []int
-
-	var dfs func(node int) bool
-	dfs = func(node int) bool {
-		visited[node] = true
-		recStack[node] = true
-		cyclePath = append(cyclePath, node)
-
-		for _, dep := range issues[node] {
-			if !visited[dep] {
-				if dfs(dep) {
-					return true
+// issues is a map of issue -> issues it depends on; references must be resolved
+// consistently, so the same issue is never keyed both with and without its repo
+// Returns every group of issues that depend on each other, directly or through other
+// issues, ordered by their lowest issue number, and an error describing them. Issues
+// that merely depend on a cycle are not part of it.
+func (d *DependencyDetector) CheckForCycles(issues map[state.IssueRef][]state.IssueRef) ([]DependencyCycle, error) {
+	var cycles []DependencyCycle
+	for _, component := range stronglyConnected(issues) {
+		selfLoop := len(component) == 1 && slices.Contains(issues[component[0]], component[0])
+		if len(component) == 1 && !selfLoop {
+			continue
+		}
+		cycles = append(cycles, DependencyCycle{
+			Issues: component,
+			Path:   cyclePath(issues, component),
+		})
+	}
+	if len(cycles) == 0 {
+		return nil, nil
+	}
+
+	sort.Slice(cycles, func(i, j int) bool {
+		return state.CompareIssueRefs(cycles[i].Issues[0], cycles[j].Issues[0]) < 0
+	})
+	paths := make([]string, len(cycles))
+	for i, c := range cycles {
+		paths[i] = c.String()
+	}
+	return cycles, fmt.Errorf("dependency cycle detected: %s", strings.Join(paths, "; "))
+}
+
+// stronglyConnected returns the strongly connected components of the graph (Tarjan's
+// algorithm), each sorted. Nodes are visited in ascending order for stable results.
+func stronglyConnected(graph map[state.IssueRef][]state.IssueRef) [][]state.IssueRef {
+	var nodes []state.IssueRef
+	for node := range graph {
+		nodes = append(nodes, node)
+	}
+	slices.SortFunc(nodes, state.CompareIssueRefs)
+
+	index := make(map[state.IssueRef]int)
+	lowlink := make(map[state.IssueRef]int)
+	onStack := make(map[state.IssueRef]bool)
+	var stack []state.IssueRef
+	var components [][]state.IssueRef
+
+	var visit func(node state.IssueRef)
+	visit = func(node state.IssueRef) {
+		index[node] = len(index)
+		lowlink[node] = index[node]
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, dep := range graph[node] {
+			if _, seen := index[dep]; !seen {
+				visit(dep)
+				lowlink[node] = min(lowlink[node], lowlink[dep])
+			} else if onStack[dep] {
+				lowlink[node] = min(lowlink[node], index[dep])
+			}
+		}
+
+		if lowlink[node] == index[node] {
+			var component []state.IssueRef
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == node {
+					break
 				}
-			} else if recStack[dep] {
-				// Found cycle - find where it starts
-				cyclePath = append(cyclePath, dep)
-				return true
 			}
+			slices.SortFunc(component, state.CompareIssueRefs)
+			components = append(components, component)
 		}
+	}
 
-		recStack[node] = false
-		cyclePath = cyclePath[:len(cyclePath)-1]
-		return false
+	for _, node := range nodes {
+		if _, seen := index[node]; !seen {
+			visit(node)
+		}
 	}
+	return components
+}
 
-	for node := range issues {
-		if !visited[node] {
-			cyclePath = nil
-			if dfs(node) {
-				// Extract just the cycle portion
-				cycleStart := -1
-				for i, n := range cyclePath {
-					if n == cyclePath[len(cyclePath)-1] && i < len(cyclePath)-1 {
-						cycleStart = i
-						break
-					}
-				}
-				if cycleStart >= 0 {
-					cycle := cyclePath[cycleStart:]
-					return fmt.Errorf("dependency cycle detected: %s", formatCycle(cycle))
-				}
-				return fmt.Errorf("dependency cycle detected")
+// cyclePath returns a closed path from the lowest issue in component back to itself,
+// following only dependencies inside the component
+func cyclePath(graph map[state.IssueRef][]state.IssueRef, component []state.IssueRef) []state.IssueRef {
+	start := component[0]
+	visited := make(map[state.IssueRef]bool)
+	path := []state.IssueRef{start}
+
+	var walk func(node state.IssueRef) bool
+	walk = func(node state.IssueRef) bool {
+		for _, dep := range graph[node] {
+			if dep == start {
+				path = append(path, start)
+				return true
 			}
+			if visited[dep] || !slices.Contains(component, dep) {
+				continue
+			}
+			visited[dep] = true
+			path = append(path, dep)
+			if walk(dep) {
+				return true
+			}
+			path = path[:len(path)-1]
 		}
+		return false
 	}
 
-	return nil
+	walk(start)
+	return path
 }
 
 // hasNoDepsOverride checks if the issue has an override to skip dependency detection
@@ -151,17 +250,20 @@ func (d *DependencyDetector) hasNoDepsOverride(issue *providers.Issue) bool {
 		}
 	}
 
-	// Check for /no-deps comment (this would need to be checked via comments)
+	// Check for /no-deps in the body; /no-deps comments are applied by the daemon
 	return strings.Contains(issue.Body, "/no-deps")
 }
 
-// deduplicateDeps removes duplicates and self-references from deps
-func (d *DependencyDetector) deduplicateDeps(deps []int, selfIssueNum int) []int {
-	seen := make(map[int]bool)
-	var result []int
+// deduplicateDeps removes duplicates and self-references from deps. References that
+// name repo itself are made relative, so "#5" and "owner/repo#5" count once.
+func (d *DependencyDetector) deduplicateDeps(deps []state.IssueRef, repo string, selfIssueNum int) []state.IssueRef {
+	self := state.IssueRef{Number: selfIssueNum}
+	seen := make(map[state.IssueRef]bool)
+	var result []state.IssueRef
 
 	for _, dep := range deps {
-		if dep != selfIssueNum && !seen[dep] {
+		dep = dep.RelativeTo(repo)
+		if dep != self && !seen[dep] {
 			seen[dep] = true
 			result = append(result, dep)
 		}
@@ -171,10 +273,10 @@ func (d *DependencyDetector) deduplicateDeps(deps []int, selfIssueNum int) []int
 }
 
 // formatCycle formats a cycle path for display
-func formatCycle(cycle []int) string {
+func formatCycle(cycle []state.IssueRef) string {
 	parts := make([]string, len(cycle))
-	for i, n := range cycle {
-		parts[i] = fmt.Sprintf("#%d", n)
+	for i, ref := range cycle {
+		parts[i] = ref.String()
 	}
 	return strings.Join(parts, " -> ")
 }
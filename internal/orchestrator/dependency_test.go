@@ -1,7 +1,14 @@
 package orchestrator
 
 import (
+	"context"
+	"errors"
+	"slices"
 	"testing"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/state"
 )
 
 func TestParseIssueReferences(t *testing.T) {
@@ -10,62 +17,67 @@ func TestParseIssueReferences(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    string
-		expected []int
+		expected []state.IssueRef
 	}{
 		{
 			name:     "depends on #123",
 			input:    "This issue depends on #123",
-			expected: []int{123},
+			expected: refs(123),
 		},
 		{
 			name:     "depend on #456",
 			input:    "depend on #456",
-			expected: []int{456},
+			expected: refs(456),
 		},
 		{
 			name:     "after #789",
 			input:    "This should be done after #789",
-			expected: []int{789},
+			expected: refs(789),
 		},
 		{
 			name:     "requires #100",
 			input:    "requires #100 to be completed first",
-			expected: []int{100},
+			expected: refs(100),
 		},
 		{
 			name:     "require #101",
 			input:    "require #101",
-			expected: []int{101},
+			expected: refs(101),
 		},
 		{
 			name:     "blocked by #200",
 			input:    "Currently blocked by #200",
-			expected: []int{200},
+			expected: refs(200),
 		},
 		{
 			name:     "waiting for #300",
 			input:    "waiting for #300",
-			expected: []int{300},
+			expected: refs(300),
 		},
 		{
 			name:     "waiting on #301",
 			input:    "waiting on #301",
-			expected: []int{301},
+			expected: refs(301),
 		},
 		{
 			name:     "multiple dependencies",
 			input:    "depends on #1, also requires #2 and blocked by #3",
-			expected: []int{1, 2, 3},
+			expected: refs(1, 2, 3),
 		},
 		{
 			name:     "no dependencies",
 			input:    "This is a standalone issue",
 			expected: nil,
 		},
+		{
+			name:     "other repository",
+			input:    "depends on owner/lib#12 and after my.org/web-app#3",
+			expected: []state.IssueRef{{Repo: "owner/lib", Number: 12}, {Repo: "my.org/web-app", Number: 3}},
+		},
 		{
 			name:     "case insensitive",
 			input:    "DEPENDS ON #999",
-			expected: []int{999},
+			expected: refs(999),
 		},
 	}
 
@@ -78,7 +90,7 @@ func TestParseIssueReferences(t *testing.T) {
 			}
 			for i, v := range result {
 				if v != tt.expected[i] {
-					t.Errorf("expected %d at index %d, got %d", tt.expected[i], i, v)
+					t.Errorf("expected %s at index %d, got %s", tt.expected[i], i, v)
 				}
 			}
 		})
@@ -154,7 +166,7 @@ func TestCheckForCycles(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := detector.CheckForCycles(tt.issues)
+			_, err := detector.CheckForCycles(refGraph(tt.issues))
 			if tt.expectError && err == nil {
 				t.Error("expected error for cycle, got nil")
 			}
@@ -170,48 +182,230 @@ func TestDeduplicateDeps(t *testing.T) {
 
 	tests := []struct {
 		name      string
-		deps      []int
+		deps      []state.IssueRef
 		selfIssue int
-		expected  []int
+		expected  []state.IssueRef
 	}{
 		{
 			name:      "remove duplicates",
-			deps:      []int{1, 2, 1, 3, 2},
+			deps:      refs(1, 2, 1, 3, 2),
 			selfIssue: 99,
-			expected:  []int{1, 2, 3},
+			expected:  refs(1, 2, 3),
 		},
 		{
 			name:      "remove self reference",
-			deps:      []int{1, 2, 3},
+			deps:      refs(1, 2, 3),
 			selfIssue: 2,
-			expected:  []int{1, 3},
+			expected:  refs(1, 3),
 		},
 		{
 			name:      "empty list",
-			deps:      []int{},
+			deps:      refs(),
 			selfIssue: 1,
 			expected:  nil,
 		},
 		{
 			name:      "all self references",
-			deps:      []int{5, 5, 5},
+			deps:      refs(5, 5, 5),
 			selfIssue: 5,
 			expected:  nil,
 		},
+		{
+			name:      "own repo spelled out",
+			deps:      []state.IssueRef{{Repo: "owner/repo", Number: 1}, {Number: 1}, {Repo: "owner/repo", Number: 5}},
+			selfIssue: 5,
+			expected:  refs(1),
+		},
+		{
+			name:      "same number in another repo",
+			deps:      []state.IssueRef{{Repo: "owner/lib", Number: 5}, {Number: 5}},
+			selfIssue: 5,
+			expected:  []state.IssueRef{{Repo: "owner/lib", Number: 5}},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := detector.deduplicateDeps(tt.deps, tt.selfIssue)
-			if len(result) != len(tt.expected) {
-				t.Errorf("expected length %d, got %d", len(tt.expected), len(result))
-				return
+			result := detector.deduplicateDeps(tt.deps, "owner/repo", tt.selfIssue)
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
 			}
-			for i, v := range result {
-				if v != tt.expected[i] {
-					t.Errorf("expected %d at index %d, got %d", tt.expected[i], i, v)
+		})
+	}
+}
+
+// linkedDepsProvider adds structured issue links to the mock provider
+type linkedDepsProvider struct {
+	*providers.MockProvider
+	links map[int][]int
+	err   error
+}
+
+func (p *linkedDepsProvider) GetIssueDependencies(ctx context.Context, repo string, number int) ([]int, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.links[number], nil
+}
+
+func TestDetectDependencies_MergesLinkedAndTextDeps(t *testing.T) {
+	mock := providers.NewMockProvider()
+	provider := &linkedDepsProvider{MockProvider: mock, links: map[int][]int{10: {3, 4}}}
+	detector := NewDependencyDetector(provider, nil, "auto")
+
+	issue := &providers.Issue{Number: 10, Body: "This depends on #4 and requires #5"}
+	deps, err := detector.DetectDependencies(context.Background(), "owner/repo", issue)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := refs(3, 4, 5); !slices.Equal(deps, expected) {
+		t.Errorf("expected %v, got %v", expected, deps)
+	}
+}
+
+func TestDetectDependencies_FallsBackToTextOnLinkError(t *testing.T) {
+	mock := providers.NewMockProvider()
+	provider := &linkedDepsProvider{MockProvider: mock, err: errors.New("404 not found")}
+	detector := NewDependencyDetector(provider, nil, "auto")
+
+	issue := &providers.Issue{Number: 10, Body: "blocked by #7"}
+	deps, err := detector.DetectDependencies(context.Background(), "owner/repo", issue)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(deps, refs(7)) {
+		t.Errorf("expected [7], got %v", deps)
+	}
+}
+
+func TestBuildDependencyGraph(t *testing.T) {
+	mock := providers.NewMockProvider()
+	cfg := config.DefaultConfig()
+	repo := "owner/repo"
+
+	// #1 is done, #2 depends on #1 and #5 (untriggered, still open), #3 and #4 form a cycle
+	mock.AddIssue(repo, &providers.Issue{Number: 1, Title: "Base", Labels: []string{cfg.TriggerLabel, state.PhaseCompleted.Label()}})
+	mock.AddIssue(repo, &providers.Issue{Number: 2, Title: "Feature", Labels: []string{cfg.TriggerLabel}})
+	mock.AddIssue(repo, &providers.Issue{Number: 3, Title: "Left", Labels: []string{cfg.TriggerLabel}})
+	mock.AddIssue(repo, &providers.Issue{Number: 4, Title: "Right", Labels: []string{cfg.TriggerLabel}})
+	mock.AddIssue(repo, &providers.Issue{Number: 5, Title: "Untriggered"})
+
+	for num, deps := range map[int][]int{2: {1, 5}, 3: {4}, 4: {3}} {
+		st := state.NewState()
+		st.DependsOn = refs(deps...)
+		addStateComment(t, mock, repo, num, st)
+	}
+
+	graph, err := BuildDependencyGraph(context.Background(), mock, cfg, repo)
+	if err != nil {
+		t.Fatalf("BuildDependencyGraph failed: %v", err)
+	}
+
+	if len(graph.Nodes) != 4 {
+		t.Fatalf("expected 4 triggered issues, got %d", len(graph.Nodes))
+	}
+	want := map[int][]int{1: nil, 2: {5}, 3: {4}, 4: {3}}
+	for _, node := range graph.Nodes {
+		if !slices.Equal(node.BlockedBy, refs(want[node.Issue]...)) {
+			t.Errorf("#%d: expected blocked by %v, got %v", node.Issue, want[node.Issue], node.BlockedBy)
+		}
+	}
+	if graph.Nodes[0].Phase != state.PhaseCompleted {
+		t.Errorf("expected #1 to be completed, got %s", graph.Nodes[0].Phase)
+	}
+	if len(graph.Cycles) != 1 || !slices.Equal(graph.Cycles[0].Issues, refs(3, 4)) {
+		t.Errorf("expected the #3/#4 cycle to be reported, got %v", graph.Cycles)
+	}
+}
+
+func TestCheckForCycles_MultipleCycles(t *testing.T) {
+	detector := NewDependencyDetector(nil, nil, "auto")
+
+	tests := []struct {
+		name   string
+		issues map[int][]int
+		want   [][]int // Issues in each cycle
+		paths  []string
+	}{
+		{
+			name: "two separate cycles",
+			issues: map[int][]int{
+				1: {2},
+				2: {1},
+				3: {4},
+				4: {5},
+				5: {3},
+			},
+			want:  [][]int{{1, 2}, {3, 4, 5}},
+			paths: []string{"#1 -> #2 -> #1", "#3 -> #4 -> #5 -> #3"},
+		},
+		{
+			name: "dependents of a cycle are not part of it",
+			issues: map[int][]int{
+				1: {2},
+				2: {1},
+				3: {1},
+				4: {3},
+				5: {},
+			},
+			want:  [][]int{{1, 2}},
+			paths: []string{"#1 -> #2 -> #1"},
+		},
+		{
+			name: "cycles sharing an issue form one group",
+			issues: map[int][]int{
+				1: {2, 3},
+				2: {1},
+				3: {1},
+			},
+			want:  [][]int{{1, 2, 3}},
+			paths: []string{"#1 -> #2 -> #1"},
+		},
+		{
+			name: "self-dependency next to a cycle",
+			issues: map[int][]int{
+				1: {1},
+				2: {3},
+				3: {2},
+				4: {2},
+			},
+			want:  [][]int{{1}, {2, 3}},
+			paths: []string{"#1 -> #1", "#2 -> #3 -> #2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cycles, err := detector.CheckForCycles(refGraph(tt.issues))
+			if err == nil {
+				t.Fatal("expected an error for cycles")
+			}
+			if len(cycles) != len(tt.want) {
+				t.Fatalf("expected %d cycles, got %v", len(tt.want), cycles)
+			}
+			for i, cycle := range cycles {
+				if !slices.Equal(cycle.Issues, refs(tt.want[i]...)) {
+					t.Errorf("cycle %d: expected issues %v, got %v", i, tt.want[i], cycle.Issues)
+				}
+				if cycle.String() != tt.paths[i] {
+					t.Errorf("cycle %d: expected path %s, got %s", i, tt.paths[i], cycle)
 				}
 			}
 		})
 	}
 }
+
+// refs returns same-repo references to the given issue numbers
+func refs(nums ...int) []state.IssueRef {
+	return state.SameRepoRefs(nums)
+}
+
+// refGraph converts a graph of issue numbers into one of same-repo references
+func refGraph(issues map[int][]int) map[state.IssueRef][]state.IssueRef {
+	graph := make(map[state.IssueRef][]state.IssueRef, len(issues))
+	for num, deps := range issues {
+		graph[state.IssueRef{Number: num}] = refs(deps...)
+	}
+	return graph
+}
@@ -0,0 +1,95 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+// DependencyNode is one triggered issue in a DependencyGraph
+type DependencyNode struct {
+	Issue     int
+	Title     string
+	Phase     state.Phase
+	DependsOn []state.IssueRef // Dependencies recorded in the issue's state
+	BlockedBy []state.IssueRef // Dependencies that have not completed yet
+}
+
+// DependencyGraph is the dependency graph of a repository's triggered issues,
+// as recorded in their persisted state
+type DependencyGraph struct {
+	Repo   string
+	Nodes  []DependencyNode  // Sorted by issue number
+	Cycles []DependencyCycle // Groups of issues that depend on each other
+}
+
+// BuildDependencyGraph loads the state of every triggered issue in repo and resolves
+// which of their dependencies are still open. Dependencies that are not triggered
+// themselves, including issues in other repositories, are looked up by their phase
+// label, as the daemon does.
+func BuildDependencyGraph(ctx context.Context, provider providers.Provider, cfg *config.Config, repo string) (*DependencyGraph, error) {
+	issues, err := ListTriggeredIssues(ctx, provider, cfg, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &DependencyGraph{Repo: repo}
+	// Keyed by references relative to repo, as dependencies are stored
+	phases := make(map[state.IssueRef]state.Phase)
+	edges := make(map[state.IssueRef][]state.IssueRef)
+
+	for _, issue := range issues {
+		node := DependencyNode{
+			Issue: issue.Number,
+			Title: issue.Title,
+			Phase: state.ParsePhaseFromLabels(issue.Labels),
+		}
+
+		comments, err := provider.GetComments(ctx, repo, issue.Number)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get comments for #%d: %w", issue.Number, err)
+		}
+		var bodies []string
+		for _, c := range comments {
+			bodies = append(bodies, c.Body)
+		}
+		if st, err := state.ParseFromComments(bodies); err == nil {
+			node.Phase = st.CurrentPhase
+			node.DependsOn = st.DependsOn
+		}
+
+		ref := state.IssueRef{Number: node.Issue}
+		phases[ref] = node.Phase
+		if len(node.DependsOn) > 0 {
+			edges[ref] = node.DependsOn
+		}
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	for i := range graph.Nodes {
+		node := &graph.Nodes[i]
+		for _, dep := range node.DependsOn {
+			phase, known := phases[dep]
+			if !known {
+				phase = state.PhaseNew // Unknown dependencies block, as in CanProceed
+				r := dep.Resolve(repo)
+				if depIssue, err := provider.GetIssue(ctx, r.Repo, r.Number); err == nil {
+					phase = state.ParsePhaseFromLabels(depIssue.Labels)
+				}
+				phases[dep] = phase
+			}
+			if phase != state.PhaseCompleted {
+				node.BlockedBy = append(node.BlockedBy, dep)
+			}
+		}
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].Issue < graph.Nodes[j].Issue })
+	graph.Cycles, _ = NewDependencyDetector(provider, nil, cfg.Concurrency.DependencyDetection).CheckForCycles(edges)
+
+	return graph, nil
+}
@@ -0,0 +1,61 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/anthropics/ultra-engineer/internal/progress"
+	"github.com/anthropics/ultra-engineer/internal/sandbox"
+	"github.com/anthropics/ultra-engineer/internal/state"
+	"github.com/anthropics/ultra-engineer/internal/workflow"
+)
+
+// runPrePRHooks runs the hooks.pre_pr commands in the sandbox. When one fails, Claude gets
+// its output to fix the code and the commands run again, up to hooks.max_fix_attempts times.
+func (o *Orchestrator) runPrePRHooks(ctx context.Context, st *state.State, sb *sandbox.Sandbox, reporter *progress.Reporter) error {
+	if len(o.config.Hooks.PrePR) == 0 {
+		return nil
+	}
+
+	maxAttempts := o.config.Hooks.MaxFixAttempts
+	for attempt := 1; ; attempt++ {
+		o.logger.Printf("Running %d pre-PR checks...", len(o.config.Hooks.PrePR))
+		reporter.ForceUpdate(ctx, progress.StatusPrePRChecks)
+		command, output, err := o.runHookCommands(ctx, o.config.Hooks.PrePR, sb)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt > maxAttempts {
+			return fmt.Errorf("pre-PR check %q still fails after %d fix attempts: %w", command, maxAttempts, err)
+		}
+
+		o.logger.Printf("Pre-PR check %q failed, fixing (attempt %d/%d)", command, attempt, maxAttempts)
+		reporter.ForceUpdate(ctx, progress.FormatFixingPrePR(command, attempt, maxAttempts))
+		output = workflow.SummarizeLogs(output, o.config.CI.MaxLogBytes)
+		st.SessionID, err = o.implPhase.FixHookFailure(ctx, command, output, st.SessionID, sb)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// runHookCommands runs commands in order, each limited to hooks.timeout, and stops at the
+// first one that fails. Returns the failed command and its output.
+func (o *Orchestrator) runHookCommands(ctx context.Context, commands []string, sb *sandbox.Sandbox) (string, string, error) {
+	for _, command := range commands {
+		cmdCtx, cancel := context.WithTimeout(ctx, o.config.Hooks.Timeout)
+		output, err := sb.RunCommand(cmdCtx, command)
+		if err != nil && errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
+			output += fmt.Sprintf("\n(killed after the %s timeout)", o.config.Hooks.Timeout)
+		}
+		cancel()
+		if err != nil {
+			return command, output, err
+		}
+	}
+	return "", "", nil
+}
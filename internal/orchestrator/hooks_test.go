@@ -0,0 +1,77 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/progress"
+	"github.com/anthropics/ultra-engineer/internal/sandbox"
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+// claudeRuns counts the runs of a fake Claude that appends a line to .ultra-engineer/runs
+func claudeRuns(t *testing.T, sb *sandbox.Sandbox) int {
+	t.Helper()
+	data, _ := os.ReadFile(sb.RepoPath(".ultra-engineer/runs"))
+	return strings.Count(string(data), "\n")
+}
+
+func TestRunPrePRHooks_PassWithoutClaude(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Claude.Command = fakeClaudeCommand(t, "echo run >> .ultra-engineer/runs")
+	cfg.Hooks.PrePR = []string{"true", "echo ok"}
+	o, mock := newTestOrchestrator(t, cfg)
+	st := state.NewState()
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+	reporter := progress.NewReporterWithState(mock, "owner/repo", 1, time.Minute, false, st)
+
+	if err := o.runPrePRHooks(context.Background(), st, sb, reporter); err != nil {
+		t.Fatalf("expected passing hooks to succeed, got: %v", err)
+	}
+	if runs := claudeRuns(t, sb); runs != 0 {
+		t.Errorf("expected Claude not to run, ran %d times", runs)
+	}
+}
+
+func TestRunPrePRHooks_ClaudeFixesFailure(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Claude.Command = fakeClaudeCommand(t, "echo run >> .ultra-engineer/runs\ntouch fixed")
+	cfg.Hooks.PrePR = []string{"test -f fixed"}
+	o, mock := newTestOrchestrator(t, cfg)
+	st := state.NewState()
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+	reporter := progress.NewReporterWithState(mock, "owner/repo", 1, time.Minute, false, st)
+
+	if err := o.runPrePRHooks(context.Background(), st, sb, reporter); err != nil {
+		t.Fatalf("expected the hook to pass after Claude's fix, got: %v", err)
+	}
+	if runs := claudeRuns(t, sb); runs != 1 {
+		t.Errorf("expected one fix, got %d", runs)
+	}
+}
+
+func TestRunPrePRHooks_FailsAfterMaxAttempts(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Claude.Command = fakeClaudeCommand(t, "echo run >> .ultra-engineer/runs")
+	cfg.Hooks.PrePR = []string{"echo still broken; false", "touch second-ran"}
+	cfg.Hooks.MaxFixAttempts = 2
+	o, mock := newTestOrchestrator(t, cfg)
+	st := state.NewState()
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+	reporter := progress.NewReporterWithState(mock, "owner/repo", 1, time.Minute, false, st)
+
+	err := o.runPrePRHooks(context.Background(), st, sb, reporter)
+	if err == nil || !strings.Contains(err.Error(), `"echo still broken; false" still fails after 2 fix attempts`) {
+		t.Fatalf("expected the failing hook to be named, got: %v", err)
+	}
+	if runs := claudeRuns(t, sb); runs != 2 {
+		t.Errorf("expected 2 fix attempts, got %d", runs)
+	}
+	if _, err := os.Stat(sb.RepoPath("second-ran")); err == nil {
+		t.Error("expected hooks after the failing one not to run")
+	}
+}
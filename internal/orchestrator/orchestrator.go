@@ -5,13 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/anthropics/ultra-engineer/internal/claude"
 	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/logging"
 	"github.com/anthropics/ultra-engineer/internal/progress"
 	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/retry"
 	"github.com/anthropics/ultra-engineer/internal/sandbox"
 	"github.com/anthropics/ultra-engineer/internal/security"
 	"github.com/anthropics/ultra-engineer/internal/state"
@@ -21,15 +25,36 @@ import (
 const (
 	// NeedsManualResolutionLabel is added when merge conflicts cannot be resolved automatically
 	NeedsManualResolutionLabel = "needs-manual-resolution"
+
+	// PausedLabel stops the daemon from picking up an issue without failing it
+	PausedLabel = "paused"
+
+	// AbortLabel is added by the abort command; the daemon cancels any worker still processing the issue
+	AbortLabel = "abort"
+
+	// RetryLimitLabel is added when /retry is refused because defaults.max_retries is reached
+	RetryLimitLabel = "retry-limit-reached"
 )
 
+// ErrUserAborted fails an issue whose work a user stopped with /abort. As a job's
+// context.Cause it means the daemon cancelled the job for an /abort comment.
+var ErrUserAborted = errors.New("user aborted")
+
+// errCIFixesExhausted fails an issue whose CI still fails after ci.max_fix_attempts fixes
+var errCIFixesExhausted = errors.New("CI failures could not be fixed")
+
 // Orchestrator coordinates the issue processing workflow
 type Orchestrator struct {
 	config   *config.Config
+	configMu *sync.RWMutex // Guards the config fields a daemon reload can change
 	provider providers.Provider
 	claude   *claude.Client
 	sandbox  *sandbox.Manager
 	logger   *log.Logger
+	store    state.StateStore // nil when state is only kept in comments
+
+	estimator *progress.Estimator // Shared phase duration history for ETA estimates
+	spend     *dailySpend         // Claude cost per day, for claude.max_cost_per_day
 
 	qaPhase   *workflow.QAPhase
 	planPhase *workflow.PlanningPhase
@@ -38,47 +63,85 @@ type Orchestrator struct {
 	ciMonitor *workflow.CIMonitor // may be nil if provider doesn't support CI or CI is disabled
 }
 
-// New creates a new orchestrator
-func New(cfg *config.Config, provider providers.Provider, logger *log.Logger) *Orchestrator {
+// NewClaudeClient creates the Claude client for cfg. Create one per process and share it,
+// so claude.max_concurrent holds across everything that runs Claude.
+func NewClaudeClient(cfg *config.Config) *claude.Client {
 	// Create retry config for infinite retry mode
 	// MaxAttempts: 0 means retry indefinitely for transient errors
 	// Permanent errors (auth failures, invalid requests) always stop immediately
-	infiniteRetryConfig := config.RetryConfig{
-		MaxAttempts:    0, // 0 means infinite retry
-		BackoffBase:    cfg.Retry.BackoffBase,
-		RateLimitRetry: cfg.Retry.RateLimitRetry,
-	}
+	infiniteRetryConfig := cfg.Retry
+	infiniteRetryConfig.MaxAttempts = 0 // 0 means infinite retry
 
 	claudeClient := claude.NewClientWithRetry(cfg.Claude.Command, cfg.Claude.Timeout, infiniteRetryConfig)
-	sandboxMgr := sandbox.NewManager("")
+	claudeClient.SetModel(cfg.Claude.Model)
+	claudeClient.SetAppendSystemPromptFile(cfg.Claude.AppendSystemPrompt)
+	claudeClient.SetMaxConcurrent(cfg.Claude.MaxConcurrent)
+	return claudeClient
+}
+
+// New creates a new orchestrator that runs Claude through claudeClient
+func New(cfg *config.Config, provider providers.Provider, claudeClient *claude.Client, logger *log.Logger) *Orchestrator {
+	sandboxMgr := sandbox.NewManager(cfg.Sandbox.BaseDir)
 
 	// Initialize CI monitor if provider supports it and CI is enabled
 	var ciMonitor *workflow.CIMonitor
 	if ciProvider, ok := provider.(providers.CIProvider); ok && cfg.CI.WaitForCI {
-		ciMonitor = workflow.NewCIMonitor(ciProvider, cfg.CI.PollInterval, cfg.CI.Timeout)
+		ciMonitor = workflow.NewCIMonitor(ciProvider, cfg.CI.PollInterval, cfg.CI.MaxPollInterval, cfg.CI.Timeout, cfg.CI.MaxLogBytes)
+	}
+
+	// Use a local state store if configured; comments remain the fallback
+	var store state.StateStore
+	// Dry runs never persist state so a later real run starts fresh
+	if cfg.State.Backend == "file" && !cfg.DryRun {
+		store = state.NewFileStore(cfg.State.Dir)
+	}
+
+	// Daily spend is kept in the state directory whatever the backend, so a restart
+	// doesn't reset claude.max_cost_per_day
+	var spendFile spendStore
+	if cfg.Claude.MaxCostPerDay > 0 && !cfg.DryRun {
+		spendFile = state.NewFileStore(cfg.State.Dir)
+	}
+	spend, err := newDailySpend(spendFile)
+	if err != nil {
+		logger.Printf("Warning: failed to load daily Claude spend, starting from zero: %v", err)
 	}
 
 	return &Orchestrator{
 		config:    cfg,
+		configMu:  &sync.RWMutex{},
 		provider:  provider,
 		claude:    claudeClient,
 		sandbox:   sandboxMgr,
 		logger:    logger,
-		qaPhase:   workflow.NewQAPhase(claudeClient, provider),
-		planPhase: workflow.NewPlanningPhase(claudeClient, provider, cfg.Claude.ReviewCycles),
-		implPhase: workflow.NewImplementationPhase(claudeClient, provider, cfg.Claude.ReviewCycles),
-		prPhase:   workflow.NewPRPhase(provider, claudeClient),
+		store:     store,
+		estimator: progress.NewEstimator(cfg.Progress.HistoryFile),
+		spend:     spend,
+		qaPhase:   workflow.NewQAPhase(claudeClient, provider, cfg.Claude, cfg.Progress),
+		planPhase: workflow.NewPlanningPhase(claudeClient, provider, cfg.Claude.PlanReviews(), cfg.Approval, cfg.Claude),
+		implPhase: workflow.NewImplementationPhase(claudeClient, provider, cfg.Claude.CodeReviews(), cfg.Claude),
+		prPhase:   workflow.NewPRPhase(provider, claudeClient, cfg.Defaults),
 		ciMonitor: ciMonitor,
 	}
 }
 
+// withLogger returns a shallow copy of the orchestrator that logs through logger
+// Used to attach per-issue fields without sharing mutable logger state between workers
+func (o *Orchestrator) withLogger(logger *log.Logger) *Orchestrator {
+	scoped := *o
+	scoped.logger = logger
+	return &scoped
+}
+
 // ProcessIssue processes a single issue through the workflow
 func (o *Orchestrator) ProcessIssue(ctx context.Context, repo string, issue *providers.Issue) error {
+	o = o.withLogger(logging.With(o.logger, "repo", repo, "issue", issue.Number))
 	o.logger.Printf("Processing issue #%d: %s", issue.Number, issue.Title)
 
 	// Get or create sandbox
 	issueID := fmt.Sprintf("%s-%d", repo, issue.Number)
 	sb, err := o.sandbox.GetOrCreate(repo, issueID)
+	defer o.sandbox.Release(issueID)
 	if err != nil {
 		return fmt.Errorf("failed to create sandbox: %w", err)
 	}
@@ -93,23 +156,191 @@ func (o *Orchestrator) ProcessIssue(ctx context.Context, repo string, issue *pro
 		}
 	}
 
-	// Clone repo if needed
+	// Clone repo if needed, or bring an existing sandbox up to date
 	if !sb.Exists() {
 		o.logger.Printf("Cloning repository...")
 		if err := o.provider.Clone(ctx, repo, sb.RepoDir); err != nil {
-			return fmt.Errorf("failed to clone: %w", err)
+			// Remove any partial checkout so the next attempt clones again
+			os.RemoveAll(sb.RepoDir)
+			return o.handleCloneFailure(ctx, repo, issue, st, err)
+		}
+		st.CloneAttempts = 0
+	} else if o.config.Sandbox.Reuse {
+		o.logger.Printf("Fetching latest changes into existing sandbox...")
+		if err := sb.FetchLatest(ctx); err != nil {
+			// A stale sandbox is still usable; Claude fetches again before pushing
+			o.logger.Printf("Failed to update sandbox: %v", err)
 		}
 	}
 
-	return o.runStateMachine(ctx, repo, issue, st, sb)
+	sb.AuthorName = o.config.Git.AuthorName
+	sb.AuthorEmail = o.config.Git.AuthorEmail
+	sb.SignCommits = o.config.Git.SignCommits
+	sb.SigningKey = o.config.Git.SigningKey
+	if err := sb.ConfigureIdentity(ctx); err != nil {
+		o.logger.Printf("Warning: failed to set the commit identity: %v", err)
+	}
+
+	return o.runWithIssueTimeout(ctx, repo, issue, st, sb)
+}
+
+// runWithIssueTimeout runs the state machine under defaults.issue_timeout.
+// When the deadline hits, the issue is failed using the parent context so the
+// failure can still be reported after the run context has expired.
+func (o *Orchestrator) runWithIssueTimeout(ctx context.Context, repo string, issue *providers.Issue, st *state.State, sb *sandbox.Sandbox) error {
+	timeout := o.config.Defaults.IssueTimeout
+	if timeout <= 0 {
+		return o.runStateMachine(ctx, repo, issue, st, sb)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := o.runStateMachine(runCtx, repo, issue, st, sb)
+	if ctx.Err() != nil || !errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+
+	st.FailureReason = "issue_timeout"
+	reporter := progress.NewReporterWithState(o.provider, repo, issue.Number,
+		o.progressDebounce(), o.config.Progress.Enabled, st)
+	err = o.fail(ctx, repo, issue.Number, st, fmt.Errorf("issue timed out after %v", timeout), reporter)
+	o.saveState(repo, issue.Number, st)
+	return err
+}
+
+// handleCloneFailure fails the issue for permanent clone errors (missing repo,
+// no access) and leaves it pending for transient ones until max_clone_attempts
+func (o *Orchestrator) handleCloneFailure(ctx context.Context, repo string, issue *providers.Issue, st *state.State, cloneErr error) error {
+	st.CloneAttempts++
+	maxAttempts := o.config.Sandbox.MaxCloneAttempts
+
+	reporter := progress.NewReporterWithState(o.provider, repo, issue.Number,
+		o.progressDebounce(), o.config.Progress.Enabled, st)
+	err := fmt.Errorf("failed to clone: %w", cloneErr)
+
+	if retry.ClassifyGitClone(cloneErr) == retry.Permanent {
+		return o.fail(ctx, repo, issue.Number, st, err, reporter)
+	}
+	if maxAttempts > 0 && st.CloneAttempts >= maxAttempts {
+		return o.fail(ctx, repo, issue.Number, st, fmt.Errorf("%w (gave up after %d attempts)", err, st.CloneAttempts), reporter)
+	}
+
+	o.logger.Printf("Clone failed (attempt %d), will retry next poll: %v", st.CloneAttempts, cloneErr)
+	o.saveState(repo, issue.Number, st)
+	reporter.ForceUpdate(ctx, progress.FormatCloneRetry(st.CloneAttempts, maxAttempts))
+	return nil
+}
+
+// claudeAttemptHooks track consecutive failed Claude invocations in st and stop
+// retrying once claude.max_consecutive_failures is reached. Rate limits aren't counted.
+// They also add up the tokens and cost of every invocation in st and the daily spend,
+// and refuse further invocations once claude.max_cost_per_issue is spent.
+func (o *Orchestrator) claudeAttemptHooks(st *state.State) claude.AttemptHooks {
+	return claude.AttemptHooks{
+		BeforeAttempt: func() error {
+			if limit := o.config.Claude.MaxCostPerIssue; limit > 0 && st.CostUSD >= limit {
+				return fmt.Errorf("%w: $%.2f spent on this issue, claude.max_cost_per_issue is $%.2f", claude.ErrCostLimit, st.CostUSD, limit)
+			}
+			return nil
+		},
+		OnSuccess: func() {
+			st.ClaudeFailures = 0
+		},
+		OnUsage: func(usage claude.Usage) {
+			st.TokensIn += usage.InputTokens
+			st.TokensOut += usage.OutputTokens
+			st.CostUSD += usage.CostUSD
+			if err := o.spend.Add(usage.CostUSD); err != nil {
+				o.logger.Printf("Warning: failed to save daily Claude spend: %v", err)
+			}
+		},
+		OnFailure: func(err error) error {
+			if retry.ClassifyClaude(err) == retry.RateLimited {
+				return nil
+			}
+			st.ClaudeFailures++
+			maxFailures := o.config.Claude.MaxConsecutiveFailures
+			if maxFailures > 0 && st.ClaudeFailures >= maxFailures {
+				return fmt.Errorf("%w: failed %d times in a row, last error: %v", claude.ErrTooManyFailures, st.ClaudeFailures, err)
+			}
+			o.logger.Printf("Claude failed (%d in a row): %v", st.ClaudeFailures, err)
+			return nil
+		},
+	}
+}
+
+// react adds reaction to a comment, routing inline review comments through the review
+// API when the provider has one. An empty reaction is one the config turned off.
+func (o *Orchestrator) react(ctx context.Context, repo string, c *providers.Comment, reaction string) {
+	if reaction == "" {
+		return
+	}
+	if rcProvider, ok := o.provider.(providers.ReviewCommentProvider); ok && c.Path != "" {
+		if err := rcProvider.ReactToReviewComment(ctx, repo, c.ID, reaction); err != nil {
+			o.logger.Printf("Warning: failed to react to review comment %d: %v", c.ID, err)
+		}
+		return
+	}
+	if err := o.provider.ReactToComment(ctx, repo, c.ID, reaction); err != nil {
+		o.logger.Printf("Warning: failed to react to comment %d: %v", c.ID, err)
+	}
+}
+
+// acknowledgeFeedback marks addressed PR comments so reviewers can see what was handled.
+// Every comment gets the ack and done reactions; inline review comments also get an
+// in-thread reply naming the commit when the provider supports it and a new commit was pushed.
+func (o *Orchestrator) acknowledgeFeedback(ctx context.Context, repo string, prNumber int, comments []*providers.Comment, commitSHA string) {
+	rcProvider, hasReviewAPI := o.provider.(providers.ReviewCommentProvider)
+
+	for _, c := range comments {
+		o.react(ctx, repo, c, o.config.Progress.AckReaction)
+		o.react(ctx, repo, c, o.config.Progress.DoneReaction)
+		if c.Path == "" || !hasReviewAPI || commitSHA == "" {
+			continue
+		}
+		reply := state.AddBotMarker(fmt.Sprintf("Addressed in %s", commitSHA))
+		if err := rcProvider.ReplyToReviewComment(ctx, repo, prNumber, c, reply); err != nil {
+			o.logger.Printf("Warning: failed to reply to review comment %d: %v", c.ID, err)
+		}
+	}
 }
 
 func (o *Orchestrator) loadState(ctx context.Context, repo string, issueNum int) (*state.State, error) {
 	comments, err := o.provider.GetComments(ctx, repo, issueNum)
 	if err != nil {
+		// The local store still knows where the issue is
+		if o.store != nil {
+			if st, storeErr := o.store.Load(repo, issueNum); storeErr == nil {
+				return st, nil
+			}
+		}
 		return nil, err
 	}
+	return o.loadStateFrom(repo, issueNum, comments)
+}
+
+// loadStateFrom is loadState for callers that already fetched the issue's comments.
+// With a local store, whichever of the stored and the comment state was updated last
+// wins: the store misses states posted while it was disabled or by another host, and
+// the comments miss updates whose posting failed.
+func (o *Orchestrator) loadStateFrom(repo string, issueNum int, comments []*providers.Comment) (*state.State, error) {
+	fromComments, commentErr := stateFromComments(comments)
+	if o.store == nil {
+		return fromComments, commentErr
+	}
+	stored, err := o.store.Load(repo, issueNum)
+	if err != nil {
+		return fromComments, commentErr
+	}
+	if fromComments != nil && fromComments.LastUpdated.After(stored.LastUpdated) {
+		return fromComments, nil
+	}
+	return stored, nil
+}
 
+// stateFromComments returns the most recent state found in comments
+func stateFromComments(comments []*providers.Comment) (*state.State, error) {
 	// Find the most recent state and track which comment it came from
 	var latestState *state.State
 	var latestCommentID int64
@@ -133,19 +364,75 @@ func (o *Orchestrator) loadState(ctx context.Context, repo string, issueNum int)
 	return latestState, nil
 }
 
+// postWithState posts a bot comment carrying st, so the change survives a restart
+// whatever the state backend
+func (o *Orchestrator) postWithState(ctx context.Context, repo string, issueNum int, st *state.State, message string) {
+	o.saveState(repo, issueNum, st)
+	body, err := st.AppendToBody(message)
+	if err != nil {
+		o.logger.Printf("Failed to serialize state for #%d: %v", issueNum, err)
+		body = message
+	}
+	o.provider.CreateComment(ctx, repo, issueNum, state.AddBotMarker(body))
+}
+
+// isDryRunStopPhase reports whether a dry run should stop before running phase
+func isDryRunStopPhase(phase state.Phase) bool {
+	return phase == state.PhaseApproval || phase == state.PhaseImplementing || phase == state.PhaseReview
+}
+
+// saveState writes state to the local store, if one is configured
+func (o *Orchestrator) saveState(repo string, issueNum int, st *state.State) {
+	if o.store == nil {
+		return
+	}
+	if err := o.store.Save(repo, issueNum, st); err != nil {
+		o.logger.Printf("Failed to save state for issue #%d: %v", issueNum, err)
+	}
+}
+
 func (o *Orchestrator) runStateMachine(ctx context.Context, repo string, issue *providers.Issue, st *state.State, sb *sandbox.Sandbox) error {
 	// Create progress reporter for this issue with state persistence
 	reporter := progress.NewReporterWithState(
 		o.provider,
 		repo,
 		issue.Number,
-		o.config.Progress.DebounceInterval,
+		o.progressDebounce(),
 		o.config.Progress.Enabled,
 		st,
 	)
+	reporter.SetEstimator(o.estimator)
+
+	// Count Claude failures across retries so a prompt that always fails can't spin forever,
+	// and add up what Claude costs
+	ctx = claude.WithAttemptHooks(ctx, o.claudeAttemptHooks(st))
+
+	// Persist final state however the state machine exits
+	defer o.saveState(repo, issue.Number, st)
+
+	// The daemon cancels running workers on /abort; one posted between runs is caught here
+	if abort, err := o.FindAbortComment(ctx, repo, issue, st.LastCommentTime); err != nil {
+		o.logger.Printf("Warning: failed to check for /abort: %v", err)
+	} else if abort != nil {
+		o.react(ctx, repo, abort, o.config.Progress.AckReaction)
+		return o.fail(ctx, repo, issue.Number, st, ErrUserAborted, reporter)
+	}
 
+	base := o
 	for {
-		o.logger.Printf("Phase: %s", st.CurrentPhase)
+		// Scope log lines to the phase being run
+		o := base.withLogger(logging.With(base.logger, "phase", string(st.CurrentPhase)))
+		logging.With(o.logger, "event", "phase_start").Printf("Phase: %s", st.CurrentPhase)
+		o.saveState(repo, issue.Number, st)
+
+		// Dry runs stop once the plan is ready; implementation would commit and push
+		if o.config.DryRun && isDryRunStopPhase(st.CurrentPhase) {
+			o.logger.Printf("Dry run: stopping before implementation of issue #%d", issue.Number)
+			if plan, err := o.planPhase.GetPlan(sb.RepoDir); err == nil {
+				o.logger.Printf("Dry run: plan for issue #%d:\n%s", issue.Number, plan)
+			}
+			return nil
+		}
 
 		switch st.CurrentPhase {
 		case state.PhaseNew:
@@ -194,16 +481,22 @@ func (o *Orchestrator) runStateMachine(ctx context.Context, repo string, issue *
 
 		case state.PhaseCompleted:
 			o.logger.Printf("Issue #%d completed", issue.Number)
+			if err := o.estimator.Record(st.PhaseTimings); err != nil {
+				o.logger.Printf("Failed to record phase timings: %v", err)
+			}
 			reporter.Finalize(ctx, progress.FormatCompleted(st.PRNumber))
+			o.unassignBot(ctx, repo, issue.Number)
 			return nil
 
-		case state.PhaseFailed:
-			return fmt.Errorf("issue failed: %s", st.Error)
+		case state.PhaseFailed, state.PhaseAborted:
+			return fmt.Errorf("issue %s: %s", st.CurrentPhase, st.Error)
 		}
 	}
 }
 
 func (o *Orchestrator) handleNew(ctx context.Context, repo string, issue *providers.Issue, st *state.State, sb *sandbox.Sandbox, reporter *progress.Reporter) error {
+	o.assignBot(ctx, repo, issue.Number)
+
 	o.logger.Printf("Analyzing issue...")
 	reporter.ForceUpdate(ctx, progress.StatusAnalyzing)
 
@@ -213,6 +506,7 @@ func (o *Orchestrator) handleNew(ctx context.Context, repo string, issue *provid
 	}
 
 	if result.NoMoreQuestions {
+		recordIssueBaseline(st, issue)
 		st.SetPhase(state.PhasePlanning)
 		o.setLabel(ctx, repo, issue.Number, state.PhasePlanning)
 		reporter.ForceUpdate(ctx, progress.StatusPlanning)
@@ -262,24 +556,84 @@ func (o *Orchestrator) handleQuestions(ctx context.Context, repo string, issue *
 	}
 
 	// React to acknowledge we've read the comment
-	o.provider.ReactToComment(ctx, repo, answer.ID, "+1")
+	o.react(ctx, repo, answer, o.config.Progress.AckReaction)
 
 	if workflow.IsAbort(answer.Body) {
-		return false, fmt.Errorf("user aborted")
+		return false, ErrUserAborted
 	}
 
 	st.LastCommentTime = answer.CreatedAt
-	// Move to planning (simplified - skip follow-up questions for now)
-	st.SetPhase(state.PhasePlanning)
-	o.setLabel(ctx, repo, issue.Number, state.PhasePlanning)
-	return false, nil
+	if workflow.ParseSlashCommand(answer.Body) == workflow.CommandStatus {
+		o.reportStatus(ctx, repo, issue.Number, st, reporter)
+		return true, nil // Still waiting for answers
+	}
+	questions := o.qaPhase.CurrentQuestions(sb.RepoDir)
+	st.AddQA(questions, workflow.NormalizeAnswers(questions, workflow.ParseUserAnswers(answer.Body)))
+
+	// Stop asking once the round limit is reached and plan with what we have, without
+	// running Claude for questions that would never be posted
+	if maxRounds := o.config.Claude.MaxQARounds; maxRounds > 0 && st.QARound >= maxRounds {
+		o.logger.Printf("Reached max Q&A rounds (%d), proceeding to planning", maxRounds)
+		note := fmt.Sprintf("Reached the limit of %d question rounds. Proceeding with the current understanding of the issue.", maxRounds)
+		o.provider.CreateComment(ctx, repo, issue.Number, state.AddBotMarker(note))
+		recordIssueBaseline(st, issue)
+		st.SetPhase(state.PhasePlanning)
+		o.setLabel(ctx, repo, issue.Number, state.PhasePlanning)
+		return false, nil
+	}
+
+	o.logger.Printf("Checking for follow-up questions (round %d answered)...", st.QARound)
+	reporter.ForceUpdate(ctx, progress.StatusAnalyzing)
+
+	o.react(ctx, repo, answer, o.config.Progress.WorkingReaction)
+	result, err := o.qaPhase.GenerateFollowUpQuestions(ctx, issue, st.QAHistory, sb.RepoDir)
+	if err != nil {
+		return false, err
+	}
+	o.react(ctx, repo, answer, o.config.Progress.DoneReaction)
+
+	if result.NoMoreQuestions {
+		recordIssueBaseline(st, issue)
+		st.SetPhase(state.PhasePlanning)
+		o.setLabel(ctx, repo, issue.Number, state.PhasePlanning)
+		return false, nil
+	}
+
+	// Post the next round and keep waiting in the questions phase
+	st.QARound++
+	if err := o.qaPhase.PostQuestions(ctx, repo, issue.Number, result.Questions, st.QARound, st); err != nil {
+		st.QARound--
+		return false, err
+	}
+	return true, nil
 }
 
 func (o *Orchestrator) handlePlanning(ctx context.Context, repo string, issue *providers.Issue, st *state.State, sb *sandbox.Sandbox, reporter *progress.Reporter) error {
-	o.logger.Printf("Running %d plan reviews...", o.config.Claude.ReviewCycles)
+	totalCycles := o.config.Claude.PlanReviews()
 	reporter.ForceUpdate(ctx, progress.StatusPlanning)
 
-	totalCycles := o.config.Claude.ReviewCycles
+	// Start over on a re-plan, keeping nothing of the outdated plan
+	if st.PlanOutdated {
+		o.logger.Printf("Discarding plan to re-plan from scratch...")
+		if err := o.planPhase.DiscardPlan(sb.RepoDir); err != nil {
+			return fmt.Errorf("failed to discard plan: %w", err)
+		}
+	}
+
+	// Q&A normally leaves a plan behind; a recreated sandbox won't have one
+	if !o.planPhase.HasPlan(sb.RepoDir) {
+		o.logger.Printf("No plan in sandbox, creating one...")
+		if err := o.planPhase.CreatePlan(ctx, issue, st.QAHistory, st.ReplanGuidance, sb.RepoDir); err != nil {
+			return fmt.Errorf("failed to create plan: %w", err)
+		}
+	}
+	if st.PlanOutdated {
+		st.PlanVersion++
+		st.PlanOutdated = false
+		st.ReplanGuidance = ""
+	}
+
+	o.logger.Printf("Running %d plan reviews...", totalCycles)
 	err := o.planPhase.RunFullReviewCycle(ctx, sb.RepoDir, func(i int) {
 		o.logger.Printf("Plan review %d/%d", i, totalCycles)
 		reporter.ForceUpdate(ctx, progress.FormatPlanReview(i, totalCycles))
@@ -335,15 +689,34 @@ func (o *Orchestrator) handleApproval(ctx context.Context, repo string, issue *p
 	}
 
 	// React to acknowledge we've read the comment
-	o.provider.ReactToComment(ctx, repo, response.ID, "+1")
+	o.react(ctx, repo, response, o.config.Progress.AckReaction)
 
 	st.LastCommentTime = response.CreatedAt
 
 	if workflow.IsAbort(response.Body) {
-		return false, fmt.Errorf("user aborted")
+		return false, ErrUserAborted
+	}
+
+	// Explicit slash commands take priority over phrase matching
+	cmd := workflow.ParseSlashCommand(response.Body)
+	if cmd == workflow.CommandReplan {
+		o.requestReplan(ctx, repo, issue.Number, st, workflow.StripSlashCommand(response.Body))
+		return false, nil
 	}
+	if cmd == workflow.CommandStatus {
+		o.reportStatus(ctx, repo, issue.Number, st, reporter)
+		return true, nil // Still waiting for approval
+	}
+	if workflow.IsApprovalCommand(cmd) || (cmd == workflow.CommandNone && o.planPhase.IsApproval(response.Body)) {
+		// Implementation reads the plan from the sandbox; make sure it holds the plan that was approved
+		if plan := workflow.LatestPostedPlan(comments); plan != "" {
+			if err := o.planPhase.WritePlan(sb.RepoDir, plan); err != nil {
+				return false, fmt.Errorf("failed to write approved plan: %w", err)
+			}
+		} else if !o.planPhase.HasPlan(sb.RepoDir) {
+			return false, fmt.Errorf("no plan found to implement")
+		}
 
-	if workflow.IsApproval(response.Body) {
 		st.SetPhase(state.PhaseImplementing)
 		o.setLabel(ctx, repo, issue.Number, state.PhaseImplementing)
 		return false, nil
@@ -351,9 +724,25 @@ func (o *Orchestrator) handleApproval(ctx context.Context, repo string, issue *p
 
 	// Handle feedback
 	feedback := workflow.ExtractFeedback(response.Body)
+	if cmd == workflow.CommandChanges {
+		feedback = workflow.StripSlashCommand(response.Body)
+		if feedback == "" {
+			o.logger.Printf("Ignoring /changes without feedback")
+			return true, nil // Wait for actual feedback
+		}
+	}
 	o.logger.Printf("Integrating feedback...")
 	reporter.ForceUpdate(ctx, progress.StatusPlanning)
 
+	// Revise the plan the user saw, even if the sandbox was recreated since it was posted
+	if !o.planPhase.HasPlan(sb.RepoDir) {
+		if plan := workflow.LatestPostedPlan(comments); plan != "" {
+			if err := o.planPhase.WritePlan(sb.RepoDir, plan); err != nil {
+				return false, fmt.Errorf("failed to restore plan: %w", err)
+			}
+		}
+	}
+
 	needsReReview, err := o.planPhase.IntegrateFeedback(ctx, feedback, sb.RepoDir)
 	if err != nil {
 		return false, err
@@ -361,7 +750,7 @@ func (o *Orchestrator) handleApproval(ctx context.Context, repo string, issue *p
 
 	if needsReReview {
 		o.logger.Printf("Re-reviewing plan...")
-		totalCycles := o.config.Claude.ReviewCycles
+		totalCycles := o.config.Claude.PlanReviews()
 		o.planPhase.RunFullReviewCycle(ctx, sb.RepoDir, func(i int) {
 			o.logger.Printf("Plan re-review %d/%d", i, totalCycles)
 			reporter.ForceUpdate(ctx, progress.FormatPlanReview(i, totalCycles))
@@ -392,24 +781,42 @@ func (o *Orchestrator) handleImplementing(ctx context.Context, repo string, issu
 
 	o.logger.Printf("Implementing with git operations...")
 	reporter.ForceUpdate(ctx, progress.StatusImplementing)
-	result, err := o.implPhase.ImplementWithGit(ctx, issue.Title, issue.Number, baseBranch, sb)
+	// Surface tool activity as debounced progress updates while Claude works
+	onEvent := func(eventType, content string) {
+		if eventType == claude.EventToolUse {
+			reporter.Update(ctx, progress.FormatImplementingTool(content))
+		}
+	}
+	o.forgetClosedPR(ctx, repo, st)
+	branch := o.implementationBranch(ctx, repo, issue, st)
+	result, err := o.implPhase.ImplementWithGit(ctx, issue.Title, issue.Number, baseBranch, branch, st.SessionID, sb, onEvent)
+	if result != nil && result.SessionID != "" {
+		st.SessionID = result.SessionID
+	}
 	if err != nil {
 		return err
 	}
 
 	// Handle merge conflict
 	if result.MergeConflict {
-		return o.failWithMergeConflict(ctx, repo, issue.Number, st, result.ConflictingFiles, reporter)
+		return o.failWithMergeConflict(ctx, repo, issue.Number, st, result.ConflictingFiles, []string{
+			fmt.Sprintf("Fetched latest changes from origin/%s", baseBranch),
+			fmt.Sprintf("Attempted to rebase onto %s", baseBranch),
+			"Tried to resolve conflicts using code context",
+		}, reporter)
 	}
 
-	// Store branch name from Claude's choice (for PR workflow)
+	// Store the branch Claude pushed to (for PR workflow)
 	if result.BranchName != "" {
 		st.BranchName = result.BranchName
 	}
+	if result.DiffStat != nil {
+		o.logger.Printf("Implementation: %s", result.DiffStat.Summary())
+	}
 
-	o.logger.Printf("Running %d code reviews...", o.config.Claude.ReviewCycles)
-	totalCycles := o.config.Claude.ReviewCycles
-	err = o.implPhase.RunFullCodeReviewCycle(ctx, sb, func(i int) {
+	totalCycles := o.config.Claude.CodeReviews()
+	o.logger.Printf("Running %d code reviews...", totalCycles)
+	st.SessionID, err = o.implPhase.RunFullCodeReviewCycle(ctx, st.SessionID, sb, func(i int) {
 		o.logger.Printf("Code review %d/%d", i, totalCycles)
 		reporter.ForceUpdate(ctx, progress.FormatCodeReview(i, totalCycles))
 	})
@@ -417,6 +824,10 @@ func (o *Orchestrator) handleImplementing(ctx context.Context, repo string, issu
 		return err
 	}
 
+	if err := o.runPrePRHooks(ctx, st, sb, reporter); err != nil {
+		return err
+	}
+
 	st.SetPhase(state.PhaseReview)
 	o.setLabel(ctx, repo, issue.Number, state.PhaseReview)
 
@@ -426,6 +837,50 @@ func (o *Orchestrator) handleImplementing(ctx context.Context, repo string, issu
 	return nil
 }
 
+// implementationBranch returns the branch Claude is told to implement on, or "" to leave
+// the choice to Claude when git.branch_template is not set. The branch recorded for the
+// issue is kept while its PR is open, so a new implementation after a re-plan goes to that
+// PR. Otherwise the template is rendered, with a suffix if the branch already exists.
+func (o *Orchestrator) implementationBranch(ctx context.Context, repo string, issue *providers.Issue, st *state.State) string {
+	if o.config.Git.BranchTemplate == "" {
+		return ""
+	}
+	if st.BranchName != "" && st.PRNumber != 0 {
+		return st.BranchName
+	}
+
+	branch := workflow.RenderBranchName(o.config.Git.BranchTemplate, issue.Number, issue.Title)
+	unique, err := workflow.UniqueBranchName(branch, func(name string) (bool, error) {
+		return o.provider.BranchExists(ctx, repo, name)
+	})
+	if err != nil {
+		o.logger.Printf("Warning: failed to find a free branch name, using %s: %v", branch, err)
+		return branch
+	}
+	return unique
+}
+
+// forgetClosedPR clears the PR recorded for the issue if it was merged or closed, as on
+// /retry after the PR was merged. The new implementation then gets a new branch and PR
+// instead of building on the old one.
+func (o *Orchestrator) forgetClosedPR(ctx context.Context, repo string, st *state.State) {
+	if st.PRNumber == 0 {
+		return
+	}
+	pr, err := o.provider.GetPR(ctx, repo, st.PRNumber)
+	if err != nil {
+		o.logger.Printf("Warning: failed to get PR #%d: %v", st.PRNumber, err)
+		return
+	}
+	if strings.EqualFold(pr.State, "open") {
+		return
+	}
+	o.logger.Printf("PR #%d is %s, implementing on a new branch", st.PRNumber, strings.ToLower(pr.State))
+	st.PRNumber = 0
+	st.PRDraft = false
+	st.BranchName = ""
+}
+
 func (o *Orchestrator) handleReview(ctx context.Context, repo string, issue *providers.Issue, st *state.State, sb *sandbox.Sandbox, reporter *progress.Reporter) (bool, error) {
 	if st.PRNumber == 0 {
 		o.logger.Printf("Creating PR...")
@@ -438,13 +893,23 @@ func (o *Orchestrator) handleReview(ctx context.Context, repo string, issue *pro
 		// Note: Claude already committed and pushed the branch during implementation
 		// We just need to create the PR now
 
-		pr, err := o.prPhase.CreatePR(ctx, repo, issue, st.BranchName, baseBranch, sb.RepoDir)
+		// Recompute the diff here so changes from code review are included
+		diff, err := sb.DiffStat(ctx, baseBranch)
+		if err != nil {
+			o.logger.Printf("Warning: failed to compute diff stat: %v", err)
+		}
+
+		pr, err := o.prPhase.CreatePR(ctx, repo, issue, st.BranchName, baseBranch, sb, diff)
 		if err != nil {
 			return false, err
 		}
 
 		st.PRNumber = pr.PR.Number
+		st.PRDraft = pr.PR.Draft
 		o.logger.Printf("Created PR #%d", st.PRNumber)
+		if pr.SetupErr != nil {
+			o.logger.Printf("Warning: %v", pr.SetupErr)
+		}
 
 		// Initialize LastPRCommentTime after PR creation to avoid processing old comments
 		st.LastPRCommentTime = time.Now()
@@ -454,6 +919,8 @@ func (o *Orchestrator) handleReview(ctx context.Context, repo string, issue *pro
 		o.provider.CreateComment(ctx, repo, issue.Number, comment)
 	}
 
+	o.checkStatusCommand(ctx, repo, issue, st, reporter)
+
 	// Check for PR feedback (general comments and inline review comments)
 	var allComments []*providers.Comment
 
@@ -473,9 +940,14 @@ func (o *Orchestrator) handleReview(ctx context.Context, repo string, issue *pro
 
 	// Filter for new comments using CreatedAt timestamp (not ID)
 	// This handles the fact that general comments and review comments have different ID spaces
-	var newFeedback []string
+	var newFeedback []*providers.Comment
 	var latestTime time.Time
+	seen := make(map[int64]bool)
 	for _, c := range allComments {
+		if seen[c.ID] {
+			continue
+		}
+		seen[c.ID] = true
 		if c.CreatedAt.After(st.LastPRCommentTime) && !state.IsBotComment(c.Body) {
 			// Check authorization before including feedback
 			authorized := security.IsAuthorized(o.config.AllowedUsers, c.Author, o.logger)
@@ -483,26 +955,71 @@ func (o *Orchestrator) handleReview(ctx context.Context, repo string, issue *pro
 				// Skip unauthorized feedback (already logged by IsAuthorized)
 				continue
 			}
-			newFeedback = append(newFeedback, c.Body)
+			newFeedback = append(newFeedback, c)
 			if c.CreatedAt.After(latestTime) {
 				latestTime = c.CreatedAt
 			}
 		}
 	}
 
+	// /status is answered on the issue, not addressed as feedback
+	statusRequested := false
+	feedback := newFeedback[:0]
+	for _, c := range newFeedback {
+		if workflow.ParseSlashCommand(c.Body) == workflow.CommandStatus {
+			o.react(ctx, repo, c, o.config.Progress.AckReaction)
+			statusRequested = true
+			continue
+		}
+		feedback = append(feedback, c)
+	}
+	newFeedback = feedback
+	if statusRequested {
+		if len(newFeedback) == 0 {
+			st.LastPRCommentTime = latestTime
+		}
+		o.reportStatus(ctx, repo, issue.Number, st, reporter)
+	}
+
+	// A /replan supersedes the feedback given before it
+	for i := len(newFeedback) - 1; i >= 0; i-- {
+		c := newFeedback[i]
+		if workflow.ParseSlashCommand(c.Body) != workflow.CommandReplan {
+			continue
+		}
+		o.react(ctx, repo, c, o.config.Progress.AckReaction)
+		st.LastPRCommentTime = latestTime
+		o.requestReplan(ctx, repo, issue.Number, st, workflow.StripSlashCommand(c.Body))
+		return false, nil
+	}
+
 	if len(newFeedback) > 0 {
 		o.logger.Printf("Processing %d PR feedback comment(s)...", len(newFeedback))
 
-		// Combine all feedback into one prompt
-		combinedFeedback := strings.Join(newFeedback, "\n\n---\n\n")
+		for _, c := range newFeedback {
+			o.react(ctx, repo, c, o.config.Progress.WorkingReaction)
+		}
+		if err := o.syncWithRemoteBranch(ctx, repo, issue.Number, st, sb, reporter); err != nil {
+			return false, err
+		}
+		beforeSHA, _ := sb.HeadCommit(ctx)
 
-		// Address the feedback - Claude fixes code AND handles git operations
-		if err := o.implPhase.AddressFeedback(ctx, combinedFeedback, sb, st.BranchName); err != nil {
+		// Address all feedback in one run - Claude fixes code AND handles git operations
+		sessionID, err := o.implPhase.AddressFeedback(ctx, newFeedback, st.SessionID, sb, st.BranchName)
+		st.SessionID = sessionID
+		if err != nil {
 			return false, err
 		}
 
-		// Update state and persist via reporter
+		afterSHA, _ := sb.HeadCommit(ctx)
+		if afterSHA == beforeSHA {
+			afterSHA = "" // No new commit; nothing to point reviewers at
+		}
+		o.acknowledgeFeedback(ctx, repo, st.PRNumber, newFeedback, afterSHA)
+
+		// Update state and persist via reporter; CI restarts on the new commit
 		st.LastPRCommentTime = latestTime
+		resetCIBackoff(st)
 		reporter.ForceUpdate(ctx, "🔧 Addressed PR feedback and pushed changes")
 
 		// Post acknowledgment on the issue
@@ -523,29 +1040,65 @@ func (o *Orchestrator) handleReview(ctx context.Context, repo string, issue *pro
 			return true, nil
 		}
 		if ciResult.failed {
-			return false, fmt.Errorf("CI failures could not be fixed after %d attempts", o.config.CI.MaxFixAttempts)
+			return false, fmt.Errorf("%w after %d attempts", errCIFixesExhausted, o.config.CI.MaxFixAttempts)
 		}
 	}
 
+	// A draft PR can't be merged; take it out of draft now that CI is no longer pending
+	if st.PRDraft {
+		if err := o.provider.MarkPRReady(ctx, repo, st.PRNumber); err != nil {
+			o.logger.Printf("Failed to mark PR #%d ready for review, will retry: %v", st.PRNumber, err)
+			return true, nil
+		}
+		o.logger.Printf("Marked PR #%d ready for review", st.PRNumber)
+		st.PRDraft = false
+		reporter.ForceUpdate(ctx, progress.StatusPRReady)
+	}
+
 	// Check if mergeable
 	mergeable, err := o.provider.IsMergeable(ctx, repo, st.PRNumber)
 	if err != nil {
 		return false, err
 	}
 
+	// Wait for required reviews/checks to land instead of waiting for the next poll
+	if !mergeable && o.config.Defaults.AutoMerge && o.config.Defaults.MergeWaitTimeout > 0 {
+		o.logger.Printf("PR #%d not mergeable yet, waiting up to %v", st.PRNumber, o.config.Defaults.MergeWaitTimeout)
+		reporter.ForceUpdate(ctx, progress.StatusWaitingPRApproval)
+		mergeable, err = o.prPhase.WaitForMergeable(ctx, repo, st.PRNumber, o.config.Defaults.MergeWaitTimeout, o.config.Defaults.MergePollInterval)
+		if err != nil {
+			return false, err
+		}
+		if !mergeable {
+			o.logger.Printf("PR #%d still not mergeable after %v, will retry on next poll", st.PRNumber, o.config.Defaults.MergeWaitTimeout)
+			return true, nil
+		}
+	}
+
 	if mergeable && o.config.Defaults.AutoMerge {
 		o.logger.Printf("Merging PR #%d", st.PRNumber)
-		if err := o.provider.MergePR(ctx, repo, st.PRNumber); err != nil {
+		if err := o.provider.MergePR(ctx, repo, st.PRNumber, providers.MergeMethod(o.config.Defaults.MergeMethod)); err != nil {
 			if errors.Is(err, providers.ErrMergeNotAllowed) {
 				// Merge not allowed yet (e.g. pending approvals, branch protection).
-				// This is temporary — wait and retry on the next poll cycle.
+				// This is temporary — keep the PR open and retry on the next poll cycle.
 				o.logger.Printf("Merge not allowed yet, will retry: %v", err)
+				o.reportMergeBlocked(ctx, repo, issue.Number, st, err, reporter)
 				return true, nil
 			}
+			if errors.Is(err, providers.ErrMergeMethodRejected) {
+				return false, fmt.Errorf("check defaults.merge_method: %w", err)
+			}
 			return false, err
 		}
+		st.MergeBlockedReason = ""
 		st.SetPhase(state.PhaseCompleted)
 		o.setLabel(ctx, repo, issue.Number, state.PhaseCompleted)
+		if o.config.Defaults.CloseIssueOnMerge {
+			// "Closes #N" only closes the issue on some providers and base branches
+			if err := o.provider.CloseIssue(ctx, repo, issue.Number); err != nil {
+				o.logger.Printf("Failed to close issue #%d: %v", issue.Number, err)
+			}
+		}
 		sb.Cleanup()
 		return false, nil
 	}
@@ -553,13 +1106,122 @@ func (o *Orchestrator) handleReview(ctx context.Context, repo string, issue *pro
 	return true, nil // Wait for CI/reviews
 }
 
+// checkStatusCommand answers /status comments posted on the issue since the last processed
+// comment. Phases waiting for a reply handle /status as that reply instead.
+func (o *Orchestrator) checkStatusCommand(ctx context.Context, repo string, issue *providers.Issue, st *state.State, reporter *progress.Reporter) {
+	comments, err := o.provider.GetComments(ctx, repo, issue.Number)
+	if err != nil {
+		o.logger.Printf("Warning: failed to check for /status comments: %v", err)
+		return
+	}
+
+	requested := false
+	for _, c := range comments {
+		if !c.CreatedAt.After(st.LastCommentTime) || state.IsBotComment(c.Body) {
+			continue
+		}
+		if workflow.ParseSlashCommand(c.Body) != workflow.CommandStatus {
+			continue
+		}
+		if !security.IsAuthorized(o.config.AllowedUsers, c.Author, o.logger) && c.Author != issue.Author {
+			continue
+		}
+		o.react(ctx, repo, c, o.config.Progress.AckReaction)
+		st.LastCommentTime = c.CreatedAt
+		requested = true
+	}
+	if requested {
+		o.reportStatus(ctx, repo, issue.Number, st, reporter)
+	}
+}
+
+// reportStatus answers a /status command by refreshing the progress comment right away,
+// bypassing debounce, or with a separate comment when progress reporting is off
+func (o *Orchestrator) reportStatus(ctx context.Context, repo string, issueNum int, st *state.State, reporter *progress.Reporter) {
+	o.logger.Printf("Reporting status of issue #%d", issueNum)
+	summary := progress.FormatStatusSummary(st)
+	if o.config.Progress.Enabled {
+		reporter.ForceUpdate(ctx, summary)
+		return
+	}
+	if !st.StartedAt.IsZero() {
+		summary += "\n" + progress.FormatElapsed(time.Since(st.StartedAt))
+	}
+	o.provider.CreateComment(ctx, repo, issueNum, state.AddBotMarker(summary))
+}
+
+// requestReplan sends the issue back to planning to write a new plan from scratch,
+// following guidance if given. Unlike feedback, which revises the plan in place,
+// nothing of the current plan is kept. An open PR stays open and receives the new
+// implementation once the new plan is approved.
+func (o *Orchestrator) requestReplan(ctx context.Context, repo string, issueNum int, st *state.State, guidance string) {
+	o.logger.Printf("Re-planning issue #%d from scratch", issueNum)
+	st.PlanOutdated = true
+	st.ReplanGuidance = guidance
+	st.SetPhase(state.PhasePlanning)
+	o.setLabel(ctx, repo, issueNum, state.PhasePlanning)
+}
+
+// syncWithRemoteBranch brings commits someone else pushed to the PR branch into the
+// sandbox before Claude changes and pushes it, so they are built on rather than
+// overwritten. Local commits that conflict with them fail the issue as a merge conflict.
+func (o *Orchestrator) syncWithRemoteBranch(ctx context.Context, repo string, issueNum int, st *state.State, sb *sandbox.Sandbox, reporter *progress.Reporter) error {
+	if st.BranchName == "" {
+		return nil
+	}
+	if err := sb.FetchRemoteBranch(ctx, st.BranchName); err != nil {
+		// Claude fetches again before pushing
+		o.logger.Printf("Warning: %v", err)
+		return nil
+	}
+	behind, err := sb.IsBehindRemote(ctx, st.BranchName)
+	if err != nil || !behind {
+		return err
+	}
+
+	o.logger.Printf("Branch %s has commits pushed outside the bot, rebasing onto them", st.BranchName)
+	conflicts, err := sb.RebaseOntoRemote(ctx, st.BranchName)
+	if errors.Is(err, sandbox.ErrRebaseConflict) {
+		return o.failWithMergeConflict(ctx, repo, issueNum, st, conflicts, []string{
+			fmt.Sprintf("Fetched commits pushed to %s by someone else", st.BranchName),
+			"Attempted to rebase the bot's unpushed changes onto them",
+		}, reporter)
+	}
+	return err
+}
+
+// reportMergeBlocked explains on the issue why the merge was refused
+// The comment is only posted when the reason changes to avoid repeating it every poll
+func (o *Orchestrator) reportMergeBlocked(ctx context.Context, repo string, issueNum int, st *state.State, mergeErr error, reporter *progress.Reporter) {
+	reason := mergeErr.Error()
+	if reason == st.MergeBlockedReason {
+		return
+	}
+	st.MergeBlockedReason = reason
+
+	comment := state.AddBotMarker(fmt.Sprintf(
+		"**Merge blocked:** PR #%d is ready, but the provider does not allow merging it yet "+
+			"(e.g. required reviews or status checks are pending). The PR stays open and the merge "+
+			"will be retried automatically.\n\nDetails: `%s`", st.PRNumber, reason))
+	o.provider.CreateComment(ctx, repo, issueNum, comment)
+	reporter.ForceUpdate(ctx, progress.StatusWaitingPRApproval)
+}
+
 // ciHandleResult contains the result of CI status handling
 type ciHandleResult struct {
 	shouldWait bool // true if we should wait and poll again later
 	failed     bool // true if CI fix attempts exhausted
 }
 
-// handleCIStatus checks CI status and handles failures
+// resetCIBackoff makes the next poll check CI again, backing off from ci.poll_interval anew
+func resetCIBackoff(st *state.State) {
+	st.CINextCheckTime = time.Time{}
+	st.CICheckInterval = 0
+	st.CIChecks = ""
+}
+
+// handleCIStatus checks CI status and handles failures. While CI stays pending, checks
+// back off from ci.poll_interval to ci.max_poll_interval; polls in between skip the check.
 func (o *Orchestrator) handleCIStatus(ctx context.Context, repo string, issue *providers.Issue, st *state.State, sb *sandbox.Sandbox, reporter *progress.Reporter) (*ciHandleResult, error) {
 	// Initialize CI wait start time if not set
 	if st.CIWaitStartTime.IsZero() {
@@ -580,6 +1242,10 @@ func (o *Orchestrator) handleCIStatus(ctx context.Context, repo string, issue *p
 		return &ciHandleResult{shouldWait: false}, nil
 	}
 
+	if time.Now().Before(st.CINextCheckTime) {
+		return &ciHandleResult{shouldWait: true}, nil
+	}
+
 	// Check CI status
 	ciResult, err := ciProvider.GetCIStatus(ctx, repo, st.PRNumber)
 	if err != nil {
@@ -587,6 +1253,10 @@ func (o *Orchestrator) handleCIStatus(ctx context.Context, repo string, issue *p
 		return &ciHandleResult{shouldWait: true}, nil // Continue polling
 	}
 
+	if o.config.CI.RequiredOnly {
+		o.applyRequiredChecks(ctx, repo, st.PRNumber, ciResult)
+	}
+
 	st.LastCIStatus = string(ciResult.OverallStatus)
 
 	switch ciResult.OverallStatus {
@@ -596,10 +1266,14 @@ func (o *Orchestrator) handleCIStatus(ctx context.Context, repo string, issue *p
 		// Reset CI tracking for next iteration
 		st.CIWaitStartTime = time.Time{}
 		st.CIFixAttempts = 0
+		resetCIBackoff(st)
 		return &ciHandleResult{shouldWait: false}, nil
 
 	case providers.CIStatusPending:
-		reporter.Update(ctx, progress.StatusWaitingCI)
+		st.CICheckInterval, st.CIChecks = o.ciMonitor.NextCheckInterval(st.CICheckInterval, st.CIChecks, ciResult.Checks)
+		st.CINextCheckTime = time.Now().Add(st.CICheckInterval)
+		// Force the update so the next check time is persisted even without a new status
+		reporter.ForceUpdate(ctx, progress.StatusWaitingCI)
 		return &ciHandleResult{shouldWait: true}, nil
 
 	case providers.CIStatusFailure:
@@ -613,7 +1287,7 @@ func (o *Orchestrator) handleCIStatus(ctx context.Context, repo string, issue *p
 		// Collect failed checks
 		var failedChecks []providers.CICheck
 		for _, check := range ciResult.Checks {
-			if check.Status == providers.CIStatusFailure {
+			if check.Status == providers.CIStatusFailure && !check.Optional {
 				failedChecks = append(failedChecks, check)
 			}
 		}
@@ -642,8 +1316,17 @@ func (o *Orchestrator) handleCIStatus(ctx context.Context, repo string, issue *p
 		}
 		checkNameSummary := strings.Join(checkNames, ", ")
 
+		if err := o.syncWithRemoteBranch(ctx, repo, issue.Number, st, sb, reporter); err != nil {
+			return nil, err
+		}
+
 		// Call Claude to fix the CI failure
-		if err := o.implPhase.FixCIFailure(ctx, checkNameSummary, logs, st.BranchName, sb); err != nil {
+		sessionID, err := o.implPhase.FixCIFailure(ctx, checkNameSummary, logs, st.BranchName, st.SessionID, sb)
+		st.SessionID = sessionID
+		if errors.Is(err, claude.ErrTooManyFailures) {
+			return nil, err
+		}
+		if err != nil {
 			o.logger.Printf("CI fix attempt failed: %v", err)
 			// Don't return error, let it try again on next poll
 		}
@@ -653,6 +1336,7 @@ func (o *Orchestrator) handleCIStatus(ctx context.Context, repo string, issue *p
 
 		// Reset wait start time for the new commit
 		st.CIWaitStartTime = time.Now()
+		resetCIBackoff(st)
 		return &ciHandleResult{shouldWait: true}, nil
 
 	case providers.CIStatusUnknown:
@@ -663,10 +1347,60 @@ func (o *Orchestrator) handleCIStatus(ctx context.Context, repo string, issue *p
 	return &ciHandleResult{shouldWait: true}, nil
 }
 
+// applyRequiredChecks limits the checks that can block ciResult to those branch protection
+// requires on the PR's base branch. When that is unknown, every check stays blocking.
+func (o *Orchestrator) applyRequiredChecks(ctx context.Context, repo string, prNumber int, ciResult *providers.CIResult) {
+	rcProvider, ok := o.provider.(providers.RequiredChecksProvider)
+	if !ok {
+		return
+	}
+
+	pr, err := o.provider.GetPR(ctx, repo, prNumber)
+	if err != nil {
+		o.logger.Printf("Warning: failed to get PR #%d, treating all checks as required: %v", prNumber, err)
+		return
+	}
+
+	required, err := rcProvider.GetRequiredChecks(ctx, repo, pr.BaseRef)
+	if err != nil {
+		o.logger.Printf("Warning: failed to get required checks, treating all checks as required: %v", err)
+		return
+	}
+	providers.ApplyRequiredChecks(ciResult, required)
+}
+
 func (o *Orchestrator) fail(ctx context.Context, repo string, issueNum int, st *state.State, err error, reporter *progress.Reporter) error {
+	if errors.Is(context.Cause(ctx), ErrUserAborted) {
+		// The daemon cancelled the job for an /abort comment; whatever the work failed
+		// with, report the abort, and still reach the provider with the cancelled context
+		err = ErrUserAborted
+		ctx = context.WithoutCancel(ctx)
+	}
+
 	o.logger.Printf("Error: %v", err)
 	st.Error = err.Error()
-	st.SetPhase(state.PhaseFailed)
+	if errors.Is(err, ErrUserAborted) {
+		st.FailureReason = "user_aborted"
+		st.Stop(state.PhaseAborted)
+		reporter.Finalize(ctx, progress.StatusAborted)
+
+		// State is persisted via reporter
+		comment := state.AddBotMarker("**Aborted** on request. Comment `/retry` to start again.")
+		o.provider.CreateComment(ctx, repo, issueNum, comment)
+		o.setLabel(ctx, repo, issueNum, state.PhaseAborted)
+		o.unassignBot(ctx, repo, issueNum)
+		return err
+	}
+
+	switch {
+	case errors.Is(err, claude.ErrTooManyFailures):
+		st.FailureReason = "claude_failures"
+	case errors.Is(err, errCIFixesExhausted):
+		st.FailureReason = "ci_fixes_exhausted"
+	case errors.Is(err, claude.ErrCostLimit):
+		st.FailureReason = "cost_limit"
+	}
+	st.Stop(state.PhaseFailed)
 
 	reporter.Finalize(ctx, progress.FormatFailed(err))
 
@@ -674,17 +1408,39 @@ func (o *Orchestrator) fail(ctx context.Context, repo string, issueNum int, st *
 	comment := state.AddBotMarker(fmt.Sprintf("**Error:**\n```\n%s\n```", err.Error()))
 	o.provider.CreateComment(ctx, repo, issueNum, comment)
 	o.setLabel(ctx, repo, issueNum, state.PhaseFailed)
+	o.unassignBot(ctx, repo, issueNum)
 
 	return err
 }
 
-// failWithMergeConflict handles the case when Claude cannot resolve a merge conflict
-func (o *Orchestrator) failWithMergeConflict(ctx context.Context, repo string, issueNum int, st *state.State, conflictingFiles []string, reporter *progress.Reporter) error {
+// assignBot assigns defaults.bot_username to the issue so it's visible the bot owns it
+func (o *Orchestrator) assignBot(ctx context.Context, repo string, issueNum int) {
+	if o.config.Defaults.BotUsername == "" {
+		return
+	}
+	if err := o.provider.AssignIssue(ctx, repo, issueNum, o.config.Defaults.BotUsername); err != nil {
+		o.logger.Printf("Failed to assign %s: %v", o.config.Defaults.BotUsername, err)
+	}
+}
+
+// unassignBot removes defaults.bot_username from the issue once the bot stops working on it
+func (o *Orchestrator) unassignBot(ctx context.Context, repo string, issueNum int) {
+	if o.config.Defaults.BotUsername == "" {
+		return
+	}
+	if err := o.provider.UnassignIssue(ctx, repo, issueNum, o.config.Defaults.BotUsername); err != nil {
+		o.logger.Printf("Failed to unassign %s: %v", o.config.Defaults.BotUsername, err)
+	}
+}
+
+// failWithMergeConflict handles the case when a merge conflict cannot be resolved
+// attempted lists what was tried, for the comment
+func (o *Orchestrator) failWithMergeConflict(ctx context.Context, repo string, issueNum int, st *state.State, conflictingFiles, attempted []string, reporter *progress.Reporter) error {
 	o.logger.Printf("Merge conflict in files: %v", conflictingFiles)
 
 	st.FailureReason = "merge_conflict"
 	st.Error = fmt.Sprintf("Merge conflict in: %s", strings.Join(conflictingFiles, ", "))
-	st.SetPhase(state.PhaseFailed)
+	st.Stop(state.PhaseFailed)
 
 	reporter.Finalize(ctx, progress.FormatFailed(fmt.Errorf("merge conflict")))
 
@@ -696,9 +1452,10 @@ func (o *Orchestrator) failWithMergeConflict(ctx context.Context, repo string, i
 		sb.WriteString(fmt.Sprintf("- `%s`\n", f))
 	}
 	sb.WriteString("\n**What was attempted:**\n")
-	sb.WriteString("- Fetched latest changes from origin/main\n")
-	sb.WriteString("- Attempted to rebase onto main\n")
-	sb.WriteString("- Tried to resolve conflicts using code context\n\n")
+	for _, a := range attempted {
+		sb.WriteString(fmt.Sprintf("- %s\n", a))
+	}
+	sb.WriteString("\n")
 	sb.WriteString("**To resolve:**\n")
 	sb.WriteString("1. Manually resolve the conflicts in the listed files\n")
 	sb.WriteString("2. Push the resolved changes to the branch\n")
@@ -709,16 +1466,71 @@ func (o *Orchestrator) failWithMergeConflict(ctx context.Context, repo string, i
 
 	// Update labels
 	o.setLabel(ctx, repo, issueNum, state.PhaseFailed)
-	o.provider.RemoveLabel(ctx, repo, issueNum, o.config.TriggerLabel)
+	o.removeTriggerLabels(ctx, repo, issueNum)
 	o.provider.AddLabel(ctx, repo, issueNum, NeedsManualResolutionLabel)
+	o.unassignBot(ctx, repo, issueNum)
 
 	return fmt.Errorf("merge conflict: %s", strings.Join(conflictingFiles, ", "))
 }
 
-// CheckForRetry checks if a failed issue has a /retry comment and should be retried
+// FindAbortComment returns the latest authorized /abort comment on the issue posted after
+// since, or nil. The issue author may abort as well as allowed_users.
+func (o *Orchestrator) FindAbortComment(ctx context.Context, repo string, issue *providers.Issue, since time.Time) (*providers.Comment, error) {
+	comments, err := o.provider.GetComments(ctx, repo, issue.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(comments) - 1; i >= 0; i-- {
+		c := comments[i]
+		if !c.CreatedAt.After(since) || state.IsBotComment(c.Body) || !workflow.IsAbort(c.Body) {
+			continue
+		}
+		if c.Author == issue.Author || security.IsAuthorized(o.config.AllowedUsers, c.Author, o.logger) {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+// retryRefusal explains why a /retry posted at the given time is refused, or returns "".
+// limitReached is set when defaults.max_retries is used up, so only a person can go on.
+func (o *Orchestrator) retryRefusal(st *state.State, at time.Time) (refusal string, limitReached bool) {
+	defaults := o.config.Defaults
+	if defaults.MaxRetries > 0 && st.RetryCount >= defaults.MaxRetries {
+		return fmt.Sprintf("**Retry limit reached:** this issue was already retried %d times (`defaults.max_retries`). It needs manual intervention.", st.RetryCount), true
+	}
+	if defaults.RetryInterval > 0 && !st.LastRetryTime.IsZero() {
+		if next := st.LastRetryTime.Add(defaults.RetryInterval); at.Before(next) {
+			return fmt.Sprintf("**Retry refused:** the last retry was less than %s ago (`defaults.retry_interval`). Comment `/retry` again after %s.",
+				defaults.RetryInterval, next.UTC().Format("15:04 UTC")), false
+		}
+	}
+	return "", false
+}
+
+// resumePhaseFor picks the phase /retry resumes an issue in, from its failure reason and the
+// phase it stopped in. A merge conflict re-runs implementation, which rebases onto the base
+// branch; exhausted CI fixes go back to review for a new round of fixes. Otherwise the
+// phase that stopped is run again, except that anything from implementation on re-runs
+// implementation.
+func resumePhaseFor(reason string, lastPhase state.Phase) state.Phase {
+	switch reason {
+	case "merge_conflict":
+		return state.PhaseImplementing
+	case "ci_fixes_exhausted":
+		return state.PhaseReview
+	}
+	switch lastPhase {
+	case state.PhaseNew, state.PhaseQuestions, state.PhasePlanning, state.PhaseApproval:
+		return lastPhase
+	}
+	return state.PhaseImplementing
+}
+
+// CheckForRetry checks if a failed or aborted issue has a /retry comment and should be retried
 func (o *Orchestrator) CheckForRetry(ctx context.Context, repo string, issue *providers.Issue, st *state.State) bool {
-	// Check if issue is in failed phase
-	if st.CurrentPhase != state.PhaseFailed {
+	if !st.CurrentPhase.Stopped() {
 		return false
 	}
 
@@ -740,25 +1552,55 @@ func (o *Orchestrator) CheckForRetry(ctx context.Context, repo string, issue *pr
 					continue
 				}
 
+				if refusal, limitReached := o.retryRefusal(st, c.CreatedAt); refusal != "" {
+					o.logger.Printf("Retry of issue #%d refused: %s", issue.Number, refusal)
+					st.LastCommentTime = c.CreatedAt
+					if limitReached {
+						o.provider.AddLabel(ctx, repo, issue.Number, RetryLimitLabel)
+					}
+					// Carry the state in the refusal so the command isn't answered again
+					o.postWithState(ctx, repo, issue.Number, st, refusal)
+					return false
+				}
+
 				// Found retry command - reset state for retry
 				o.logger.Printf("Retry requested for issue #%d", issue.Number)
 
+				if st.FailureReason == "dependency_cycle" {
+					// Detect dependencies again; the cycle may have been broken
+					st.DependsOn = nil
+					st.BlockedBy = nil
+				}
+				resume := resumePhaseFor(st.FailureReason, st.StoppedPhase)
+				if resume == state.PhaseReview {
+					if st.PRNumber == 0 {
+						resume = state.PhaseImplementing
+					}
+					st.CIFixAttempts = 0
+					st.CIWaitStartTime = time.Time{}
+					resetCIBackoff(st)
+				}
 				st.FailureReason = ""
 				st.Error = ""
-				st.SetPhase(state.PhaseImplementing)
+				st.ClaudeFailures = 0
+				st.RetryCount++
+				st.LastRetryTime = c.CreatedAt
+				st.SetPhase(resume)
 				st.LastCommentTime = c.CreatedAt
+				o.saveState(repo, issue.Number, st)
 
 				// Update labels
 				o.provider.RemoveLabel(ctx, repo, issue.Number, NeedsManualResolutionLabel)
-				o.provider.RemoveLabel(ctx, repo, issue.Number, state.PhaseFailed.Label())
-				o.provider.AddLabel(ctx, repo, issue.Number, o.config.TriggerLabel)
-				o.setLabel(ctx, repo, issue.Number, state.PhaseImplementing)
+				o.provider.RemoveLabel(ctx, repo, issue.Number, AbortLabel)
+				o.provider.AddLabel(ctx, repo, issue.Number, o.triggerLabel())
+				o.setLabel(ctx, repo, issue.Number, resume)
+				o.assignBot(ctx, repo, issue.Number)
 
 				// React to acknowledge
-				o.provider.ReactToComment(ctx, repo, c.ID, "+1")
+				o.react(ctx, repo, c, o.config.Progress.AckReaction)
 
 				// Post comment about retry (state persisted via progress reporter)
-				comment := state.AddBotMarker("Retrying implementation...")
+				comment := state.AddBotMarker(fmt.Sprintf("Retrying from the %s phase...", resume))
 				o.provider.CreateComment(ctx, repo, issue.Number, comment)
 
 				return true
@@ -769,19 +1611,14 @@ func (o *Orchestrator) CheckForRetry(ctx context.Context, repo string, issue *pr
 	return false
 }
 
-// HasNewComment checks if there are any new comments since the last processed time
-func (o *Orchestrator) HasNewComment(ctx context.Context, repo string, issueNum int, st *state.State) (bool, error) {
-	comments, err := o.provider.GetComments(ctx, repo, issueNum)
-	if err != nil {
-		return false, err
-	}
-
+// hasNewComment checks if there are any new comments since the last processed time
+func hasNewComment(comments []*providers.Comment, st *state.State) bool {
 	for _, c := range comments {
 		if c.CreatedAt.After(st.LastCommentTime) && !state.IsBotComment(c.Body) {
-			return true, nil
+			return true
 		}
 	}
-	return false, nil
+	return false
 }
 
 func (o *Orchestrator) setLabel(ctx context.Context, repo string, issueNum int, phase state.Phase) {
@@ -802,18 +1639,20 @@ func (o *Orchestrator) WaitForInteraction(ctx context.Context, duration time.Dur
 }
 
 // CanProceed checks if all dependencies are satisfied (completed, not just in-progress)
-// repoStates is the subset of allStates for the given repo (issueNum -> state)
-func (o *Orchestrator) CanProceed(ctx context.Context, repo string, issue *providers.Issue, st *state.State, repoStates map[int]*state.State) bool {
+// allStates holds the tracked states of every repo (repo -> issueNum -> state), so
+// dependencies on issues in other repositories are checked against their own repo
+func (o *Orchestrator) CanProceed(ctx context.Context, repo string, issue *providers.Issue, st *state.State, allStates map[string]map[int]*state.State) bool {
 	if len(st.DependsOn) == 0 {
 		return true
 	}
 
-	for _, depNum := range st.DependsOn {
-		depState, exists := repoStates[depNum]
+	for _, dep := range st.DependsOn {
+		dep = dep.Resolve(repo)
+		depState, exists := allStates[dep.Repo][dep.Number]
 		if !exists {
 			// Dependency not tracked - might be completed or doesn't exist
 			// Try to check via provider
-			depIssue, err := o.provider.GetIssue(ctx, repo, depNum)
+			depIssue, err := o.provider.GetIssue(ctx, dep.Repo, dep.Number)
 			if err != nil {
 				// Can't find dependency - block to be safe
 				return false
@@ -834,79 +1673,117 @@ func (o *Orchestrator) CanProceed(ctx context.Context, repo string, issue *provi
 	return true
 }
 
+// reportBlocked shows an issue's blockers in its progress comment and saves its state,
+// so the dependency graph survives restarts and is visible to the deps command
+func (o *Orchestrator) reportBlocked(ctx context.Context, repo string, issueNum int, st *state.State) {
+	o.logger.Printf("Issue #%d is blocked by %v", issueNum, st.BlockedBy)
+	reporter := progress.NewReporterWithState(o.provider, repo, issueNum, o.progressDebounce(), o.config.Progress.Enabled, st)
+	if err := reporter.ForceUpdate(ctx, progress.FormatBlocked(st.BlockedBy)); err != nil {
+		o.logger.Printf("Failed to report issue #%d as blocked: %v", issueNum, err)
+	}
+	o.saveState(repo, issueNum, st)
+}
+
+// failDependencyCycle fails an issue that is part of a dependency cycle, since none of
+// the issues in it can ever proceed
+func (o *Orchestrator) failDependencyCycle(ctx context.Context, repo string, issueNum int, st *state.State, cycle DependencyCycle) {
+	path := cycle.Format(repo)
+	o.logger.Printf("Issue #%d is part of dependency cycle %s", issueNum, path)
+
+	err := fmt.Errorf("dependency cycle: %s", path)
+	st.FailureReason = "dependency_cycle"
+	st.Error = err.Error()
+	st.Stop(state.PhaseFailed)
+
+	reporter := progress.NewReporterWithState(o.provider, repo, issueNum, o.progressDebounce(), o.config.Progress.Enabled, st)
+	reporter.Finalize(ctx, progress.FormatFailed(err))
+
+	comment := fmt.Sprintf("**Dependency cycle:** This issue is part of a dependency cycle and cannot proceed:\n\n%s\n\nRemove one of the dependencies, then comment `/retry` on each issue in the cycle.", path)
+	o.provider.CreateComment(ctx, repo, issueNum, state.AddBotMarker(comment))
+	o.setLabel(ctx, repo, issueNum, state.PhaseFailed)
+	o.saveState(repo, issueNum, st)
+}
+
 // CheckAndUnblockIssues re-evaluates blocked issues after any issue completes or fails
 // If a dependency failed, dependent issues should also be marked as failed
-// Returns list of newly-ready issues that should be submitted to the worker pool
-func (o *Orchestrator) CheckAndUnblockIssues(ctx context.Context, repo string, completedOrFailedIssue int, repoStates map[int]*state.State) ([]*providers.Issue, error) {
-	var readyIssues []*providers.Issue
+// finished is the completed or failed issue with its repo; dependents are looked up in
+// every repo of allStates (repo -> issueNum -> state)
+// Returns jobs for newly-ready issues that should be submitted to the worker pool
+func (o *Orchestrator) CheckAndUnblockIssues(ctx context.Context, finished state.IssueRef, allStates map[string]map[int]*state.State) ([]*Job, error) {
+	var readyJobs []*Job
 
-	completedState, exists := repoStates[completedOrFailedIssue]
+	finishedState, exists := allStates[finished.Repo][finished.Number]
 	if !exists {
 		return nil, nil
 	}
 
 	// Check each issue that might depend on the completed/failed issue
-	for issueNum, st := range repoStates {
-		if issueNum == completedOrFailedIssue {
-			continue
-		}
+	for repo, repoStates := range allStates {
+		// How the finished issue is referred to from this repo
+		ref := finished.RelativeTo(repo)
 
-		// Skip if not blocked or already processing
-		if len(st.BlockedBy) == 0 {
-			continue
-		}
+		for issueNum, st := range repoStates {
+			if repo == finished.Repo && issueNum == finished.Number {
+				continue
+			}
 
-		// Check if this issue was blocked by the completed/failed one
-		wasBlocked := false
-		for _, blockedBy := range st.BlockedBy {
-			if blockedBy == completedOrFailedIssue {
-				wasBlocked = true
-				break
+			// Skip if not blocked or already processing
+			if len(st.BlockedBy) == 0 {
+				continue
 			}
-		}
 
-		if !wasBlocked {
-			continue
-		}
+			// Check if this issue was blocked by the completed/failed one
+			wasBlocked := false
+			for _, blockedBy := range st.BlockedBy {
+				if blockedBy.Resolve(repo) == finished {
+					wasBlocked = true
+					break
+				}
+			}
 
-		// If dependency failed, mark this issue as failed too
-		if completedState.CurrentPhase == state.PhaseFailed {
-			st.CurrentPhase = state.PhaseFailed
-			st.FailureReason = "dependency_failed"
-			st.Error = fmt.Sprintf("Dependency #%d failed", completedOrFailedIssue)
+			if !wasBlocked {
+				continue
+			}
 
-			// Post comment about the failure (state persisted via progress reporter)
-			comment := state.AddBotMarker(fmt.Sprintf("**Blocked:** Dependency #%d failed. This issue cannot proceed until the dependency is resolved.\n\nRetry with `/retry` after fixing the dependency.", completedOrFailedIssue))
-			o.provider.CreateComment(ctx, repo, issueNum, comment)
-			o.setLabel(ctx, repo, issueNum, state.PhaseFailed)
-			continue
-		}
+			// If dependency failed or was aborted, mark this issue as failed too
+			if finishedState.CurrentPhase.Stopped() {
+				st.Stop(state.PhaseFailed)
+				st.FailureReason = "dependency_failed"
+				st.Error = fmt.Sprintf("Dependency %s %s", ref, finishedState.CurrentPhase)
 
-		// Dependency completed - update blocked_by
-		var newBlockedBy []int
-		for _, b := range st.BlockedBy {
-			if b != completedOrFailedIssue {
-				newBlockedBy = append(newBlockedBy, b)
+				// Post comment about the failure (state persisted via progress reporter)
+				comment := state.AddBotMarker(fmt.Sprintf("**Blocked:** Dependency %s failed. This issue cannot proceed until the dependency is resolved.\n\nRetry with `/retry` after fixing the dependency.", ref))
+				o.provider.CreateComment(ctx, repo, issueNum, comment)
+				o.setLabel(ctx, repo, issueNum, state.PhaseFailed)
+				continue
 			}
-		}
-		st.BlockedBy = newBlockedBy
 
-		// If no longer blocked, add to ready list
-		if len(st.BlockedBy) == 0 {
-			// Get the issue from provider
-			issue, err := o.provider.GetIssue(ctx, repo, issueNum)
-			if err != nil {
-				o.logger.Printf("Failed to get issue #%d: %v", issueNum, err)
-				continue
+			// Dependency completed - update blocked_by
+			var newBlockedBy []state.IssueRef
+			for _, b := range st.BlockedBy {
+				if b.Resolve(repo) != finished {
+					newBlockedBy = append(newBlockedBy, b)
+				}
 			}
+			st.BlockedBy = newBlockedBy
+
+			// If no longer blocked, add to ready list
+			if len(st.BlockedBy) == 0 {
+				// Get the issue from provider
+				issue, err := o.provider.GetIssue(ctx, repo, issueNum)
+				if err != nil {
+					o.logger.Printf("Failed to get issue #%d: %v", issueNum, err)
+					continue
+				}
 
-			// Post comment that we're unblocked
-			comment := fmt.Sprintf("Dependency #%d completed. Proceeding with this issue.", completedOrFailedIssue)
-			o.provider.CreateComment(ctx, repo, issueNum, state.AddBotMarker(comment))
+				// Post comment that we're unblocked
+				comment := fmt.Sprintf("Dependency %s completed. Proceeding with this issue.", ref)
+				o.provider.CreateComment(ctx, repo, issueNum, state.AddBotMarker(comment))
 
-			readyIssues = append(readyIssues, issue)
+				readyJobs = append(readyJobs, &Job{Issue: issue, Repository: repo, State: st})
+			}
 		}
 	}
 
-	return readyIssues, nil
+	return readyJobs, nil
 }
@@ -0,0 +1,1194 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/claude"
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/progress"
+	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/sandbox"
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+// newTestOrchestrator creates an orchestrator backed by a mock provider
+func newTestOrchestrator(t *testing.T, cfg *config.Config) (*Orchestrator, *providers.MockProvider) {
+	t.Helper()
+	mock := providers.NewMockProvider()
+	return New(cfg, mock, NewClaudeClient(cfg), log.New(io.Discard, "", 0)), mock
+}
+
+// addStateComment posts a comment carrying serialized state
+func addStateComment(t *testing.T, mock *providers.MockProvider, repo string, issueNum int, st *state.State) {
+	t.Helper()
+	body, err := st.AppendToBody("status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mock.CreateComment(context.Background(), repo, issueNum, body); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadState_PrefersNewerFileState(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.State.Backend = "file"
+	cfg.State.Dir = t.TempDir()
+	o, mock := newTestOrchestrator(t, cfg)
+
+	commentState := state.NewState()
+	commentState.CurrentPhase = state.PhaseQuestions
+	addStateComment(t, mock, "owner/repo", 1, commentState)
+
+	stored := state.NewState()
+	stored.CurrentPhase = state.PhaseReview
+	o.saveState("owner/repo", 1, stored)
+
+	st, err := o.loadState(context.Background(), "owner/repo", 1)
+	if err != nil {
+		t.Fatalf("loadState failed: %v", err)
+	}
+	if st.CurrentPhase != state.PhaseReview {
+		t.Errorf("expected stored phase %s, got %s", state.PhaseReview, st.CurrentPhase)
+	}
+}
+
+func TestLoadState_PrefersNewerCommentState(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.State.Backend = "file"
+	cfg.State.Dir = t.TempDir()
+	o, mock := newTestOrchestrator(t, cfg)
+
+	// A file left behind by an earlier run, then progress posted without the store
+	stored := state.NewState()
+	stored.CurrentPhase = state.PhaseQuestions
+	o.saveState("owner/repo", 1, stored)
+
+	time.Sleep(time.Millisecond)
+	commentState := state.NewState()
+	commentState.CurrentPhase = state.PhaseReview
+	addStateComment(t, mock, "owner/repo", 1, commentState)
+
+	st, err := o.loadState(context.Background(), "owner/repo", 1)
+	if err != nil {
+		t.Fatalf("loadState failed: %v", err)
+	}
+	if st.CurrentPhase != state.PhaseReview {
+		t.Errorf("expected newer comment phase %s, got %s", state.PhaseReview, st.CurrentPhase)
+	}
+}
+
+func TestLoadState_FallsBackToComments(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.State.Backend = "file"
+	cfg.State.Dir = t.TempDir()
+	o, mock := newTestOrchestrator(t, cfg)
+
+	commentState := state.NewState()
+	commentState.CurrentPhase = state.PhaseApproval
+	addStateComment(t, mock, "owner/repo", 2, commentState)
+
+	st, err := o.loadState(context.Background(), "owner/repo", 2)
+	if err != nil {
+		t.Fatalf("loadState failed: %v", err)
+	}
+	if st.CurrentPhase != state.PhaseApproval {
+		t.Errorf("expected comment phase %s, got %s", state.PhaseApproval, st.CurrentPhase)
+	}
+	if st.StatusCommentID == 0 {
+		t.Error("expected status comment ID to be set from comment")
+	}
+}
+
+// fakeClaudeCommand writes a script standing in for the claude CLI that runs body in the work dir
+func fakeClaudeCommand(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "claude")
+	script := "#!/bin/sh\nmkdir -p .ultra-engineer\n" + body + "\necho '{\"type\":\"result\",\"result\":\"ok\"}'\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestHandleQuestions_PostsFollowUpRound(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Claude.Command = fakeClaudeCommand(t, `echo "1. Which database?" > .ultra-engineer/questions.md`)
+	cfg.Claude.MaxQARounds = 3
+	o, mock := newTestOrchestrator(t, cfg)
+	issue := &providers.Issue{Number: 1, Title: "Add feature", Author: "alice"}
+	mock.AddIssue("owner/repo", issue)
+
+	// The first round of questions was answered
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+	os.MkdirAll(sb.RepoPath(".ultra-engineer"), 0755)
+	os.WriteFile(sb.RepoPath(".ultra-engineer/questions.md"), []byte("1. Which API?"), 0644)
+	st := state.NewState()
+	st.SetPhase(state.PhaseQuestions)
+	st.QARound = 1
+	st.LastCommentTime = time.Now().Add(-time.Hour)
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 100, Body: "1A", Author: "alice", CreatedAt: time.Now()})
+	reporter := progress.NewReporterWithState(mock, "owner/repo", 1, time.Minute, false, st)
+
+	waiting, err := o.handleQuestions(context.Background(), "owner/repo", issue, st, sb, reporter)
+	if err != nil {
+		t.Fatalf("handleQuestions failed: %v", err)
+	}
+	if !waiting {
+		t.Error("expected to wait for answers to follow-up questions")
+	}
+	if st.CurrentPhase != state.PhaseQuestions {
+		t.Errorf("expected phase %s, got %s", state.PhaseQuestions, st.CurrentPhase)
+	}
+	if st.QARound != 2 {
+		t.Errorf("expected round 2, got %d", st.QARound)
+	}
+	if len(st.QAHistory) != 1 || st.QAHistory[0].Questions != "1. Which API?" || st.QAHistory[0].Answers != "1A" {
+		t.Errorf("unexpected QA history: %+v", st.QAHistory)
+	}
+	if len(mock.CreatedComments) != 1 {
+		t.Fatalf("expected follow-up questions comment, got %d comments", len(mock.CreatedComments))
+	}
+}
+
+func TestHandleQuestions_AdvancesWhenNoMoreQuestions(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Claude.Command = fakeClaudeCommand(t, `echo "NO_QUESTIONS_NEEDED" > .ultra-engineer/questions.md`)
+	cfg.Claude.MaxQARounds = 3
+	o, mock := newTestOrchestrator(t, cfg)
+	issue := &providers.Issue{Number: 1, Title: "Add feature", Author: "alice"}
+	mock.AddIssue("owner/repo", issue)
+
+	// The first round of questions was answered
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+	os.MkdirAll(sb.RepoPath(".ultra-engineer"), 0755)
+	os.WriteFile(sb.RepoPath(".ultra-engineer/questions.md"), []byte("1. Which API?"), 0644)
+	st := state.NewState()
+	st.SetPhase(state.PhaseQuestions)
+	st.QARound = 1
+	st.LastCommentTime = time.Now().Add(-time.Hour)
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 100, Body: "1A", Author: "alice", CreatedAt: time.Now()})
+	reporter := progress.NewReporterWithState(mock, "owner/repo", 1, time.Minute, false, st)
+
+	waiting, err := o.handleQuestions(context.Background(), "owner/repo", issue, st, sb, reporter)
+	if err != nil {
+		t.Fatalf("handleQuestions failed: %v", err)
+	}
+	if waiting {
+		t.Error("expected to continue to planning")
+	}
+	if st.CurrentPhase != state.PhasePlanning {
+		t.Errorf("expected phase %s, got %s", state.PhasePlanning, st.CurrentPhase)
+	}
+	if len(st.QAHistory) != 1 {
+		t.Errorf("expected answered round in history, got %d entries", len(st.QAHistory))
+	}
+	if st.IssueHash != issueHash(issue) {
+		t.Error("expected the issue to be recorded as the baseline for edits")
+	}
+}
+
+func TestHandleQuestions_EnforcesMaxRounds(t *testing.T) {
+	// Claude always has more questions, and counts its runs
+	cfg := config.DefaultConfig()
+	cfg.Claude.Command = fakeClaudeCommand(t, `echo run >> .ultra-engineer/runs; echo "1. Another question?" > .ultra-engineer/questions.md`)
+	cfg.Claude.MaxQARounds = 3
+	o, mock := newTestOrchestrator(t, cfg)
+	issue := &providers.Issue{Number: 1, Title: "Add feature", Author: "alice"}
+	mock.AddIssue("owner/repo", issue)
+
+	// The first round of questions was answered
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+	os.MkdirAll(sb.RepoPath(".ultra-engineer"), 0755)
+	os.WriteFile(sb.RepoPath(".ultra-engineer/questions.md"), []byte("1. Which API?"), 0644)
+	st := state.NewState()
+	st.SetPhase(state.PhaseQuestions)
+	st.QARound = 1
+	st.LastCommentTime = time.Now().Add(-time.Hour)
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 100, Body: "1A", Author: "alice", CreatedAt: time.Now()})
+	reporter := progress.NewReporterWithState(mock, "owner/repo", 1, time.Minute, false, st)
+
+	answeredAt := time.Now()
+	for round := 1; round <= 3; round++ {
+		if round > 1 {
+			answeredAt = answeredAt.Add(time.Minute)
+			mock.AddComment("owner/repo", 1, &providers.Comment{ID: int64(100 + round), Body: "1A", Author: "alice", CreatedAt: answeredAt})
+		}
+
+		waiting, err := o.handleQuestions(context.Background(), "owner/repo", issue, st, sb, reporter)
+		if err != nil {
+			t.Fatalf("round %d: handleQuestions failed: %v", round, err)
+		}
+
+		if round < 3 {
+			if !waiting || st.CurrentPhase != state.PhaseQuestions || st.QARound != round+1 {
+				t.Fatalf("round %d: expected to wait in round %d, got waiting=%v phase=%s round=%d",
+					round, round+1, waiting, st.CurrentPhase, st.QARound)
+			}
+			continue
+		}
+
+		if waiting {
+			t.Error("expected to stop waiting once the round limit is reached")
+		}
+	}
+
+	if st.CurrentPhase != state.PhasePlanning {
+		t.Errorf("expected phase %s, got %s", state.PhasePlanning, st.CurrentPhase)
+	}
+	if st.QARound != 3 {
+		t.Errorf("expected no round beyond the limit, got %d", st.QARound)
+	}
+	if len(st.QAHistory) != 3 {
+		t.Errorf("expected 3 answered rounds, got %d", len(st.QAHistory))
+	}
+	// Follow-ups were generated after rounds 1 and 2 only
+	if runs, _ := os.ReadFile(filepath.Join(sb.RepoDir, ".ultra-engineer", "runs")); strings.Count(string(runs), "run") != 2 {
+		t.Errorf("expected Claude to run for 2 follow-up rounds, got %q", runs)
+	}
+
+	// Two follow-up rounds plus the note about proceeding
+	if len(mock.CreatedComments) != 3 {
+		t.Fatalf("expected 3 comments, got %d", len(mock.CreatedComments))
+	}
+	last := mock.CreatedComments[2].Body
+	if !strings.Contains(last, "Proceeding with the current understanding") {
+		t.Errorf("expected proceeding note, got %q", last)
+	}
+}
+
+func TestHandleReview_MergeNotAllowedKeepsPROpen(t *testing.T) {
+	o, mock := newTestOrchestrator(t, config.DefaultConfig())
+	ctx := context.Background()
+
+	issue := &providers.Issue{Number: 1, Title: "Add feature"}
+	mock.AddIssue("owner/repo", issue)
+	pr, _ := mock.CreatePR(ctx, "owner/repo", providers.PRCreate{Title: "t", Head: "feat", Base: "main"})
+	mock.SetPRMergeable("owner/repo", pr.Number, true)
+	mock.MergeError = fmt.Errorf("%w: review is required", providers.ErrMergeNotAllowed)
+
+	st := state.NewState()
+	st.SetPhase(state.PhaseReview)
+	st.PRNumber = pr.Number
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+	reporter := progress.NewReporterWithState(mock, "owner/repo", 1, time.Minute, false, st)
+
+	// Poll twice; the blocking reason should only be reported once
+	for i := 0; i < 2; i++ {
+		waiting, err := o.handleReview(ctx, "owner/repo", issue, st, sb, reporter)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !waiting {
+			t.Fatal("expected to keep waiting for merge")
+		}
+	}
+
+	if st.CurrentPhase != state.PhaseReview {
+		t.Errorf("expected phase %s, got %s", state.PhaseReview, st.CurrentPhase)
+	}
+	if len(mock.CreatedComments) != 1 {
+		t.Fatalf("expected 1 merge blocked comment, got %d", len(mock.CreatedComments))
+	}
+	if !strings.Contains(mock.CreatedComments[0].Body, "Merge blocked") {
+		t.Errorf("unexpected comment: %q", mock.CreatedComments[0].Body)
+	}
+}
+
+func TestRunStateMachine_DryRunStopsBeforeImplementation(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.DryRun = true
+	o, mock := newTestOrchestrator(t, cfg)
+
+	issue := &providers.Issue{Number: 1, Title: "Add feature"}
+	st := state.NewState()
+	st.SetPhase(state.PhaseImplementing)
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+
+	if err := o.runStateMachine(context.Background(), "owner/repo", issue, st, sb); err != nil {
+		t.Fatalf("expected dry run to stop cleanly, got %v", err)
+	}
+	if st.CurrentPhase != state.PhaseImplementing {
+		t.Errorf("expected phase to be unchanged, got %s", st.CurrentPhase)
+	}
+	if len(mock.CreatedComments) != 0 || len(mock.AddedLabels) != 0 {
+		t.Error("expected no provider writes")
+	}
+}
+
+func TestRunStateMachine_AbortCommentBetweenRuns(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.AllowedUsers = []string{"bob"}
+	o, mock := newTestOrchestrator(t, cfg)
+
+	issue := &providers.Issue{Number: 1, Title: "Add feature", Author: "alice"}
+	mock.AddIssue("owner/repo", issue)
+	st := state.NewState()
+	st.SetPhase(state.PhaseReview)
+	st.LastCommentTime = time.Now().Add(-time.Hour)
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 1, Body: "/abort", Author: "mallory", CreatedAt: time.Now()})
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 2, Body: "/abort please", Author: "alice", CreatedAt: time.Now()})
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+
+	err := o.runStateMachine(context.Background(), "owner/repo", issue, st, sb)
+	if !errors.Is(err, ErrUserAborted) {
+		t.Fatalf("expected ErrUserAborted, got %v", err)
+	}
+	if st.CurrentPhase != state.PhaseAborted || st.FailureReason != "user_aborted" {
+		t.Errorf("expected the issue aborted as user_aborted, got phase %s, reason %q", st.CurrentPhase, st.FailureReason)
+	}
+	if len(mock.Reactions) != 1 || mock.Reactions[0].CommentID != 2 {
+		t.Errorf("expected only the issue author's /abort acknowledged, got %v", mock.Reactions)
+	}
+}
+
+func TestCheckForRetry_EnforcesInterval(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Defaults.RetryInterval = time.Hour
+	o, mock := newTestOrchestrator(t, cfg)
+	issue := &providers.Issue{Number: 1, Title: "Add feature"}
+	mock.AddIssue("owner/repo", issue)
+	st := state.NewState()
+	st.SetPhase(state.PhaseFailed)
+
+	first := time.Now().Add(-2 * time.Hour)
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 1, Body: "/retry", Author: "alice", CreatedAt: first})
+	if !o.CheckForRetry(context.Background(), "owner/repo", issue, st) {
+		t.Fatal("expected the first retry to be accepted")
+	}
+	if st.RetryCount != 1 || !st.LastRetryTime.Equal(first) {
+		t.Errorf("expected the retry recorded, got count %d at %v", st.RetryCount, st.LastRetryTime)
+	}
+
+	st.SetPhase(state.PhaseFailed)
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 2, Body: "/retry", Author: "alice", CreatedAt: first.Add(time.Minute)})
+	if o.CheckForRetry(context.Background(), "owner/repo", issue, st) {
+		t.Fatal("expected a retry within retry_interval to be refused")
+	}
+	if st.CurrentPhase != state.PhaseFailed || st.RetryCount != 1 {
+		t.Errorf("expected the issue to stay failed, got phase %s, count %d", st.CurrentPhase, st.RetryCount)
+	}
+	last := mock.CreatedComments[len(mock.CreatedComments)-1]
+	if !strings.Contains(last.Body, "Retry refused") {
+		t.Errorf("expected the refusal explained, got %q", last.Body)
+	}
+
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 3, Body: "/retry", Author: "alice", CreatedAt: first.Add(90 * time.Minute)})
+	if !o.CheckForRetry(context.Background(), "owner/repo", issue, st) {
+		t.Error("expected a retry after retry_interval to be accepted")
+	}
+}
+
+func TestCheckForRetry_EnforcesMaxRetries(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Defaults.MaxRetries = 2
+	o, mock := newTestOrchestrator(t, cfg)
+	issue := &providers.Issue{Number: 1, Title: "Add feature"}
+	mock.AddIssue("owner/repo", issue)
+	st := state.NewState()
+	st.SetPhase(state.PhaseFailed)
+	st.RetryCount = 2
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 1, Body: "/retry", Author: "alice", CreatedAt: time.Now()})
+
+	if o.CheckForRetry(context.Background(), "owner/repo", issue, st) {
+		t.Fatal("expected a retry beyond max_retries to be refused")
+	}
+	if !slices.Contains(issue.Labels, RetryLimitLabel) {
+		t.Errorf("expected the %s label, got %v", RetryLimitLabel, issue.Labels)
+	}
+	last := mock.CreatedComments[len(mock.CreatedComments)-1]
+	if !strings.Contains(last.Body, "Retry limit reached") {
+		t.Errorf("expected the limit explained, got %q", last.Body)
+	}
+
+	// The refused comment is consumed, so it isn't answered again
+	comments := len(mock.CreatedComments)
+	if o.CheckForRetry(context.Background(), "owner/repo", issue, st) || len(mock.CreatedComments) != comments {
+		t.Error("expected the refused /retry to be handled once")
+	}
+}
+
+func TestCheckForRetry_RefusalSurvivesReload(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Defaults.MaxRetries = 1
+	o, mock := newTestOrchestrator(t, cfg)
+	issue := &providers.Issue{Number: 1, Title: "Add feature"}
+	mock.AddIssue("owner/repo", issue)
+
+	failed := state.NewState()
+	failed.SetPhase(state.PhaseFailed)
+	failed.RetryCount = 1
+	addStateComment(t, mock, "owner/repo", 1, failed)
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 100, Body: "/retry", Author: "alice", CreatedAt: time.Now()})
+
+	for poll := 1; poll <= 2; poll++ {
+		// Each poll rebuilds the state from the comments, as filterPendingIssues does
+		st, err := o.loadState(context.Background(), "owner/repo", 1)
+		if err != nil {
+			t.Fatalf("poll %d: loadState failed: %v", poll, err)
+		}
+		if o.CheckForRetry(context.Background(), "owner/repo", issue, st) {
+			t.Fatalf("poll %d: expected the retry to be refused", poll)
+		}
+	}
+
+	refusals := 0
+	for _, c := range mock.CreatedComments {
+		if strings.Contains(c.Body, "Retry limit reached") {
+			refusals++
+		}
+	}
+	if refusals != 1 {
+		t.Errorf("expected the /retry to be refused once, got %d refusals", refusals)
+	}
+	if labels := mock.AddedLabels; len(labels) != 1 || labels[0].Label != RetryLimitLabel {
+		t.Errorf("expected the %s label added once, got %+v", RetryLimitLabel, labels)
+	}
+}
+
+func TestResumePhaseFor(t *testing.T) {
+	tests := []struct {
+		reason    string
+		lastPhase state.Phase
+		want      state.Phase
+	}{
+		{"merge_conflict", state.PhaseReview, state.PhaseImplementing},
+		{"merge_conflict", state.PhaseImplementing, state.PhaseImplementing},
+		{"ci_fixes_exhausted", state.PhaseReview, state.PhaseReview},
+		{"claude_failures", state.PhasePlanning, state.PhasePlanning},
+		{"issue_timeout", state.PhasePlanning, state.PhasePlanning},
+		{"user_aborted", state.PhaseApproval, state.PhaseApproval},
+		{"claude_failures", state.PhaseNew, state.PhaseNew},
+		{"claude_failures", state.PhaseReview, state.PhaseImplementing},
+		{"dependency_cycle", state.PhaseImplementing, state.PhaseImplementing},
+		{"", "", state.PhaseImplementing}, // Failed before the stopped phase was recorded
+	}
+
+	for _, tt := range tests {
+		if got := resumePhaseFor(tt.reason, tt.lastPhase); got != tt.want {
+			t.Errorf("resumePhaseFor(%q, %q) = %s, want %s", tt.reason, tt.lastPhase, got, tt.want)
+		}
+	}
+}
+
+func TestCheckForRetry_ResumesReviewAfterCIFixesExhausted(t *testing.T) {
+	o, mock := newTestOrchestrator(t, config.DefaultConfig())
+	issue := &providers.Issue{Number: 1, Title: "Add feature"}
+	mock.AddIssue("owner/repo", issue)
+	st := state.NewState()
+	st.SetPhase(state.PhaseReview)
+	st.PRNumber = 7
+	st.CIFixAttempts = 3
+	reporter := progress.NewReporterWithState(mock, "owner/repo", 1, time.Minute, false, st)
+	o.fail(context.Background(), "owner/repo", 1, st, fmt.Errorf("%w after 3 attempts", errCIFixesExhausted), reporter)
+	if st.FailureReason != "ci_fixes_exhausted" || st.StoppedPhase != state.PhaseReview {
+		t.Fatalf("expected the CI failure recorded in review, got reason %q, phase %s", st.FailureReason, st.StoppedPhase)
+	}
+
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 1, Body: "/retry", Author: "alice", CreatedAt: time.Now()})
+	if !o.CheckForRetry(context.Background(), "owner/repo", issue, st) {
+		t.Fatal("expected the retry to be accepted")
+	}
+	if st.CurrentPhase != state.PhaseReview || st.CIFixAttempts != 0 {
+		t.Errorf("expected review with fresh CI fix attempts, got phase %s, %d attempts", st.CurrentPhase, st.CIFixAttempts)
+	}
+	if !slices.Contains(issue.Labels, state.PhaseReview.Label()) {
+		t.Errorf("expected the review label, got %v", issue.Labels)
+	}
+}
+
+func TestProcessIssue_TransientCloneFailureRetriesThenFails(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Sandbox.BaseDir = t.TempDir()
+	cfg.Sandbox.MaxCloneAttempts = 2
+	o, mock := newTestOrchestrator(t, cfg)
+	mock.CloneError = fmt.Errorf("fatal: unable to access: Could not resolve host: example.com")
+
+	issue := &providers.Issue{Number: 1, Title: "Add feature"}
+
+	if err := o.ProcessIssue(context.Background(), "owner/repo", issue); err != nil {
+		t.Fatalf("expected first transient failure to be deferred, got %v", err)
+	}
+	st, err := o.loadState(context.Background(), "owner/repo", 1)
+	if err != nil {
+		t.Fatalf("loadState failed: %v", err)
+	}
+	if st.CloneAttempts != 1 {
+		t.Errorf("expected 1 clone attempt recorded, got %d", st.CloneAttempts)
+	}
+	if st.CurrentPhase == state.PhaseFailed {
+		t.Error("expected issue not to fail on first transient clone error")
+	}
+
+	if err := o.ProcessIssue(context.Background(), "owner/repo", issue); err == nil {
+		t.Fatal("expected issue to fail once max_clone_attempts is reached")
+	}
+	if !hasAddedLabel(mock, state.PhaseFailed.Label()) {
+		t.Error("expected failed label after exhausting clone attempts")
+	}
+}
+
+func TestProcessIssue_PermanentCloneFailureFailsImmediately(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Sandbox.BaseDir = t.TempDir()
+	o, mock := newTestOrchestrator(t, cfg)
+	mock.CloneError = fmt.Errorf("remote: Repository not found.")
+
+	issue := &providers.Issue{Number: 1, Title: "Add feature"}
+	if err := o.ProcessIssue(context.Background(), "owner/repo", issue); err == nil {
+		t.Fatal("expected permanent clone failure to fail the issue")
+	}
+	if !hasAddedLabel(mock, state.PhaseFailed.Label()) {
+		t.Error("expected failed label after permanent clone error")
+	}
+}
+
+func hasAddedLabel(mock *providers.MockProvider, label string) bool {
+	for _, l := range mock.AddedLabels {
+		if l.Label == label {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRunWithIssueTimeout_FailsStuckIssue(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Claude.Command = fakeClaudeCommand(t, "exec sleep 5")
+	cfg.Defaults.IssueTimeout = 200 * time.Millisecond
+	o, mock := newTestOrchestrator(t, cfg)
+
+	issue := &providers.Issue{Number: 1, Title: "Add feature"}
+	mock.AddIssue("owner/repo", issue)
+	st := state.NewState()
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+
+	start := time.Now()
+	err := o.runWithIssueTimeout(context.Background(), "owner/repo", issue, st, sb)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected issue timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("expected worker to be freed promptly, took %v", elapsed)
+	}
+
+	if st.CurrentPhase != state.PhaseFailed {
+		t.Errorf("expected failed phase, got %s", st.CurrentPhase)
+	}
+	if st.FailureReason != "issue_timeout" {
+		t.Errorf("expected failure reason issue_timeout, got %q", st.FailureReason)
+	}
+	if !hasAddedLabel(mock, state.PhaseFailed.Label()) {
+		t.Error("expected failed label")
+	}
+
+	last := mock.CreatedComments[len(mock.CreatedComments)-1]
+	if !strings.Contains(last.Body, "issue timed out") {
+		t.Errorf("expected timeout comment, got %q", last.Body)
+	}
+}
+
+func TestAcknowledgeFeedback_ReactsAndRepliesInThread(t *testing.T) {
+	o, mock := newTestOrchestrator(t, config.DefaultConfig())
+
+	comments := []*providers.Comment{
+		{ID: 1, Body: "Please add tests"},
+		{ID: 2, Body: "Rename this", Path: "main.go", Line: 10},
+	}
+	o.acknowledgeFeedback(context.Background(), "owner/repo", 5, comments, "abc1234")
+
+	if len(mock.Reactions) != 1 || mock.Reactions[0].CommentID != 1 {
+		t.Errorf("expected +1 on general comment 1, got %+v", mock.Reactions)
+	}
+	if len(mock.ReviewReactions) != 1 || mock.ReviewReactions[0].CommentID != 2 {
+		t.Errorf("expected +1 on review comment 2, got %+v", mock.ReviewReactions)
+	}
+	if len(mock.ReviewReplies) != 1 {
+		t.Fatalf("expected 1 review reply, got %d", len(mock.ReviewReplies))
+	}
+	reply := mock.ReviewReplies[0]
+	if reply.CommentID != 2 || reply.PRNumber != 5 || !strings.Contains(reply.Body, "Addressed in abc1234") {
+		t.Errorf("unexpected reply %+v", reply)
+	}
+	if !state.IsBotComment(reply.Body) {
+		t.Error("expected reply to carry the bot marker so it isn't treated as new feedback")
+	}
+}
+
+func TestAcknowledgeFeedback_NoReplyWithoutNewCommit(t *testing.T) {
+	o, mock := newTestOrchestrator(t, config.DefaultConfig())
+
+	comments := []*providers.Comment{{ID: 2, Body: "Rename this", Path: "main.go", Line: 10}}
+	o.acknowledgeFeedback(context.Background(), "owner/repo", 5, comments, "")
+
+	if len(mock.ReviewReactions) != 1 {
+		t.Errorf("expected review comment to still get a reaction, got %+v", mock.ReviewReactions)
+	}
+	if len(mock.ReviewReplies) != 0 {
+		t.Errorf("expected no reply without a new commit, got %+v", mock.ReviewReplies)
+	}
+}
+
+func TestClaudeFailures_FailIssueAfterLimit(t *testing.T) {
+	calls := filepath.Join(t.TempDir(), "calls")
+	cfg := config.DefaultConfig()
+	cfg.Claude.Command = fakeClaudeCommand(t, "echo call >> "+calls+"\necho '503 service unavailable' >&2\nexit 1")
+	cfg.Claude.MaxConsecutiveFailures = 3
+	cfg.Retry.BackoffBase = time.Millisecond
+	o, mock := newTestOrchestrator(t, cfg)
+
+	issue := &providers.Issue{Number: 1, Title: "Add feature"}
+	mock.AddIssue("owner/repo", issue)
+	st := state.NewState()
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+
+	err := o.runWithIssueTimeout(context.Background(), "owner/repo", issue, st, sb)
+	if !errors.Is(err, claude.ErrTooManyFailures) {
+		t.Fatalf("expected ErrTooManyFailures, got %v", err)
+	}
+
+	data, _ := os.ReadFile(calls)
+	if n := strings.Count(string(data), "call"); n != 3 {
+		t.Errorf("expected 3 claude calls, got %d", n)
+	}
+	if st.CurrentPhase != state.PhaseFailed || st.FailureReason != "claude_failures" {
+		t.Errorf("expected failed with claude_failures, got %s / %q", st.CurrentPhase, st.FailureReason)
+	}
+	if st.ClaudeFailures != 3 {
+		t.Errorf("expected 3 recorded failures, got %d", st.ClaudeFailures)
+	}
+}
+
+func TestClaudeFailureHooks_ResetOnSuccess(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Claude.MaxConsecutiveFailures = 3
+	o, _ := newTestOrchestrator(t, cfg)
+	st := state.NewState()
+	hooks := o.claudeAttemptHooks(st)
+
+	hooks.OnFailure(errors.New("claude timed out after 30m"))
+	hooks.OnFailure(errors.New("claude timed out after 30m"))
+	hooks.OnSuccess()
+	if st.ClaudeFailures != 0 {
+		t.Fatalf("expected success to reset failures, got %d", st.ClaudeFailures)
+	}
+
+	if err := hooks.OnFailure(errors.New("rate limit exceeded")); err != nil || st.ClaudeFailures != 0 {
+		t.Errorf("expected rate limits not to count, got err=%v failures=%d", err, st.ClaudeFailures)
+	}
+}
+
+func TestClaudeAttemptHooks_AccumulateUsage(t *testing.T) {
+	o, _ := newTestOrchestrator(t, config.DefaultConfig())
+	st := state.NewState()
+	hooks := o.claudeAttemptHooks(st)
+
+	hooks.OnUsage(claude.Usage{InputTokens: 1000, OutputTokens: 200, CostUSD: 0.5})
+	hooks.OnUsage(claude.Usage{InputTokens: 500, OutputTokens: 100, CostUSD: 0.25})
+	if st.TokensIn != 1500 || st.TokensOut != 300 || st.CostUSD != 0.75 {
+		t.Errorf("expected usage summed across runs, got %d in, %d out, $%v", st.TokensIn, st.TokensOut, st.CostUSD)
+	}
+}
+
+func TestHandleApproval_WritesApprovedPlan(t *testing.T) {
+	o, mock := newTestOrchestrator(t, config.DefaultConfig())
+	issue := &providers.Issue{Number: 1, Title: "Add feature", Author: "alice"}
+	mock.AddIssue("owner/repo", issue)
+
+	// The sandbox was recreated after the plan was posted, so it has no plan.md
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+	st := state.NewState()
+	st.SetPhase(state.PhaseApproval)
+	st.LastCommentTime = time.Now().Add(-time.Hour)
+	if err := o.planPhase.PostPlan(context.Background(), "owner/repo", 1, "# Plan\n\n1. Add cache", st); err != nil {
+		t.Fatal(err)
+	}
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 100, Body: "/approve", Author: "alice", CreatedAt: time.Now()})
+
+	reporter := progress.NewReporterWithState(mock, "owner/repo", 1, time.Minute, false, st)
+	waiting, err := o.handleApproval(context.Background(), "owner/repo", issue, st, sb, reporter)
+	if err != nil || waiting {
+		t.Fatalf("expected approval to proceed, got waiting=%v err=%v", waiting, err)
+	}
+	if st.CurrentPhase != state.PhaseImplementing {
+		t.Errorf("expected implementing phase, got %s", st.CurrentPhase)
+	}
+
+	plan, err := o.planPhase.GetPlan(sb.RepoDir)
+	if err != nil || plan != "# Plan\n\n1. Add cache" {
+		t.Errorf("expected approved plan in sandbox, got %q (err %v)", plan, err)
+	}
+}
+
+func TestHandleApproval_RestoresPlanBeforeFeedback(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Claude.Command = fakeClaudeCommand(t, `echo "1. Use Redis" >> .ultra-engineer/plan.md`)
+	o, mock := newTestOrchestrator(t, cfg)
+	issue := &providers.Issue{Number: 1, Title: "Add feature", Author: "alice"}
+	mock.AddIssue("owner/repo", issue)
+
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+	st := state.NewState()
+	st.SetPhase(state.PhaseApproval)
+	st.LastCommentTime = time.Now().Add(-time.Hour)
+	if err := o.planPhase.PostPlan(context.Background(), "owner/repo", 1, "# Plan", st); err != nil {
+		t.Fatal(err)
+	}
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 100, Body: "Please use Redis", Author: "alice", CreatedAt: time.Now()})
+
+	reporter := progress.NewReporterWithState(mock, "owner/repo", 1, time.Minute, false, st)
+	waiting, err := o.handleApproval(context.Background(), "owner/repo", issue, st, sb, reporter)
+	if err != nil || !waiting {
+		t.Fatalf("expected revised plan to be posted, got waiting=%v err=%v", waiting, err)
+	}
+
+	plan, _ := o.planPhase.GetPlan(sb.RepoDir)
+	if plan != "# Plan\n1. Use Redis" {
+		t.Errorf("expected feedback applied to the posted plan, got %q", plan)
+	}
+}
+
+func TestHandleApproval_ReplanFromScratch(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Claude.Command = fakeClaudeCommand(t, `case "$2" in *"Write an implementation plan"*) echo "# New plan" > .ultra-engineer/plan.md; printf '%s' "$2" > .ultra-engineer/prompt.txt;; esac`)
+	o, mock := newTestOrchestrator(t, cfg)
+	ctx := context.Background()
+	issue := &providers.Issue{Number: 1, Title: "Add feature", Author: "alice"}
+	mock.AddIssue("owner/repo", issue)
+
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+	if err := o.planPhase.WritePlan(sb.RepoDir, "# Old plan"); err != nil {
+		t.Fatal(err)
+	}
+	st := state.NewState()
+	st.SetPhase(state.PhaseApproval)
+	st.LastCommentTime = time.Now().Add(-time.Hour)
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 100, Body: "/replan Use SQLite instead", Author: "alice", CreatedAt: time.Now()})
+
+	reporter := progress.NewReporterWithState(mock, "owner/repo", 1, time.Minute, false, st)
+	waiting, err := o.handleApproval(ctx, "owner/repo", issue, st, sb, reporter)
+	if err != nil || waiting {
+		t.Fatalf("expected /replan to proceed to planning, got waiting=%v err=%v", waiting, err)
+	}
+	if st.CurrentPhase != state.PhasePlanning || !st.PlanOutdated || st.ReplanGuidance != "Use SQLite instead" {
+		t.Fatalf("expected an outdated plan with guidance in planning, got %s outdated=%v guidance=%q", st.CurrentPhase, st.PlanOutdated, st.ReplanGuidance)
+	}
+	if !hasAddedLabel(mock, state.PhasePlanning.Label()) {
+		t.Errorf("expected the planning label, got %v", mock.AddedLabels)
+	}
+
+	if err := o.handlePlanning(ctx, "owner/repo", issue, st, sb, reporter); err != nil {
+		t.Fatalf("handlePlanning failed: %v", err)
+	}
+	if plan, _ := o.planPhase.GetPlan(sb.RepoDir); plan != "# New plan" {
+		t.Errorf("expected a plan written from scratch, got %q", plan)
+	}
+	prompt, _ := os.ReadFile(filepath.Join(sb.RepoDir, ".ultra-engineer", "prompt.txt"))
+	if !strings.Contains(string(prompt), "Use SQLite instead") {
+		t.Errorf("expected guidance in the planning prompt, got %q", prompt)
+	}
+	if st.CurrentPhase != state.PhaseApproval || st.PlanVersion != 1 || st.PlanOutdated || st.ReplanGuidance != "" {
+		t.Errorf("expected plan version 1 awaiting approval, got %s version=%d outdated=%v guidance=%q", st.CurrentPhase, st.PlanVersion, st.PlanOutdated, st.ReplanGuidance)
+	}
+}
+
+func TestHandleApproval_StatusCommand(t *testing.T) {
+	o, mock := newTestOrchestrator(t, config.DefaultConfig())
+	ctx := context.Background()
+	issue := &providers.Issue{Number: 1, Title: "Add feature", Author: "alice"}
+	mock.AddIssue("owner/repo", issue)
+
+	st := state.NewState()
+	st.SetPhase(state.PhaseApproval)
+	st.StartedAt = time.Now().Add(-12 * time.Minute)
+	st.LastCommentTime = time.Now().Add(-time.Hour)
+	statusAt := time.Now()
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 100, Body: "/status", Author: "alice", CreatedAt: statusAt})
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+
+	// A long debounce interval shows the update isn't waiting for it
+	reporter := progress.NewReporterWithState(mock, "owner/repo", 1, time.Hour, true, st)
+	reporter.ForceUpdate(ctx, progress.StatusWaitingApproval)
+
+	waiting, err := o.handleApproval(ctx, "owner/repo", issue, st, sb, reporter)
+	if err != nil || !waiting {
+		t.Fatalf("expected to keep waiting for approval, got waiting=%v err=%v", waiting, err)
+	}
+	if len(mock.UpdatedComments) != 1 {
+		t.Fatalf("expected the progress comment to be refreshed, got %d updates", len(mock.UpdatedComments))
+	}
+	body := mock.UpdatedComments[0].Body
+	if !strings.Contains(body, "📋 Status: phase approval") || !strings.Contains(body, "Elapsed: 12m") {
+		t.Errorf("expected the status summary with elapsed time, got:\n%s", body)
+	}
+	if !st.LastCommentTime.Equal(statusAt) || st.CurrentPhase != state.PhaseApproval {
+		t.Errorf("expected /status consumed in the approval phase, got %s at %v", st.CurrentPhase, st.LastCommentTime)
+	}
+}
+
+func TestHandleReview_StatusCommandOnIssue(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Progress.Enabled = false
+	o, mock := newTestOrchestrator(t, cfg)
+	ctx := context.Background()
+	issue := &providers.Issue{Number: 1, Title: "Add feature"}
+	mock.AddIssue("owner/repo", issue)
+	pr, _ := mock.CreatePR(ctx, "owner/repo", providers.PRCreate{Title: "t", Head: "feat", Base: "main"})
+
+	st := state.NewState()
+	st.SetPhase(state.PhaseReview)
+	st.PRNumber = pr.Number
+	st.LastCommentTime = time.Now().Add(-time.Hour)
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 100, Body: "/status", Author: "alice", CreatedAt: time.Now()})
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+	reporter := progress.NewReporterWithState(mock, "owner/repo", 1, time.Minute, false, st)
+
+	for i := 0; i < 2; i++ {
+		if _, err := o.handleReview(ctx, "owner/repo", issue, st, sb, reporter); err != nil {
+			t.Fatalf("handleReview failed: %v", err)
+		}
+	}
+	if len(mock.CreatedComments) != 1 || !strings.Contains(mock.CreatedComments[0].Body, fmt.Sprintf("phase review, PR #%d", pr.Number)) {
+		t.Fatalf("expected one status comment with the PR, got %+v", mock.CreatedComments)
+	}
+}
+
+func TestHandleReview_ReplanCommand(t *testing.T) {
+	o, mock := newTestOrchestrator(t, config.DefaultConfig())
+	ctx := context.Background()
+	issue := &providers.Issue{Number: 1, Title: "Add feature"}
+	mock.AddIssue("owner/repo", issue)
+	pr, _ := mock.CreatePR(ctx, "owner/repo", providers.PRCreate{Title: "t", Head: "feat", Base: "main"})
+
+	st := state.NewState()
+	st.SetPhase(state.PhaseReview)
+	st.PRNumber = pr.Number
+	st.LastPRCommentTime = time.Now().Add(-time.Hour)
+	replanAt := time.Now()
+	mock.AddPRReviewComment("owner/repo", pr.Number, &providers.Comment{ID: 7, Body: "Rename this", Author: "alice", CreatedAt: replanAt.Add(-time.Minute)})
+	mock.AddPRReviewComment("owner/repo", pr.Number, &providers.Comment{ID: 8, Body: "/replan", Author: "alice", CreatedAt: replanAt})
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+	reporter := progress.NewReporterWithState(mock, "owner/repo", 1, time.Minute, false, st)
+
+	// Addressing the feedback would run Claude, which isn't available here
+	waiting, err := o.handleReview(ctx, "owner/repo", issue, st, sb, reporter)
+	if err != nil || waiting {
+		t.Fatalf("expected /replan to proceed to planning, got waiting=%v err=%v", waiting, err)
+	}
+	if st.CurrentPhase != state.PhasePlanning || !st.PlanOutdated {
+		t.Errorf("expected an outdated plan in planning, got %s outdated=%v", st.CurrentPhase, st.PlanOutdated)
+	}
+	if !st.LastPRCommentTime.Equal(replanAt) || st.PRNumber != pr.Number {
+		t.Errorf("expected PR #%d kept with comments up to the command processed, got PR #%d at %v", pr.Number, st.PRNumber, st.LastPRCommentTime)
+	}
+}
+
+func TestHandleReview_MarksDraftPRReady(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.CI.WaitForCI = false
+	o, mock := newTestOrchestrator(t, cfg)
+	ctx := context.Background()
+	issue := &providers.Issue{Number: 1, Title: "Add feature"}
+	mock.AddIssue("owner/repo", issue)
+	pr, _ := mock.CreatePR(ctx, "owner/repo", providers.PRCreate{Title: "t", Head: "feat", Base: "main", Draft: true})
+
+	st := state.NewState()
+	st.SetPhase(state.PhaseReview)
+	st.PRNumber = pr.Number
+	st.PRDraft = true
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+	reporter := progress.NewReporterWithState(mock, "owner/repo", 1, time.Minute, false, st)
+
+	for i := 0; i < 2; i++ {
+		if _, err := o.handleReview(ctx, "owner/repo", issue, st, sb, reporter); err != nil {
+			t.Fatalf("handleReview failed: %v", err)
+		}
+	}
+	if len(mock.ReadyPRs) != 1 || mock.ReadyPRs[0] != pr.Number {
+		t.Fatalf("expected PR #%d marked ready once, got %v", pr.Number, mock.ReadyPRs)
+	}
+	if st.PRDraft || pr.Draft {
+		t.Errorf("expected the PR to be out of draft")
+	}
+}
+
+func TestHandleReview_ClosesIssueOnMerge(t *testing.T) {
+	for _, closeOnMerge := range []bool{false, true} {
+		t.Run(fmt.Sprintf("close_issue_on_merge=%v", closeOnMerge), func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Defaults.CloseIssueOnMerge = closeOnMerge
+			o, mock := newTestOrchestrator(t, cfg)
+			ctx := context.Background()
+
+			issue := &providers.Issue{Number: 1, Title: "Add feature", State: "open"}
+			mock.AddIssue("owner/repo", issue)
+			pr, _ := mock.CreatePR(ctx, "owner/repo", providers.PRCreate{Title: "t", Head: "feat", Base: "main"})
+			mock.SetPRMergeable("owner/repo", pr.Number, true)
+
+			st := state.NewState()
+			st.SetPhase(state.PhaseReview)
+			st.PRNumber = pr.Number
+			sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+			reporter := progress.NewReporterWithState(mock, "owner/repo", 1, time.Minute, false, st)
+
+			if _, err := o.handleReview(ctx, "owner/repo", issue, st, sb, reporter); err != nil {
+				t.Fatalf("handleReview failed: %v", err)
+			}
+			if st.CurrentPhase != state.PhaseCompleted {
+				t.Fatalf("expected completed phase, got %s", st.CurrentPhase)
+			}
+
+			want := "open"
+			if closeOnMerge {
+				want = "closed"
+			}
+			if got, _ := mock.GetIssue(ctx, "owner/repo", 1); got.State != want {
+				t.Errorf("expected issue state %q, got %q", want, got.State)
+			}
+		})
+	}
+}
+
+func TestBotAssignment(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Claude.Command = fakeClaudeCommand(t, `echo "1. Which API?" > .ultra-engineer/questions.md`)
+	cfg.Defaults.BotUsername = "ultra-bot"
+	o, mock := newTestOrchestrator(t, cfg)
+	ctx := context.Background()
+
+	issue := &providers.Issue{Number: 1, Title: "Add feature"}
+	mock.AddIssue("owner/repo", issue)
+	st := state.NewState()
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+	reporter := progress.NewReporterWithState(mock, "owner/repo", 1, time.Minute, false, st)
+
+	if err := o.handleNew(ctx, "owner/repo", issue, st, sb, reporter); err != nil {
+		t.Fatalf("handleNew failed: %v", err)
+	}
+	if got := mock.Assignees["owner/repo"][1]; len(got) != 1 || got[0] != "ultra-bot" {
+		t.Fatalf("expected bot assigned after handleNew, got %v", got)
+	}
+
+	o.fail(ctx, "owner/repo", 1, st, errors.New("boom"), reporter)
+	if got := mock.Assignees["owner/repo"][1]; len(got) != 0 {
+		t.Errorf("expected bot unassigned after failure, got %v", got)
+	}
+}
+
+func TestAcknowledgeFeedback_UsesConfiguredReactions(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Progress.AckReaction = ""
+	cfg.Progress.DoneReaction = "rocket"
+	o, mock := newTestOrchestrator(t, cfg)
+
+	comments := []*providers.Comment{
+		{ID: 1, Body: "Please add tests"},
+		{ID: 2, Body: "Rename this", Path: "main.go", Line: 10},
+	}
+	o.acknowledgeFeedback(context.Background(), "owner/repo", 5, comments, "")
+
+	if len(mock.Reactions) != 1 || mock.Reactions[0].Reaction != "rocket" {
+		t.Errorf("expected only the done reaction on comment 1, got %+v", mock.Reactions)
+	}
+	if len(mock.ReviewReactions) != 1 || mock.ReviewReactions[0].Reaction != "rocket" {
+		t.Errorf("expected only the done reaction on review comment 2, got %+v", mock.ReviewReactions)
+	}
+}
+
+func TestReact_EmptyReactionIsDisabled(t *testing.T) {
+	o, mock := newTestOrchestrator(t, config.DefaultConfig())
+
+	o.react(context.Background(), "owner/repo", &providers.Comment{ID: 1}, "")
+	o.react(context.Background(), "owner/repo", &providers.Comment{ID: 1}, "eyes")
+
+	if len(mock.Reactions) != 1 || mock.Reactions[0].Reaction != "eyes" {
+		t.Errorf("expected only the eyes reaction, got %+v", mock.Reactions)
+	}
+}
+
+func TestImplementationBranch(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	o, mock := newTestOrchestrator(t, cfg)
+	issue := &providers.Issue{Number: 42, Title: "Add dark mode"}
+	st := state.NewState()
+
+	if got := o.implementationBranch(ctx, "owner/repo", issue, st); got != "" {
+		t.Errorf("expected Claude to choose without a template, got %q", got)
+	}
+
+	cfg.Git.BranchTemplate = "ue/{issue}-{slug}"
+	if got := o.implementationBranch(ctx, "owner/repo", issue, st); got != "ue/42-add-dark-mode" {
+		t.Errorf("expected the rendered template, got %q", got)
+	}
+
+	// A branch left over from an earlier attempt, e.g. merged before /retry
+	mock.AddBranch("owner/repo", "ue/42-add-dark-mode")
+	mock.AddBranch("owner/repo", "ue/42-add-dark-mode-2")
+	if got := o.implementationBranch(ctx, "owner/repo", issue, st); got != "ue/42-add-dark-mode-3" {
+		t.Errorf("expected the first free suffix, got %q", got)
+	}
+
+	st.BranchName = "ue/42-dark-mode"
+	st.PRNumber = 7
+	if got := o.implementationBranch(ctx, "owner/repo", issue, st); got != "ue/42-dark-mode" {
+		t.Errorf("expected the branch of the open PR after a re-plan, got %q", got)
+	}
+
+	mock.BranchExistsError = errors.New("API error 500")
+	st.BranchName, st.PRNumber = "", 0
+	if got := o.implementationBranch(ctx, "owner/repo", issue, st); got != "ue/42-add-dark-mode" {
+		t.Errorf("expected the rendered template when the check fails, got %q", got)
+	}
+}
+
+func TestForgetClosedPR(t *testing.T) {
+	ctx := context.Background()
+	o, mock := newTestOrchestrator(t, config.DefaultConfig())
+	pr, _ := mock.CreatePR(ctx, "owner/repo", providers.PRCreate{Title: "Add dark mode", Head: "ue/42-add-dark-mode", Base: "main"})
+	st := state.NewState()
+	st.PRNumber = pr.Number
+	st.BranchName = "ue/42-add-dark-mode"
+
+	o.forgetClosedPR(ctx, "owner/repo", st)
+	if st.PRNumber != pr.Number || st.BranchName == "" {
+		t.Fatal("expected an open PR to be kept")
+	}
+
+	pr.State = "MERGED"
+	o.forgetClosedPR(ctx, "owner/repo", st)
+	if st.PRNumber != 0 || st.BranchName != "" {
+		t.Errorf("expected a merged PR and its branch to be forgotten, got PR #%d on %q", st.PRNumber, st.BranchName)
+	}
+}
+
+// ciMockProvider adds CI status and branch protection's required checks to the mock provider
+type ciMockProvider struct {
+	*providers.MockProvider
+	result      providers.CIResult
+	required    []string
+	statusCalls int
+}
+
+func (p *ciMockProvider) GetCIStatus(ctx context.Context, repo string, prNumber int) (*providers.CIResult, error) {
+	p.statusCalls++
+	result := p.result
+	result.Checks = slices.Clone(p.result.Checks)
+	return &result, nil
+}
+
+func (p *ciMockProvider) GetCILogs(ctx context.Context, repo string, checkRunID int64) (string, error) {
+	return "", nil
+}
+
+func (p *ciMockProvider) GetRequiredChecks(ctx context.Context, repo, branch string) ([]string, error) {
+	return p.required, nil
+}
+
+func TestHandleCIStatus_BacksOffWhilePending(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.CI.PollInterval = time.Minute
+	cfg.CI.MaxPollInterval = 4 * time.Minute
+	cfg.CI.WaitForCI = true
+	ci := &ciMockProvider{MockProvider: providers.NewMockProvider()}
+	o := New(cfg, ci, NewClaudeClient(cfg), log.New(io.Discard, "", 0))
+	issue := &providers.Issue{Number: 1, Title: "Add feature"}
+	ci.AddIssue("owner/repo", issue)
+
+	// PR #1 is waiting for CI
+	pr, err := ci.CreatePR(context.Background(), "owner/repo", providers.PRCreate{Title: "Add feature", Head: "ue/1", Base: "main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := state.NewState()
+	st.SetPhase(state.PhaseReview)
+	st.PRNumber = pr.Number
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+	reporter := progress.NewReporterWithState(ci, "owner/repo", 1, time.Minute, false, st)
+	ci.result = providers.CIResult{OverallStatus: providers.CIStatusPending, Checks: []providers.CICheck{{Name: "build", Status: providers.CIStatusPending}}}
+
+	// check runs handleCIStatus as a later poll would, once the next check is due if due is set
+	check := func(due bool) {
+		t.Helper()
+		if due {
+			st.CINextCheckTime = time.Now().Add(-time.Second)
+		}
+		result, err := o.handleCIStatus(context.Background(), "owner/repo", issue, st, sb, reporter)
+		if err != nil || !result.shouldWait {
+			t.Fatalf("expected to keep waiting, got %+v, %v", result, err)
+		}
+	}
+
+	check(false)
+	if ci.statusCalls != 1 || st.CICheckInterval != time.Minute || !st.CINextCheckTime.After(time.Now()) {
+		t.Fatalf("expected the first check at the base interval, got %d calls, %v until %v", ci.statusCalls, st.CICheckInterval, st.CINextCheckTime)
+	}
+	check(false)
+	if ci.statusCalls != 1 {
+		t.Errorf("expected a poll before the next check time to skip the check, got %d calls", ci.statusCalls)
+	}
+
+	for _, want := range []time.Duration{2 * time.Minute, 4 * time.Minute, 4 * time.Minute} {
+		check(true)
+		if st.CICheckInterval != want {
+			t.Errorf("expected the interval to back off to %v, got %v", want, st.CICheckInterval)
+		}
+	}
+
+	ci.result.Checks[0].Status = providers.CIStatusSuccess
+	ci.result.Checks = append(ci.result.Checks, providers.CICheck{Name: "lint", Status: providers.CIStatusPending})
+	check(true)
+	if st.CICheckInterval != time.Minute {
+		t.Errorf("expected a changed check to return to the base interval, got %v", st.CICheckInterval)
+	}
+	if ci.statusCalls != 5 {
+		t.Errorf("expected 5 CI checks, got %d", ci.statusCalls)
+	}
+}
+
+func TestHandleCIStatus_IgnoresOptionalCheckFailures(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.CI.RequiredOnly = true
+	cfg.CI.WaitForCI = true
+	ci := &ciMockProvider{MockProvider: providers.NewMockProvider()}
+	o := New(cfg, ci, NewClaudeClient(cfg), log.New(io.Discard, "", 0))
+	issue := &providers.Issue{Number: 1, Title: "Add feature"}
+	ci.AddIssue("owner/repo", issue)
+
+	// PR #1 is waiting for CI
+	pr, err := ci.CreatePR(context.Background(), "owner/repo", providers.PRCreate{Title: "Add feature", Head: "ue/1", Base: "main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := state.NewState()
+	st.SetPhase(state.PhaseReview)
+	st.PRNumber = pr.Number
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+	reporter := progress.NewReporterWithState(ci, "owner/repo", 1, time.Minute, false, st)
+	ci.required = []string{"build"}
+	ci.result = providers.CIResult{OverallStatus: providers.CIStatusFailure, Checks: []providers.CICheck{
+		{Name: "build", Status: providers.CIStatusSuccess},
+		{Name: "lint", Status: providers.CIStatusFailure},
+	}}
+
+	result, err := o.handleCIStatus(context.Background(), "owner/repo", issue, st, sb, reporter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.shouldWait || result.failed {
+		t.Errorf("expected CI to pass on the required checks, got %+v", result)
+	}
+	if st.CIFixAttempts != 0 || st.LastCIStatus != string(providers.CIStatusSuccess) {
+		t.Errorf("expected no fix for the optional check, got %d attempts and status %q", st.CIFixAttempts, st.LastCIStatus)
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -26,19 +30,29 @@ type Daemon struct {
 	allStates    map[string]map[int]*state.State // repo -> issueNum -> state
 	allStatesMu  sync.RWMutex
 	claudeClient *claude.Client
+
+	lastSandboxCleanup time.Time
+	spendLimitLogged   string // Day the daily spend limit was last logged, to log it once
+
+	reloadCh chan *config.Config // Configs queued by Reload, applied by the polling loop
 }
 
+// sandboxCleanupInterval is how often the daemon checks for stale sandboxes
+const sandboxCleanupInterval = time.Hour
+
 // NewDaemon creates a new daemon
 func NewDaemon(cfg *config.Config, provider providers.Provider, logger *log.Logger) *Daemon {
-	claudeClient := claude.NewClientWithRetry(cfg.Claude.Command, cfg.Claude.Timeout, cfg.Retry)
+	// One client for everything the daemon runs Claude for
+	claudeClient := NewClaudeClient(cfg)
 
 	return &Daemon{
 		config:       cfg,
 		provider:     provider,
-		orchestrator: New(cfg, provider, logger),
+		orchestrator: New(cfg, provider, claudeClient, logger),
 		logger:       logger,
 		claudeClient: claudeClient,
 		allStates:    make(map[string]map[int]*state.State),
+		reloadCh:     make(chan *config.Config, 1),
 	}
 }
 
@@ -46,7 +60,10 @@ func NewDaemon(cfg *config.Config, provider providers.Provider, logger *log.Logg
 func (d *Daemon) Run(ctx context.Context, repos []string) error {
 	d.logger.Printf("Starting daemon for repos: %v", repos)
 	d.logger.Printf("Polling interval: %s", d.config.PollInterval)
-	d.logger.Printf("Trigger label: %s", d.config.TriggerLabel)
+	d.logger.Printf("Trigger labels: %s", strings.Join(d.config.TriggerLabelList(), ", "))
+	if d.config.TriggerLabelPrefix != "" {
+		d.logger.Printf("Trigger label prefix: %s", d.config.TriggerLabelPrefix)
+	}
 	d.logger.Printf("Concurrency: max %d per repo, %d total", d.config.Concurrency.MaxPerRepo, d.config.Concurrency.MaxTotal)
 
 	// Initialize worker pool
@@ -57,23 +74,28 @@ func (d *Daemon) Run(ctx context.Context, repos []string) error {
 	// Initialize dependency detector
 	d.depDetector = NewDependencyDetector(d.provider, d.claudeClient, d.config.Concurrency.DependencyDetection)
 
-	ticker := time.NewTicker(d.config.PollInterval)
-	defer ticker.Stop()
-
 	// Initial poll
 	if err := d.poll(ctx, repos); err != nil {
 		d.logger.Printf("Poll error: %v", err)
 	}
 
+	// A timer rather than a ticker, so the interval can stretch when API quota runs low
+	timer := time.NewTimer(d.nextPollInterval(ctx))
+	defer timer.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			d.logger.Printf("Daemon shutting down...")
 			return d.Shutdown(ctx)
-		case <-ticker.C:
+		case <-timer.C:
 			if err := d.poll(ctx, repos); err != nil {
 				d.logger.Printf("Poll error: %v", err)
 			}
+			timer.Reset(d.nextPollInterval(ctx))
+		case cfg := <-d.reloadCh:
+			d.applyConfig(cfg)
+			timer.Reset(d.nextPollInterval(ctx))
 		}
 	}
 }
@@ -88,19 +110,29 @@ func (d *Daemon) poll(ctx context.Context, repos []string) error {
 	// 1. Drain results channel to process completed jobs first
 	d.processCompletedJobs(ctx)
 
-	// 2. Fetch all issues with trigger label across all configured repos
+	// 2. Stop workers whose issue was aborted while in flight
+	d.cancelAbortedJobs(ctx)
+
+	// 3. Fetch all issues with trigger label across all configured repos
 	allIssues := d.fetchTriggeredIssues(ctx, repos)
 
-	// 3. Load state for each issue, filter out completed/failed
+	// 4. Load state for each issue, filter out completed/failed
 	pendingIssues := d.filterPendingIssues(ctx, allIssues)
 
-	// 4. Detect dependencies for new issues
+	// 5. Apply dependency commands, detect dependencies for new issues, failing any
+	// that form a cycle
+	d.applyDependencyCommands(ctx, pendingIssues)
 	d.detectDependencies(ctx, pendingIssues)
+	pendingIssues = d.failDependencyCycles(ctx, pendingIssues)
 
-	// 5. Resolve dependencies, mark blocked issues
+	// 6. Resolve dependencies, mark blocked issues
 	readyIssues := d.resolveReadyIssues(ctx, pendingIssues)
 
-	// 6. Respect per-repo limits when submitting to worker pool
+	// 7. Respect per-repo limits when submitting to worker pool, and start nothing new
+	// once the day's Claude budget is spent
+	if d.dailySpendExceeded() {
+		readyIssues = nil
+	}
 	for _, issueInfo := range readyIssues {
 		job := &Job{
 			Issue:      issueInfo.issue,
@@ -112,17 +144,59 @@ func (d *Daemon) poll(ctx context.Context, repos []string) error {
 		}
 	}
 
-	// 7. Log status of all active/blocked issues
+	// 8. Log status of all active/blocked issues
 	d.reportStatus()
 
+	// 9. Remove sandboxes left behind by failed or abandoned issues
+	d.cleanupStaleSandboxes()
+
 	return nil
 }
 
+// cleanupStaleSandboxes applies the sandbox age and size limits, at most once per sandboxCleanupInterval
+func (d *Daemon) cleanupStaleSandboxes() {
+	cfg := d.config.Sandbox
+	if cfg.MaxAge <= 0 && cfg.MaxTotalBytes <= 0 {
+		return
+	}
+	if time.Since(d.lastSandboxCleanup) < sandboxCleanupInterval {
+		return
+	}
+	d.lastSandboxCleanup = time.Now()
+
+	removed, err := d.orchestrator.sandbox.CleanupStale(cfg.MaxAge, cfg.MaxTotalBytes)
+	if err != nil {
+		d.logger.Printf("Sandbox cleanup error: %v", err)
+	}
+	if len(removed) > 0 {
+		d.logger.Printf("Removed %d stale sandbox(es): %s", len(removed), strings.Join(removed, ", "))
+	}
+}
+
+// dailySpendExceeded reports whether Claude has cost claude.max_cost_per_day today, logging
+// it once per day. Running jobs carry on; the next day's spend starts from zero.
+func (d *Daemon) dailySpendExceeded() bool {
+	limit := d.config.Claude.MaxCostPerDay
+	if limit <= 0 {
+		return false
+	}
+	today := d.orchestrator.spend.Today()
+	if today.CostUSD < limit {
+		return false
+	}
+	if d.spendLimitLogged != today.Day {
+		d.spendLimitLogged = today.Day
+		d.logger.Printf("Claude spent $%.2f today, reaching claude.max_cost_per_day of $%.2f; not starting issues until tomorrow", today.CostUSD, limit)
+	}
+	return true
+}
+
 // issueInfo holds issue data with repo context
 type issueInfo struct {
-	issue *providers.Issue
-	repo  string
-	state *state.State
+	issue    *providers.Issue
+	repo     string
+	state    *state.State
+	comments []*providers.Comment // Fetched once per poll by filterPendingIssues; nil until then
 }
 
 // processCompletedJobs drains the results channel non-blocking
@@ -142,27 +216,24 @@ func (d *Daemon) processCompletedJobs(ctx context.Context) {
 				d.logger.Printf("Issue #%d completed successfully", result.Job.Issue.Number)
 			}
 
-			// Trigger re-evaluation of blocked issues
+			// Trigger re-evaluation of blocked issues, in any repo. It works on a snapshot,
+			// so the lock isn't held while the provider is called
 			d.allStatesMu.RLock()
-			repoStates := d.allStates[result.Job.Repository]
+			taken, snapshot := d.snapshotStates()
 			d.allStatesMu.RUnlock()
-
-			readyIssues, _ := d.orchestrator.CheckAndUnblockIssues(ctx, result.Job.Repository,
-				result.Job.Issue.Number, repoStates)
+			finished := state.IssueRef{Repo: result.Job.Repository, Number: result.Job.Issue.Number}
+			readyJobs, _ := d.orchestrator.CheckAndUnblockIssues(ctx, finished, snapshot)
+			d.allStatesMu.Lock()
+			d.applyStates(taken, snapshot)
+			d.allStatesMu.Unlock()
 
 			// Submit newly-ready issues to worker pool
-			for _, issue := range readyIssues {
-				d.allStatesMu.RLock()
-				st := d.allStates[result.Job.Repository][issue.Number]
-				d.allStatesMu.RUnlock()
-
-				job := &Job{
-					Issue:      issue,
-					Repository: result.Job.Repository,
-					State:      st,
-				}
+			if d.dailySpendExceeded() {
+				readyJobs = nil
+			}
+			for _, job := range readyJobs {
 				if d.workerPool.TrySubmit(job) {
-					d.logger.Printf("Unblocked issue #%d submitted to worker pool", issue.Number)
+					d.logger.Printf("Unblocked issue %s#%d submitted to worker pool", job.Repository, job.Issue.Number)
 				}
 			}
 		default:
@@ -171,12 +242,78 @@ func (d *Daemon) processCompletedJobs(ctx context.Context) {
 	}
 }
 
-// fetchTriggeredIssues fetches all issues with the trigger label from all repos
+// snapshotStates copies allStates for work done without allStatesMu held. taken holds the
+// states that were copied, and snapshot their copies. Callers hold allStatesMu for reading.
+func (d *Daemon) snapshotStates() (taken, snapshot map[string]map[int]*state.State) {
+	taken = make(map[string]map[int]*state.State, len(d.allStates))
+	snapshot = make(map[string]map[int]*state.State, len(d.allStates))
+	for repo, repoStates := range d.allStates {
+		taken[repo] = make(map[int]*state.State, len(repoStates))
+		snapshot[repo] = make(map[int]*state.State, len(repoStates))
+		for issueNum, st := range repoStates {
+			cp := *st
+			cp.PhaseTimings = maps.Clone(st.PhaseTimings)
+			taken[repo][issueNum] = st
+			snapshot[repo][issueNum] = &cp
+		}
+	}
+	return taken, snapshot
+}
+
+// applyStates stores the states of a snapshot back into allStates. States replaced since
+// the snapshot was taken, by a newer poll, are kept. Callers hold allStatesMu.
+func (d *Daemon) applyStates(taken, snapshot map[string]map[int]*state.State) {
+	for repo, repoStates := range snapshot {
+		for issueNum, st := range repoStates {
+			if d.allStates[repo][issueNum] == taken[repo][issueNum] {
+				d.allStates[repo][issueNum] = st
+			}
+		}
+	}
+}
+
+// cancelAbortedJobs cancels in-flight jobs whose issue carries the abort label or got an
+// authorized /abort comment while the job ran. Active issues are fetched directly because
+// abort also removes the trigger label.
+func (d *Daemon) cancelAbortedJobs(ctx context.Context) {
+	if d.workerPool == nil {
+		return
+	}
+
+	for jobID := range d.workerPool.GetActiveStates() {
+		repo, issueNum := ParseJobID(jobID)
+		issue, err := d.provider.GetIssue(ctx, repo, issueNum)
+		if err != nil {
+			d.logger.Printf("Error checking abort label on %s: %v", jobID, err)
+			continue
+		}
+		if hasLabel(issue.Labels, AbortLabel) {
+			// The abort command already failed the issue, so the worker just stops
+			if d.workerPool.CancelJob(jobID, nil) {
+				d.logger.Printf("Issue #%d in %s was aborted, cancelled its worker", issueNum, repo)
+			}
+			continue
+		}
+
+		abort, err := d.orchestrator.FindAbortComment(ctx, repo, issue, d.workerPool.CommentsSince(jobID))
+		if err != nil {
+			d.logger.Printf("Error checking abort comments on %s: %v", jobID, err)
+			continue
+		}
+		// The worker fails the issue itself once it sees ErrUserAborted as the cause
+		if abort != nil && d.workerPool.CancelJob(jobID, ErrUserAborted) {
+			d.orchestrator.react(ctx, repo, abort, d.config.Progress.AckReaction)
+			d.logger.Printf("Issue #%d in %s: %s commented /abort, cancelled its worker", issueNum, repo, abort.Author)
+		}
+	}
+}
+
+// fetchTriggeredIssues fetches all issues with a trigger label from all repos
 func (d *Daemon) fetchTriggeredIssues(ctx context.Context, repos []string) []issueInfo {
 	var allIssues []issueInfo
 
 	for _, repo := range repos {
-		issues, err := d.provider.ListIssuesWithLabel(ctx, repo, d.config.TriggerLabel)
+		issues, err := ListTriggeredIssues(ctx, d.provider, d.config, repo)
 		if err != nil {
 			d.logger.Printf("Error fetching issues from %s: %v", repo, err)
 			continue
@@ -190,23 +327,77 @@ func (d *Daemon) fetchTriggeredIssues(ctx context.Context, repos []string) []iss
 		}
 	}
 
-	return allIssues
+	// Highest priority, then oldest first, so the intake limit favours issues that matter
+	// most and have waited longest
+	sortByPriority(allIssues, d.config.Concurrency)
+
+	return limitIntake(allIssues, d.config.Concurrency.MaxIntakePerPoll)
+}
+
+// limitIntake keeps the first limit unstarted issues from the sorted issues, so a burst of
+// triggered issues doesn't cost a state load each per poll. Paused and completed issues
+// would be skipped anyway and are dropped. Issues with a phase label are kept outside the
+// limit: those in flight or waiting on people must carry on, and failed ones must have
+// retry requests noticed. A limit of 0 keeps everything.
+func limitIntake(issues []issueInfo, limit int) []issueInfo {
+	if limit <= 0 {
+		return issues
+	}
+
+	var kept []issueInfo
+	taken := 0
+	for _, info := range issues {
+		phase := state.ParsePhaseFromLabels(info.issue.Labels)
+		started := slices.ContainsFunc(info.issue.Labels, func(l string) bool { return strings.HasPrefix(l, "phase:") })
+		switch {
+		case hasLabel(info.issue.Labels, PausedLabel) || phase == state.PhaseCompleted:
+			continue
+		case started:
+			kept = append(kept, info)
+		case taken < limit:
+			kept = append(kept, info)
+			taken++
+		}
+	}
+	return kept
 }
 
-// filterPendingIssues loads state for each issue and filters out completed/failed
+// filterPendingIssues loads state for each issue and filters out completed ones, and
+// failed or aborted ones without a /retry
 func (d *Daemon) filterPendingIssues(ctx context.Context, issues []issueInfo) []issueInfo {
 	var pending []issueInfo
 
+	var activeStates map[string]*state.State
+	if d.workerPool != nil {
+		activeStates = d.workerPool.GetActiveStates()
+	}
+
 	for _, info := range issues {
+		// Skip paused issues entirely; state is left untouched for resume
+		if hasLabel(info.issue.Labels, PausedLabel) {
+			continue
+		}
+
 		phase := state.ParsePhaseFromLabels(info.issue.Labels)
 
-		// Skip completed/failed issues
-		if phase == state.PhaseCompleted || phase == state.PhaseFailed {
+		// Skip completed issues; failed and aborted ones are checked for /retry below
+		if phase == state.PhaseCompleted {
 			continue
 		}
 
+		// Fetch the comments once per poll; state, waiting phases and dependency
+		// commands are all read from them
+		comments, err := d.provider.GetComments(ctx, info.repo, info.issue.Number)
+		if err != nil {
+			d.logger.Printf("Error fetching comments for #%d: %v", info.issue.Number, err)
+			continue
+		}
+		if comments == nil {
+			comments = []*providers.Comment{}
+		}
+
 		// Load or create state
-		st, err := d.orchestrator.loadState(ctx, info.repo, info.issue.Number)
+		st, err := d.orchestrator.loadStateFrom(info.repo, info.issue.Number, comments)
 		if err != nil {
 			st = state.NewState()
 			if phase != state.PhaseNew {
@@ -219,8 +410,13 @@ func (d *Daemon) filterPendingIssues(ctx context.Context, issues []issueInfo) []
 			continue
 		}
 
-		// Skip failed issues unless retry was requested
-		if st.CurrentPhase == state.PhaseFailed {
+		// The abort command only sets labels
+		if phase.Stopped() && !st.CurrentPhase.Stopped() {
+			st.CurrentPhase = phase
+		}
+
+		// Skip failed and aborted issues unless retry was requested
+		if st.CurrentPhase.Stopped() {
 			if d.orchestrator.CheckForRetry(ctx, info.repo, info.issue, st) {
 				d.logger.Printf("Retry requested for issue #%d", info.issue.Number)
 				// State was updated by CheckForRetry, continue to process
@@ -230,10 +426,15 @@ func (d *Daemon) filterPendingIssues(ctx context.Context, issues []issueInfo) []
 			}
 		}
 
+		// Offer a re-plan when the issue was edited after Q&A; the state of an issue a
+		// worker is processing belongs to the worker
+		if _, active := activeStates[fmt.Sprintf("%s-%d", info.repo, info.issue.Number)]; !active {
+			d.checkIssueEdit(ctx, info.repo, info.issue, st)
+		}
+
 		// Skip waiting phases (questions, approval) unless there's new comment activity
 		if st.CurrentPhase == state.PhaseQuestions || st.CurrentPhase == state.PhaseApproval {
-			hasNewComment, _ := d.orchestrator.HasNewComment(ctx, info.repo, info.issue.Number, st)
-			if !hasNewComment {
+			if !hasNewComment(comments, st) {
 				continue // No new activity, skip
 			}
 		}
@@ -247,17 +448,28 @@ func (d *Daemon) filterPendingIssues(ctx context.Context, issues []issueInfo) []
 		d.allStatesMu.Unlock()
 
 		info.state = st
+		info.comments = comments
 		pending = append(pending, info)
 	}
 
 	return pending
 }
 
+// hasLabel checks if a label is present in a list of labels
+func hasLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
 // detectDependencies detects dependencies for issues that don't have them yet
 func (d *Daemon) detectDependencies(ctx context.Context, issues []issueInfo) {
 	for _, info := range issues {
-		// Skip if dependencies already detected
-		if info.state.DependsOn != nil {
+		// Skip if dependencies already detected or declared by command
+		if info.state.DependsOn != nil || !info.state.DependencyCommandTime.IsZero() {
 			continue
 		}
 
@@ -272,42 +484,78 @@ func (d *Daemon) detectDependencies(ctx context.Context, issues []issueInfo) {
 			d.logger.Printf("Issue #%d depends on: %v", info.issue.Number, deps)
 		}
 	}
-
-	// Check for cycles across all known issues
-	d.checkForCycles()
 }
 
-// checkForCycles checks for dependency cycles across all tracked issues
-func (d *Daemon) checkForCycles() {
-	d.allStatesMu.RLock()
-	defer d.allStatesMu.RUnlock()
+// failDependencyCycles checks the tracked issues of all repos for dependency cycles,
+// fails exactly the issues that are part of one and returns the remaining pending
+// issues. Issues that only depend on a cycle stay blocked.
+func (d *Daemon) failDependencyCycles(ctx context.Context, pending []issueInfo) []issueInfo {
+	type cycleMember struct {
+		ref   state.IssueRef
+		state *state.State
+		cycle DependencyCycle
+	}
+	var members []cycleMember
+	inCycle := make(map[state.IssueRef]bool)
+
+	// Get active states snapshot before acquiring allStatesMu to avoid deadlock
+	activeStates := d.workerPool.GetActiveStates()
 
-	depGraph := make(map[int][]int)
-	for _, repoStates := range d.allStates {
+	d.allStatesMu.RLock()
+	// One graph across repos, keyed by absolute references, so cycles spanning
+	// repositories are found too
+	depGraph := make(map[state.IssueRef][]state.IssueRef)
+	for repo, repoStates := range d.allStates {
 		for issueNum, st := range repoStates {
-			if len(st.DependsOn) > 0 {
-				depGraph[issueNum] = st.DependsOn
+			if len(st.DependsOn) == 0 || st.CurrentPhase.Stopped() {
+				continue
+			}
+			ref := state.IssueRef{Repo: repo, Number: issueNum}
+			for _, dep := range st.DependsOn {
+				depGraph[ref] = append(depGraph[ref], dep.Resolve(repo))
 			}
 		}
 	}
 
-	if err := d.depDetector.CheckForCycles(depGraph); err != nil {
+	cycles, err := d.depDetector.CheckForCycles(depGraph)
+	if err != nil {
 		d.logger.Printf("Warning: %v", err)
-		// Mark issues in cycles as failed
-		// This would require more complex cycle detection to identify which issues
 	}
+	for _, cycle := range cycles {
+		for _, ref := range cycle.Issues {
+			inCycle[ref] = true
+			// Leave an issue a worker is already processing to that worker
+			if _, active := activeStates[fmt.Sprintf("%s-%d", ref.Repo, ref.Number)]; !active {
+				members = append(members, cycleMember{ref, d.allStates[ref.Repo][ref.Number], cycle})
+			}
+		}
+	}
+	d.allStatesMu.RUnlock()
+
+	if len(members) == 0 {
+		return pending
+	}
+	for _, m := range members {
+		d.orchestrator.failDependencyCycle(ctx, m.ref.Repo, m.ref.Number, m.state, m.cycle)
+	}
+
+	var remaining []issueInfo
+	for _, info := range pending {
+		if !inCycle[state.IssueRef{Repo: info.repo, Number: info.issue.Number}] {
+			remaining = append(remaining, info)
+		}
+	}
+	return remaining
 }
 
 // resolveReadyIssues returns issues that are ready to be processed (all deps satisfied)
 func (d *Daemon) resolveReadyIssues(ctx context.Context, issues []issueInfo) []issueInfo {
-	var ready []issueInfo
+	var ready, newlyBlocked []issueInfo
 
 	// Get active states snapshot before acquiring allStatesMu to avoid deadlock
 	activeStates := d.workerPool.GetActiveStates()
 
 	d.allStatesMu.RLock()
-	defer d.allStatesMu.RUnlock()
-
 	for _, info := range issues {
 		// Check if this specific issue is already being processed
 		jobID := fmt.Sprintf("%s-%d", info.repo, info.issue.Number)
@@ -316,32 +564,56 @@ func (d *Daemon) resolveReadyIssues(ctx context.Context, issues []issueInfo) []i
 		}
 
 		// Check dependencies
-		repoStates := d.allStates[info.repo]
-		if d.orchestrator.CanProceed(ctx, info.repo, info.issue, info.state, repoStates) {
+		if d.orchestrator.CanProceed(ctx, info.repo, info.issue, info.state, d.allStates) {
+			info.state.BlockedBy = nil
 			ready = append(ready, info)
 		} else {
 			// Update blocked_by field if blocked
-			info.state.BlockedBy = d.getBlockingIssues(info.state, repoStates)
+			blockedBy := d.getBlockingIssues(info.repo, info.state, d.allStates)
+			if !slices.Equal(blockedBy, info.state.BlockedBy) {
+				newlyBlocked = append(newlyBlocked, info)
+			}
+			info.state.BlockedBy = blockedBy
 		}
 	}
+	d.allStatesMu.RUnlock()
 
+	// Persist changed blockers so the dependency graph survives restarts
+	for _, info := range newlyBlocked {
+		d.orchestrator.reportBlocked(ctx, info.repo, info.issue.Number, info.state)
+	}
+
+	sortByPriority(ready, d.config.Concurrency)
 	return ready
 }
 
+// sortByPriority orders issues so higher priority labels are submitted first when
+// worker slots are scarce; issues of equal priority are ordered oldest first
+func sortByPriority(issues []issueInfo, cfg config.ConcurrencyConfig) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		pi, pj := cfg.Priority(issues[i].issue.Labels), cfg.Priority(issues[j].issue.Labels)
+		if pi != pj {
+			return pi > pj
+		}
+		return issues[i].issue.CreatedAt.Before(issues[j].issue.CreatedAt)
+	})
+}
+
 // getBlockingIssues returns the list of issues blocking this one
-func (d *Daemon) getBlockingIssues(st *state.State, repoStates map[int]*state.State) []int {
-	var blocking []int
+func (d *Daemon) getBlockingIssues(repo string, st *state.State, allStates map[string]map[int]*state.State) []state.IssueRef {
+	var blocking []state.IssueRef
 
-	for _, depNum := range st.DependsOn {
-		depState, exists := repoStates[depNum]
+	for _, dep := range st.DependsOn {
+		r := dep.Resolve(repo)
+		depState, exists := allStates[r.Repo][r.Number]
 		if !exists {
 			// Unknown dependency - consider it blocking
-			blocking = append(blocking, depNum)
+			blocking = append(blocking, dep)
 			continue
 		}
 
 		if depState.CurrentPhase != state.PhaseCompleted {
-			blocking = append(blocking, depNum)
+			blocking = append(blocking, dep)
 		}
 	}
 
@@ -354,6 +626,18 @@ func (d *Daemon) reportStatus() {
 	if activeCount > 0 {
 		d.logger.Printf("Active jobs: %d", activeCount)
 	}
+	d.reportSuppressed()
+}
+
+// reportSuppressed lists the writes a dry run held back since it last reported
+func (d *Daemon) reportSuppressed() {
+	dryRun, ok := d.provider.(*providers.DryRunProvider)
+	if !ok {
+		return
+	}
+	if actions := dryRun.TakeSuppressed(); len(actions) > 0 {
+		d.logger.Printf("Dry run: %d write(s) not sent:\n  %s", len(actions), strings.Join(actions, "\n  "))
+	}
 }
 
 // processJobWorker is the worker function that processes a single job
@@ -399,12 +683,13 @@ func (d *Daemon) persistAllInProgressStates(ctx context.Context) {
 	activeStates := d.workerPool.GetActiveStates()
 	for jobID, st := range activeStates {
 		repo, issueNum := ParseJobID(jobID)
+		d.orchestrator.saveState(repo, issueNum, st)
 		comment, err := st.AppendToBody("State saved during shutdown")
 		if err != nil {
 			d.logger.Printf("Failed to serialize state for %s: %v", jobID, err)
 			continue
 		}
-		if _, err := d.provider.CreateComment(ctx, repo, issueNum, comment); err != nil {
+		if _, err := d.provider.CreateComment(ctx, repo, issueNum, state.AddBotMarker(comment)); err != nil {
 			d.logger.Printf("Failed to persist state for %s: %v", jobID, err)
 		}
 	}
@@ -417,5 +702,6 @@ func (d *Daemon) RunOnce(ctx context.Context, repo string, issueNum int) error {
 		return err
 	}
 
+	defer d.reportSuppressed()
 	return d.orchestrator.ProcessIssue(ctx, repo, issue)
 }
@@ -0,0 +1,528 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/sandbox"
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+func TestFilterPendingIssues_SkipsPaused(t *testing.T) {
+	mock := providers.NewMockProvider()
+	cfg := config.DefaultConfig()
+	d := NewDaemon(cfg, mock, log.New(io.Discard, "", 0))
+
+	issues := []issueInfo{
+		{repo: "owner/repo", issue: &providers.Issue{Number: 1, Labels: []string{cfg.TriggerLabel}}},
+		{repo: "owner/repo", issue: &providers.Issue{Number: 2, Labels: []string{cfg.TriggerLabel, PausedLabel, "phase:implementing"}}},
+	}
+
+	pending := d.filterPendingIssues(context.Background(), issues)
+	if len(pending) != 1 {
+		t.Fatalf("expected 1 pending issue, got %d", len(pending))
+	}
+	if pending[0].issue.Number != 1 {
+		t.Errorf("expected issue #1 to be pending, got #%d", pending[0].issue.Number)
+	}
+}
+
+func TestNewDaemon_SharesClaudeClient(t *testing.T) {
+	d := NewDaemon(config.DefaultConfig(), providers.NewMockProvider(), log.New(io.Discard, "", 0))
+
+	if d.claudeClient == nil || d.orchestrator.claude != d.claudeClient {
+		t.Error("expected the orchestrator to use the daemon's Claude client")
+	}
+}
+
+func TestReportStatus_ListsDryRunWrites(t *testing.T) {
+	var out strings.Builder
+	logger := log.New(&out, "", 0)
+	dryRun := providers.NewDryRunProvider(providers.NewMockProvider(), log.New(io.Discard, "", 0))
+	d := NewDaemon(config.DefaultConfig(), dryRun, logger)
+	d.workerPool = NewWorkerPool(context.Background(), 1, 1)
+	t.Cleanup(d.workerPool.Cancel)
+
+	dryRun.CreateComment(context.Background(), "owner/repo", 1, "Working on it")
+	dryRun.AddLabel(context.Background(), "owner/repo", 1, "phase:planning")
+	d.reportStatus()
+
+	want := "Dry run: 2 write(s) not sent:\n  comment on owner/repo#1\n  add label \"phase:planning\" to owner/repo#1\n"
+	if out.String() != want {
+		t.Errorf("expected %q, got %q", want, out.String())
+	}
+
+	// Each write is reported once
+	out.Reset()
+	d.reportStatus()
+	if out.Len() != 0 {
+		t.Errorf("expected nothing new to report, got %q", out.String())
+	}
+}
+
+func TestCancelAbortedJobs_CancelsOnlyAbortedIssue(t *testing.T) {
+	mock := providers.NewMockProvider()
+	cfg := config.DefaultConfig()
+	d := NewDaemon(cfg, mock, log.New(io.Discard, "", 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	started := make(chan struct{}, 2)
+	d.workerPool = NewWorkerPool(ctx, 2, 2)
+	d.workerPool.SetWorkerFunc(func(ctx context.Context, job *Job) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	d.workerPool.Start()
+	defer d.workerPool.Cancel()
+
+	aborted := &providers.Issue{Number: 1, Labels: []string{AbortLabel, state.PhaseFailed.Label()}}
+	running := &providers.Issue{Number: 2, Labels: []string{cfg.TriggerLabel}}
+	mock.AddIssue("owner/repo", aborted)
+	mock.AddIssue("owner/repo", running)
+
+	for _, issue := range []*providers.Issue{aborted, running} {
+		if !d.workerPool.TrySubmit(&Job{Issue: issue, Repository: "owner/repo", State: state.NewState()}) {
+			t.Fatalf("failed to submit issue #%d", issue.Number)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		<-started
+	}
+
+	d.cancelAbortedJobs(ctx)
+
+	select {
+	case result := <-d.workerPool.Results():
+		if result.Job.Issue.Number != 1 {
+			t.Errorf("expected aborted issue #1 to stop, got #%d", result.Job.Issue.Number)
+		}
+		if !errors.Is(result.Error, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", result.Error)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("aborted job was not cancelled")
+	}
+
+	select {
+	case result := <-d.workerPool.Results():
+		t.Errorf("expected issue #%d to keep running", result.Job.Issue.Number)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestCancelAbortedJobs_AbortCommentFailsImplementation(t *testing.T) {
+	started := filepath.Join(t.TempDir(), "started")
+	cfg := config.DefaultConfig()
+	cfg.Claude.Command = fakeClaudeCommand(t, "touch "+started+"\nexec sleep 30")
+	mock := providers.NewMockProvider()
+	d := NewDaemon(cfg, mock, log.New(io.Discard, "", 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+	d.workerPool = NewWorkerPool(ctx, 1, 1)
+	d.workerPool.SetWorkerFunc(func(ctx context.Context, job *Job) error {
+		return d.orchestrator.runStateMachine(ctx, job.Repository, job.Issue, job.State, sb)
+	})
+	d.workerPool.Start()
+	defer d.workerPool.Cancel()
+
+	issue := &providers.Issue{Number: 1, Title: "Add feature", Author: "alice", Labels: []string{cfg.TriggerLabel}}
+	mock.AddIssue("owner/repo", issue)
+	st := state.NewState()
+	st.CurrentPhase = state.PhaseImplementing
+	st.LastCommentTime = time.Now().Add(-time.Hour)
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 1, Body: "/abort", Author: "alice", CreatedAt: st.LastCommentTime.Add(-time.Minute)})
+
+	if !d.workerPool.TrySubmit(&Job{Issue: issue, Repository: "owner/repo", State: st}) {
+		t.Fatal("failed to submit the issue")
+	}
+	for _, err := os.Stat(started); err != nil; _, err = os.Stat(started) {
+		if ctx.Err() != nil {
+			t.Fatal("implementation did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	d.cancelAbortedJobs(ctx)
+	select {
+	case result := <-d.workerPool.Results():
+		t.Fatalf("expected an /abort from before the job to be ignored, job ended with: %v", result.Error)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	mock.AddComment("owner/repo", 1, &providers.Comment{ID: 2, Body: "/abort", Author: "alice", CreatedAt: time.Now()})
+	d.cancelAbortedJobs(ctx)
+
+	select {
+	case result := <-d.workerPool.Results():
+		if !errors.Is(result.Error, ErrUserAborted) {
+			t.Errorf("expected ErrUserAborted, got %v", result.Error)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("aborted job was not cancelled")
+	}
+	if st.CurrentPhase != state.PhaseAborted || st.FailureReason != "user_aborted" {
+		t.Errorf("expected the issue aborted as user_aborted, got phase %s, reason %q", st.CurrentPhase, st.FailureReason)
+	}
+	if !slices.Contains(issue.Labels, state.PhaseAborted.Label()) {
+		t.Error("expected the aborted label despite the cancelled job context")
+	}
+	if len(mock.Reactions) != 1 || mock.Reactions[0].CommentID != 2 {
+		t.Errorf("expected the /abort comment to be acknowledged, got reactions %v", mock.Reactions)
+	}
+}
+
+func TestFilterPendingIssues_RetriesFailedAndAborted(t *testing.T) {
+	mock := providers.NewMockProvider()
+	cfg := config.DefaultConfig()
+	d := NewDaemon(cfg, mock, log.New(io.Discard, "", 0))
+
+	stopped := []struct {
+		phase state.Phase
+		retry bool
+	}{
+		{state.PhaseFailed, true},
+		{state.PhaseAborted, true},
+		{state.PhaseAborted, false},
+	}
+	var issues []issueInfo
+	for i, tt := range stopped {
+		n := i + 1
+		issue := &providers.Issue{Number: n, Labels: []string{cfg.TriggerLabel, tt.phase.Label()}}
+		mock.AddIssue("owner/repo", issue)
+		issues = append(issues, issueInfo{repo: "owner/repo", issue: issue})
+
+		st := state.NewState()
+		st.CurrentPhase = tt.phase
+		addStateComment(t, mock, "owner/repo", n, st)
+		if tt.retry {
+			mock.AddComment("owner/repo", n, &providers.Comment{ID: int64(10 + n), Body: "/retry", Author: "alice", CreatedAt: time.Now()})
+		}
+	}
+
+	pending := d.filterPendingIssues(context.Background(), issues)
+	if len(pending) != 2 || pending[0].issue.Number != 1 || pending[1].issue.Number != 2 {
+		t.Fatalf("expected the failed and aborted issues with /retry to be pending, got %d issues", len(pending))
+	}
+	for _, info := range pending {
+		labels := info.issue.Labels
+		if !slices.Contains(labels, state.PhaseImplementing.Label()) ||
+			slices.Contains(labels, state.PhaseFailed.Label()) || slices.Contains(labels, state.PhaseAborted.Label()) {
+			t.Errorf("expected issue #%d relabelled for implementation, got %v", info.issue.Number, labels)
+		}
+	}
+}
+
+func TestFetchTriggeredIssues_CapsIntakeToOldest(t *testing.T) {
+	mock := providers.NewMockProvider()
+	cfg := config.DefaultConfig()
+	cfg.Concurrency.MaxIntakePerPoll = 2
+	d := NewDaemon(cfg, mock, log.New(io.Discard, "", 0))
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.AddIssue("owner/a", &providers.Issue{Number: 1, Labels: []string{cfg.TriggerLabel}, CreatedAt: base.Add(3 * time.Hour)})
+	mock.AddIssue("owner/a", &providers.Issue{Number: 2, Labels: []string{cfg.TriggerLabel}, CreatedAt: base.Add(1 * time.Hour)})
+	mock.AddIssue("owner/b", &providers.Issue{Number: 3, Labels: []string{cfg.TriggerLabel}, CreatedAt: base.Add(2 * time.Hour)})
+	mock.AddIssue("owner/b", &providers.Issue{Number: 4, Labels: []string{cfg.TriggerLabel, PausedLabel}, CreatedAt: base})
+	mock.AddIssue("owner/b", &providers.Issue{Number: 5, Labels: []string{cfg.TriggerLabel, state.PhaseFailed.Label()}, CreatedAt: base.Add(4 * time.Hour)})
+	mock.AddIssue("owner/b", &providers.Issue{Number: 6, Labels: []string{cfg.TriggerLabel, state.PhaseApproval.Label()}, CreatedAt: base.Add(5 * time.Hour)})
+	mock.AddIssue("owner/a", &providers.Issue{Number: 7, Labels: []string{cfg.TriggerLabel, state.PhaseReview.Label()}, CreatedAt: base.Add(6 * time.Hour)})
+
+	issues := d.fetchTriggeredIssues(context.Background(), []string{"owner/a", "owner/b"})
+
+	var got []int
+	for _, info := range issues {
+		got = append(got, info.issue.Number)
+	}
+	// The two oldest unstarted issues, plus the started ones: those waiting on people or in
+	// review carry on, and /retry is still seen on the failed one
+	want := []int{2, 3, 5, 6, 7}
+	if len(got) != len(want) {
+		t.Fatalf("expected issues %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected issues %v, got %v", want, got)
+		}
+	}
+}
+
+func TestPoll_SubmitsHighPriorityFirst(t *testing.T) {
+	mock := providers.NewMockProvider()
+	cfg := config.DefaultConfig()
+	d := NewDaemon(cfg, mock, log.New(io.Discard, "", 0))
+	d.depDetector = NewDependencyDetector(mock, nil, "disabled")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// One worker slot, so only the first submitted issue runs
+	submitted := make(chan int, 3)
+	d.workerPool = NewWorkerPool(ctx, 1, 1)
+	d.workerPool.SetWorkerFunc(func(ctx context.Context, job *Job) error {
+		submitted <- job.Issue.Number
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	d.workerPool.Start()
+	defer d.workerPool.Cancel()
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.AddIssue("owner/repo", &providers.Issue{Number: 1, Labels: []string{cfg.TriggerLabel, "priority:low"}, CreatedAt: base})
+	mock.AddIssue("owner/repo", &providers.Issue{Number: 2, Labels: []string{cfg.TriggerLabel}, CreatedAt: base.Add(time.Hour)})
+	mock.AddIssue("owner/repo", &providers.Issue{Number: 3, Labels: []string{cfg.TriggerLabel, "priority:high"}, CreatedAt: base.Add(2 * time.Hour)})
+
+	if err := d.poll(ctx, []string{"owner/repo"}); err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+
+	select {
+	case num := <-submitted:
+		if num != 3 {
+			t.Errorf("expected high-priority issue #3 to take the slot, got #%d", num)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no issue was submitted")
+	}
+}
+
+func TestSortByPriority_TiesBrokenByAge(t *testing.T) {
+	cfg := config.DefaultConfig().Concurrency
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	issues := []issueInfo{
+		{issue: &providers.Issue{Number: 1, Labels: []string{"priority:medium"}, CreatedAt: base.Add(time.Hour)}},
+		{issue: &providers.Issue{Number: 2, Labels: []string{"priority:low", "priority:high"}, CreatedAt: base.Add(2 * time.Hour)}},
+		{issue: &providers.Issue{Number: 3, CreatedAt: base}},
+		{issue: &providers.Issue{Number: 4, Labels: []string{"priority:low"}, CreatedAt: base}},
+	}
+
+	sortByPriority(issues, cfg)
+
+	want := []int{2, 3, 1, 4}
+	for i, info := range issues {
+		if info.issue.Number != want[i] {
+			t.Fatalf("expected order %v, got #%d at position %d", want, info.issue.Number, i)
+		}
+	}
+}
+
+func TestResolveReadyIssues_PersistsBlockers(t *testing.T) {
+	mock := providers.NewMockProvider()
+	cfg := config.DefaultConfig()
+	d := NewDaemon(cfg, mock, log.New(io.Discard, "", 0))
+	d.workerPool = NewWorkerPool(context.Background(), 1, 1)
+	defer d.workerPool.Cancel()
+
+	blocker := &providers.Issue{Number: 1, Labels: []string{cfg.TriggerLabel}}
+	blocked := &providers.Issue{Number: 2, Labels: []string{cfg.TriggerLabel}}
+	mock.AddIssue("owner/repo", blocker)
+	mock.AddIssue("owner/repo", blocked)
+
+	st := state.NewState()
+	st.DependsOn = refs(1)
+	d.allStates["owner/repo"] = map[int]*state.State{1: state.NewState(), 2: st}
+	issues := []issueInfo{{repo: "owner/repo", issue: blocked, state: st}}
+
+	for i := 0; i < 2; i++ {
+		if ready := d.resolveReadyIssues(context.Background(), issues); len(ready) != 0 {
+			t.Fatalf("expected #2 to be blocked, got %d ready", len(ready))
+		}
+	}
+
+	// The blocker is recorded once, in a state-carrying progress comment
+	comments, _ := mock.GetComments(context.Background(), "owner/repo", 2)
+	if len(comments) != 1 {
+		t.Fatalf("expected one progress comment, got %d", len(comments))
+	}
+	saved, err := state.Parse(comments[0].Body)
+	if err != nil {
+		t.Fatalf("progress comment has no state: %v", err)
+	}
+	if len(saved.BlockedBy) != 1 || saved.BlockedBy[0] != (state.IssueRef{Number: 1}) || len(saved.DependsOn) != 1 {
+		t.Errorf("expected persisted dependency on #1, got depends_on=%v blocked_by=%v", saved.DependsOn, saved.BlockedBy)
+	}
+}
+
+func TestFailDependencyCycles_FailsOnlyCycleMembers(t *testing.T) {
+	mock := providers.NewMockProvider()
+	cfg := config.DefaultConfig()
+	d := NewDaemon(cfg, mock, log.New(io.Discard, "", 0))
+	d.depDetector = NewDependencyDetector(mock, nil, "disabled")
+	d.workerPool = NewWorkerPool(context.Background(), 1, 1)
+	defer d.workerPool.Cancel()
+
+	// #1 and #2 form a cycle, #3 and #4 another; #5 only depends on the first cycle
+	deps := map[int][]int{1: {2}, 2: {1}, 3: {4}, 4: {3}, 5: {1}}
+	var pending []issueInfo
+	d.allStates["owner/repo"] = make(map[int]*state.State)
+	for num := 1; num <= 5; num++ {
+		issue := &providers.Issue{Number: num, Labels: []string{cfg.TriggerLabel}}
+		mock.AddIssue("owner/repo", issue)
+		st := state.NewState()
+		st.DependsOn = refs(deps[num]...)
+		d.allStates["owner/repo"][num] = st
+		pending = append(pending, issueInfo{repo: "owner/repo", issue: issue, state: st})
+	}
+
+	remaining := d.failDependencyCycles(context.Background(), pending)
+
+	if len(remaining) != 1 || remaining[0].issue.Number != 5 {
+		t.Fatalf("expected only #5 to remain pending, got %v", remaining)
+	}
+	for num := 1; num <= 5; num++ {
+		st := d.allStates["owner/repo"][num]
+		inCycle := num != 5
+		if inCycle != (st.CurrentPhase == state.PhaseFailed && st.FailureReason == "dependency_cycle") {
+			t.Errorf("#%d: phase %s, failure reason %q", num, st.CurrentPhase, st.FailureReason)
+		}
+	}
+
+	comments, _ := mock.GetComments(context.Background(), "owner/repo", 3)
+	found := false
+	for _, c := range comments {
+		if strings.Contains(c.Body, "#3 -> #4 -> #3") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected #3 to get a comment listing its cycle")
+	}
+	if issue, _ := mock.GetIssue(context.Background(), "owner/repo", 5); hasLabel(issue.Labels, state.PhaseFailed.Label()) {
+		t.Error("expected #5 to be left alone")
+	}
+}
+
+func TestCrossRepoDependency_BlocksUntilCompleted(t *testing.T) {
+	mock := providers.NewMockProvider()
+	cfg := config.DefaultConfig()
+	d := NewDaemon(cfg, mock, log.New(io.Discard, "", 0))
+	d.workerPool = NewWorkerPool(context.Background(), 1, 1)
+	defer d.workerPool.Cancel()
+
+	// owner/app#2 depends on owner/lib#2, which has the same number in another repo
+	lib := &providers.Issue{Number: 2, Labels: []string{cfg.TriggerLabel}}
+	app := &providers.Issue{Number: 2, Labels: []string{cfg.TriggerLabel}}
+	mock.AddIssue("owner/lib", lib)
+	mock.AddIssue("owner/app", app)
+
+	libState := state.NewState()
+	appState := state.NewState()
+	appState.DependsOn = []state.IssueRef{{Repo: "owner/lib", Number: 2}}
+	d.allStates["owner/lib"] = map[int]*state.State{2: libState}
+	d.allStates["owner/app"] = map[int]*state.State{2: appState}
+	issues := []issueInfo{{repo: "owner/app", issue: app, state: appState}}
+
+	if ready := d.resolveReadyIssues(context.Background(), issues); len(ready) != 0 {
+		t.Fatalf("expected owner/app#2 to be blocked, got %d ready", len(ready))
+	}
+	if !slices.Equal(appState.BlockedBy, appState.DependsOn) {
+		t.Fatalf("expected owner/app#2 to be blocked by owner/lib#2, got %v", appState.BlockedBy)
+	}
+
+	libState.CurrentPhase = state.PhaseCompleted
+	finished := state.IssueRef{Repo: "owner/lib", Number: 2}
+	jobs, err := d.orchestrator.CheckAndUnblockIssues(context.Background(), finished, d.allStates)
+	if err != nil {
+		t.Fatalf("CheckAndUnblockIssues failed: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].Repository != "owner/app" || jobs[0].State != appState {
+		t.Fatalf("expected owner/app#2 to be unblocked, got %v", jobs)
+	}
+
+	comments, _ := mock.GetComments(context.Background(), "owner/app", 2)
+	if len(comments) == 0 || !strings.Contains(comments[len(comments)-1].Body, "Dependency owner/lib#2 completed") {
+		t.Errorf("expected an unblock comment naming owner/lib#2, got %v", comments)
+	}
+}
+
+func TestProcessCompletedJobs_StoresUnblockedStates(t *testing.T) {
+	mock := providers.NewMockProvider()
+	cfg := config.DefaultConfig()
+	d := NewDaemon(cfg, mock, log.New(io.Discard, "", 0))
+	d.workerPool = NewWorkerPool(context.Background(), 1, 1)
+	defer d.workerPool.Cancel()
+
+	done := &providers.Issue{Number: 1, Labels: []string{cfg.TriggerLabel}}
+	blocked := &providers.Issue{Number: 2, Labels: []string{cfg.TriggerLabel}}
+	mock.AddIssue("owner/repo", done)
+	mock.AddIssue("owner/repo", blocked)
+
+	doneState := state.NewState()
+	doneState.CurrentPhase = state.PhaseCompleted
+	blockedState := state.NewState()
+	blockedState.BlockedBy = []state.IssueRef{{Number: 1}}
+	replaced := state.NewState()
+	d.allStates["owner/repo"] = map[int]*state.State{1: doneState, 2: blockedState}
+
+	d.workerPool.results <- &JobResult{Job: &Job{Issue: done, Repository: "owner/repo", State: doneState}}
+	taken, snapshot := d.snapshotStates()
+	d.processCompletedJobs(context.Background())
+
+	st := d.allStates["owner/repo"][2]
+	if len(st.BlockedBy) != 0 {
+		t.Errorf("expected #2 to be stored unblocked, got blocked by %v", st.BlockedBy)
+	}
+	if len(blockedState.BlockedBy) != 1 {
+		t.Errorf("expected the state read under the lock to be left alone, got blocked by %v", blockedState.BlockedBy)
+	}
+
+	// A state replaced by a poll while the snapshot was worked on is kept
+	d.allStates["owner/repo"][2] = replaced
+	d.applyStates(taken, snapshot)
+	if d.allStates["owner/repo"][2] != replaced {
+		t.Error("expected the state stored after the snapshot to be kept")
+	}
+}
+
+func TestFailDependencyCycles_AcrossRepos(t *testing.T) {
+	mock := providers.NewMockProvider()
+	cfg := config.DefaultConfig()
+	d := NewDaemon(cfg, mock, log.New(io.Discard, "", 0))
+	d.depDetector = NewDependencyDetector(mock, nil, "disabled")
+	d.workerPool = NewWorkerPool(context.Background(), 1, 1)
+	defer d.workerPool.Cancel()
+
+	var pending []issueInfo
+	for _, repo := range []string{"owner/app", "owner/lib"} {
+		other := "owner/lib"
+		if repo == other {
+			other = "owner/app"
+		}
+		issue := &providers.Issue{Number: 1, Labels: []string{cfg.TriggerLabel}}
+		mock.AddIssue(repo, issue)
+		st := state.NewState()
+		st.DependsOn = []state.IssueRef{{Repo: other, Number: 1}}
+		d.allStates[repo] = map[int]*state.State{1: st}
+		pending = append(pending, issueInfo{repo: repo, issue: issue, state: st})
+	}
+
+	if remaining := d.failDependencyCycles(context.Background(), pending); len(remaining) != 0 {
+		t.Fatalf("expected both issues to be failed, got %v", remaining)
+	}
+
+	comments, _ := mock.GetComments(context.Background(), "owner/app", 1)
+	found := false
+	for _, c := range comments {
+		if strings.Contains(c.Body, "#1 -> owner/lib#1 -> #1") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected owner/app#1 to get a comment with the cycle relative to its repo")
+	}
+}
@@ -0,0 +1,58 @@
+package orchestrator
+
+import (
+	"context"
+	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/providers"
+)
+
+// rateLimitLowFraction is the share of the quota below which polling slows down
+const rateLimitLowFraction = 0.1
+
+// pollBackoff returns the interval until the next poll given the provider's quota.
+// Above the low-quota threshold it returns base. Below it, the interval grows in
+// proportion to how depleted the quota is, but never waits past the reset time.
+func pollBackoff(base time.Duration, info *providers.RateLimitInfo, now time.Time) time.Duration {
+	if info == nil || info.Limit <= 0 {
+		return base
+	}
+
+	threshold := float64(info.Limit) * rateLimitLowFraction
+	if float64(info.Remaining) >= threshold {
+		return base
+	}
+
+	untilReset := info.Reset.Sub(now)
+	if info.Remaining <= 0 {
+		return max(base, untilReset)
+	}
+
+	interval := time.Duration(float64(base) * threshold / float64(info.Remaining))
+	interval = min(interval, untilReset)
+	return max(interval, base)
+}
+
+// nextPollInterval returns how long to wait before the next poll, backing off
+// when the provider reports a low API quota
+func (d *Daemon) nextPollInterval(ctx context.Context) time.Duration {
+	base := d.config.PollInterval
+
+	rlProvider, ok := d.provider.(providers.RateLimitProvider)
+	if !ok {
+		return base
+	}
+
+	info, err := rlProvider.RateLimitInfo(ctx)
+	if err != nil {
+		d.logger.Printf("Failed to check rate limit: %v", err)
+		return base
+	}
+
+	interval := pollBackoff(base, info, time.Now())
+	if interval > base {
+		d.logger.Printf("Rate limit low (%d/%d remaining, resets %s), next poll in %s",
+			info.Remaining, info.Limit, info.Reset.Format(time.RFC3339), interval.Round(time.Second))
+	}
+	return interval
+}
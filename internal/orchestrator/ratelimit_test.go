@@ -0,0 +1,35 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/providers"
+)
+
+func TestPollBackoff(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	base := time.Minute
+
+	tests := []struct {
+		name string
+		info *providers.RateLimitInfo
+		want time.Duration
+	}{
+		{"unknown quota", nil, base},
+		{"plenty left", &providers.RateLimitInfo{Limit: 5000, Remaining: 4000, Reset: now.Add(time.Hour)}, base},
+		{"at threshold", &providers.RateLimitInfo{Limit: 5000, Remaining: 500, Reset: now.Add(time.Hour)}, base},
+		{"near exhausted", &providers.RateLimitInfo{Limit: 5000, Remaining: 50, Reset: now.Add(time.Hour)}, 10 * time.Minute},
+		{"capped at reset", &providers.RateLimitInfo{Limit: 5000, Remaining: 5, Reset: now.Add(20 * time.Minute)}, 20 * time.Minute},
+		{"exhausted", &providers.RateLimitInfo{Limit: 5000, Remaining: 0, Reset: now.Add(45 * time.Minute)}, 45 * time.Minute},
+		{"reset already passed", &providers.RateLimitInfo{Limit: 5000, Remaining: 0, Reset: now.Add(-time.Minute)}, base},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pollBackoff(base, tt.info, now); got != tt.want {
+				t.Errorf("pollBackoff() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
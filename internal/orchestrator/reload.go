@@ -0,0 +1,110 @@
+package orchestrator
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+)
+
+// Reload queues cfg to be applied by the polling loop. Settings that are safe to change
+// while issues are in flight take effect before the next poll; changes to anything else
+// are logged as needing a restart. A reload queued before the previous one was applied
+// replaces it.
+func (d *Daemon) Reload(cfg *config.Config) {
+	for {
+		select {
+		case d.reloadCh <- cfg:
+			return
+		default:
+		}
+		// Drop the pending reload so the newest config wins
+		select {
+		case <-d.reloadCh:
+		default:
+		}
+	}
+}
+
+// applyConfig copies the live-reloadable settings from cfg into the running config and
+// logs what changed. It runs on the polling goroutine, so poll-side reads need no lock;
+// workers read these fields through the orchestrator under configMu.
+func (d *Daemon) applyConfig(cfg *config.Config) {
+	old := *d.config
+
+	d.orchestrator.configMu.Lock()
+	d.config.PollInterval = cfg.PollInterval
+	d.config.TriggerLabel = cfg.TriggerLabel
+	d.config.TriggerLabels = cfg.TriggerLabels
+	d.config.TriggerLabelPrefix = cfg.TriggerLabelPrefix
+	d.config.Progress.DebounceInterval = cfg.Progress.DebounceInterval
+	d.orchestrator.configMu.Unlock()
+
+	var applied []string
+	if old.PollInterval != d.config.PollInterval {
+		applied = append(applied, "poll_interval")
+	}
+	if !reflect.DeepEqual(old.TriggerLabelList(), d.config.TriggerLabelList()) || old.TriggerLabelPrefix != d.config.TriggerLabelPrefix {
+		applied = append(applied, "trigger labels")
+	}
+	if old.Progress.DebounceInterval != d.config.Progress.DebounceInterval {
+		applied = append(applied, "progress.debounce_interval")
+	}
+
+	// The live settings now match, so anything else that differs needs a restart
+	pending := changedSections(d.config, cfg)
+
+	if len(applied) == 0 && len(pending) == 0 {
+		d.logger.Printf("Config reloaded, nothing changed")
+		return
+	}
+	if len(applied) > 0 {
+		d.logger.Printf("Config reloaded, applied: %s", strings.Join(applied, ", "))
+		d.logger.Printf("Polling interval: %s", d.config.PollInterval)
+		d.logger.Printf("Trigger labels: %s", strings.Join(d.config.TriggerLabelList(), ", "))
+	}
+	if len(pending) > 0 {
+		d.logger.Printf("Config reloaded, restart required to apply: %s", strings.Join(pending, ", "))
+	}
+}
+
+// changedSections returns the top-level config keys whose values differ between a and b.
+// Settings not read from the file (dry run, unknown keys) are ignored.
+func changedSections(a, b *config.Config) []string {
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	t := va.Type()
+
+	var changed []string
+	for i := 0; i < t.NumField(); i++ {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			changed = append(changed, tag)
+		}
+	}
+	return changed
+}
+
+// isTriggerLabel reports whether label triggers processing under the current config
+func (o *Orchestrator) isTriggerLabel(label string) bool {
+	o.configMu.RLock()
+	defer o.configMu.RUnlock()
+	return o.config.IsTriggerLabel(label)
+}
+
+// triggerLabel returns the label added to re-trigger processing of an issue
+func (o *Orchestrator) triggerLabel() string {
+	o.configMu.RLock()
+	defer o.configMu.RUnlock()
+	return o.config.TriggerLabelList()[0]
+}
+
+// progressDebounce returns the minimum time between progress comment updates
+func (o *Orchestrator) progressDebounce() time.Duration {
+	o.configMu.RLock()
+	defer o.configMu.RUnlock()
+	return o.config.Progress.DebounceInterval
+}
@@ -0,0 +1,67 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/providers"
+)
+
+func TestApplyConfig_ChangesTriggerLabel(t *testing.T) {
+	mock := providers.NewMockProvider()
+	mock.AddIssue("owner/repo", &providers.Issue{Number: 1, Labels: []string{"ai-implement"}})
+	mock.AddIssue("owner/repo", &providers.Issue{Number: 2, Labels: []string{"ai-build"}})
+
+	var logs bytes.Buffer
+	d := NewDaemon(config.DefaultConfig(), mock, log.New(&logs, "", 0))
+
+	reloaded := config.DefaultConfig()
+	reloaded.TriggerLabel = "ai-build"
+	reloaded.PollInterval = 5 * time.Minute
+	reloaded.Concurrency.MaxTotal = 10
+	d.Reload(reloaded)
+	d.applyConfig(<-d.reloadCh)
+
+	issues := d.fetchTriggeredIssues(context.Background(), []string{"owner/repo"})
+	if len(issues) != 1 || issues[0].issue.Number != 2 {
+		t.Fatalf("expected only issue #2 after reload, got %v", issues)
+	}
+	if !d.orchestrator.isTriggerLabel("ai-build") || d.orchestrator.isTriggerLabel("ai-implement") {
+		t.Error("expected workers to see the reloaded trigger label")
+	}
+	if d.config.PollInterval != 5*time.Minute {
+		t.Errorf("expected poll interval 5m, got %s", d.config.PollInterval)
+	}
+	if d.config.Concurrency.MaxTotal != 5 {
+		t.Errorf("expected concurrency to wait for a restart, got max_total %d", d.config.Concurrency.MaxTotal)
+	}
+
+	out := logs.String()
+	if !strings.Contains(out, "applied: poll_interval, trigger labels") {
+		t.Errorf("expected applied settings to be logged, got:\n%s", out)
+	}
+	if !strings.Contains(out, "restart required to apply: concurrency") {
+		t.Errorf("expected concurrency change to need a restart, got:\n%s", out)
+	}
+}
+
+func TestReload_NewestConfigWins(t *testing.T) {
+	d := NewDaemon(config.DefaultConfig(), providers.NewMockProvider(), log.New(&bytes.Buffer{}, "", 0))
+
+	first := config.DefaultConfig()
+	first.TriggerLabel = "first"
+	second := config.DefaultConfig()
+	second.TriggerLabel = "second"
+
+	d.Reload(first)
+	d.Reload(second)
+
+	if got := <-d.reloadCh; got.TriggerLabel != "second" {
+		t.Errorf("expected the latest reload to be pending, got %q", got.TriggerLabel)
+	}
+}
@@ -0,0 +1,112 @@
+package orchestrator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/security"
+	"github.com/anthropics/ultra-engineer/internal/state"
+	"github.com/anthropics/ultra-engineer/internal/workflow"
+)
+
+// replanPhases are the phases past Q&A, where the plan was made from the issue as it was
+var replanPhases = map[state.Phase]bool{
+	state.PhasePlanning:     true,
+	state.PhaseApproval:     true,
+	state.PhaseImplementing: true,
+	state.PhaseReview:       true,
+}
+
+// recordIssueBaseline takes the issue as Q&A left it as the baseline for edits. The worker
+// calls it when Q&A completes, so the baseline is persisted with the rest of the state.
+func recordIssueBaseline(st *state.State, issue *providers.Issue) {
+	st.LastIssueUpdate = issue.UpdatedAt
+	st.IssueHash = issueHash(issue)
+}
+
+// checkIssueEdit offers a re-plan when the issue's title or body was edited after Q&A,
+// and re-plans from scratch when an authorized user accepts with "/replan" or "yes".
+// Issues whose state has no baseline, from before it was recorded, aren't checked.
+// Must not be called for an issue a worker is processing, since it changes st.
+func (d *Daemon) checkIssueEdit(ctx context.Context, repo string, issue *providers.Issue, st *state.State) {
+	if !replanPhases[st.CurrentPhase] || st.IssueHash == "" {
+		return
+	}
+
+	if issue.UpdatedAt.After(st.LastIssueUpdate) {
+		st.LastIssueUpdate = issue.UpdatedAt
+		// UpdatedAt also moves on comments and label changes; only an edit counts
+		if hash := issueHash(issue); hash != st.IssueHash {
+			st.IssueHash = hash
+			d.offerReplan(ctx, repo, issue.Number, st)
+			return
+		}
+	}
+
+	if !st.ReplanPromptTime.IsZero() {
+		d.checkReplanReply(ctx, repo, issue.Number, st)
+	}
+}
+
+// offerReplan asks whether to re-plan after the issue was edited
+func (d *Daemon) offerReplan(ctx context.Context, repo string, issueNum int, st *state.State) {
+	d.logger.Printf("Issue #%d was edited during %s, offering to re-plan", issueNum, st.CurrentPhase)
+	st.ReplanPromptTime = time.Now()
+	d.orchestrator.postWithState(ctx, repo, issueNum, st, "**The issue was edited** after its requirements were settled. Reply `/replan` (or `yes`) to plan again from the updated description; otherwise work continues with the current plan.")
+}
+
+// checkReplanReply re-plans when an authorized user accepted the offer
+func (d *Daemon) checkReplanReply(ctx context.Context, repo string, issueNum int, st *state.State) {
+	comments, err := d.provider.GetComments(ctx, repo, issueNum)
+	if err != nil {
+		d.logger.Printf("Error checking re-plan reply for #%d: %v", issueNum, err)
+		return
+	}
+
+	for _, c := range comments {
+		if !c.CreatedAt.After(st.ReplanPromptTime) || state.IsBotComment(c.Body) {
+			continue
+		}
+		if !isReplanReply(c.Body) {
+			continue
+		}
+		if !security.IsAuthorized(d.config.AllowedUsers, c.Author, d.logger) {
+			// Skip unauthorized replies (already logged by IsAuthorized)
+			continue
+		}
+
+		d.logger.Printf("Re-plan of issue #%d requested by %s", issueNum, c.Author)
+		d.orchestrator.react(ctx, repo, c, d.config.Progress.AckReaction)
+		st.ReplanPromptTime = time.Time{}
+		if c.CreatedAt.After(st.LastCommentTime) {
+			// Keep the planning and approval phases from reading the reply as feedback
+			st.LastCommentTime = c.CreatedAt
+		}
+		var guidance string
+		if workflow.ParseSlashCommand(c.Body) == workflow.CommandReplan {
+			guidance = workflow.StripSlashCommand(c.Body)
+		}
+		d.orchestrator.requestReplan(ctx, repo, issueNum, st, guidance)
+		d.orchestrator.postWithState(ctx, repo, issueNum, st, "Re-planning from the updated issue description.")
+		return
+	}
+}
+
+// isReplanReply reports whether a comment accepts a re-plan offer
+func isReplanReply(body string) bool {
+	if workflow.ParseSlashCommand(body) == workflow.CommandReplan {
+		return true
+	}
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(body), "\n")
+	return strings.Trim(strings.ToLower(strings.TrimSpace(firstLine)), ".!") == "yes"
+}
+
+// issueHash fingerprints the parts of an issue that state its requirements
+func issueHash(issue *providers.Issue) string {
+	sum := sha256.Sum256([]byte(issue.Title + "\x00" + issue.Body))
+	return hex.EncodeToString(sum[:8])
+}
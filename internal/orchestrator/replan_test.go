@@ -0,0 +1,151 @@
+package orchestrator
+
+import (
+	"context"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+func TestCheckIssueEdit_OffersReplanOnceAfterEdit(t *testing.T) {
+	ctx := context.Background()
+	mock := providers.NewMockProvider()
+	d := NewDaemon(config.DefaultConfig(), mock, log.New(io.Discard, "", 0))
+	issue := &providers.Issue{Number: 1, Title: "Add login", Body: "Use passwords", UpdatedAt: time.Now().Add(-time.Hour)}
+	mock.AddIssue("owner/repo", issue)
+	st := state.NewState()
+	st.CurrentPhase = state.PhaseImplementing
+	recordIssueBaseline(st, issue)
+
+	// A comment or label change moves UpdatedAt without changing the requirements
+	issue.UpdatedAt = issue.UpdatedAt.Add(time.Minute)
+	d.checkIssueEdit(ctx, "owner/repo", issue, st)
+	if len(mock.CreatedComments) != 0 {
+		t.Fatalf("expected no offer without an edit, got %v", mock.CreatedComments)
+	}
+
+	issue.Body = "Use passkeys"
+	issue.UpdatedAt = issue.UpdatedAt.Add(time.Minute)
+	d.checkIssueEdit(ctx, "owner/repo", issue, st)
+	d.checkIssueEdit(ctx, "owner/repo", issue, st)
+
+	comments, _ := mock.GetComments(ctx, "owner/repo", 1)
+	if len(comments) != 1 || !strings.Contains(comments[0].Body, "/replan") {
+		t.Fatalf("expected one re-plan offer, got %v", comments)
+	}
+	if st.ReplanPromptTime.IsZero() || st.CurrentPhase != state.PhaseImplementing {
+		t.Errorf("expected a pending offer in the implementing phase, got %v in %s", st.ReplanPromptTime, st.CurrentPhase)
+	}
+}
+
+func TestCheckIssueEdit_IgnoresEarlyPhases(t *testing.T) {
+	mock := providers.NewMockProvider()
+	d := NewDaemon(config.DefaultConfig(), mock, log.New(io.Discard, "", 0))
+	issue := &providers.Issue{Number: 1, Title: "Add login", Body: "Use passwords", UpdatedAt: time.Now().Add(-time.Hour)}
+	mock.AddIssue("owner/repo", issue)
+	st := state.NewState()
+	st.CurrentPhase = state.PhaseQuestions
+	recordIssueBaseline(st, issue)
+
+	issue.Body = "Use passkeys"
+	issue.UpdatedAt = issue.UpdatedAt.Add(time.Minute)
+	d.checkIssueEdit(context.Background(), "owner/repo", issue, st)
+
+	if len(mock.CreatedComments) != 0 {
+		t.Errorf("expected edits during Q&A to be left to Q&A, got comments %v", mock.CreatedComments)
+	}
+}
+
+func TestFilterPendingIssues_OffersReplanForEditDuringApproval(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	mock := providers.NewMockProvider()
+	d := NewDaemon(cfg, mock, log.New(io.Discard, "", 0))
+	issue := &providers.Issue{Number: 1, Title: "Add login", Body: "Use passwords", UpdatedAt: time.Now().Add(-time.Hour)}
+	mock.AddIssue("owner/repo", issue)
+	st := state.NewState()
+	issue.Labels = []string{cfg.TriggerLabel, state.PhaseApproval.Label()}
+
+	// The worker recorded the baseline when Q&A completed and persisted it
+	st.CurrentPhase = state.PhaseApproval
+	st.LastCommentTime = time.Now()
+	recordIssueBaseline(st, issue)
+	addStateComment(t, mock, "owner/repo", 1, st)
+
+	// Every poll loads the state afresh, and drops it while approval waits for a reply
+	issues := []issueInfo{{repo: "owner/repo", issue: issue}}
+	d.filterPendingIssues(ctx, issues)
+	issue.Body = "Use passkeys"
+	issue.UpdatedAt = issue.UpdatedAt.Add(time.Minute)
+	d.filterPendingIssues(ctx, issues)
+
+	var offers int
+	for _, c := range mock.CreatedComments {
+		if strings.Contains(c.Body, "/replan") {
+			offers++
+		}
+	}
+	if offers != 1 {
+		t.Errorf("expected one re-plan offer for the edit, got %d", offers)
+	}
+}
+
+func TestCheckIssueEdit_ReplanReply(t *testing.T) {
+	tests := []struct {
+		name       string
+		allowed    []string
+		author     string
+		body       string
+		wantReplan bool
+	}{
+		{name: "slash command", author: "alice", body: "/replan", wantReplan: true},
+		{name: "yes", author: "alice", body: "Yes!", wantReplan: true},
+		{name: "other reply", author: "alice", body: "yes, but later"},
+		{name: "unauthorized", allowed: []string{"bob"}, author: "alice", body: "/replan"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cfg := config.DefaultConfig()
+			cfg.AllowedUsers = tt.allowed
+			mock := providers.NewMockProvider()
+			d := NewDaemon(cfg, mock, log.New(io.Discard, "", 0))
+			issue := &providers.Issue{Number: 1, Title: "Add login", Body: "Use passwords", UpdatedAt: time.Now().Add(-time.Hour)}
+			mock.AddIssue("owner/repo", issue)
+			st := state.NewState()
+			st.CurrentPhase = state.PhaseImplementing
+			recordIssueBaseline(st, issue)
+			st.ReplanPromptTime = time.Now().Add(-time.Minute)
+
+			mock.AddComment("owner/repo", 1, &providers.Comment{ID: 10, Body: tt.body, Author: tt.author, CreatedAt: time.Now()})
+			d.checkIssueEdit(ctx, "owner/repo", issue, st)
+
+			replanned := st.CurrentPhase == state.PhasePlanning && st.PlanOutdated
+			if replanned != tt.wantReplan {
+				t.Fatalf("expected re-plan=%v, got phase %s", tt.wantReplan, st.CurrentPhase)
+			}
+			if !tt.wantReplan {
+				if st.ReplanPromptTime.IsZero() {
+					t.Error("expected the offer to stay pending")
+				}
+				return
+			}
+			if !st.ReplanPromptTime.IsZero() {
+				t.Error("expected the offer to be cleared")
+			}
+			if !hasAddedLabel(mock, state.PhasePlanning.Label()) {
+				t.Errorf("expected the planning label, got %v", mock.AddedLabels)
+			}
+			if len(mock.Reactions) != 1 || mock.Reactions[0].CommentID != 10 {
+				t.Errorf("expected the reply to be acknowledged, got %+v", mock.Reactions)
+			}
+		})
+	}
+}
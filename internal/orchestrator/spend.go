@@ -0,0 +1,70 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+// spendDayFormat keys daily spend by local calendar day
+const spendDayFormat = "2006-01-02"
+
+// spendStore persists the daily spend; *state.FileStore implements it
+type spendStore interface {
+	LoadSpend() (state.Spend, error)
+	SaveSpend(spend state.Spend) error
+}
+
+// dailySpend adds up what Claude costs per calendar day, for claude.max_cost_per_day
+type dailySpend struct {
+	mu    sync.Mutex
+	store spendStore // nil keeps the spend in memory only
+	spend state.Spend
+	now   func() time.Time
+}
+
+// newDailySpend creates a daily spend tracker, resuming today's spend from store if set
+func newDailySpend(store spendStore) (*dailySpend, error) {
+	d := &dailySpend{store: store, now: time.Now}
+	if store == nil {
+		return d, nil
+	}
+	spend, err := store.LoadSpend()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return d, err
+	}
+	d.spend = spend
+	return d, nil
+}
+
+// rollover starts a new day's spend once the day has changed. Callers hold mu.
+func (d *dailySpend) rollover() {
+	if today := d.now().Format(spendDayFormat); d.spend.Day != today {
+		d.spend = state.Spend{Day: today}
+	}
+}
+
+// Add records cost against today's spend and persists it
+func (d *dailySpend) Add(costUSD float64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.rollover()
+	d.spend.CostUSD += costUSD
+	if d.store == nil {
+		return nil
+	}
+	return d.store.SaveSpend(d.spend)
+}
+
+// Today returns today's date and what Claude has cost so far today
+func (d *dailySpend) Today() state.Spend {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.rollover()
+	return d.spend
+}
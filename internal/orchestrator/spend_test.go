@@ -0,0 +1,107 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/claude"
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/sandbox"
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+func TestDailySpend_PersistsAndRollsOver(t *testing.T) {
+	store := state.NewFileStore(t.TempDir())
+	now := time.Date(2026, 10, 16, 23, 0, 0, 0, time.Local)
+
+	spend, err := newDailySpend(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spend.now = func() time.Time { return now }
+	spend.Add(1.5)
+	spend.Add(2)
+
+	// A restart resumes the day's spend
+	restarted, err := newDailySpend(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted.now = func() time.Time { return now }
+	if today := restarted.Today(); today.CostUSD != 3.5 {
+		t.Errorf("expected $3.50 spent after a restart, got $%v", today.CostUSD)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if today := restarted.Today(); today.Day != "2026-10-17" || today.CostUSD != 0 {
+		t.Errorf("expected the next day to start from zero, got %+v", today)
+	}
+}
+
+func TestClaudeCost_FailsIssueAtPerIssueLimit(t *testing.T) {
+	calls := filepath.Join(t.TempDir(), "calls")
+	cfg := config.DefaultConfig()
+	cfg.Claude.Command = fakeClaudeCommand(t, "echo call >> "+calls+`
+echo '{"type":"result","result":"ok","total_cost_usd":1.25}'; exit 0`)
+	cfg.Claude.MaxCostPerIssue = 1
+	o, mock := newTestOrchestrator(t, cfg)
+
+	issue := &providers.Issue{Number: 1, Title: "Add feature"}
+	mock.AddIssue("owner/repo", issue)
+	st := state.NewState()
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+
+	err := o.runWithIssueTimeout(context.Background(), "owner/repo", issue, st, sb)
+	if !errors.Is(err, claude.ErrCostLimit) {
+		t.Fatalf("expected ErrCostLimit, got %v", err)
+	}
+
+	data, _ := os.ReadFile(calls)
+	if n := strings.Count(string(data), "call"); n != 1 {
+		t.Errorf("expected Claude not to run past the limit, got %d calls", n)
+	}
+	if st.CurrentPhase != state.PhaseFailed || st.FailureReason != "cost_limit" {
+		t.Errorf("expected failed with cost_limit, got %s / %q", st.CurrentPhase, st.FailureReason)
+	}
+	if st.CostUSD != 1.25 {
+		t.Errorf("expected the spent $1.25 recorded, got $%v", st.CostUSD)
+	}
+}
+
+func TestPoll_DailySpendLimitStopsNewJobs(t *testing.T) {
+	mock := providers.NewMockProvider()
+	cfg := config.DefaultConfig()
+	cfg.Claude.MaxCostPerDay = 5
+	cfg.State.Dir = t.TempDir()
+	mock.AddIssue("owner/repo", &providers.Issue{Number: 1, Title: "Add feature", Labels: []string{cfg.TriggerLabel}})
+
+	// Spend from before a restart counts
+	previous := NewDaemon(cfg, mock, log.New(io.Discard, "", 0))
+	previous.orchestrator.spend.Add(5)
+	d := NewDaemon(cfg, mock, log.New(io.Discard, "", 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d.workerPool = NewWorkerPool(ctx, 2, 2)
+	d.depDetector = NewDependencyDetector(mock, d.claudeClient, "disabled")
+
+	d.poll(ctx, []string{"owner/repo"})
+	if n := d.workerPool.GetActiveCount(); n != 0 {
+		t.Fatalf("expected no jobs once the daily limit is spent, got %d", n)
+	}
+
+	tomorrow := time.Now().Add(24 * time.Hour)
+	d.orchestrator.spend.now = func() time.Time { return tomorrow }
+	d.poll(ctx, []string{"owner/repo"})
+	if n := d.workerPool.GetActiveCount(); n != 1 {
+		t.Errorf("expected the issue submitted the next day, got %d jobs", n)
+	}
+}
@@ -0,0 +1,60 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/providers"
+)
+
+// ListTriggeredIssues returns the open issues in repo that carry a trigger label.
+// Issues with several trigger labels are returned once.
+func ListTriggeredIssues(ctx context.Context, provider providers.Provider, cfg *config.Config, repo string) ([]*providers.Issue, error) {
+	// Labels can't be queried by prefix, so prefix mode lists all open issues and filters locally
+	labels := cfg.TriggerLabelList()
+	if cfg.TriggerLabelPrefix != "" {
+		labels = []string{""}
+	}
+
+	seen := make(map[int]bool)
+	var result []*providers.Issue
+	for _, label := range labels {
+		issues, err := provider.ListIssuesWithLabel(ctx, repo, label)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues with label %q: %w", label, err)
+		}
+		for _, issue := range issues {
+			if seen[issue.Number] || !hasTriggerLabel(cfg, issue.Labels) {
+				continue
+			}
+			seen[issue.Number] = true
+			result = append(result, issue)
+		}
+	}
+	return result, nil
+}
+
+// hasTriggerLabel reports whether any of labels triggers processing
+func hasTriggerLabel(cfg *config.Config, labels []string) bool {
+	for _, l := range labels {
+		if cfg.IsTriggerLabel(l) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeTriggerLabels removes every trigger label currently on the issue (best-effort)
+func (o *Orchestrator) removeTriggerLabels(ctx context.Context, repo string, issueNum int) {
+	issue, err := o.provider.GetIssue(ctx, repo, issueNum)
+	if err != nil {
+		o.logger.Printf("Failed to load issue #%d to remove trigger labels: %v", issueNum, err)
+		return
+	}
+	for _, l := range issue.Labels {
+		if o.isTriggerLabel(l) {
+			o.provider.RemoveLabel(ctx, repo, issueNum, l)
+		}
+	}
+}
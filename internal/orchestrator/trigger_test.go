@@ -0,0 +1,65 @@
+package orchestrator
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/providers"
+)
+
+func triggeredNumbers(t *testing.T, mock *providers.MockProvider, cfg *config.Config) []int {
+	t.Helper()
+	issues, err := ListTriggeredIssues(context.Background(), mock, cfg, "owner/repo")
+	if err != nil {
+		t.Fatalf("ListTriggeredIssues failed: %v", err)
+	}
+	var numbers []int
+	for _, issue := range issues {
+		numbers = append(numbers, issue.Number)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+func TestListTriggeredIssues_DedupesMultipleLabels(t *testing.T) {
+	mock := providers.NewMockProvider()
+	mock.AddIssue("owner/repo", &providers.Issue{Number: 1, Labels: []string{"ai-implement", "ai-fix"}})
+	mock.AddIssue("owner/repo", &providers.Issue{Number: 2, Labels: []string{"ai-fix"}})
+	mock.AddIssue("owner/repo", &providers.Issue{Number: 3, Labels: []string{"bug"}})
+
+	cfg := config.DefaultConfig()
+	cfg.TriggerLabels = []string{"ai-implement", "ai-fix"}
+
+	got := triggeredNumbers(t, mock, cfg)
+	if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("expected issues [1 2], got %v", got)
+	}
+}
+
+func TestListTriggeredIssues_LegacySingleLabel(t *testing.T) {
+	mock := providers.NewMockProvider()
+	mock.AddIssue("owner/repo", &providers.Issue{Number: 1, Labels: []string{"ai-implement"}})
+	mock.AddIssue("owner/repo", &providers.Issue{Number: 2, Labels: []string{"ai-fix"}})
+
+	got := triggeredNumbers(t, mock, config.DefaultConfig())
+	if len(got) != 1 || got[0] != 1 {
+		t.Errorf("expected issues [1], got %v", got)
+	}
+}
+
+func TestListTriggeredIssues_PrefixMode(t *testing.T) {
+	mock := providers.NewMockProvider()
+	mock.AddIssue("owner/repo", &providers.Issue{Number: 1, Labels: []string{"ai-implement", "ai-fix"}})
+	mock.AddIssue("owner/repo", &providers.Issue{Number: 2, Labels: []string{"ai-docs"}})
+	mock.AddIssue("owner/repo", &providers.Issue{Number: 3, Labels: []string{"maintenance"}})
+
+	cfg := config.DefaultConfig()
+	cfg.TriggerLabelPrefix = "ai-"
+
+	got := triggeredNumbers(t, mock, cfg)
+	if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("expected issues [1 2], got %v", got)
+	}
+}
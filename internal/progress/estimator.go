@@ -0,0 +1,152 @@
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+// maxEstimatorSamples bounds the rolling average so recent issues dominate
+const maxEstimatorSamples = 20
+
+// estimatedPhases are the phases the bot works through, in order
+// Questions and approval are excluded since they wait on humans
+var estimatedPhases = []state.Phase{
+	state.PhaseNew,
+	state.PhasePlanning,
+	state.PhaseImplementing,
+	state.PhaseReview,
+}
+
+// phaseStats is the rolling average duration of a phase
+type phaseStats struct {
+	Average time.Duration `json:"average"`
+	Samples int           `json:"samples"`
+}
+
+// Estimator estimates remaining time from historical phase durations
+type Estimator struct {
+	mu    sync.Mutex
+	path  string // Optional file to persist history across restarts
+	stats map[state.Phase]*phaseStats
+}
+
+// NewEstimator creates an estimator, loading history from path if it exists
+// If path is empty, history is only kept in memory
+func NewEstimator(path string) *Estimator {
+	e := &Estimator{
+		path:  path,
+		stats: make(map[state.Phase]*phaseStats),
+	}
+
+	if path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			json.Unmarshal(data, &e.stats)
+		}
+	}
+
+	return e
+}
+
+// Record adds the phase timings of a finished issue to the rolling averages
+func (e *Estimator) Record(timings map[state.Phase]time.Duration) error {
+	if len(timings) == 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for phase, d := range timings {
+		s := e.stats[phase]
+		if s == nil {
+			s = &phaseStats{}
+			e.stats[phase] = s
+		}
+		if s.Samples < maxEstimatorSamples {
+			s.Samples++
+		}
+		s.Average += (d - s.Average) / time.Duration(s.Samples)
+	}
+
+	return e.save()
+}
+
+// save writes history to disk (must be called with lock held)
+func (e *Estimator) save() error {
+	if e.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(e.stats)
+	if err != nil {
+		return fmt.Errorf("failed to serialize estimator history: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(e.path), 0755); err != nil {
+		return fmt.Errorf("failed to create estimator directory: %w", err)
+	}
+	if err := os.WriteFile(e.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write estimator history: %w", err)
+	}
+	return nil
+}
+
+// Remaining estimates the time left for an issue in the given phase
+// elapsed is the time already spent in the current phase
+// Returns false if there is not enough history to estimate
+func (e *Estimator) Remaining(phase state.Phase, elapsed time.Duration) (time.Duration, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Find where the current phase sits in the workflow
+	start := -1
+	switch phase {
+	case state.PhaseQuestions:
+		start = 1 // Planning is next
+	case state.PhaseApproval:
+		start = 2 // Implementing is next
+	default:
+		for i, p := range estimatedPhases {
+			if p == phase {
+				start = i
+				break
+			}
+		}
+	}
+	if start < 0 {
+		return 0, false // Completed, failed or unknown
+	}
+
+	var remaining time.Duration
+	for i, p := range estimatedPhases[start:] {
+		s := e.stats[p]
+		if s == nil || s.Samples == 0 {
+			return 0, false
+		}
+		d := s.Average
+		if i == 0 && p == phase {
+			d -= elapsed
+			if d < 0 {
+				d = 0
+			}
+		}
+		remaining += d
+	}
+
+	return remaining, true
+}
+
+// FormatETA formats an estimated remaining duration for the progress comment
+func FormatETA(remaining time.Duration) string {
+	minutes := int(math.Ceil(remaining.Minutes()))
+	if minutes < 1 {
+		minutes = 1
+	}
+	return fmt.Sprintf("Estimated time remaining: ~%d min", minutes)
+}
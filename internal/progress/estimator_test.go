@@ -0,0 +1,100 @@
+package progress
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+func TestEstimator_NoHistory(t *testing.T) {
+	e := NewEstimator("")
+
+	if _, ok := e.Remaining(state.PhasePlanning, 0); ok {
+		t.Error("expected no estimate without history")
+	}
+}
+
+func TestEstimator_RemainingFromAverages(t *testing.T) {
+	e := NewEstimator("")
+	e.Record(map[state.Phase]time.Duration{
+		state.PhaseNew:          2 * time.Minute,
+		state.PhasePlanning:     4 * time.Minute,
+		state.PhaseImplementing: 10 * time.Minute,
+		state.PhaseReview:       6 * time.Minute,
+	})
+	e.Record(map[state.Phase]time.Duration{
+		state.PhaseNew:          2 * time.Minute,
+		state.PhasePlanning:     8 * time.Minute,
+		state.PhaseImplementing: 10 * time.Minute,
+		state.PhaseReview:       6 * time.Minute,
+	})
+
+	// Planning average is 6m; 1m already elapsed, then implementing and review
+	remaining, ok := e.Remaining(state.PhasePlanning, time.Minute)
+	if !ok {
+		t.Fatal("expected an estimate")
+	}
+	if remaining != 21*time.Minute {
+		t.Errorf("expected 21m remaining, got %v", remaining)
+	}
+
+	// Waiting for approval excludes the human wait and starts at implementing
+	remaining, ok = e.Remaining(state.PhaseApproval, time.Hour)
+	if !ok || remaining != 16*time.Minute {
+		t.Errorf("expected 16m remaining during approval, got %v (ok=%v)", remaining, ok)
+	}
+
+	if _, ok := e.Remaining(state.PhaseCompleted, 0); ok {
+		t.Error("expected no estimate for completed issues")
+	}
+}
+
+func TestEstimator_PersistsHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eta.json")
+
+	e := NewEstimator(path)
+	if err := e.Record(map[state.Phase]time.Duration{state.PhaseReview: 3 * time.Minute}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	reloaded := NewEstimator(path)
+	remaining, ok := reloaded.Remaining(state.PhaseReview, 0)
+	if !ok || remaining != 3*time.Minute {
+		t.Errorf("expected persisted 3m estimate, got %v (ok=%v)", remaining, ok)
+	}
+}
+
+func TestFormatETA(t *testing.T) {
+	if got := FormatETA(90 * time.Second); got != "Estimated time remaining: ~2 min" {
+		t.Errorf("unexpected format: %q", got)
+	}
+	if got := FormatETA(0); got != "Estimated time remaining: ~1 min" {
+		t.Errorf("unexpected format for zero: %q", got)
+	}
+}
+
+func TestReporter_ETALine(t *testing.T) {
+	mock := providers.NewMockProvider()
+	st := state.NewState()
+	st.SetPhase(state.PhaseReview)
+
+	reporter := NewReporterWithState(mock, "owner/repo", 1, time.Minute, true, st)
+	estimator := NewEstimator("")
+	reporter.SetEstimator(estimator)
+
+	reporter.ForceUpdate(context.Background(), StatusCodeReview)
+	if strings.Contains(mock.CreatedComments[0].Body, "Estimated time remaining") {
+		t.Error("expected ETA line to be omitted without history")
+	}
+
+	estimator.Record(map[state.Phase]time.Duration{state.PhaseReview: 5 * time.Minute})
+	reporter.ForceUpdate(context.Background(), StatusCreatingPR)
+	if !strings.Contains(mock.UpdatedComments[0].Body, "Estimated time remaining: ~5 min") {
+		t.Errorf("expected ETA line, got:\n%s", mock.UpdatedComments[0].Body)
+	}
+}
@@ -13,17 +13,24 @@ import (
 
 // Status messages with emojis
 const (
-	StatusAnalyzing       = "🔍 Analyzing issue and generating questions..."
-	StatusPlanning        = "📝 Creating implementation plan..."
-	StatusPlanReview      = "🔄 Reviewing plan (%d/%d)..."
-	StatusWaitingAnswers  = "❓ Waiting for answers..."
-	StatusWaitingApproval = "⏳ Waiting for approval..."
-	StatusImplementing    = "🔨 Implementing changes..."
-	StatusCodeReview      = "✅ Code review (%d/%d)..."
-	StatusCreatingPR      = "🚀 Creating PR..."
-	StatusCompleted       = "✨ Completed successfully"
-	StatusCompletedWithPR = "✨ Completed successfully - PR #%d"
-	StatusFailed          = "❌ Failed: %s"
+	StatusAnalyzing        = "🔍 Analyzing issue and generating questions..."
+	StatusPlanning         = "📝 Creating implementation plan..."
+	StatusPlanReview       = "🔄 Reviewing plan (%d/%d)..."
+	StatusWaitingAnswers   = "❓ Waiting for answers..."
+	StatusCloneRetry       = "🔁 Clone failed, retrying next poll (attempt %s)..."
+	StatusBlocked          = "⛓️ Blocked by %s"
+	StatusWaitingApproval  = "⏳ Waiting for approval..."
+	StatusImplementing     = "🔨 Implementing changes..."
+	StatusImplementingTool = "🔨 Implementing changes (%s)..."
+	StatusCodeReview       = "✅ Code review (%d/%d)..."
+	StatusPrePRChecks      = "🧪 Running pre-PR checks..."
+	StatusFixingPrePR      = "🔧 Fixing pre-PR check %s (attempt %d/%d)..."
+	StatusCreatingPR       = "🚀 Creating PR..."
+	StatusCompleted        = "✨ Completed successfully"
+	StatusCompletedWithPR  = "✨ Completed successfully - PR #%d"
+	StatusFailed           = "❌ Failed: %s"
+	StatusAborted          = "🛑 Aborted"
+	StatusSummary          = "📋 Status: %s"
 
 	// CI status messages
 	StatusWaitingCI        = "⏳ Waiting for CI to complete..."
@@ -34,6 +41,7 @@ const (
 	StatusCIFixMaxAttempts = "❌ CI fix attempts exhausted (%d/%d)"
 
 	// PR merge status messages
+	StatusPRReady           = "👀 PR marked ready for review"
 	StatusWaitingPRApproval = "⏳ Waiting for PR approval..."
 	StatusMerged            = "🎉 PR merged successfully"
 )
@@ -50,6 +58,7 @@ type Reporter struct {
 	mu               sync.Mutex
 	enabled          bool
 	st               *state.State // State to persist with status updates (includes history)
+	estimator        *Estimator   // Optional; adds an ETA line when history is available
 }
 
 // NewReporter creates a new progress reporter (without state persistence)
@@ -87,6 +96,13 @@ func NewReporterWithState(provider providers.Provider, repo string, issueNumber
 	return r
 }
 
+// SetEstimator enables the estimated time remaining line in the progress comment
+func (r *Reporter) SetEstimator(e *Estimator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.estimator = e
+}
+
 // Update posts or updates the status comment with debouncing
 // Updates are skipped if less than debounceInterval has passed since the last update
 func (r *Reporter) Update(ctx context.Context, status string) error {
@@ -152,7 +168,7 @@ func (r *Reporter) doUpdate(ctx context.Context, status string) error {
 	// Track last status to avoid duplicate updates
 	r.lastStatusMsg = status
 
-	body := r.formatStatusLog()
+	body := r.statusBody()
 
 	if r.statusCommentID == 0 {
 		// Create new comment
@@ -183,7 +199,7 @@ func (r *Reporter) persistStateOnly(ctx context.Context) error {
 		return nil // Nothing to persist
 	}
 
-	body := r.formatStatusLog()
+	body := r.statusBody()
 	if err := r.provider.UpdateComment(ctx, r.repo, r.statusCommentID, body); err != nil {
 		return fmt.Errorf("failed to persist state: %w", err)
 	}
@@ -192,10 +208,29 @@ func (r *Reporter) persistStateOnly(ctx context.Context) error {
 	return nil
 }
 
+// statusBody formats the status log, dropping the oldest entries when it would not fit the
+// provider's comment size limit. The status comment is edited in place and holds the state,
+// so unlike plans it cannot be split across comments.
+func (r *Reporter) statusBody() string {
+	body := r.formatStatusLog()
+	limit := providers.MaxCommentLength(r.provider)
+	for limit > 0 && len(body) > limit && r.st != nil && len(r.st.StatusHistory) > 1 {
+		r.st.StatusHistory = r.st.StatusHistory[1:]
+		body = r.formatStatusLog()
+	}
+	return body
+}
+
 // formatStatusLog formats all status entries as a log with timestamps
 func (r *Reporter) formatStatusLog() string {
 	var lines []string
 	lines = append(lines, "**Progress Log**")
+	if r.st != nil && !r.st.StartedAt.IsZero() {
+		lines = append(lines, FormatElapsed(time.Since(r.st.StartedAt)))
+	}
+	if r.st != nil && (r.st.TokensIn > 0 || r.st.TokensOut > 0 || r.st.CostUSD > 0) {
+		lines = append(lines, FormatUsage(r.st.TokensIn, r.st.TokensOut, r.st.CostUSD))
+	}
 	lines = append(lines, "")
 
 	// Use history from state if available
@@ -209,6 +244,13 @@ func (r *Reporter) formatStatusLog() string {
 		}
 	}
 
+	// Estimate remaining time; omitted when there is no history yet
+	if r.estimator != nil && r.st != nil && !r.st.PhaseStartedAt.IsZero() {
+		if remaining, ok := r.estimator.Remaining(r.st.CurrentPhase, time.Since(r.st.PhaseStartedAt)); ok {
+			lines = append(lines, "", FormatETA(remaining))
+		}
+	}
+
 	body := joinLines(lines)
 
 	// Include state in the comment if available
@@ -235,16 +277,91 @@ func joinLines(lines []string) string {
 
 // Helpers for formatting status messages
 
+// FormatElapsed formats the running clock shown under the progress log header
+func FormatElapsed(elapsed time.Duration) string {
+	minutes := int(elapsed.Minutes())
+	switch {
+	case minutes < 1:
+		return "Elapsed: <1m"
+	case minutes < 60:
+		return fmt.Sprintf("Elapsed: %dm", minutes)
+	default:
+		return fmt.Sprintf("Elapsed: %dh %dm", minutes/60, minutes%60)
+	}
+}
+
+// FormatUsage formats the Claude usage shown under the progress log header
+func FormatUsage(tokensIn, tokensOut int64, costUSD float64) string {
+	return fmt.Sprintf("Claude usage: %s tokens in, %s out, $%.2f", formatTokens(tokensIn), formatTokens(tokensOut), costUSD)
+}
+
+// formatTokens abbreviates a token count, e.g. 12345 as "12.3k"
+func formatTokens(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
 // FormatPlanReview formats the plan review status message
 func FormatPlanReview(iteration, total int) string {
 	return fmt.Sprintf(StatusPlanReview, iteration, total)
 }
 
+// FormatCloneRetry formats the status shown while waiting to retry a failed clone
+func FormatCloneRetry(attempt, maxAttempts int) string {
+	count := fmt.Sprintf("%d", attempt)
+	if maxAttempts > 0 {
+		count = fmt.Sprintf("%d/%d", attempt, maxAttempts)
+	}
+	return fmt.Sprintf(StatusCloneRetry, count)
+}
+
+// FormatBlocked formats the status shown while an issue waits for its dependencies
+func FormatBlocked(blockedBy []state.IssueRef) string {
+	refs := make([]string, len(blockedBy))
+	for i, ref := range blockedBy {
+		refs[i] = ref.String()
+	}
+	return fmt.Sprintf(StatusBlocked, strings.Join(refs, ", "))
+}
+
+// FormatStatusSummary formats the summary posted in answer to a /status command:
+// the phase, the PR once there is one, and the issues blocking this one
+func FormatStatusSummary(st *state.State) string {
+	parts := []string{"phase " + string(st.CurrentPhase)}
+	if st.PRNumber > 0 {
+		parts = append(parts, fmt.Sprintf("PR #%d", st.PRNumber))
+	}
+	if len(st.BlockedBy) > 0 {
+		refs := make([]string, len(st.BlockedBy))
+		for i, ref := range st.BlockedBy {
+			refs[i] = ref.String()
+		}
+		parts = append(parts, "blocked by "+strings.Join(refs, ", "))
+	}
+	return fmt.Sprintf(StatusSummary, strings.Join(parts, ", "))
+}
+
+// FormatImplementingTool formats the implementing status with the tool Claude is using
+func FormatImplementingTool(tool string) string {
+	return fmt.Sprintf(StatusImplementingTool, tool)
+}
+
 // FormatCodeReview formats the code review status message
 func FormatCodeReview(iteration, total int) string {
 	return fmt.Sprintf(StatusCodeReview, iteration, total)
 }
 
+// FormatFixingPrePR formats the status shown while Claude fixes a failed pre-PR check
+func FormatFixingPrePR(command string, attempt, maxAttempts int) string {
+	return fmt.Sprintf(StatusFixingPrePR, "`"+command+"`", attempt, maxAttempts)
+}
+
 // FormatCompleted formats the completed status message with optional PR number
 func FormatCompleted(prNumber int) string {
 	if prNumber > 0 {
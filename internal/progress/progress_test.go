@@ -2,10 +2,12 @@ package progress
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/state"
 )
 
 func TestReporter_FirstUpdateCreatesComment(t *testing.T) {
@@ -132,6 +134,24 @@ func TestReporter_DisabledDoesNothing(t *testing.T) {
 	}
 }
 
+func TestReporter_ElapsedHeader(t *testing.T) {
+	mock := providers.NewMockProvider()
+	st := state.NewState()
+
+	reporter := NewReporterWithState(mock, "owner/repo", 1, time.Minute, true, st)
+	reporter.ForceUpdate(context.Background(), StatusAnalyzing)
+	if strings.Contains(mock.CreatedComments[0].Body, "Elapsed:") {
+		t.Error("expected no elapsed line before processing starts")
+	}
+
+	st.SetPhase(state.PhasePlanning)
+	st.StartedAt = time.Now().Add(-12 * time.Minute)
+	reporter.ForceUpdate(context.Background(), StatusPlanning)
+	if !strings.Contains(mock.UpdatedComments[0].Body, "**Progress Log**\nElapsed: 12m") {
+		t.Errorf("expected elapsed header, got:\n%s", mock.UpdatedComments[0].Body)
+	}
+}
+
 func TestFormatStatusMessages(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -142,6 +162,11 @@ func TestFormatStatusMessages(t *testing.T) {
 		{"CodeReview", func() string { return FormatCodeReview(3, 5) }, "3/5"},
 		{"CompletedWithPR", func() string { return FormatCompleted(123) }, "PR #123"},
 		{"CompletedNoPR", func() string { return FormatCompleted(0) }, "Completed successfully"},
+		{"ElapsedUnderMinute", func() string { return FormatElapsed(30 * time.Second) }, "Elapsed: <1m"},
+		{"ElapsedMinutes", func() string { return FormatElapsed(12*time.Minute + 30*time.Second) }, "Elapsed: 12m"},
+		{"ElapsedHours", func() string { return FormatElapsed(75 * time.Minute) }, "Elapsed: 1h 15m"},
+		{"CloneRetry", func() string { return FormatCloneRetry(2, 5) }, "attempt 2/5"},
+		{"CloneRetryUnlimited", func() string { return FormatCloneRetry(2, 0) }, "attempt 2)"},
 	}
 
 	for _, tt := range tests {
@@ -154,6 +179,70 @@ func TestFormatStatusMessages(t *testing.T) {
 	}
 }
 
+func TestFormatStatusSummary(t *testing.T) {
+	st := state.NewState()
+	st.CurrentPhase = state.PhaseQuestions
+	if got := FormatStatusSummary(st); got != "📋 Status: phase questions" {
+		t.Errorf("unexpected summary: %q", got)
+	}
+
+	st.CurrentPhase = state.PhaseReview
+	st.PRNumber = 12
+	st.BlockedBy = []state.IssueRef{{Number: 3}, {Repo: "owner/lib", Number: 4}}
+	if got := FormatStatusSummary(st); got != "📋 Status: phase review, PR #12, blocked by #3, owner/lib#4" {
+		t.Errorf("unexpected summary: %q", got)
+	}
+}
+
 func containsStr(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsStr(s[1:], substr) || s[:len(substr)] == substr)
 }
+
+func TestReporter_TrimsHistoryToCommentLimit(t *testing.T) {
+	mock := providers.NewMockProvider()
+	mock.AddIssue("owner/repo", &providers.Issue{Number: 1})
+	mock.MaxCommentLen = 4000
+
+	st := state.NewState()
+	for i := 0; i < 200; i++ {
+		st.StatusHistory = append(st.StatusHistory, "12:00:00|"+strings.Repeat("x", 50))
+	}
+	reporter := NewReporterWithState(mock, "owner/repo", 1, 0, true, st)
+
+	if err := reporter.ForceUpdate(context.Background(), StatusMerged); err != nil {
+		t.Fatalf("ForceUpdate failed: %v", err)
+	}
+	body := mock.CreatedComments[0].Body
+	if len(body) > mock.MaxCommentLen {
+		t.Errorf("status comment is %d bytes, want at most %d", len(body), mock.MaxCommentLen)
+	}
+	if !strings.Contains(body, StatusMerged) {
+		t.Error("expected the latest status to be kept")
+	}
+}
+
+func TestFormatUsage(t *testing.T) {
+	if got := FormatUsage(12345, 4567, 1.234); got != "Claude usage: 12.3k tokens in, 4.6k out, $1.23" {
+		t.Errorf("unexpected format: %q", got)
+	}
+	if got := FormatUsage(2_500_000, 800, 0); got != "Claude usage: 2.5M tokens in, 800 out, $0.00" {
+		t.Errorf("unexpected format: %q", got)
+	}
+}
+
+func TestReporter_UsageLine(t *testing.T) {
+	mock := providers.NewMockProvider()
+	st := state.NewState()
+	reporter := NewReporterWithState(mock, "owner/repo", 1, time.Minute, true, st)
+
+	reporter.ForceUpdate(context.Background(), StatusAnalyzing)
+	if strings.Contains(mock.CreatedComments[0].Body, "Claude usage") {
+		t.Error("expected the usage line to be omitted before Claude ran")
+	}
+
+	st.TokensIn, st.TokensOut, st.CostUSD = 1500, 300, 0.5
+	reporter.ForceUpdate(context.Background(), StatusPlanning)
+	if !strings.Contains(mock.UpdatedComments[0].Body, "Claude usage: 1.5k tokens in, 300 out, $0.50") {
+		t.Errorf("expected the usage line, got:\n%s", mock.UpdatedComments[0].Body)
+	}
+}
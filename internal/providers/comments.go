@@ -0,0 +1,175 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+// GitHubMaxCommentLength is the largest comment body GitHub accepts
+const GitHubMaxCommentLength = 65536
+
+// Markers around each part of a comment split by PostLargeComment
+const (
+	commentPartEnd      = "<!-- /ultra-engineer-part -->"
+	commentContinuedMsg = "\n\n*Continued in the next comment.*"
+)
+
+var commentPartRegex = regexp.MustCompile(`^<!-- ultra-engineer-part (\d+)/(\d+) -->\n`)
+
+// MaxCommentLength returns the provider's comment size limit in bytes, or 0 if it has none
+func MaxCommentLength(p Provider) int {
+	if clProvider, ok := p.(CommentLimitProvider); ok {
+		return clProvider.MaxCommentLength()
+	}
+	return 0
+}
+
+// PostLargeComment posts body as a bot comment. When it does not fit the provider's
+// comment size limit, it is split at line boundaries into numbered parts that are
+// posted in order; MergeCommentParts reassembles them. Returns the first comment's ID.
+func PostLargeComment(ctx context.Context, p Provider, repo string, number int, body string) (int64, error) {
+	limit := MaxCommentLength(p)
+	if limit <= 0 || len(state.AddBotMarker(body)) <= limit {
+		return p.CreateComment(ctx, repo, number, state.AddBotMarker(body))
+	}
+
+	// Leave room for the part markers, continuation note, footer and bot marker
+	overhead := len(state.AddBotMarker(formatCommentPart(999, 999, ""))) + len(commentContinuedMsg)
+	parts := splitComment(body, limit-overhead)
+
+	var firstID int64
+	for i, part := range parts {
+		text := formatCommentPart(i+1, len(parts), part)
+		if i < len(parts)-1 {
+			text += commentContinuedMsg
+		}
+		id, err := p.CreateComment(ctx, repo, number, state.AddBotMarker(text))
+		if err != nil {
+			return firstID, fmt.Errorf("failed to post comment part %d/%d: %w", i+1, len(parts), err)
+		}
+		if i == 0 {
+			firstID = id
+		}
+	}
+	return firstID, nil
+}
+
+// MergeCommentParts returns comments with each complete set of parts posted by
+// PostLargeComment replaced by a single comment holding the original body.
+// Incomplete sets are left as they are.
+func MergeCommentParts(comments []*Comment) []*Comment {
+	consumed := make(map[int]bool)
+	var merged []*Comment
+
+	for i, c := range comments {
+		if consumed[i] {
+			continue
+		}
+		n, total, text, ok := parseCommentPart(c.Body)
+		if !ok || n != 1 {
+			merged = append(merged, c)
+			continue
+		}
+
+		texts := []string{text}
+		var used []int
+		for j := i + 1; j < len(comments) && len(texts) < total; j++ {
+			if pn, pt, ptext, ok := parseCommentPart(comments[j].Body); ok && pt == total && pn == len(texts)+1 {
+				texts = append(texts, ptext)
+				used = append(used, j)
+			}
+		}
+		if len(texts) < total {
+			merged = append(merged, c)
+			continue
+		}
+
+		for _, j := range used {
+			consumed[j] = true
+		}
+		whole := *c
+		whole.Body = state.AddBotMarker(strings.Join(texts, ""))
+		merged = append(merged, &whole)
+	}
+	return merged
+}
+
+func formatCommentPart(n, total int, text string) string {
+	return fmt.Sprintf("<!-- ultra-engineer-part %d/%d -->\n%s\n%s", n, total, text, commentPartEnd)
+}
+
+// parseCommentPart extracts the part number, part count and text from a bot comment
+// written by PostLargeComment
+func parseCommentPart(body string) (n, total int, text string, ok bool) {
+	if !state.IsBotComment(body) {
+		return 0, 0, "", false
+	}
+	m := commentPartRegex.FindStringSubmatch(body)
+	if m == nil {
+		return 0, 0, "", false
+	}
+	end := strings.LastIndex(body, "\n"+commentPartEnd)
+	if end < len(m[0]) {
+		return 0, 0, "", false
+	}
+	n, _ = strconv.Atoi(m[1])
+	total, _ = strconv.Atoi(m[2])
+	return n, total, body[len(m[0]):end], true
+}
+
+// splitComment splits body into parts of at most size bytes. It breaks between lines,
+// preferring breaks outside fenced code blocks; lines longer than size are cut.
+// Concatenating the parts gives back body.
+func splitComment(body string, size int) []string {
+	size = max(size, utf8.UTFMax)
+
+	var parts []string
+	var cur strings.Builder
+	safe := 0 // Length of cur up to the last line boundary outside a code block
+	inFence := false
+
+	for _, line := range strings.SplitAfter(body, "\n") {
+		fence := strings.HasPrefix(strings.TrimSpace(line), "```")
+
+		for cur.Len()+len(line) > size {
+			if cur.Len() == 0 {
+				// A single line longer than a part; cut it at a rune boundary
+				cut := size
+				for cut > 0 && !utf8.RuneStart(line[cut]) {
+					cut--
+				}
+				parts = append(parts, line[:cut])
+				line = line[cut:]
+				continue
+			}
+
+			s := cur.String()
+			if safe == 0 {
+				// No break outside a code block; break inside rather than not at all
+				safe = len(s)
+			}
+			parts = append(parts, s[:safe])
+			cur.Reset()
+			cur.WriteString(s[safe:])
+			safe = 0
+		}
+
+		cur.WriteString(line)
+		if fence {
+			inFence = !inFence
+		}
+		if !inFence {
+			safe = cur.Len()
+		}
+	}
+	if cur.Len() > 0 {
+		parts = append(parts, cur.String())
+	}
+	return parts
+}
@@ -0,0 +1,97 @@
+package providers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+func TestSplitComment_RoundTrips(t *testing.T) {
+	body := strings.Repeat("intro line\n", 20) +
+		"```go\n" + strings.Repeat("code line\n", 5) + "```\n" +
+		strings.Repeat("é", 300) + "\n" +
+		"tail"
+
+	parts := splitComment(body, 100)
+	if len(parts) < 2 {
+		t.Fatalf("expected several parts, got %d", len(parts))
+	}
+	for i, part := range parts {
+		if len(part) > 100 {
+			t.Errorf("part %d is %d bytes, want at most 100", i, len(part))
+		}
+	}
+	if got := strings.Join(parts, ""); got != body {
+		t.Errorf("joined parts differ from body:\n%q", got)
+	}
+
+	// The code block fits in one part, so no part should break inside it
+	for i, part := range parts {
+		if strings.Count(part, "```")%2 != 0 {
+			t.Errorf("part %d splits the code block: %q", i, part)
+		}
+	}
+}
+
+func TestPostLargeComment_FitsInOneComment(t *testing.T) {
+	mock := NewMockProvider()
+	mock.MaxCommentLen = 1000
+
+	if _, err := PostLargeComment(context.Background(), mock, "owner/repo", 1, "short"); err != nil {
+		t.Fatalf("PostLargeComment failed: %v", err)
+	}
+	if len(mock.CreatedComments) != 1 || mock.CreatedComments[0].Body != state.AddBotMarker("short") {
+		t.Errorf("expected one unsplit comment, got %+v", mock.CreatedComments)
+	}
+}
+
+func TestPostLargeComment_SplitsAndMerges(t *testing.T) {
+	mock := NewMockProvider()
+	mock.MaxCommentLen = 2000
+	body := strings.Repeat("a line of the plan\n", 500)
+
+	id, err := PostLargeComment(context.Background(), mock, "owner/repo", 1, body)
+	if err != nil {
+		t.Fatalf("PostLargeComment failed: %v", err)
+	}
+	if len(mock.CreatedComments) < 2 {
+		t.Fatalf("expected the body to be split, got %d comment(s)", len(mock.CreatedComments))
+	}
+	if id != mock.CreatedComments[0].ID {
+		t.Errorf("expected ID of the first part, got %d", id)
+	}
+	for _, c := range mock.CreatedComments {
+		if !state.IsBotComment(c.Body) {
+			t.Errorf("expected every part to be a bot comment: %q", c.Body)
+		}
+	}
+
+	comments, _ := mock.GetComments(context.Background(), "owner/repo", 1)
+	// A user reply between the parts must not stop them being merged
+	comments = append(comments[:1], append([]*Comment{{Body: "interjection"}}, comments[1:]...)...)
+
+	merged := MergeCommentParts(comments)
+	if len(merged) != 2 {
+		t.Fatalf("expected the parts merged into one comment plus the reply, got %d", len(merged))
+	}
+	if merged[0].Body != state.AddBotMarker(body) {
+		t.Errorf("merged body differs from the original")
+	}
+	if merged[1].Body != "interjection" {
+		t.Errorf("expected the reply to be kept, got %q", merged[1].Body)
+	}
+}
+
+func TestMergeCommentParts_KeepsIncompleteSets(t *testing.T) {
+	comments := []*Comment{
+		{Body: state.AddBotMarker(formatCommentPart(1, 3, "one"))},
+		{Body: state.AddBotMarker(formatCommentPart(2, 3, "two"))},
+	}
+
+	merged := MergeCommentParts(comments)
+	if len(merged) != 2 || merged[0] != comments[0] || merged[1] != comments[1] {
+		t.Errorf("expected incomplete parts to be left alone, got %+v", merged)
+	}
+}
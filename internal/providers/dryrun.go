@@ -0,0 +1,245 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DryRunProvider wraps a Provider and suppresses every mutating call.
+// Reads go to the wrapped provider. Comments the bot would have posted are
+// kept in memory and returned by GetComments, so state round-trips within
+// a single process without anything being written to the provider. Each
+// suppressed write is also recorded for the daemon's status output.
+type DryRunProvider struct {
+	inner  Provider
+	logger *log.Logger
+
+	mu         sync.Mutex
+	nextID     int64                         // Simulated comment IDs count down from -1
+	comments   map[string]map[int][]*Comment // repo -> issueNum -> simulated comments
+	suppressed []string                      // Writes not sent since TakeSuppressed was last called
+}
+
+// NewDryRunProvider wraps provider so that writes are logged instead of sent
+func NewDryRunProvider(provider Provider, logger *log.Logger) *DryRunProvider {
+	return &DryRunProvider{
+		inner:    provider,
+		logger:   logger,
+		comments: make(map[string]map[int][]*Comment),
+	}
+}
+
+// suppress logs a write that isn't sent and records its first line, without any comment
+// body that follows, for TakeSuppressed
+func (d *DryRunProvider) suppress(format string, args ...interface{}) {
+	action := fmt.Sprintf(format, args...)
+	summary, _, _ := strings.Cut(action, ":\n")
+	d.mu.Lock()
+	d.suppressed = append(d.suppressed, summary)
+	d.mu.Unlock()
+	d.logger.Printf("[dry-run] Would %s", action)
+}
+
+// TakeSuppressed returns the writes that weren't sent since it was last called, oldest first
+func (d *DryRunProvider) TakeSuppressed() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	actions := d.suppressed
+	d.suppressed = nil
+	return actions
+}
+
+func (d *DryRunProvider) Name() string {
+	return d.inner.Name()
+}
+
+func (d *DryRunProvider) GetIssue(ctx context.Context, repo string, number int) (*Issue, error) {
+	return d.inner.GetIssue(ctx, repo, number)
+}
+
+func (d *DryRunProvider) ListIssuesWithLabel(ctx context.Context, repo string, label string) ([]*Issue, error) {
+	return d.inner.ListIssuesWithLabel(ctx, repo, label)
+}
+
+// GetComments returns the real comments followed by simulated ones
+func (d *DryRunProvider) GetComments(ctx context.Context, repo string, number int) ([]*Comment, error) {
+	comments, err := d.inner.GetComments(ctx, repo, number)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, c := range d.comments[repo][number] {
+		copied := *c
+		comments = append(comments, &copied)
+	}
+	return comments, nil
+}
+
+func (d *DryRunProvider) CreateComment(ctx context.Context, repo string, number int, body string) (int64, error) {
+	d.mu.Lock()
+	d.nextID--
+	id := d.nextID
+	if d.comments[repo] == nil {
+		d.comments[repo] = make(map[int][]*Comment)
+	}
+	d.comments[repo][number] = append(d.comments[repo][number], &Comment{
+		ID:        id,
+		Body:      body,
+		Author:    "ultra-engineer[dry-run]",
+		CreatedAt: time.Now(),
+	})
+	d.mu.Unlock()
+
+	d.suppress("comment on %s#%d:\n%s", repo, number, body)
+	return id, nil
+}
+
+func (d *DryRunProvider) UpdateComment(ctx context.Context, repo string, commentID int64, body string) error {
+	d.mu.Lock()
+	for _, issueComments := range d.comments[repo] {
+		for _, c := range issueComments {
+			if c.ID == commentID {
+				c.Body = body
+			}
+		}
+	}
+	d.mu.Unlock()
+
+	d.suppress("update comment %d on %s", commentID, repo)
+	return nil
+}
+
+func (d *DryRunProvider) UpdateIssueBody(ctx context.Context, repo string, number int, body string) error {
+	d.suppress("update body of %s#%d", repo, number)
+	return nil
+}
+
+func (d *DryRunProvider) CloseIssue(ctx context.Context, repo string, number int) error {
+	d.suppress("close %s#%d", repo, number)
+	return nil
+}
+
+func (d *DryRunProvider) ReopenIssue(ctx context.Context, repo string, number int) error {
+	d.suppress("reopen %s#%d", repo, number)
+	return nil
+}
+
+func (d *DryRunProvider) ReactToComment(ctx context.Context, repo string, commentID int64, reaction string) error {
+	d.suppress("react %q to comment %d on %s", reaction, commentID, repo)
+	return nil
+}
+
+func (d *DryRunProvider) AssignIssue(ctx context.Context, repo string, number int, assignee string) error {
+	d.suppress("assign %s to %s#%d", assignee, repo, number)
+	return nil
+}
+
+func (d *DryRunProvider) UnassignIssue(ctx context.Context, repo string, number int, assignee string) error {
+	d.suppress("unassign %s from %s#%d", assignee, repo, number)
+	return nil
+}
+
+func (d *DryRunProvider) AddLabel(ctx context.Context, repo string, number int, label string) error {
+	d.suppress("add label %q to %s#%d", label, repo, number)
+	return nil
+}
+
+func (d *DryRunProvider) RemoveLabel(ctx context.Context, repo string, number int, label string) error {
+	d.suppress("remove label %q from %s#%d", label, repo, number)
+	return nil
+}
+
+func (d *DryRunProvider) CreatePR(ctx context.Context, repo string, pr PRCreate) (*PR, error) {
+	d.suppress("create PR on %s: %s (%s -> %s)", repo, pr.Title, pr.Head, pr.Base)
+	if len(pr.Labels) > 0 {
+		d.suppress("add labels %q to the PR", pr.Labels)
+	}
+	return &PR{
+		Title:   pr.Title,
+		Body:    pr.Body,
+		HeadRef: pr.Head,
+		BaseRef: pr.Base,
+		State:   "open",
+		Draft:   pr.Draft,
+	}, nil
+}
+
+func (d *DryRunProvider) GetPR(ctx context.Context, repo string, number int) (*PR, error) {
+	return d.inner.GetPR(ctx, repo, number)
+}
+
+func (d *DryRunProvider) GetPRComments(ctx context.Context, repo string, number int) ([]*Comment, error) {
+	return d.inner.GetPRComments(ctx, repo, number)
+}
+
+func (d *DryRunProvider) GetPRReviewComments(ctx context.Context, repo string, number int) ([]*Comment, error) {
+	return d.inner.GetPRReviewComments(ctx, repo, number)
+}
+
+func (d *DryRunProvider) MarkPRReady(ctx context.Context, repo string, number int) error {
+	d.suppress("mark PR #%d on %s ready for review", number, repo)
+	return nil
+}
+
+func (d *DryRunProvider) MergePR(ctx context.Context, repo string, number int, method MergeMethod) error {
+	d.suppress("merge PR #%d on %s", number, repo)
+	return nil
+}
+
+func (d *DryRunProvider) IsMergeable(ctx context.Context, repo string, number int) (bool, error) {
+	return d.inner.IsMergeable(ctx, repo, number)
+}
+
+func (d *DryRunProvider) Clone(ctx context.Context, repo string, dest string) error {
+	// Cloning only writes to the local sandbox
+	return d.inner.Clone(ctx, repo, dest)
+}
+
+func (d *DryRunProvider) GetDefaultBranch(ctx context.Context, repo string) (string, error) {
+	return d.inner.GetDefaultBranch(ctx, repo)
+}
+
+func (d *DryRunProvider) BranchExists(ctx context.Context, repo, branch string) (bool, error) {
+	return d.inner.BranchExists(ctx, repo, branch)
+}
+
+func (d *DryRunProvider) IsCollaborator(ctx context.Context, repo, username string) (bool, error) {
+	return d.inner.IsCollaborator(ctx, repo, username)
+}
+
+func (d *DryRunProvider) ReactToReviewComment(ctx context.Context, repo string, commentID int64, reaction string) error {
+	d.suppress("react %q to review comment %d on %s", reaction, commentID, repo)
+	return nil
+}
+
+func (d *DryRunProvider) ReplyToReviewComment(ctx context.Context, repo string, prNumber int, comment *Comment, body string) error {
+	d.suppress("reply to review comment %d on %s PR #%d:\n%s", comment.ID, repo, prNumber, body)
+	return nil
+}
+
+// GetIssueDependencies forwards to the wrapped provider if it supports structured links
+func (d *DryRunProvider) GetIssueDependencies(ctx context.Context, repo string, number int) ([]int, error) {
+	if depProvider, ok := d.inner.(IssueDependencyProvider); ok {
+		return depProvider.GetIssueDependencies(ctx, repo, number)
+	}
+	return nil, nil
+}
+
+// RateLimitInfo forwards to the wrapped provider if it exposes its quota
+func (d *DryRunProvider) RateLimitInfo(ctx context.Context) (*RateLimitInfo, error) {
+	if rlProvider, ok := d.inner.(RateLimitProvider); ok {
+		return rlProvider.RateLimitInfo(ctx)
+	}
+	return nil, nil
+}
+
+// MaxCommentLength forwards to the wrapped provider so dry runs split comments the same way
+func (d *DryRunProvider) MaxCommentLength() int {
+	return MaxCommentLength(d.inner)
+}
@@ -0,0 +1,70 @@
+package providers
+
+import (
+	"context"
+	"io"
+	"log"
+	"slices"
+	"testing"
+)
+
+func TestDryRunProvider_SuppressesWrites(t *testing.T) {
+	mock := NewMockProvider()
+	mock.AddIssue("owner/repo", &Issue{Number: 1})
+	d := NewDryRunProvider(mock, log.New(io.Discard, "", 0))
+	ctx := context.Background()
+
+	id, err := d.CreateComment(ctx, "owner/repo", 1, "hello")
+	if err != nil {
+		t.Fatalf("CreateComment failed: %v", err)
+	}
+	d.AddLabel(ctx, "owner/repo", 1, "phase:planning")
+	d.RemoveLabel(ctx, "owner/repo", 1, "ai-implement")
+	d.ReactToComment(ctx, "owner/repo", id, "+1")
+	d.MergePR(ctx, "owner/repo", 1, MergeMethodDefault)
+
+	if len(mock.CreatedComments) != 0 || len(mock.AddedLabels) != 0 || len(mock.RemovedLabels) != 0 ||
+		len(mock.Reactions) != 0 || len(mock.MergeMethods) != 0 {
+		t.Error("expected no writes to reach the wrapped provider")
+	}
+
+	want := []string{
+		"comment on owner/repo#1",
+		`add label "phase:planning" to owner/repo#1`,
+		`remove label "ai-implement" from owner/repo#1`,
+		`react "+1" to comment -1 on owner/repo`,
+		"merge PR #1 on owner/repo",
+	}
+	if got := d.TakeSuppressed(); !slices.Equal(got, want) {
+		t.Errorf("expected suppressed writes %q, got %q", want, got)
+	}
+	if got := d.TakeSuppressed(); len(got) != 0 {
+		t.Errorf("expected suppressed writes to be taken once, got %q", got)
+	}
+}
+
+func TestDryRunProvider_SimulatedCommentsRoundTrip(t *testing.T) {
+	mock := NewMockProvider()
+	mock.AddComment("owner/repo", 1, &Comment{ID: 10, Body: "real"})
+	d := NewDryRunProvider(mock, log.New(io.Discard, "", 0))
+	ctx := context.Background()
+
+	id, _ := d.CreateComment(ctx, "owner/repo", 1, "simulated")
+	if err := d.UpdateComment(ctx, "owner/repo", id, "updated"); err != nil {
+		t.Fatalf("UpdateComment failed: %v", err)
+	}
+
+	comments, err := d.GetComments(ctx, "owner/repo", 1)
+	if err != nil {
+		t.Fatalf("GetComments failed: %v", err)
+	}
+	if len(comments) != 2 {
+		t.Fatalf("expected real and simulated comments, got %d", len(comments))
+	}
+	if comments[0].Body != "real" || comments[1].Body != "updated" {
+		t.Errorf("unexpected comments: %q, %q", comments[0].Body, comments[1].Body)
+	}
+	if comments[1].ID >= 0 {
+		t.Errorf("expected negative simulated ID, got %d", comments[1].ID)
+	}
+}
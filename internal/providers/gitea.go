@@ -3,17 +3,22 @@ package providers
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/anthropics/ultra-engineer/internal/config"
 	"github.com/anthropics/ultra-engineer/internal/retry"
+	"github.com/anthropics/ultra-engineer/internal/state"
 )
 
 // GiteaProvider implements Provider using Gitea API directly
@@ -22,27 +27,74 @@ type GiteaProvider struct {
 	token     string
 	client    *http.Client
 	retryOpts *retry.Options
+	caCert    string // CA certificates git trusts instead of the system ones when cloning
+	proxy     string // Proxy also used by git when cloning
+
+	labelSpecs map[string]state.LabelSpec // Colors and descriptions of created labels
 }
 
+// defaultGiteaTimeout is the API request timeout when gitea.http_timeout is not set
+const defaultGiteaTimeout = 30 * time.Second
+
 // NewGiteaProvider creates a new Gitea provider
-func NewGiteaProvider(url, token string) *GiteaProvider {
-	return &GiteaProvider{
-		baseURL: strings.TrimSuffix(url, "/"),
-		token:   token,
-		client:  &http.Client{Timeout: 30 * time.Second},
+func NewGiteaProvider(cfg config.GiteaConfig) (*GiteaProvider, error) {
+	client, err := newGiteaHTTPClient(cfg)
+	if err != nil {
+		return nil, err
 	}
+	return &GiteaProvider{
+		baseURL: strings.TrimSuffix(cfg.URL, "/"),
+		token:   cfg.Token,
+		client:  client,
+		caCert:  cfg.CACert,
+		proxy:   cfg.Proxy,
+	}, nil
 }
 
 // NewGiteaProviderWithRetry creates a new Gitea provider with retry support
-func NewGiteaProviderWithRetry(url, token string, retryConfig config.RetryConfig) *GiteaProvider {
+func NewGiteaProviderWithRetry(cfg config.GiteaConfig, retryConfig config.RetryConfig) (*GiteaProvider, error) {
+	g, err := NewGiteaProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
 	opts := retry.DefaultOptions(retryConfig)
 	opts.Classifier = retry.ClassifyHTTPError
-	return &GiteaProvider{
-		baseURL:   strings.TrimSuffix(url, "/"),
-		token:     token,
-		client:    &http.Client{Timeout: 30 * time.Second},
-		retryOpts: &opts,
+	g.retryOpts = &opts
+	return g, nil
+}
+
+// newGiteaHTTPClient builds the API client from the timeout, CA and proxy settings
+func newGiteaHTTPClient(cfg config.GiteaConfig) (*http.Client, error) {
+	timeout := cfg.HTTPTimeout
+	if timeout <= 0 {
+		timeout = defaultGiteaTimeout
+	}
+
+	// The default transport's settings, including proxies from the environment
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gitea.proxy: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if cfg.CACert != "" {
+		pem, err := os.ReadFile(cfg.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gitea.ca_cert: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in gitea.ca_cert %s", cfg.CACert)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
 	}
+
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
 }
 
 func (g *GiteaProvider) Name() string {
@@ -62,20 +114,26 @@ func (g *GiteaProvider) doRequest(ctx context.Context, method, path string, body
 
 // doRequestOnce performs a single HTTP request to the Gitea API
 func (g *GiteaProvider) doRequestOnce(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	data, _, err := g.send(ctx, method, path, body)
+	return data, err
+}
+
+// send performs a single HTTP request to the Gitea API and returns the response headers too
+func (g *GiteaProvider) send(ctx context.Context, method, path string, body interface{}) ([]byte, http.Header, error) {
 	url := g.baseURL + "/api/v1" + path
 
 	var reqBody io.Reader
 	if body != nil {
 		jsonBytes, err := json.Marshal(body)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
 		reqBody = bytes.NewReader(jsonBytes)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "token "+g.token)
@@ -84,20 +142,87 @@ func (g *GiteaProvider) doRequestOnce(ctx context.Context, method, path string,
 
 	resp, err := g.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))
+		err := fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+			if delay := retry.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); delay > 0 {
+				return nil, nil, &retry.RetryableError{Err: err, RetryAfter: delay}
+			}
+		}
+		return nil, nil, err
+	}
+
+	return respBody, resp.Header, nil
+}
+
+// giteaPageSize is the number of items asked for per page of a list endpoint.
+// The server may cap it lower; pages are followed until the Link header has no next page.
+const giteaPageSize = 50
+
+// giteaPage is one page of a list endpoint
+type giteaPage struct {
+	data []byte
+	next bool // The Link header names a next page
+}
+
+// getPage fetches one page of a list endpoint
+func (g *GiteaProvider) getPage(ctx context.Context, path string) (giteaPage, error) {
+	fetch := func() (giteaPage, error) {
+		data, header, err := g.send(ctx, "GET", path, nil)
+		if err != nil {
+			return giteaPage{}, err
+		}
+		return giteaPage{data: data, next: hasNextPage(header.Get("Link"))}, nil
+	}
+	if g.retryOpts != nil {
+		return retry.DoWithResult(ctx, *g.retryOpts, fetch)
+	}
+	return fetch()
+}
+
+// giteaGetAll fetches every page of a list endpoint and decodes the items
+func giteaGetAll[T any](ctx context.Context, g *GiteaProvider, path string) ([]T, error) {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+
+	var all []T
+	for page := 1; ; page++ {
+		p, err := g.getPage(ctx, fmt.Sprintf("%s%spage=%d&limit=%d", path, sep, page, giteaPageSize))
+		if err != nil {
+			return nil, err
+		}
+		var items []T
+		if err := json.Unmarshal(p.data, &items); err != nil {
+			return nil, fmt.Errorf("failed to parse page %d of %s: %w", page, path, err)
+		}
+		all = append(all, items...)
+		if !p.next || len(items) == 0 {
+			return all, nil
+		}
 	}
+}
 
-	return respBody, nil
+// hasNextPage reports whether a Link header (RFC 8288) names a next page
+func hasNextPage(link string) bool {
+	for _, part := range strings.Split(link, ",") {
+		for _, param := range strings.Split(part, ";")[1:] {
+			if strings.ReplaceAll(strings.TrimSpace(param), " ", "") == `rel="next"` {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // Gitea API structs
@@ -107,6 +232,7 @@ type giteaIssue struct {
 	Body      string       `json:"body"`
 	State     string       `json:"state"`
 	User      giteaUser    `json:"user"`
+	Assignees []giteaUser  `json:"assignees"`
 	Labels    []giteaLabel `json:"labels"`
 	CreatedAt time.Time    `json:"created_at"`
 	UpdatedAt time.Time    `json:"updated_at"`
@@ -178,17 +304,15 @@ func (g *GiteaProvider) GetIssue(ctx context.Context, repo string, number int) (
 }
 
 func (g *GiteaProvider) ListIssuesWithLabel(ctx context.Context, repo string, label string) ([]*Issue, error) {
-	path := fmt.Sprintf("/repos/%s/issues?state=open&labels=%s", repo, url.QueryEscape(label))
-	data, err := g.doRequest(ctx, "GET", path, nil)
+	path := fmt.Sprintf("/repos/%s/issues?state=open&type=issues", repo)
+	if label != "" {
+		path += "&labels=" + url.QueryEscape(label)
+	}
+	issues, err := giteaGetAll[giteaIssue](ctx, g, path)
 	if err != nil {
 		return nil, err
 	}
 
-	var issues []giteaIssue
-	if err := json.Unmarshal(data, &issues); err != nil {
-		return nil, fmt.Errorf("failed to parse issues: %w", err)
-	}
-
 	result := make([]*Issue, len(issues))
 	for i, gi := range issues {
 		labels := make([]string, len(gi.Labels))
@@ -212,16 +336,11 @@ func (g *GiteaProvider) ListIssuesWithLabel(ctx context.Context, repo string, la
 
 func (g *GiteaProvider) GetComments(ctx context.Context, repo string, number int) ([]*Comment, error) {
 	path := fmt.Sprintf("/repos/%s/issues/%d/comments", repo, number)
-	data, err := g.doRequest(ctx, "GET", path, nil)
+	comments, err := giteaGetAll[giteaComment](ctx, g, path)
 	if err != nil {
 		return nil, err
 	}
 
-	var comments []giteaComment
-	if err := json.Unmarshal(data, &comments); err != nil {
-		return nil, fmt.Errorf("failed to parse comments: %w", err)
-	}
-
 	result := make([]*Comment, len(comments))
 	for i, c := range comments {
 		result[i] = &Comment{
@@ -261,40 +380,101 @@ func (g *GiteaProvider) UpdateIssueBody(ctx context.Context, repo string, number
 	return err
 }
 
+func (g *GiteaProvider) CloseIssue(ctx context.Context, repo string, number int) error {
+	path := fmt.Sprintf("/repos/%s/issues/%d", repo, number)
+	_, err := g.doRequest(ctx, "PATCH", path, map[string]string{"state": "closed"})
+	return err
+}
+
+func (g *GiteaProvider) ReopenIssue(ctx context.Context, repo string, number int) error {
+	path := fmt.Sprintf("/repos/%s/issues/%d", repo, number)
+	_, err := g.doRequest(ctx, "PATCH", path, map[string]string{"state": "open"})
+	return err
+}
+
 func (g *GiteaProvider) ReactToComment(ctx context.Context, repo string, commentID int64, reaction string) error {
 	path := fmt.Sprintf("/repos/%s/issues/comments/%d/reactions", repo, commentID)
 	_, err := g.doRequest(ctx, "POST", path, map[string]string{"content": reaction})
 	return err
 }
 
-func (g *GiteaProvider) AddLabel(ctx context.Context, repo string, number int, label string) error {
-	// First get the label ID
-	labelID, err := g.getLabelID(ctx, repo, label)
+func (g *GiteaProvider) AssignIssue(ctx context.Context, repo string, number int, assignee string) error {
+	return g.editAssignees(ctx, repo, number, func(current []string) []string {
+		if slices.Contains(current, assignee) {
+			return nil
+		}
+		return append(current, assignee)
+	})
+}
+
+func (g *GiteaProvider) UnassignIssue(ctx context.Context, repo string, number int, assignee string) error {
+	return g.editAssignees(ctx, repo, number, func(current []string) []string {
+		if !slices.Contains(current, assignee) {
+			return nil
+		}
+		return slices.DeleteFunc(current, func(a string) bool { return a == assignee })
+	})
+}
+
+// editAssignees replaces the issue's assignee list with edit(current).
+// Gitea's API only sets the full list, so the current assignees are fetched first.
+// A nil result means nothing needs to change.
+func (g *GiteaProvider) editAssignees(ctx context.Context, repo string, number int, edit func(current []string) []string) error {
+	path := fmt.Sprintf("/repos/%s/issues/%d", repo, number)
+	data, err := g.doRequest(ctx, "GET", path, nil)
 	if err != nil {
-		// Try to create the label
-		labelID, err = g.createLabel(ctx, repo, label)
+		return err
+	}
+
+	var gi giteaIssue
+	if err := json.Unmarshal(data, &gi); err != nil {
+		return fmt.Errorf("failed to parse issue: %w", err)
+	}
+	current := make([]string, 0, len(gi.Assignees))
+	for _, a := range gi.Assignees {
+		current = append(current, a.Login)
+	}
+
+	assignees := edit(current)
+	if assignees == nil {
+		return nil
+	}
+	_, err = g.doRequest(ctx, "PATCH", path, map[string][]string{"assignees": assignees})
+	return err
+}
+
+func (g *GiteaProvider) AddLabel(ctx context.Context, repo string, number int, label string) error {
+	return g.addLabels(ctx, repo, number, []string{label})
+}
+
+// addLabels adds labels to an issue or PR, creating the ones the repository doesn't have yet
+func (g *GiteaProvider) addLabels(ctx context.Context, repo string, number int, labels []string) error {
+	labelIDs := make([]int64, len(labels))
+	for i, label := range labels {
+		// First get the label ID
+		labelID, err := g.getLabelID(ctx, repo, label)
 		if err != nil {
-			return fmt.Errorf("failed to get or create label: %w", err)
+			// Try to create the label
+			labelID, err = g.createLabel(ctx, repo, label)
+			if err != nil {
+				return fmt.Errorf("failed to get or create label: %w", err)
+			}
 		}
+		labelIDs[i] = labelID
 	}
 
 	path := fmt.Sprintf("/repos/%s/issues/%d/labels", repo, number)
-	_, err = g.doRequest(ctx, "POST", path, map[string][]int64{"labels": {labelID}})
+	_, err := g.doRequest(ctx, "POST", path, map[string][]int64{"labels": labelIDs})
 	return err
 }
 
 func (g *GiteaProvider) getLabelID(ctx context.Context, repo string, labelName string) (int64, error) {
 	path := fmt.Sprintf("/repos/%s/labels", repo)
-	data, err := g.doRequest(ctx, "GET", path, nil)
+	labels, err := giteaGetAll[giteaLabel](ctx, g, path)
 	if err != nil {
 		return 0, err
 	}
 
-	var labels []giteaLabel
-	if err := json.Unmarshal(data, &labels); err != nil {
-		return 0, err
-	}
-
 	for _, l := range labels {
 		if l.Name == labelName {
 			return l.ID, nil
@@ -304,11 +484,18 @@ func (g *GiteaProvider) getLabelID(ctx context.Context, repo string, labelName s
 	return 0, fmt.Errorf("label not found: %s", labelName)
 }
 
+// SetLabelSpecs implements LabelStyleProvider
+func (g *GiteaProvider) SetLabelSpecs(specs map[string]state.LabelSpec) {
+	g.labelSpecs = specs
+}
+
 func (g *GiteaProvider) createLabel(ctx context.Context, repo string, labelName string) (int64, error) {
+	spec := state.LabelSpecFor(g.labelSpecs, labelName)
 	path := fmt.Sprintf("/repos/%s/labels", repo)
 	data, err := g.doRequest(ctx, "POST", path, map[string]string{
-		"name":  labelName,
-		"color": "#0052cc",
+		"name":        labelName,
+		"color":       "#" + spec.Color,
+		"description": spec.Description,
 	})
 	if err != nil {
 		return 0, err
@@ -334,9 +521,13 @@ func (g *GiteaProvider) RemoveLabel(ctx context.Context, repo string, number int
 }
 
 func (g *GiteaProvider) CreatePR(ctx context.Context, repo string, pr PRCreate) (*PR, error) {
+	title := pr.Title
+	if pr.Draft {
+		title = giteaDraftPrefix + title
+	}
 	path := fmt.Sprintf("/repos/%s/pulls", repo)
 	data, err := g.doRequest(ctx, "POST", path, map[string]interface{}{
-		"title": pr.Title,
+		"title": title,
 		"body":  pr.Body,
 		"head":  pr.Head,
 		"base":  pr.Base,
@@ -350,7 +541,7 @@ func (g *GiteaProvider) CreatePR(ctx context.Context, repo string, pr PRCreate)
 		return nil, fmt.Errorf("failed to parse PR: %w", err)
 	}
 
-	return &PR{
+	result := &PR{
 		Number:    gp.Number,
 		Title:     gp.Title,
 		Body:      gp.Body,
@@ -359,7 +550,33 @@ func (g *GiteaProvider) CreatePR(ctx context.Context, repo string, pr PRCreate)
 		HTMLURL:   gp.HTMLURL,
 		HeadRef:   gp.Head.Ref,
 		BaseRef:   gp.Base.Ref,
-	}, nil
+		Draft:     giteaIsDraft(gp.Title),
+	}
+
+	if len(pr.Labels) > 0 {
+		if err := g.addLabels(ctx, repo, result.Number, pr.Labels); err != nil {
+			return result, fmt.Errorf("%w: failed to add labels: %w", ErrPRSetup, err)
+		}
+	}
+	if pr.IssueID != 0 {
+		if err := g.linkIssue(ctx, repo, pr.IssueID, result.Number); err != nil {
+			return result, fmt.Errorf("%w: failed to link issue #%d: %w", ErrPRSetup, pr.IssueID, err)
+		}
+	}
+	return result, nil
+}
+
+// linkIssue makes the issue depend on the PR, which Gitea shows in the sidebar of both.
+// "Closes #N" in the body only links them by reference.
+func (g *GiteaProvider) linkIssue(ctx context.Context, repo string, issueNum, prNum int) error {
+	owner, name, _ := strings.Cut(repo, "/")
+	path := fmt.Sprintf("/repos/%s/issues/%d/dependencies", repo, issueNum)
+	_, err := g.doRequest(ctx, "POST", path, map[string]interface{}{
+		"owner": owner,
+		"repo":  name,
+		"index": prNum,
+	})
+	return err
 }
 
 func (g *GiteaProvider) GetPR(ctx context.Context, repo string, number int) (*PR, error) {
@@ -383,22 +600,18 @@ func (g *GiteaProvider) GetPR(ctx context.Context, repo string, number int) (*PR
 		HTMLURL:   gp.HTMLURL,
 		HeadRef:   gp.Head.Ref,
 		BaseRef:   gp.Base.Ref,
+		Draft:     giteaIsDraft(gp.Title),
 	}, nil
 }
 
 func (g *GiteaProvider) GetPRComments(ctx context.Context, repo string, number int) ([]*Comment, error) {
 	// Gitea uses the same endpoint for PR comments as issue comments
 	path := fmt.Sprintf("/repos/%s/issues/%d/comments", repo, number)
-	data, err := g.doRequest(ctx, "GET", path, nil)
+	comments, err := giteaGetAll[giteaComment](ctx, g, path)
 	if err != nil {
 		return nil, err
 	}
 
-	var comments []giteaComment
-	if err := json.Unmarshal(data, &comments); err != nil {
-		return nil, fmt.Errorf("failed to parse comments: %w", err)
-	}
-
 	result := make([]*Comment, len(comments))
 	for i, c := range comments {
 		result[i] = &Comment{
@@ -419,47 +632,47 @@ type giteaReview struct {
 
 // giteaReviewComment represents a review comment from Gitea's API
 type giteaReviewComment struct {
-	ID        int64     `json:"id"`
-	Body      string    `json:"body"`
-	User      giteaUser `json:"user"`
-	CreatedAt time.Time `json:"created_at"`
+	ID               int64     `json:"id"`
+	Body             string    `json:"body"`
+	User             giteaUser `json:"user"`
+	CreatedAt        time.Time `json:"created_at"`
+	Path             string    `json:"path"`
+	Position         int       `json:"position"`          // Line in the new version of the file
+	OriginalPosition int       `json:"original_position"` // Line in the old version, for comments on removed lines
 }
 
 func (g *GiteaProvider) GetPRReviewComments(ctx context.Context, repo string, number int) ([]*Comment, error) {
 	// Gitea's API structure: first list all reviews, then fetch comments from each review
 	// Endpoint: /repos/{owner}/{repo}/pulls/{index}/reviews
 	reviewsPath := fmt.Sprintf("/repos/%s/pulls/%d/reviews", repo, number)
-	reviewsData, err := g.doRequest(ctx, "GET", reviewsPath, nil)
+	reviews, err := giteaGetAll[giteaReview](ctx, g, reviewsPath)
 	if err != nil {
 		return nil, err
 	}
 
-	var reviews []giteaReview
-	if err := json.Unmarshal(reviewsData, &reviews); err != nil {
-		return nil, fmt.Errorf("failed to parse reviews: %w", err)
-	}
-
 	// Fetch comments from each review
 	var allComments []*Comment
 	for _, review := range reviews {
 		commentsPath := fmt.Sprintf("/repos/%s/pulls/%d/reviews/%d/comments", repo, number, review.ID)
-		commentsData, err := g.doRequest(ctx, "GET", commentsPath, nil)
+		reviewComments, err := giteaGetAll[giteaReviewComment](ctx, g, commentsPath)
 		if err != nil {
 			// Log error but continue to process other reviews
 			continue
 		}
 
-		var reviewComments []giteaReviewComment
-		if err := json.Unmarshal(commentsData, &reviewComments); err != nil {
-			continue
-		}
-
 		for _, rc := range reviewComments {
+			line, outdated := rc.Position, false
+			if line == 0 && rc.OriginalPosition != 0 {
+				line, outdated = rc.OriginalPosition, true
+			}
 			allComments = append(allComments, &Comment{
 				ID:        rc.ID,
 				Body:      rc.Body,
 				Author:    rc.User.Login,
 				CreatedAt: rc.CreatedAt,
+				Path:      rc.Path,
+				Line:      line,
+				Outdated:  outdated,
 			})
 		}
 	}
@@ -467,13 +680,67 @@ func (g *GiteaProvider) GetPRReviewComments(ctx context.Context, repo string, nu
 	return allComments, nil
 }
 
-func (g *GiteaProvider) MergePR(ctx context.Context, repo string, number int) error {
+// ReactToReviewComment adds a reaction to an inline review comment.
+// Gitea stores review comments as issue comments, so the issue endpoint applies.
+func (g *GiteaProvider) ReactToReviewComment(ctx context.Context, repo string, commentID int64, reaction string) error {
+	return g.ReactToComment(ctx, repo, commentID, reaction)
+}
+
+// ReplyToReviewComment replies to an inline review comment.
+// Gitea has no reply endpoint; a comment on the same file and line joins the existing thread.
+// An outdated comment's line is from an older diff, so commenting there would start a thread
+// on an unrelated line.
+func (g *GiteaProvider) ReplyToReviewComment(ctx context.Context, repo string, prNumber int, comment *Comment, body string) error {
+	if comment.Path == "" || comment.Line == 0 {
+		return fmt.Errorf("cannot reply to review comment %d without a file and line", comment.ID)
+	}
+	if comment.Outdated {
+		return fmt.Errorf("cannot reply to review comment %d: its line is no longer in the diff", comment.ID)
+	}
+
+	path := fmt.Sprintf("/repos/%s/pulls/%d/reviews", repo, prNumber)
+	review := map[string]interface{}{
+		"event": "COMMENT",
+		"comments": []map[string]interface{}{{
+			"path":         comment.Path,
+			"body":         body,
+			"new_position": comment.Line,
+		}},
+	}
+	_, err := g.doRequest(ctx, "POST", path, review)
+	return err
+}
+
+// giteaMergeStyle maps a merge method to Gitea's merge "do" value
+func giteaMergeStyle(method MergeMethod) (string, error) {
+	switch method {
+	case MergeMethodDefault, MergeMethodSquash:
+		return "squash", nil // Use squash to avoid duplicate commits
+	case MergeMethodMerge:
+		return "merge", nil
+	case MergeMethodRebase:
+		return "rebase", nil
+	default:
+		return "", fmt.Errorf("unsupported merge method: %s", method)
+	}
+}
+
+func (g *GiteaProvider) MergePR(ctx context.Context, repo string, number int, method MergeMethod) error {
+	style, err := giteaMergeStyle(method)
+	if err != nil {
+		return err
+	}
+
 	path := fmt.Sprintf("/repos/%s/pulls/%d/merge", repo, number)
-	_, err := g.doRequest(ctx, "POST", path, map[string]string{
-		"do": "squash", // Use squash to avoid duplicate commits
+	_, err = g.doRequest(ctx, "POST", path, map[string]string{
+		"do": style,
 	})
 	if err != nil {
 		errStr := strings.ToLower(err.Error())
+		// Gitea rejects disabled merge styles with "... merge style ..." in the message
+		if strings.Contains(errStr, "merge style") {
+			return fmt.Errorf("%w: %s: %v", ErrMergeMethodRejected, style, err)
+		}
 		// Gitea returns 405 or 409 when merge is not allowed yet (e.g. pending
 		// required approvals, unresolved reviews, branch protection rules).
 		if strings.Contains(errStr, "405") || strings.Contains(errStr, "409") ||
@@ -492,6 +759,46 @@ func (g *GiteaProvider) IsMergeable(ctx context.Context, repo string, number int
 	return pr.Mergeable, nil
 }
 
+// giteaDraftPrefixes are the title prefixes that make a Gitea PR a draft ("work in
+// progress"), as in Gitea's default repository.pull-request.WORK_IN_PROGRESS_PREFIXES
+var giteaDraftPrefixes = []string{"WIP:", "[WIP]"}
+
+// giteaDraftPrefix is prepended to the title of PRs created as drafts
+const giteaDraftPrefix = "WIP: "
+
+// giteaIsDraft reports whether a PR title marks the PR as a draft
+func giteaIsDraft(title string) bool {
+	_, draft := giteaTrimDraftPrefix(title)
+	return draft
+}
+
+// giteaTrimDraftPrefix removes the draft prefix from a PR title
+func giteaTrimDraftPrefix(title string) (string, bool) {
+	for _, prefix := range giteaDraftPrefixes {
+		if len(title) >= len(prefix) && strings.EqualFold(title[:len(prefix)], prefix) {
+			return strings.TrimSpace(title[len(prefix):]), true
+		}
+	}
+	return title, false
+}
+
+// MarkPRReady removes the draft prefix from the PR title; Gitea has no separate draft flag
+func (g *GiteaProvider) MarkPRReady(ctx context.Context, repo string, number int) error {
+	pr, err := g.GetPR(ctx, repo, number)
+	if err != nil {
+		return err
+	}
+
+	title, draft := giteaTrimDraftPrefix(pr.Title)
+	if !draft {
+		return nil
+	}
+
+	path := fmt.Sprintf("/repos/%s/pulls/%d", repo, number)
+	_, err = g.doRequest(ctx, "PATCH", path, map[string]string{"title": title})
+	return err
+}
+
 func (g *GiteaProvider) Clone(ctx context.Context, repo string, dest string) error {
 	// Get repo info to get clone URL
 	path := fmt.Sprintf("/repos/%s", repo)
@@ -509,7 +816,15 @@ func (g *GiteaProvider) Clone(ctx context.Context, repo string, dest string) err
 	cloneURL := repoInfo.CloneURL
 	cloneURL = strings.Replace(cloneURL, "https://", fmt.Sprintf("https://oauth2:%s@", g.token), 1)
 
-	cmd := exec.CommandContext(ctx, "git", "clone", cloneURL, dest)
+	// Settings passed with --config stay in the clone, so later pushes use them too
+	args := []string{"clone"}
+	if g.caCert != "" {
+		args = append(args, "--config", "http.sslCAInfo="+g.caCert)
+	}
+	if g.proxy != "" {
+		args = append(args, "--config", "http.proxy="+g.proxy)
+	}
+	cmd := exec.CommandContext(ctx, "git", append(args, cloneURL, dest)...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		// Sanitize output to remove any token that might be in error messages
@@ -537,6 +852,37 @@ func (g *GiteaProvider) GetDefaultBranch(ctx context.Context, repo string) (stri
 	return repoInfo.DefaultBranch, nil
 }
 
+// BranchExists reports whether branch exists in repo
+func (g *GiteaProvider) BranchExists(ctx context.Context, repo, branch string) (bool, error) {
+	data, err := g.doRequest(ctx, "GET", fmt.Sprintf("/repos/%s/git/refs/heads/%s", repo, branch), nil)
+	if err != nil {
+		if strings.Contains(err.Error(), "API error 404") {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check branch %s: %w", branch, err)
+	}
+
+	// The endpoint returns the ref as an object when exactly one ref matches the path,
+	// and otherwise lists every ref starting with it, so feat also matches feat-2
+	type giteaRef struct {
+		Ref string `json:"ref"`
+	}
+	var refs []giteaRef
+	if err := json.Unmarshal(data, &refs); err != nil {
+		var ref giteaRef
+		if err := json.Unmarshal(data, &ref); err != nil {
+			return false, fmt.Errorf("failed to parse refs: %w", err)
+		}
+		refs = []giteaRef{ref}
+	}
+	for _, r := range refs {
+		if r.Ref == "refs/heads/"+branch {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // giteaCommitStatus represents a commit status from Gitea's API
 type giteaCommitStatus struct {
 	ID          int64  `json:"id"`
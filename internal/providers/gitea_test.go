@@ -0,0 +1,554 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/retry"
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+// newTestGiteaProvider returns a provider for the Gitea API at url
+func newTestGiteaProvider(t *testing.T, url string) *GiteaProvider {
+	t.Helper()
+	g, err := NewGiteaProvider(config.GiteaConfig{URL: url, Token: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestGiteaMergePR_Methods(t *testing.T) {
+	tests := []struct {
+		method   MergeMethod
+		expected string
+	}{
+		{MergeMethodDefault, "squash"},
+		{MergeMethodSquash, "squash"},
+		{MergeMethodMerge, "merge"},
+		{MergeMethodRebase, "rebase"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			var got map[string]string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/repos/owner/repo/pulls/5/merge" {
+					t.Errorf("unexpected path: %s", r.URL.Path)
+				}
+				json.NewDecoder(r.Body).Decode(&got)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			g := newTestGiteaProvider(t, server.URL)
+			if err := g.MergePR(context.Background(), "owner/repo", 5, tt.method); err != nil {
+				t.Fatalf("MergePR failed: %v", err)
+			}
+			if got["do"] != tt.expected {
+				t.Errorf("expected do=%q, got %q", tt.expected, got["do"])
+			}
+		})
+	}
+}
+
+func TestGiteaMergePR_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected error
+	}{
+		{"method rejected", http.StatusMethodNotAllowed, `{"message":"rebase is not an allowed merge style for this repository"}`, ErrMergeMethodRejected},
+		{"not mergeable yet", http.StatusMethodNotAllowed, `{"message":"Please try again later"}`, ErrMergeNotAllowed},
+		{"conflict with protection", http.StatusConflict, `{"message":"not enough approvals"}`, ErrMergeNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			g := newTestGiteaProvider(t, server.URL)
+			err := g.MergePR(context.Background(), "owner/repo", 5, MergeMethodRebase)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestGiteaMergePR_UnsupportedMethod(t *testing.T) {
+	g := newTestGiteaProvider(t, "http://unused")
+	if err := g.MergePR(context.Background(), "owner/repo", 5, MergeMethod("octopus")); err == nil {
+		t.Error("expected error for unsupported merge method")
+	}
+}
+
+func TestGiteaGetPRReviewComments_FileAndLine(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/repos/owner/repo/pulls/5/reviews":
+			w.Write([]byte(`[{"id": 1}]`))
+		case "/api/v1/repos/owner/repo/pulls/5/reviews/1/comments":
+			w.Write([]byte(`[
+				{"id": 10, "body": "rename this", "user": {"login": "alice"}, "path": "main.go", "position": 12},
+				{"id": 11, "body": "why removed?", "user": {"login": "alice"}, "path": "old.go", "position": 0, "original_position": 4}
+			]`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	g := newTestGiteaProvider(t, server.URL)
+	comments, err := g.GetPRReviewComments(context.Background(), "owner/repo", 5)
+	if err != nil {
+		t.Fatalf("GetPRReviewComments failed: %v", err)
+	}
+	if len(comments) != 2 {
+		t.Fatalf("expected 2 comments, got %d", len(comments))
+	}
+	if comments[0].Path != "main.go" || comments[0].Line != 12 {
+		t.Errorf("expected main.go:12, got %s:%d", comments[0].Path, comments[0].Line)
+	}
+	if comments[0].Outdated {
+		t.Error("expected a comment with a current position not to be outdated")
+	}
+	if comments[1].Path != "old.go" || comments[1].Line != 4 || !comments[1].Outdated {
+		t.Errorf("expected fallback to original position old.go:4, marked outdated, got %+v", comments[1])
+	}
+}
+
+func TestGiteaReplyToReviewComment(t *testing.T) {
+	var got struct {
+		Event    string `json:"event"`
+		Comments []struct {
+			Path        string `json:"path"`
+			Body        string `json:"body"`
+			NewPosition int    `json:"new_position"`
+		} `json:"comments"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v1/repos/owner/repo/pulls/5/reviews" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"id": 3}`))
+	}))
+	defer server.Close()
+
+	g := newTestGiteaProvider(t, server.URL)
+	comment := &Comment{ID: 10, Path: "main.go", Line: 12}
+	if err := g.ReplyToReviewComment(context.Background(), "owner/repo", 5, comment, "Addressed in abc1234"); err != nil {
+		t.Fatalf("ReplyToReviewComment failed: %v", err)
+	}
+	if got.Event != "COMMENT" || len(got.Comments) != 1 {
+		t.Fatalf("unexpected review payload: %+v", got)
+	}
+	if c := got.Comments[0]; c.Path != "main.go" || c.NewPosition != 12 || c.Body != "Addressed in abc1234" {
+		t.Errorf("unexpected review comment: %+v", c)
+	}
+
+	if err := g.ReplyToReviewComment(context.Background(), "owner/repo", 5, &Comment{ID: 11}, "x"); err == nil {
+		t.Error("expected error replying to a comment without file and line")
+	}
+
+	// The original position isn't a new_position; posting there would comment on another line
+	got.Comments = nil
+	outdated := &Comment{ID: 12, Path: "old.go", Line: 4, Outdated: true}
+	if err := g.ReplyToReviewComment(context.Background(), "owner/repo", 5, outdated, "x"); err == nil {
+		t.Error("expected error replying to an outdated comment")
+	}
+	if len(got.Comments) != 0 {
+		t.Errorf("expected nothing posted for an outdated comment, got %+v", got.Comments)
+	}
+}
+
+func TestGiteaCloseAndReopenIssue(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/api/v1/repos/owner/repo/issues/7" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"number": 7}`))
+	}))
+	defer server.Close()
+
+	g := newTestGiteaProvider(t, server.URL)
+	if err := g.CloseIssue(context.Background(), "owner/repo", 7); err != nil {
+		t.Fatalf("CloseIssue failed: %v", err)
+	}
+	if got["state"] != "closed" {
+		t.Errorf("expected state=closed, got %+v", got)
+	}
+
+	if err := g.ReopenIssue(context.Background(), "owner/repo", 7); err != nil {
+		t.Fatalf("ReopenIssue failed: %v", err)
+	}
+	if got["state"] != "open" {
+		t.Errorf("expected state=open, got %+v", got)
+	}
+}
+
+func TestGiteaAssignIssue(t *testing.T) {
+	var patches [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/owner/repo/issues/7" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(`{"number": 7, "assignees": [{"login": "alice"}]}`))
+		case "PATCH":
+			var body map[string][]string
+			json.NewDecoder(r.Body).Decode(&body)
+			patches = append(patches, body["assignees"])
+			w.Write([]byte(`{"number": 7}`))
+		}
+	}))
+	defer server.Close()
+
+	g := newTestGiteaProvider(t, server.URL)
+	ctx := context.Background()
+	if err := g.AssignIssue(ctx, "owner/repo", 7, "bot"); err != nil {
+		t.Fatalf("AssignIssue failed: %v", err)
+	}
+	if err := g.UnassignIssue(ctx, "owner/repo", 7, "alice"); err != nil {
+		t.Fatalf("UnassignIssue failed: %v", err)
+	}
+	// Neither call changes anything, so no PATCH is sent
+	if err := g.AssignIssue(ctx, "owner/repo", 7, "alice"); err != nil {
+		t.Fatalf("AssignIssue failed: %v", err)
+	}
+	if err := g.UnassignIssue(ctx, "owner/repo", 7, "bot"); err != nil {
+		t.Fatalf("UnassignIssue failed: %v", err)
+	}
+
+	want := [][]string{{"alice", "bot"}, {}}
+	if !reflect.DeepEqual(patches, want) {
+		t.Errorf("assignee updates = %v, want %v", patches, want)
+	}
+}
+
+func TestNewGiteaProvider_HTTPTimeout(t *testing.T) {
+	g := newTestGiteaProvider(t, "http://unused")
+	if g.client.Timeout != 30*time.Second {
+		t.Errorf("expected the default timeout of 30s, got %s", g.client.Timeout)
+	}
+
+	g, err := NewGiteaProvider(config.GiteaConfig{URL: "http://unused", HTTPTimeout: 2 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.client.Timeout != 2*time.Minute {
+		t.Errorf("expected the configured timeout of 2m, got %s", g.client.Timeout)
+	}
+}
+
+func TestNewGiteaProvider_CACert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"default_branch":"main"}`))
+	}))
+	defer server.Close()
+
+	// Without the server's CA the request fails certificate verification
+	if _, err := newTestGiteaProvider(t, server.URL).GetDefaultBranch(context.Background(), "owner/repo"); err == nil {
+		t.Fatal("expected an untrusted certificate to be rejected")
+	}
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caPath, caPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGiteaProvider(config.GiteaConfig{URL: server.URL, Token: "token", CACert: caPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if branch, err := g.GetDefaultBranch(context.Background(), "owner/repo"); err != nil || branch != "main" {
+		t.Errorf("expected main with the CA trusted, got %q (err %v)", branch, err)
+	}
+}
+
+func TestNewGiteaProvider_InvalidCACert(t *testing.T) {
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(caPath, []byte("not a certificate"), 0644)
+
+	for _, path := range []string{caPath, filepath.Join(t.TempDir(), "missing.pem")} {
+		_, err := NewGiteaProvider(config.GiteaConfig{URL: "https://unused", CACert: path})
+		if err == nil || !strings.Contains(err.Error(), "gitea.ca_cert") {
+			t.Errorf("%s: expected a gitea.ca_cert error, got %v", path, err)
+		}
+	}
+}
+
+func TestGiteaGetComments_FollowsPages(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		page := r.URL.Query().Get("page")
+		if page == "1" {
+			// The server capped the page size below the requested limit
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v1%s?page=2&limit=2>; rel="next", <%s/api/v1%s?page=2&limit=2>; rel="last"`, "http://"+r.Host, r.URL.Path, "http://"+r.Host, r.URL.Path))
+			w.Write([]byte(`[{"id":1,"body":"first"},{"id":2,"body":"second"}]`))
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/api/v1%s?page=1&limit=2>; rel="first"`, "http://"+r.Host, r.URL.Path))
+		w.Write([]byte(`[{"id":3,"body":"third"}]`))
+	}))
+	defer server.Close()
+
+	g := newTestGiteaProvider(t, server.URL)
+	comments, err := g.GetComments(context.Background(), "owner/repo", 1)
+	if err != nil {
+		t.Fatalf("GetComments failed: %v", err)
+	}
+	if len(comments) != 3 || comments[2].Body != "third" {
+		t.Fatalf("expected the comments of both pages, got %+v", comments)
+	}
+	want := []string{
+		"/api/v1/repos/owner/repo/issues/1/comments?page=1&limit=50",
+		"/api/v1/repos/owner/repo/issues/1/comments?page=2&limit=50",
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("expected requests %v, got %v", want, requests)
+	}
+}
+
+func TestGiteaListIssuesWithLabel_KeepsQuery(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`[{"number":4,"title":"Add cache"}]`))
+	}))
+	defer server.Close()
+
+	issues, err := newTestGiteaProvider(t, server.URL).ListIssuesWithLabel(context.Background(), "owner/repo", "ai-implement")
+	if err != nil || len(issues) != 1 {
+		t.Fatalf("expected one issue, got %v (err %v)", issues, err)
+	}
+	if query != "state=open&type=issues&labels=ai-implement&page=1&limit=50" {
+		t.Errorf("unexpected query: %s", query)
+	}
+}
+
+func TestHasNextPage(t *testing.T) {
+	tests := []struct {
+		link string
+		want bool
+	}{
+		{"", false},
+		{`<https://gitea.example.com/api/v1/repos/o/r/issues?page=2>; rel="next", <https://gitea.example.com/api/v1/repos/o/r/issues?page=5>; rel="last"`, true},
+		{`<https://gitea.example.com/api/v1/repos/o/r/issues?page=1>; rel="first", <https://gitea.example.com/api/v1/repos/o/r/issues?page=4>; rel="prev"`, false},
+		{`<https://gitea.example.com/next?page=2>; rel="last"`, false},
+	}
+
+	for _, tt := range tests {
+		if got := hasNextPage(tt.link); got != tt.want {
+			t.Errorf("hasNextPage(%q) = %v, want %v", tt.link, got, tt.want)
+		}
+	}
+}
+
+func TestGiteaAddLabel_CreatesMissingLabelWithSpec(t *testing.T) {
+	var created map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v1/repos/owner/repo/labels":
+			w.Write([]byte(`[]`))
+		case r.Method == "POST" && r.URL.Path == "/api/v1/repos/owner/repo/labels":
+			json.NewDecoder(r.Body).Decode(&created)
+			w.Write([]byte(`{"id":7,"name":"phase:failed"}`))
+		case r.Method == "POST" && r.URL.Path == "/api/v1/repos/owner/repo/issues/1/labels":
+			w.Write([]byte(`[]`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	g := newTestGiteaProvider(t, server.URL)
+	g.SetLabelSpecs(state.LabelSpecs(nil))
+	if err := g.AddLabel(context.Background(), "owner/repo", 1, "phase:failed"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+	want := state.LabelSpecs(nil)["phase:failed"]
+	if created["color"] != "#"+want.Color || created["description"] != want.Description {
+		t.Errorf("expected the label created as %+v, got %v", want, created)
+	}
+}
+
+func TestGiteaDraftPR(t *testing.T) {
+	var created, edited map[string]string
+	title := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/v1/repos/owner/repo/pulls":
+			json.NewDecoder(r.Body).Decode(&created)
+			title = created["title"]
+		case r.Method == "PATCH" && r.URL.Path == "/api/v1/repos/owner/repo/pulls/3":
+			json.NewDecoder(r.Body).Decode(&edited)
+			title = edited["title"]
+		case r.Method == "GET" && r.URL.Path == "/api/v1/repos/owner/repo/pulls/3":
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"number": 3, "title": title})
+	}))
+	defer server.Close()
+
+	g := newTestGiteaProvider(t, server.URL)
+	pr, err := g.CreatePR(context.Background(), "owner/repo", PRCreate{Title: "Implement: cache", Head: "feat", Base: "main", Draft: true})
+	if err != nil {
+		t.Fatalf("CreatePR failed: %v", err)
+	}
+	if created["title"] != "WIP: Implement: cache" || !pr.Draft {
+		t.Fatalf("expected a WIP title marking a draft, got %q draft=%v", created["title"], pr.Draft)
+	}
+
+	if err := g.MarkPRReady(context.Background(), "owner/repo", 3); err != nil {
+		t.Fatalf("MarkPRReady failed: %v", err)
+	}
+	if edited["title"] != "Implement: cache" {
+		t.Errorf("expected the WIP prefix removed, got %q", edited["title"])
+	}
+
+	// A PR that is already ready is left alone
+	edited = nil
+	if err := g.MarkPRReady(context.Background(), "owner/repo", 3); err != nil {
+		t.Fatalf("MarkPRReady failed: %v", err)
+	}
+	if edited != nil {
+		t.Errorf("expected no edit, got %v", edited)
+	}
+}
+
+func TestGiteaCreatePR_LabelsAndIssueLink(t *testing.T) {
+	var labels map[string][]int64
+	var dependency map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v1/repos/owner/repo/pulls":
+			w.Write([]byte(`{"number": 3, "title": "t"}`))
+		case "GET /api/v1/repos/owner/repo/labels":
+			w.Write([]byte(`[{"id": 7, "name": "bot"}]`))
+		case "POST /api/v1/repos/owner/repo/issues/3/labels":
+			json.NewDecoder(r.Body).Decode(&labels)
+			w.Write([]byte(`[]`))
+		case "POST /api/v1/repos/owner/repo/issues/1/dependencies":
+			json.NewDecoder(r.Body).Decode(&dependency)
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	g := newTestGiteaProvider(t, server.URL)
+	pr, err := g.CreatePR(context.Background(), "owner/repo", PRCreate{Title: "t", Head: "feat", Base: "main", IssueID: 1, Labels: []string{"bot"}})
+	if err != nil {
+		t.Fatalf("CreatePR failed: %v", err)
+	}
+	if pr.Number != 3 || !reflect.DeepEqual(labels["labels"], []int64{7}) {
+		t.Errorf("expected label 7 added to PR #3, got %v", labels)
+	}
+	if dependency["owner"] != "owner" || dependency["repo"] != "repo" || dependency["index"] != float64(3) {
+		t.Errorf("expected issue #1 to depend on PR #3, got %v", dependency)
+	}
+}
+
+func TestGiteaCreatePR_SetupFailureKeepsPR(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/repos/owner/repo/pulls" {
+			w.Write([]byte(`{"number": 3, "title": "t"}`))
+			return
+		}
+		// Issue dependencies are disabled in the repository
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	g := newTestGiteaProvider(t, server.URL)
+	pr, err := g.CreatePR(context.Background(), "owner/repo", PRCreate{Title: "t", Head: "feat", Base: "main", IssueID: 1})
+	if !errors.Is(err, ErrPRSetup) || pr == nil || pr.Number != 3 {
+		t.Fatalf("expected PR #3 with ErrPRSetup, got %+v, %v", pr, err)
+	}
+}
+
+func TestGiteaBranchExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/repos/owner/repo/git/refs/heads/ue/1-fix":
+			// Refs are matched by prefix, so a taken ue/1-fix-2 shows up for ue/1-fix too
+			w.Write([]byte(`[{"ref":"refs/heads/ue/1-fix-2"}]`))
+		case "/api/v1/repos/owner/repo/git/refs/heads/ue/1-fix-2":
+			// A single match is returned as an object rather than a list
+			w.Write([]byte(`{"ref":"refs/heads/ue/1-fix-2","object":{"type":"commit","sha":"abc"}}`))
+		case "/api/v1/repos/owner/repo/git/refs/heads/ue/2-fix":
+			w.Write([]byte(`[{"ref":"refs/heads/ue/2-fix"},{"ref":"refs/heads/ue/2-fix-2"}]`))
+		case "/api/v1/repos/owner/repo/git/refs/heads/ue/3-fix":
+			w.Write([]byte(`"not a ref"`))
+		default:
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	g := newTestGiteaProvider(t, server.URL)
+	ctx := context.Background()
+
+	for branch, want := range map[string]bool{"ue/1-fix": false, "ue/1-fix-2": true, "ue/1-other": false, "ue/2-fix": true} {
+		exists, err := g.BranchExists(ctx, "owner/repo", branch)
+		if err != nil || exists != want {
+			t.Errorf("BranchExists(%q) = %v, %v, want %v", branch, exists, err, want)
+		}
+	}
+	if _, err := g.BranchExists(ctx, "owner/repo", "ue/3-fix"); err == nil {
+		t.Error("expected an error for an unexpected response")
+	}
+}
+
+func TestGiteaSend_RetryAfter(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "120")
+			http.Error(w, "rate limited", http.StatusTooManyRequests)
+			return
+		}
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	g := newTestGiteaProvider(t, server.URL)
+
+	_, err := g.doRequestOnce(context.Background(), "GET", "/repos/owner/repo", nil)
+	var retryable *retry.RetryableError
+	if !errors.As(err, &retryable) || retryable.RetryAfter != 2*time.Minute {
+		t.Fatalf("expected the Retry-After delay on the error, got %v", err)
+	}
+	if retry.ClassifyHTTPError(err) != retry.RateLimited {
+		t.Error("expected the error to still classify as rate limited")
+	}
+
+	_, err = g.doRequestOnce(context.Background(), "GET", "/repos/owner/repo", nil)
+	if errors.As(err, &retryable) {
+		t.Errorf("expected no delay without a Retry-After header, got %v", retryable.RetryAfter)
+	}
+}
@@ -1,57 +1,82 @@
 package providers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/anthropics/ultra-engineer/internal/config"
 	"github.com/anthropics/ultra-engineer/internal/retry"
+	"github.com/anthropics/ultra-engineer/internal/state"
 )
 
 // GitHubProvider implements Provider using the gh CLI
 // Note: Authentication is handled by the gh CLI (via GH_TOKEN env var or gh auth login)
 type GitHubProvider struct {
-	retryOpts *retry.Options
+	retryOpts  *retry.Options
+	host       string                     // GitHub Enterprise hostname; empty for github.com
+	labelSpecs map[string]state.LabelSpec // Colors and descriptions of created labels
+
+	labelsMu   sync.Mutex
+	repoLabels map[string]map[string]bool // Labels known to exist, per repository
 }
 
 // NewGitHubProvider creates a new GitHub provider
-// The token parameter is kept for interface consistency but authentication
-// is handled by the gh CLI itself
-func NewGitHubProvider(token string) *GitHubProvider {
-	// If a token is provided, set it as an environment variable for gh CLI
-	// This allows explicit token configuration while still using gh CLI
-	// Note: This is not thread-safe, but provider creation should happen
-	// once during startup, not concurrently
-	if token != "" {
-		os.Setenv("GH_TOKEN", token)
-	}
-	return &GitHubProvider{}
+// The token is optional since authentication is handled by the gh CLI itself
+func NewGitHubProvider(cfg config.GitHubConfig) *GitHubProvider {
+	return &GitHubProvider{host: configureGH(cfg)}
 }
 
 // NewGitHubProviderWithRetry creates a new GitHub provider with retry support
-func NewGitHubProviderWithRetry(token string, retryConfig config.RetryConfig) *GitHubProvider {
-	if token != "" {
-		os.Setenv("GH_TOKEN", token)
-	}
+func NewGitHubProviderWithRetry(cfg config.GitHubConfig, retryConfig config.RetryConfig) *GitHubProvider {
 	opts := retry.DefaultOptions(retryConfig)
 	opts.Classifier = retry.ClassifyHTTPError
 	return &GitHubProvider{
 		retryOpts: &opts,
+		host:      configureGH(cfg),
 	}
 }
 
+// configureGH points the gh CLI at the configured host and token through its environment,
+// so the git credential helper used when pushing from the sandbox sees them too, and
+// returns the Enterprise host ("" for github.com).
+// Note: This is not thread-safe, but provider creation should happen
+// once during startup, not concurrently
+func configureGH(cfg config.GitHubConfig) string {
+	host := cfg.Hostname()
+	if host != "" {
+		os.Setenv("GH_HOST", host)
+	}
+	if cfg.Token != "" {
+		os.Setenv("GH_TOKEN", cfg.Token)
+		if host != "" {
+			// gh only reads GH_TOKEN for github.com
+			os.Setenv("GH_ENTERPRISE_TOKEN", cfg.Token)
+		}
+	}
+	return host
+}
+
 func (g *GitHubProvider) Name() string {
 	return "github"
 }
 
 // ghCmd creates a gh command with common flags
 func (g *GitHubProvider) ghCmd(ctx context.Context, args ...string) *exec.Cmd {
+	// gh api takes no repository to infer the host from; name the Enterprise host explicitly
+	if g.host != "" && len(args) > 0 && args[0] == "api" {
+		args = append([]string{"api", "--hostname", g.host}, args[1:]...)
+	}
 	cmd := exec.CommandContext(ctx, "gh", args...)
 	return cmd
 }
@@ -80,6 +105,76 @@ func (g *GitHubProvider) runGHOnce(ctx context.Context, args ...string) ([]byte,
 	return out, nil
 }
 
+// RateLimitInfo implements RateLimitProvider using the rate limit headers from the GitHub API.
+// The /rate_limit endpoint does not count against the quota.
+func (g *GitHubProvider) RateLimitInfo(ctx context.Context) (*RateLimitInfo, error) {
+	out, err := g.runGHOnce(ctx, "api", "-i", "rate_limit")
+	if err != nil {
+		return nil, err
+	}
+	return parseRateLimitHeaders(out)
+}
+
+// parseRateLimitHeaders extracts x-ratelimit-* headers from `gh api -i` output
+func parseRateLimitHeaders(out []byte) (*RateLimitInfo, error) {
+	info := &RateLimitInfo{}
+	var found bool
+
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break // End of headers
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "x-ratelimit-limit":
+			info.Limit, _ = strconv.Atoi(value)
+		case "x-ratelimit-remaining":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid x-ratelimit-remaining header: %q", value)
+			}
+			info.Remaining = n
+			found = true
+		case "x-ratelimit-reset":
+			if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+				info.Reset = time.Unix(unix, 0)
+			}
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no rate limit headers in response")
+	}
+	return info, nil
+}
+
+// ghAPIList fetches every page of a REST list endpoint and decodes the items.
+// With --jq '.[]' gh prints the items one per line, so the pages need no merging.
+func ghAPIList[T any](ctx context.Context, g *GitHubProvider, endpoint string) ([]T, error) {
+	out, err := g.runGH(ctx, "api", "--paginate", endpoint, "--jq", ".[]")
+	if err != nil {
+		return nil, err
+	}
+
+	var items []T
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var item T
+		if err := dec.Decode(&item); err == io.EOF {
+			return items, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", endpoint, err)
+		}
+		items = append(items, item)
+	}
+}
+
 // ghIssue represents gh's JSON output for issues
 type ghIssue struct {
 	Number    int       `json:"number"`
@@ -116,6 +211,7 @@ type ghPR struct {
 	URL              string `json:"url"`
 	HeadRefName      string `json:"headRefName"`
 	BaseRefName      string `json:"baseRefName"`
+	IsDraft          bool   `json:"isDraft"`
 }
 
 func (g *GitHubProvider) GetIssue(ctx context.Context, repo string, number int) (*Issue, error) {
@@ -146,8 +242,15 @@ func (g *GitHubProvider) GetIssue(ctx context.Context, repo string, number int)
 	}, nil
 }
 
+// ghListLimit caps the issues or labels listed per call; gh lists only 30 without --limit
+const ghListLimit = 1000
+
 func (g *GitHubProvider) ListIssuesWithLabel(ctx context.Context, repo string, label string) ([]*Issue, error) {
-	out, err := g.runGH(ctx, "issue", "list", "--repo", repo, "--label", label, "--state", "open", "--json", "number,title,body,state,author,labels,createdAt,updatedAt")
+	args := []string{"issue", "list", "--repo", repo, "--state", "open", "--limit", strconv.Itoa(ghListLimit), "--json", "number,title,body,state,author,labels,createdAt,updatedAt"}
+	if label != "" {
+		args = append(args, "--label", label)
+	}
+	out, err := g.runGH(ctx, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -179,6 +282,7 @@ func (g *GitHubProvider) ListIssuesWithLabel(ctx context.Context, repo string, l
 }
 
 func (g *GitHubProvider) GetComments(ctx context.Context, repo string, number int) ([]*Comment, error) {
+	// gh fetches every page of comments when they are asked for with --json
 	out, err := g.runGH(ctx, "issue", "view", strconv.Itoa(number), "--repo", repo, "--json", "comments", "--jq", ".comments")
 	if err != nil {
 		return nil, err
@@ -209,6 +313,11 @@ func (g *GitHubProvider) GetComments(ctx context.Context, repo string, number in
 	return result, nil
 }
 
+// MaxCommentLength returns GitHub's comment size limit
+func (g *GitHubProvider) MaxCommentLength() int {
+	return GitHubMaxCommentLength
+}
+
 func (g *GitHubProvider) CreateComment(ctx context.Context, repo string, number int, body string) (int64, error) {
 	// Use gh api to create a comment and get the ID back
 	endpoint := fmt.Sprintf("/repos/%s/issues/%d/comments", repo, number)
@@ -238,6 +347,16 @@ func (g *GitHubProvider) UpdateIssueBody(ctx context.Context, repo string, numbe
 	return err
 }
 
+func (g *GitHubProvider) CloseIssue(ctx context.Context, repo string, number int) error {
+	_, err := g.runGH(ctx, "issue", "close", strconv.Itoa(number), "--repo", repo)
+	return err
+}
+
+func (g *GitHubProvider) ReopenIssue(ctx context.Context, repo string, number int) error {
+	_, err := g.runGH(ctx, "issue", "reopen", strconv.Itoa(number), "--repo", repo)
+	return err
+}
+
 func (g *GitHubProvider) ReactToComment(ctx context.Context, repo string, commentID int64, reaction string) error {
 	// Use gh api to add a reaction to a comment
 	endpoint := fmt.Sprintf("/repos/%s/issues/comments/%d/reactions", repo, commentID)
@@ -245,8 +364,105 @@ func (g *GitHubProvider) ReactToComment(ctx context.Context, repo string, commen
 	return err
 }
 
+func (g *GitHubProvider) AssignIssue(ctx context.Context, repo string, number int, assignee string) error {
+	_, err := g.runGH(ctx, "issue", "edit", strconv.Itoa(number), "--repo", repo, "--add-assignee", assignee)
+	return err
+}
+
+func (g *GitHubProvider) UnassignIssue(ctx context.Context, repo string, number int, assignee string) error {
+	_, err := g.runGH(ctx, "issue", "edit", strconv.Itoa(number), "--repo", repo, "--remove-assignee", assignee)
+	return err
+}
+
 func (g *GitHubProvider) AddLabel(ctx context.Context, repo string, number int, label string) error {
+	// Unlike Gitea, GitHub doesn't create missing labels when adding them
+	if err := g.ensureLabel(ctx, repo, label); err != nil {
+		return err
+	}
 	_, err := g.runGH(ctx, "issue", "edit", strconv.Itoa(number), "--repo", repo, "--add-label", label)
+	if err != nil && strings.Contains(err.Error(), "not found") {
+		// The label was deleted since the repository's labels were listed
+		g.forgetLabels(repo)
+		if err := g.ensureLabel(ctx, repo, label); err != nil {
+			return err
+		}
+		_, err = g.runGH(ctx, "issue", "edit", strconv.Itoa(number), "--repo", repo, "--add-label", label)
+	}
+	return err
+}
+
+// ensureLabel creates label in repo if it doesn't exist yet.
+// The repository's labels are listed once and then cached.
+func (g *GitHubProvider) ensureLabel(ctx context.Context, repo string, label string) error {
+	g.labelsMu.Lock()
+	defer g.labelsMu.Unlock()
+
+	known, ok := g.repoLabels[repo]
+	if !ok {
+		names, err := g.listLabels(ctx, repo)
+		if err != nil {
+			return fmt.Errorf("failed to list labels: %w", err)
+		}
+		known = make(map[string]bool, len(names))
+		for _, name := range names {
+			known[name] = true
+		}
+		if g.repoLabels == nil {
+			g.repoLabels = make(map[string]map[string]bool)
+		}
+		g.repoLabels[repo] = known
+	}
+	if known[label] {
+		return nil
+	}
+
+	if err := g.createLabel(ctx, repo, label); err != nil {
+		return fmt.Errorf("failed to create label %s: %w", label, err)
+	}
+	known[label] = true
+	return nil
+}
+
+// forgetLabels drops the cached labels of repo, so they are listed again
+func (g *GitHubProvider) forgetLabels(repo string) {
+	g.labelsMu.Lock()
+	defer g.labelsMu.Unlock()
+	delete(g.repoLabels, repo)
+}
+
+// listLabels returns the names of the labels in repo
+func (g *GitHubProvider) listLabels(ctx context.Context, repo string) ([]string, error) {
+	out, err := g.runGH(ctx, "label", "list", "--repo", repo, "--limit", strconv.Itoa(ghListLimit), "--json", "name")
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []ghLabel
+	if err := json.Unmarshal(out, &labels); err != nil {
+		return nil, fmt.Errorf("failed to parse labels: %w", err)
+	}
+
+	names := make([]string, len(labels))
+	for i, l := range labels {
+		names[i] = l.Name
+	}
+	return names, nil
+}
+
+// SetLabelSpecs implements LabelStyleProvider
+func (g *GitHubProvider) SetLabelSpecs(specs map[string]state.LabelSpec) {
+	g.labelSpecs = specs
+}
+
+// createLabel creates a label with its spec's color and description. A label that
+// already exists, e.g. created since the labels were listed, is left as it is, so colors
+// users picked aren't overwritten.
+func (g *GitHubProvider) createLabel(ctx context.Context, repo string, label string) error {
+	spec := state.LabelSpecFor(g.labelSpecs, label)
+	_, err := g.runGH(ctx, "label", "create", label, "--repo", repo, "--color", spec.Color, "--description", spec.Description)
+	if err != nil && strings.Contains(err.Error(), "already exists") {
+		return nil
+	}
 	return err
 }
 
@@ -257,13 +473,16 @@ func (g *GitHubProvider) RemoveLabel(ctx context.Context, repo string, number in
 
 func (g *GitHubProvider) CreatePR(ctx context.Context, repo string, pr PRCreate) (*PR, error) {
 	args := []string{"pr", "create", "--repo", repo, "--title", pr.Title, "--body", pr.Body, "--head", pr.Head, "--base", pr.Base}
+	if pr.Draft {
+		args = append(args, "--draft")
+	}
 	_, err := g.runGH(ctx, args...)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get the PR we just created
-	out, err := g.runGH(ctx, "pr", "view", pr.Head, "--repo", repo, "--json", "number,title,body,state,mergeStateStatus,url,headRefName,baseRefName")
+	out, err := g.runGH(ctx, "pr", "view", pr.Head, "--repo", repo, "--json", "number,title,body,state,mergeStateStatus,url,headRefName,baseRefName,isDraft")
 	if err != nil {
 		return nil, err
 	}
@@ -273,7 +492,7 @@ func (g *GitHubProvider) CreatePR(ctx context.Context, repo string, pr PRCreate)
 		return nil, fmt.Errorf("failed to parse PR: %w", err)
 	}
 
-	return &PR{
+	result := &PR{
 		Number:    gp.Number,
 		Title:     gp.Title,
 		Body:      gp.Body,
@@ -282,11 +501,36 @@ func (g *GitHubProvider) CreatePR(ctx context.Context, repo string, pr PRCreate)
 		HTMLURL:   gp.URL,
 		HeadRef:   gp.HeadRefName,
 		BaseRef:   gp.BaseRefName,
-	}, nil
+		Draft:     gp.IsDraft,
+	}
+
+	// The issue is linked by "Closes #N" in the body
+	if err := g.addPRLabels(ctx, repo, result.Number, pr.Labels); err != nil {
+		return result, fmt.Errorf("%w: %w", ErrPRSetup, err)
+	}
+	return result, nil
+}
+
+// addPRLabels adds labels to a PR, creating the ones the repository doesn't have yet
+func (g *GitHubProvider) addPRLabels(ctx context.Context, repo string, number int, labels []string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	args := []string{"pr", "edit", strconv.Itoa(number), "--repo", repo}
+	for _, label := range labels {
+		if err := g.ensureLabel(ctx, repo, label); err != nil {
+			return err
+		}
+		args = append(args, "--add-label", label)
+	}
+	if _, err := g.runGH(ctx, args...); err != nil {
+		return fmt.Errorf("failed to add labels: %w", err)
+	}
+	return nil
 }
 
 func (g *GitHubProvider) GetPR(ctx context.Context, repo string, number int) (*PR, error) {
-	out, err := g.runGH(ctx, "pr", "view", strconv.Itoa(number), "--repo", repo, "--json", "number,title,body,state,mergeStateStatus,url,headRefName,baseRefName")
+	out, err := g.runGH(ctx, "pr", "view", strconv.Itoa(number), "--repo", repo, "--json", "number,title,body,state,mergeStateStatus,url,headRefName,baseRefName,isDraft")
 	if err != nil {
 		return nil, err
 	}
@@ -305,6 +549,7 @@ func (g *GitHubProvider) GetPR(ctx context.Context, repo string, number int) (*P
 		HTMLURL:   gp.URL,
 		HeadRef:   gp.HeadRefName,
 		BaseRef:   gp.BaseRefName,
+		Draft:     gp.IsDraft,
 	}, nil
 }
 
@@ -341,42 +586,85 @@ func (g *GitHubProvider) GetPRComments(ctx context.Context, repo string, number
 
 // ghReviewComment represents the REST API response for PR review comments (inline code comments)
 type ghReviewComment struct {
-	ID        int64     `json:"id"`
-	Body      string    `json:"body"`
-	User      ghUser    `json:"user"`
-	CreatedAt time.Time `json:"created_at"`
+	ID           int64     `json:"id"`
+	Body         string    `json:"body"`
+	User         ghUser    `json:"user"`
+	CreatedAt    time.Time `json:"created_at"`
+	Path         string    `json:"path"`
+	Line         int       `json:"line"`          // null when the line no longer exists in the diff
+	OriginalLine int       `json:"original_line"` // Line in the commit the comment was made on
 }
 
 func (g *GitHubProvider) GetPRReviewComments(ctx context.Context, repo string, number int) ([]*Comment, error) {
 	// Use gh api to fetch inline review comments from the REST API
 	// Endpoint: repos/{owner}/{repo}/pulls/{pull_number}/comments
-	out, err := g.runGH(ctx, "api", fmt.Sprintf("repos/%s/pulls/%d/comments", repo, number))
+	comments, err := ghAPIList[ghReviewComment](ctx, g, fmt.Sprintf("repos/%s/pulls/%d/comments?per_page=100", repo, number))
 	if err != nil {
 		return nil, err
 	}
 
-	var comments []ghReviewComment
-	if err := json.Unmarshal(out, &comments); err != nil {
-		return nil, fmt.Errorf("failed to parse review comments: %w", err)
-	}
-
 	result := make([]*Comment, len(comments))
 	for i, c := range comments {
+		line, outdated := c.Line, false
+		if line == 0 && c.OriginalLine != 0 {
+			line, outdated = c.OriginalLine, true
+		}
 		result[i] = &Comment{
 			ID:        c.ID,
 			Body:      c.Body,
 			Author:    c.User.Login,
 			CreatedAt: c.CreatedAt,
+			Path:      c.Path,
+			Line:      line,
+			Outdated:  outdated,
 		}
 	}
 
 	return result, nil
 }
 
-func (g *GitHubProvider) MergePR(ctx context.Context, repo string, number int) error {
-	_, err := g.runGH(ctx, "pr", "merge", strconv.Itoa(number), "--repo", repo, "--merge", "--delete-branch")
+// ReactToReviewComment adds a reaction to an inline review comment.
+// Review comments live under pulls/comments, not issues/comments like ReactToComment.
+func (g *GitHubProvider) ReactToReviewComment(ctx context.Context, repo string, commentID int64, reaction string) error {
+	endpoint := fmt.Sprintf("/repos/%s/pulls/comments/%d/reactions", repo, commentID)
+	_, err := g.runGH(ctx, "api", endpoint, "-X", "POST", "-f", "content="+reaction)
+	return err
+}
+
+// ReplyToReviewComment replies in the thread of an inline review comment
+func (g *GitHubProvider) ReplyToReviewComment(ctx context.Context, repo string, prNumber int, comment *Comment, body string) error {
+	endpoint := fmt.Sprintf("/repos/%s/pulls/%d/comments/%d/replies", repo, prNumber, comment.ID)
+	_, err := g.runGH(ctx, "api", endpoint, "-X", "POST", "-f", "body="+body)
+	return err
+}
+
+// ghMergeFlag maps a merge method to the gh pr merge flag
+func ghMergeFlag(method MergeMethod) (string, error) {
+	switch method {
+	case MergeMethodDefault, MergeMethodMerge:
+		return "--merge", nil
+	case MergeMethodSquash:
+		return "--squash", nil
+	case MergeMethodRebase:
+		return "--rebase", nil
+	default:
+		return "", fmt.Errorf("unsupported merge method: %s", method)
+	}
+}
+
+func (g *GitHubProvider) MergePR(ctx context.Context, repo string, number int, method MergeMethod) error {
+	flag, err := ghMergeFlag(method)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.runGH(ctx, "pr", "merge", strconv.Itoa(number), "--repo", repo, flag, "--delete-branch")
 	if err != nil {
 		errStr := strings.ToLower(err.Error())
+		// e.g. "Merge method squash merging is not allowed on this repository"
+		if strings.Contains(errStr, "merge method") || strings.Contains(errStr, "merging is not allowed") {
+			return fmt.Errorf("%w: %s: %v", ErrMergeMethodRejected, flag, err)
+		}
 		if strings.Contains(errStr, "not allowed") || strings.Contains(errStr, "merge not allowed") ||
 			strings.Contains(errStr, "required status check") || strings.Contains(errStr, "review is required") {
 			return fmt.Errorf("%w: %v", ErrMergeNotAllowed, err)
@@ -393,6 +681,11 @@ func (g *GitHubProvider) IsMergeable(ctx context.Context, repo string, number in
 	return pr.Mergeable, nil
 }
 
+func (g *GitHubProvider) MarkPRReady(ctx context.Context, repo string, number int) error {
+	_, err := g.runGH(ctx, "pr", "ready", strconv.Itoa(number), "--repo", repo)
+	return err
+}
+
 func (g *GitHubProvider) Clone(ctx context.Context, repo string, dest string) error {
 	cmd := g.ghCmd(ctx, "repo", "clone", repo, dest)
 	output, err := cmd.CombinedOutput()
@@ -415,6 +708,49 @@ func (g *GitHubProvider) GetDefaultBranch(ctx context.Context, repo string) (str
 	return branch, nil
 }
 
+// BranchExists reports whether branch exists in repo
+func (g *GitHubProvider) BranchExists(ctx context.Context, repo, branch string) (bool, error) {
+	endpoint := fmt.Sprintf("repos/%s/git/ref/heads/%s", repo, branch)
+	if _, err := g.runGH(ctx, "api", endpoint, "--jq", ".ref"); err != nil {
+		if strings.Contains(err.Error(), "404") {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check branch %s: %w", branch, err)
+	}
+	return true, nil
+}
+
+// ghDependencyIssue represents an issue returned by the issue dependencies API
+type ghDependencyIssue struct {
+	Number        int    `json:"number"`
+	RepositoryURL string `json:"repository_url"`
+}
+
+// GetIssueDependencies implements IssueDependencyProvider for GitHub
+// using the "blocked by" issue dependencies endpoint
+func (g *GitHubProvider) GetIssueDependencies(ctx context.Context, repo string, number int) ([]int, error) {
+	endpoint := fmt.Sprintf("repos/%s/issues/%d/dependencies/blocked_by", repo, number)
+	out, err := g.runGH(ctx, "api", endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue dependencies: %w", err)
+	}
+
+	var issues []ghDependencyIssue
+	if err := json.Unmarshal(out, &issues); err != nil {
+		return nil, fmt.Errorf("failed to parse issue dependencies: %w", err)
+	}
+
+	var deps []int
+	for _, issue := range issues {
+		// Only same-repo dependencies can be tracked by issue number
+		if issue.RepositoryURL != "" && !strings.HasSuffix(issue.RepositoryURL, "/repos/"+repo) {
+			continue
+		}
+		deps = append(deps, issue.Number)
+	}
+	return deps, nil
+}
+
 // hashNodeID generates a stable int64 hash from a GitHub node ID string
 func hashNodeID(nodeID string) int64 {
 	// Use FNV-1a hash algorithm for stable hashing
@@ -508,6 +844,83 @@ func (g *GitHubProvider) GetCIStatus(ctx context.Context, repo string, prNumber
 	return result, nil
 }
 
+// GetRequiredChecks implements RequiredChecksProvider for GitHub. It combines classic branch
+// protection, which needs admin access to read, with repository rulesets targeting the branch.
+func (g *GitHubProvider) GetRequiredChecks(ctx context.Context, repo, branch string) ([]string, error) {
+	var required []string
+
+	protectionOut, protectionErr := g.runGH(ctx, "api", fmt.Sprintf("repos/%s/branches/%s/protection/required_status_checks", repo, branch))
+	if protectionErr == nil {
+		names, err := parseRequiredStatusChecks(protectionOut)
+		if err != nil {
+			return nil, err
+		}
+		required = append(required, names...)
+	}
+
+	rulesOut, rulesErr := g.runGH(ctx, "api", fmt.Sprintf("repos/%s/rules/branches/%s", repo, branch))
+	if rulesErr == nil {
+		names, err := parseRulesetRequiredChecks(rulesOut)
+		if err != nil {
+			return nil, err
+		}
+		required = append(required, names...)
+	}
+
+	// An unprotected branch returns 404 from the protection endpoint; only fail if neither source answered
+	if protectionErr != nil && rulesErr != nil {
+		return nil, fmt.Errorf("failed to get required checks: %w", rulesErr)
+	}
+
+	slices.Sort(required)
+	return slices.Compact(required), nil
+}
+
+// parseRequiredStatusChecks parses the branch protection required_status_checks response
+func parseRequiredStatusChecks(out []byte) ([]string, error) {
+	var resp struct {
+		Contexts []string `json:"contexts"`
+		Checks   []struct {
+			Context string `json:"context"`
+		} `json:"checks"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse required status checks: %w", err)
+	}
+
+	names := resp.Contexts
+	for _, c := range resp.Checks {
+		names = append(names, c.Context)
+	}
+	return names, nil
+}
+
+// parseRulesetRequiredChecks parses the required_status_checks rules from a branch rules response
+func parseRulesetRequiredChecks(out []byte) ([]string, error) {
+	var rules []struct {
+		Type       string `json:"type"`
+		Parameters struct {
+			RequiredStatusChecks []struct {
+				Context string `json:"context"`
+			} `json:"required_status_checks"`
+		} `json:"parameters"`
+	}
+	if err := json.Unmarshal(out, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse branch rules: %w", err)
+	}
+
+	var names []string
+	for _, rule := range rules {
+		if rule.Type != "required_status_checks" {
+			continue
+		}
+		for _, c := range rule.Parameters.RequiredStatusChecks {
+			names = append(names, c.Context)
+		}
+	}
+	return names, nil
+}
+
 // IsCollaborator checks if a user is a collaborator on the repository
 func (g *GitHubProvider) IsCollaborator(ctx context.Context, repo, username string) (bool, error) {
 	// Use gh api to check collaborator permission
@@ -542,7 +955,8 @@ func (g *GitHubProvider) IsCollaborator(ctx context.Context, repo, username stri
 	}
 }
 
-// GetCILogs retrieves logs for a GitHub Actions workflow run
+// GetCILogs retrieves logs for a check run. The check run output is often empty for
+// GitHub Actions, so for Actions jobs the output of the failed steps is appended.
 func (g *GitHubProvider) GetCILogs(ctx context.Context, repo string, checkRunID int64) (string, error) {
 	// Use gh api to fetch the check run details with output
 	endpoint := fmt.Sprintf("/repos/%s/check-runs/%d", repo, checkRunID)
@@ -559,6 +973,7 @@ func (g *GitHubProvider) GetCILogs(ctx context.Context, repo string, checkRunID
 		} `json:"output"`
 		Conclusion string `json:"conclusion"`
 		HTMLURL    string `json:"html_url"`
+		DetailsURL string `json:"details_url"`
 	}
 
 	if err := json.Unmarshal(out, &checkRun); err != nil {
@@ -582,6 +997,18 @@ func (g *GitHubProvider) GetCILogs(ctx context.Context, repo string, checkRunID
 		logs.WriteString(checkRun.Output.Text)
 	}
 
+	// For Actions jobs, add the log output of the failed steps
+	if jobID, ok := parseActionsJobID(checkRun.DetailsURL); ok {
+		stepLogs, err := g.runGH(ctx, "run", "view", "--job", jobID, "--log-failed", "--repo", repo)
+		if err == nil && len(strings.TrimSpace(string(stepLogs))) > 0 {
+			if logs.Len() > 0 {
+				logs.WriteString("\n\n")
+			}
+			logs.WriteString("Failed step logs:\n")
+			logs.Write(stepLogs)
+		}
+	}
+
 	// If no output available, provide basic info
 	if logs.Len() == 0 {
 		logs.WriteString(fmt.Sprintf("Check concluded with: %s\n", checkRun.Conclusion))
@@ -590,3 +1017,15 @@ func (g *GitHubProvider) GetCILogs(ctx context.Context, repo string, checkRunID
 
 	return logs.String(), nil
 }
+
+var actionsJobURLRegex = regexp.MustCompile(`/actions/runs/\d+/job/(\d+)`)
+
+// parseActionsJobID extracts the job ID from a GitHub Actions job URL
+// (https://github.com/owner/repo/actions/runs/<run>/job/<job>)
+func parseActionsJobID(detailsURL string) (string, bool) {
+	m := actionsJobURLRegex.FindStringSubmatch(detailsURL)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
@@ -0,0 +1,329 @@
+package providers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+func TestGHMergeFlag(t *testing.T) {
+	tests := []struct {
+		method   MergeMethod
+		expected string
+		wantErr  bool
+	}{
+		{MergeMethodDefault, "--merge", false},
+		{MergeMethodMerge, "--merge", false},
+		{MergeMethodSquash, "--squash", false},
+		{MergeMethodRebase, "--rebase", false},
+		{MergeMethod("octopus"), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			got, err := ghMergeFlag(tt.method)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseRateLimitHeaders(t *testing.T) {
+	out := []byte("HTTP/2.0 200 OK\r\n" +
+		"Content-Type: application/json; charset=utf-8\r\n" +
+		"X-Ratelimit-Limit: 5000\r\n" +
+		"X-Ratelimit-Remaining: 42\r\n" +
+		"X-Ratelimit-Reset: 1700000000\r\n" +
+		"\r\n" +
+		`{"resources":{"core":{"x-ratelimit-remaining":"1"}}}`)
+
+	info, err := parseRateLimitHeaders(out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Limit != 5000 || info.Remaining != 42 {
+		t.Errorf("unexpected quota: %+v", info)
+	}
+	if info.Reset.Unix() != 1700000000 {
+		t.Errorf("unexpected reset: %v", info.Reset)
+	}
+}
+
+func TestParseRateLimitHeaders_Missing(t *testing.T) {
+	if _, err := parseRateLimitHeaders([]byte("HTTP/2.0 200 OK\r\n\r\n{}")); err == nil {
+		t.Error("expected error when headers are missing")
+	}
+}
+
+func TestParseRequiredStatusChecks(t *testing.T) {
+	out := []byte(`{"strict":true,"contexts":["build","test"],"checks":[{"context":"build","app_id":15368},{"context":"e2e","app_id":null}]}`)
+
+	got, err := parseRequiredStatusChecks(out)
+	if err != nil {
+		t.Fatalf("parseRequiredStatusChecks: %v", err)
+	}
+	if want := []string{"build", "test", "build", "e2e"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseRulesetRequiredChecks(t *testing.T) {
+	out := []byte(`[
+		{"type":"pull_request","parameters":{"required_approving_review_count":1}},
+		{"type":"required_status_checks","parameters":{"required_status_checks":[{"context":"ci/build"},{"context":"ci/test","integration_id":1}]}}
+	]`)
+
+	got, err := parseRulesetRequiredChecks(out)
+	if err != nil {
+		t.Fatalf("parseRulesetRequiredChecks: %v", err)
+	}
+	if want := []string{"ci/build", "ci/test"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseActionsJobID(t *testing.T) {
+	if id, ok := parseActionsJobID("https://github.com/owner/repo/actions/runs/123/job/456"); !ok || id != "456" {
+		t.Errorf("got %q, %v; want 456, true", id, ok)
+	}
+	if _, ok := parseActionsJobID("https://ci.example.com/builds/789"); ok {
+		t.Error("expected a non-Actions URL to be rejected")
+	}
+}
+
+func TestNewGitHubProvider_EnterpriseHost(t *testing.T) {
+	t.Setenv("GH_HOST", "")
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GH_ENTERPRISE_TOKEN", "")
+
+	g := NewGitHubProvider(config.GitHubConfig{Token: "ghp_test", APIURL: "https://github.example.com/api/v3"})
+	if got := os.Getenv("GH_HOST"); got != "github.example.com" {
+		t.Errorf("expected GH_HOST from the API URL, got %q", got)
+	}
+	if got := os.Getenv("GH_ENTERPRISE_TOKEN"); got != "ghp_test" {
+		t.Errorf("expected the token for the Enterprise host, got %q", got)
+	}
+
+	cmd := g.ghCmd(context.Background(), "api", "rate_limit")
+	if want := []string{"gh", "api", "--hostname", "github.example.com", "rate_limit"}; !slices.Equal(cmd.Args, want) {
+		t.Errorf("expected %v, got %v", want, cmd.Args)
+	}
+	cmd = g.ghCmd(context.Background(), "issue", "list")
+	if want := []string{"gh", "issue", "list"}; !slices.Equal(cmd.Args, want) {
+		t.Errorf("expected %v, got %v", want, cmd.Args)
+	}
+}
+
+func TestNewGitHubProvider_DefaultHost(t *testing.T) {
+	t.Setenv("GH_HOST", "")
+	t.Setenv("GH_ENTERPRISE_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+
+	g := NewGitHubProvider(config.GitHubConfig{Token: "ghp_test"})
+	if os.Getenv("GH_HOST") != "" || os.Getenv("GH_ENTERPRISE_TOKEN") != "" || os.Getenv("GH_TOKEN") != "ghp_test" {
+		t.Errorf("expected only GH_TOKEN set for github.com, got GH_HOST=%q GH_ENTERPRISE_TOKEN=%q", os.Getenv("GH_HOST"), os.Getenv("GH_ENTERPRISE_TOKEN"))
+	}
+	if cmd := g.ghCmd(context.Background(), "api", "rate_limit"); slices.Contains(cmd.Args, "--hostname") {
+		t.Errorf("expected no --hostname for github.com, got %v", cmd.Args)
+	}
+}
+
+// fakeGH puts a gh script that runs body on PATH and returns the file it logs its arguments to
+func fakeGH(t *testing.T, body string) string {
+	t.Helper()
+	dir := t.TempDir()
+	argsPath := filepath.Join(dir, "args")
+	script := "#!/bin/sh\necho \"$@\" >> " + argsPath + "\n" + body + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "gh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return argsPath
+}
+
+func TestGitHubGetPRReviewComments_Paginates(t *testing.T) {
+	// gh --paginate --jq '.[]' prints the items of every page one per line
+	argsPath := fakeGH(t, `echo '{"id":1,"body":"first","user":{"login":"alice"},"path":"a.go","line":3}'
+echo '{"id":2,"body":"from page two","user":{"login":"bob"},"path":"b.go","original_line":7}'`)
+	g := &GitHubProvider{}
+
+	comments, err := g.GetPRReviewComments(context.Background(), "owner/repo", 5)
+	if err != nil {
+		t.Fatalf("GetPRReviewComments failed: %v", err)
+	}
+	if len(comments) != 2 || comments[1].Body != "from page two" || comments[1].Line != 7 {
+		t.Fatalf("expected the comments of every page, got %+v", comments)
+	}
+	if comments[0].Outdated || !comments[1].Outdated {
+		t.Errorf("expected only the comment without a current line to be outdated, got %+v", comments)
+	}
+	args, _ := os.ReadFile(argsPath)
+	if !strings.Contains(string(args), "api --paginate repos/owner/repo/pulls/5/comments?per_page=100 --jq .[]") {
+		t.Errorf("expected a paginated API call, got %q", args)
+	}
+}
+
+func TestGitHubListIssuesWithLabel_RaisesLimit(t *testing.T) {
+	argsPath := fakeGH(t, `echo '[{"number":1,"title":"Add cache"}]'`)
+	g := &GitHubProvider{}
+
+	if _, err := g.ListIssuesWithLabel(context.Background(), "owner/repo", "ai-implement"); err != nil {
+		t.Fatalf("ListIssuesWithLabel failed: %v", err)
+	}
+	args, _ := os.ReadFile(argsPath)
+	if !strings.Contains(string(args), "--limit 1000") {
+		t.Errorf("expected gh's default limit of 30 to be raised, got %q", args)
+	}
+}
+
+func TestGitHubAddLabel_CreatesMissingLabel(t *testing.T) {
+	argsPath := fakeGH(t, `case "$1 $2" in
+"label list") echo '[{"name":"ai-implement"}]' ;;
+esac`)
+	g := &GitHubProvider{}
+	g.SetLabelSpecs(state.LabelSpecs(map[string]string{"phase:failed": "ff0000"}))
+
+	if err := g.AddLabel(context.Background(), "owner/repo", 1, "phase:failed"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+	args, _ := os.ReadFile(argsPath)
+	calls := strings.Split(strings.TrimSpace(string(args)), "\n")
+	if len(calls) != 3 ||
+		!strings.HasPrefix(calls[0], "label list --repo owner/repo") ||
+		!strings.HasPrefix(calls[1], "label create phase:failed --repo owner/repo --color ff0000") ||
+		!strings.HasPrefix(calls[2], "issue edit 1 --repo owner/repo --add-label phase:failed") {
+		t.Fatalf("expected the label to be created before it is added, got %q", calls)
+	}
+
+	// Both labels are now known to exist; neither is listed or created again
+	os.Remove(argsPath)
+	if err := g.AddLabel(context.Background(), "owner/repo", 2, "phase:failed"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+	if err := g.AddLabel(context.Background(), "owner/repo", 2, "ai-implement"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+	args, _ = os.ReadFile(argsPath)
+	if strings.Contains("\n"+string(args), "\nlabel ") {
+		t.Errorf("expected existing labels to be taken from the cache, got %q", args)
+	}
+}
+
+func TestGitHubAddLabel_KeepsExistingLabel(t *testing.T) {
+	// The label was created by someone else after the labels were listed
+	argsPath := fakeGH(t, `case "$1 $2" in
+"label list") echo '[]' ;;
+"label create") echo 'label with name "phase:failed" already exists; use `+"`--force`"+` to update its color and description' >&2; exit 1 ;;
+esac`)
+	g := &GitHubProvider{}
+
+	if err := g.AddLabel(context.Background(), "owner/repo", 1, "phase:failed"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+	args, _ := os.ReadFile(argsPath)
+	if strings.Contains(string(args), "--force") {
+		t.Errorf("expected the existing label not to be overwritten, got %q", args)
+	}
+	if !strings.Contains(string(args), "issue edit 1 --repo owner/repo --add-label phase:failed") {
+		t.Errorf("expected the label to be added, got %q", args)
+	}
+}
+
+func TestGitHubAddLabel_RecreatesDeletedLabel(t *testing.T) {
+	dir := t.TempDir()
+	created := filepath.Join(dir, "created")
+	argsPath := fakeGH(t, `case "$1 $2" in
+"label list") echo '[]' ;;
+"label create") touch `+created+` ;;
+"issue edit") if [ ! -f `+created+` ]; then echo "could not add label: 'phase:failed' not found" >&2; exit 1; fi ;;
+esac`)
+	g := &GitHubProvider{}
+	// The cache says the label exists, but it was deleted in the meantime
+	g.repoLabels = map[string]map[string]bool{"owner/repo": {"phase:failed": true}}
+
+	if err := g.AddLabel(context.Background(), "owner/repo", 1, "phase:failed"); err != nil {
+		t.Fatalf("AddLabel failed: %v", err)
+	}
+	args, _ := os.ReadFile(argsPath)
+	if !strings.Contains(string(args), "label create phase:failed") || strings.Count(string(args), "issue edit 1") != 2 {
+		t.Errorf("expected the label to be created and added again, got %q", args)
+	}
+}
+
+func TestGitHubCreatePR_Draft(t *testing.T) {
+	argsPath := fakeGH(t, `case "$1 $2" in
+"pr view") echo '{"number":3,"isDraft":true}' ;;
+esac`)
+	g := &GitHubProvider{}
+
+	pr, err := g.CreatePR(context.Background(), "owner/repo", PRCreate{Title: "t", Body: "b", Head: "feat", Base: "main", Draft: true})
+	if err != nil {
+		t.Fatalf("CreatePR failed: %v", err)
+	}
+	if !pr.Draft {
+		t.Errorf("expected a draft PR")
+	}
+	if err := g.MarkPRReady(context.Background(), "owner/repo", 3); err != nil {
+		t.Fatalf("MarkPRReady failed: %v", err)
+	}
+	args, _ := os.ReadFile(argsPath)
+	if !strings.Contains(string(args), "--base main --draft") {
+		t.Errorf("expected --draft, got %q", args)
+	}
+	if !strings.Contains(string(args), "pr ready 3 --repo owner/repo") {
+		t.Errorf("expected gh pr ready, got %q", args)
+	}
+}
+
+func TestGitHubCreatePR_AddsLabels(t *testing.T) {
+	argsPath := fakeGH(t, `case "$1 $2" in
+"label list") echo '[{"name":"bot"}]' ;;
+"pr view") echo '{"number":3}' ;;
+esac`)
+	g := &GitHubProvider{}
+
+	if _, err := g.CreatePR(context.Background(), "owner/repo", PRCreate{Title: "t", Head: "feat", Base: "main", Labels: []string{"bot", "needs-review"}}); err != nil {
+		t.Fatalf("CreatePR failed: %v", err)
+	}
+	args, _ := os.ReadFile(argsPath)
+	if !strings.Contains(string(args), "label create needs-review") || strings.Contains(string(args), "label create bot") {
+		t.Errorf("expected only the missing label to be created, got %q", args)
+	}
+	if !strings.Contains(string(args), "pr edit 3 --repo owner/repo --add-label bot --add-label needs-review") {
+		t.Errorf("expected the labels added to the PR, got %q", args)
+	}
+}
+
+func TestGitHubBranchExists(t *testing.T) {
+	argsPath := fakeGH(t, `case "$2" in
+*/heads/taken) echo refs/heads/taken ;;
+*/heads/broken) echo "gh: Server Error (HTTP 502)" >&2; exit 1 ;;
+*) echo "gh: Not Found (HTTP 404)" >&2; exit 1 ;;
+esac`)
+	g := &GitHubProvider{}
+	ctx := context.Background()
+
+	if exists, err := g.BranchExists(ctx, "owner/repo", "taken"); err != nil || !exists {
+		t.Errorf("expected taken to exist, got %v, %v", exists, err)
+	}
+	if exists, err := g.BranchExists(ctx, "owner/repo", "ue/1-free"); err != nil || exists {
+		t.Errorf("expected a 404 to mean the branch is free, got %v, %v", exists, err)
+	}
+	if _, err := g.BranchExists(ctx, "owner/repo", "broken"); err == nil {
+		t.Error("expected other errors to be returned")
+	}
+	args, _ := os.ReadFile(argsPath)
+	if !strings.Contains(string(args), "api repos/owner/repo/git/ref/heads/ue/1-free") {
+		t.Errorf("expected the ref endpoint, got %q", args)
+	}
+}
@@ -3,6 +3,7 @@ package providers
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 )
@@ -12,8 +13,9 @@ type MockProvider struct {
 	mu sync.RWMutex
 
 	// Issue storage
-	Issues   map[string]map[int]*Issue     // repo -> issueNum -> issue
-	Comments map[string]map[int][]*Comment // repo -> issueNum -> comments
+	Issues    map[string]map[int]*Issue     // repo -> issueNum -> issue
+	Comments  map[string]map[int][]*Comment // repo -> issueNum -> comments
+	Assignees map[string]map[int][]string   // repo -> issueNum -> assignees
 
 	// PR storage
 	PRs              map[string]map[int]*PR        // repo -> prNum -> pr
@@ -22,17 +24,28 @@ type MockProvider struct {
 	// Authorization storage
 	Collaborators map[string]map[string]bool // repo -> username -> isCollaborator
 
+	// Branch storage
+	Branches map[string]map[string]bool // repo -> branch -> exists
+
 	// Tracking calls for assertions
 	CreatedComments []MockComment
 	UpdatedComments []MockCommentUpdate
 	AddedLabels     []MockLabel
 	RemovedLabels   []MockLabel
 	Reactions       []MockReaction
+	ReviewReactions []MockReaction    // Reactions on inline review comments
+	ReviewReplies   []MockReviewReply // Replies in inline review threads
+	MergeMethods    []MergeMethod     // Methods passed to MergePR, in call order
+	ReadyPRs        []int             // PRs passed to MarkPRReady, in call order
+	PRLabels        []MockLabel       // Labels added to PRs by CreatePR
 
 	// Configurable behavior
-	DefaultBranch string
-	CloneError    error
-	MergeError    error
+	DefaultBranch      string
+	DefaultBranchError error // Returned by GetDefaultBranch when set
+	BranchExistsError  error // Returned by BranchExists when set
+	CloneError         error
+	MergeError         error
+	MaxCommentLen      int // Rejects longer comment bodies when set
 }
 
 // MockComment tracks created comments
@@ -51,6 +64,14 @@ type MockCommentUpdate struct {
 	Body      string
 }
 
+// MockReviewReply tracks replies to inline review comments
+type MockReviewReply struct {
+	Repo      string
+	PRNumber  int
+	CommentID int64
+	Body      string
+}
+
 // MockLabel tracks label operations
 type MockLabel struct {
 	Repo     string
@@ -70,9 +91,11 @@ func NewMockProvider() *MockProvider {
 	return &MockProvider{
 		Issues:           make(map[string]map[int]*Issue),
 		Comments:         make(map[string]map[int][]*Comment),
+		Assignees:        make(map[string]map[int][]string),
 		PRs:              make(map[string]map[int]*PR),
 		PRReviewComments: make(map[string]map[int][]*Comment),
 		Collaborators:    make(map[string]map[string]bool),
+		Branches:         make(map[string]map[string]bool),
 		DefaultBranch:    "main",
 	}
 }
@@ -109,6 +132,10 @@ func (m *MockProvider) ListIssuesWithLabel(ctx context.Context, repo string, lab
 	var result []*Issue
 	if repoIssues, ok := m.Issues[repo]; ok {
 		for _, issue := range repoIssues {
+			if label == "" {
+				result = append(result, issue)
+				continue
+			}
 			for _, l := range issue.Labels {
 				if l == label {
 					result = append(result, issue)
@@ -138,6 +165,10 @@ func (m *MockProvider) CreateComment(ctx context.Context, repo string, number in
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if m.MaxCommentLen > 0 && len(body) > m.MaxCommentLen {
+		return 0, fmt.Errorf("comment body is too long (maximum is %d characters)", m.MaxCommentLen)
+	}
+
 	if m.Comments[repo] == nil {
 		m.Comments[repo] = make(map[int][]*Comment)
 	}
@@ -200,6 +231,29 @@ func (m *MockProvider) UpdateIssueBody(ctx context.Context, repo string, number
 	return fmt.Errorf("issue not found")
 }
 
+// CloseIssue implements Provider
+func (m *MockProvider) CloseIssue(ctx context.Context, repo string, number int) error {
+	return m.setIssueState(repo, number, "closed")
+}
+
+// ReopenIssue implements Provider
+func (m *MockProvider) ReopenIssue(ctx context.Context, repo string, number int) error {
+	return m.setIssueState(repo, number, "open")
+}
+
+func (m *MockProvider) setIssueState(repo string, number int, issueState string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if repoIssues, ok := m.Issues[repo]; ok {
+		if issue, ok := repoIssues[number]; ok {
+			issue.State = issueState
+			return nil
+		}
+	}
+	return fmt.Errorf("issue not found")
+}
+
 // ReactToComment implements Provider
 func (m *MockProvider) ReactToComment(ctx context.Context, repo string, commentID int64, reaction string) error {
 	m.mu.Lock()
@@ -213,6 +267,58 @@ func (m *MockProvider) ReactToComment(ctx context.Context, repo string, commentI
 	return nil
 }
 
+// ReactToReviewComment implements ReviewCommentProvider
+func (m *MockProvider) ReactToReviewComment(ctx context.Context, repo string, commentID int64, reaction string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ReviewReactions = append(m.ReviewReactions, MockReaction{
+		Repo:      repo,
+		CommentID: commentID,
+		Reaction:  reaction,
+	})
+	return nil
+}
+
+// ReplyToReviewComment implements ReviewCommentProvider
+func (m *MockProvider) ReplyToReviewComment(ctx context.Context, repo string, prNumber int, comment *Comment, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ReviewReplies = append(m.ReviewReplies, MockReviewReply{
+		Repo:      repo,
+		PRNumber:  prNumber,
+		CommentID: comment.ID,
+		Body:      body,
+	})
+	return nil
+}
+
+// AssignIssue implements Provider
+func (m *MockProvider) AssignIssue(ctx context.Context, repo string, number int, assignee string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Assignees[repo] == nil {
+		m.Assignees[repo] = make(map[int][]string)
+	}
+	if !slices.Contains(m.Assignees[repo][number], assignee) {
+		m.Assignees[repo][number] = append(m.Assignees[repo][number], assignee)
+	}
+	return nil
+}
+
+// UnassignIssue implements Provider
+func (m *MockProvider) UnassignIssue(ctx context.Context, repo string, number int, assignee string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Assignees[repo] != nil {
+		m.Assignees[repo][number] = slices.DeleteFunc(m.Assignees[repo][number], func(a string) bool { return a == assignee })
+	}
+	return nil
+}
+
 // AddLabel implements Provider
 func (m *MockProvider) AddLabel(ctx context.Context, repo string, number int, label string) error {
 	m.mu.Lock()
@@ -282,9 +388,13 @@ func (m *MockProvider) CreatePR(ctx context.Context, repo string, pr PRCreate) (
 		HTMLURL:   fmt.Sprintf("https://example.com/%s/pull/%d", repo, prNum),
 		HeadRef:   pr.Head,
 		BaseRef:   pr.Base,
+		Draft:     pr.Draft,
 	}
 
 	m.PRs[repo][prNum] = newPR
+	for _, label := range pr.Labels {
+		m.PRLabels = append(m.PRLabels, MockLabel{Repo: repo, IssueNum: prNum, Label: label})
+	}
 	return newPR, nil
 }
 
@@ -331,17 +441,33 @@ func (m *MockProvider) AddPRReviewComment(repo string, prNum int, comment *Comme
 }
 
 // MergePR implements Provider
-func (m *MockProvider) MergePR(ctx context.Context, repo string, number int) error {
+func (m *MockProvider) MergePR(ctx context.Context, repo string, number int, method MergeMethod) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.MergeMethods = append(m.MergeMethods, method)
 	if m.MergeError != nil {
 		return m.MergeError
 	}
 
+	if repoPRs, ok := m.PRs[repo]; ok {
+		if pr, ok := repoPRs[number]; ok {
+			pr.State = "merged"
+			return nil
+		}
+	}
+	return fmt.Errorf("PR not found")
+}
+
+// MarkPRReady implements Provider
+func (m *MockProvider) MarkPRReady(ctx context.Context, repo string, number int) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.ReadyPRs = append(m.ReadyPRs, number)
 	if repoPRs, ok := m.PRs[repo]; ok {
 		if pr, ok := repoPRs[number]; ok {
-			pr.State = "merged"
+			pr.Draft = false
 			return nil
 		}
 	}
@@ -368,10 +494,42 @@ func (m *MockProvider) Clone(ctx context.Context, repo string, dest string) erro
 
 // GetDefaultBranch implements Provider
 func (m *MockProvider) GetDefaultBranch(ctx context.Context, repo string) (string, error) {
+	if m.DefaultBranchError != nil {
+		return "", m.DefaultBranchError
+	}
 	return m.DefaultBranch, nil
 }
 
+// AddBranch adds a branch to the mock
+func (m *MockProvider) AddBranch(repo, branch string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Branches[repo] == nil {
+		m.Branches[repo] = make(map[string]bool)
+	}
+	m.Branches[repo][branch] = true
+}
+
+// BranchExists implements Provider
+func (m *MockProvider) BranchExists(ctx context.Context, repo, branch string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.BranchExistsError != nil {
+		return false, m.BranchExistsError
+	}
+	return m.Branches[repo][branch], nil
+}
+
 // Name implements Provider
+// MaxCommentLength returns MaxCommentLen (0 means no limit)
+func (m *MockProvider) MaxCommentLength() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.MaxCommentLen
+}
+
 func (m *MockProvider) Name() string {
 	return "mock"
 }
@@ -432,6 +590,7 @@ func (m *MockProvider) Reset() {
 
 	m.Issues = make(map[string]map[int]*Issue)
 	m.Comments = make(map[string]map[int][]*Comment)
+	m.Assignees = make(map[string]map[int][]string)
 	m.PRs = make(map[string]map[int]*PR)
 	m.PRReviewComments = make(map[string]map[int][]*Comment)
 	m.Collaborators = make(map[string]map[string]bool)
@@ -440,4 +599,7 @@ func (m *MockProvider) Reset() {
 	m.AddedLabels = nil
 	m.RemovedLabels = nil
 	m.Reactions = nil
+	m.ReviewReactions = nil
+	m.ReviewReplies = nil
+	m.MergeMethods = nil
 }
@@ -3,7 +3,10 @@ package providers
 import (
 	"context"
 	"errors"
+	"slices"
 	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/state"
 )
 
 // ErrMergeNotAllowed is returned when a PR cannot be merged yet (e.g. pending
@@ -11,6 +14,25 @@ import (
 // this as a temporary condition and retry later rather than failing permanently.
 var ErrMergeNotAllowed = errors.New("merge not allowed")
 
+// ErrMergeMethodRejected is returned when the server does not allow the
+// configured merge method for the repository. Unlike ErrMergeNotAllowed,
+// retrying will not help until the configuration or repository settings change.
+var ErrMergeMethodRejected = errors.New("merge method rejected")
+
+// ErrPRSetup is returned together with the created PR when CreatePR could not add
+// the PR's labels or link it to its issue. The PR itself exists and is usable.
+var ErrPRSetup = errors.New("PR created, but not fully set up")
+
+// MergeMethod selects how a PR is merged
+type MergeMethod string
+
+const (
+	MergeMethodDefault MergeMethod = ""       // Provider default (GitHub: merge, Gitea: squash)
+	MergeMethodMerge   MergeMethod = "merge"  // Merge commit
+	MergeMethodSquash  MergeMethod = "squash" // Squash into a single commit
+	MergeMethodRebase  MergeMethod = "rebase" // Rebase commits onto the base branch
+)
+
 // Issue represents an issue from any provider
 type Issue struct {
 	Number      int
@@ -30,6 +52,9 @@ type Comment struct {
 	Body      string
 	Author    string
 	CreatedAt time.Time
+	Path      string // File an inline review comment targets (empty for general comments)
+	Line      int    // Line in Path the comment targets (0 if unknown or not inline)
+	Outdated  bool   // Line is from the diff the comment was made on; it's no longer in the current diff
 }
 
 // PR represents a pull request
@@ -42,6 +67,7 @@ type PR struct {
 	HTMLURL   string
 	HeadRef   string
 	BaseRef   string
+	Draft     bool
 }
 
 // PRCreate contains fields for creating a PR
@@ -50,35 +76,45 @@ type PRCreate struct {
 	Body    string
 	Head    string
 	Base    string
-	IssueID int // Link to issue if supported
+	IssueID int      // Link to issue if supported
+	Draft   bool     // Open the PR as a draft
+	Labels  []string // Added to the PR once it is created
 }
 
 // Provider defines the interface for git providers
 type Provider interface {
 	// Issue operations
 	GetIssue(ctx context.Context, repo string, number int) (*Issue, error)
-	ListIssuesWithLabel(ctx context.Context, repo string, label string) ([]*Issue, error)
+	ListIssuesWithLabel(ctx context.Context, repo string, label string) ([]*Issue, error) // Empty label lists all open issues
 	GetComments(ctx context.Context, repo string, number int) ([]*Comment, error)
 	CreateComment(ctx context.Context, repo string, number int, body string) (int64, error)
 	UpdateComment(ctx context.Context, repo string, commentID int64, body string) error
 	UpdateIssueBody(ctx context.Context, repo string, number int, body string) error
+	CloseIssue(ctx context.Context, repo string, number int) error
+	ReopenIssue(ctx context.Context, repo string, number int) error
 	ReactToComment(ctx context.Context, repo string, commentID int64, reaction string) error
 
+	// Assignee operations
+	AssignIssue(ctx context.Context, repo string, number int, assignee string) error
+	UnassignIssue(ctx context.Context, repo string, number int, assignee string) error
+
 	// Label operations
 	AddLabel(ctx context.Context, repo string, number int, label string) error
 	RemoveLabel(ctx context.Context, repo string, number int, label string) error
 
 	// PR operations
-	CreatePR(ctx context.Context, repo string, pr PRCreate) (*PR, error)
+	CreatePR(ctx context.Context, repo string, pr PRCreate) (*PR, error) // Returns the PR with ErrPRSetup if labels or the issue link failed
 	GetPR(ctx context.Context, repo string, number int) (*PR, error)
 	GetPRComments(ctx context.Context, repo string, number int) ([]*Comment, error)
 	GetPRReviewComments(ctx context.Context, repo string, number int) ([]*Comment, error)
-	MergePR(ctx context.Context, repo string, number int) error
+	MergePR(ctx context.Context, repo string, number int, method MergeMethod) error
 	IsMergeable(ctx context.Context, repo string, number int) (bool, error)
+	MarkPRReady(ctx context.Context, repo string, number int) error // Takes a draft PR out of draft
 
 	// Repository operations
 	Clone(ctx context.Context, repo string, dest string) error
 	GetDefaultBranch(ctx context.Context, repo string) (string, error)
+	BranchExists(ctx context.Context, repo, branch string) (bool, error)
 
 	// Authorization
 	IsCollaborator(ctx context.Context, repo, username string) (bool, error)
@@ -105,6 +141,7 @@ type CICheck struct {
 	Conclusion string   // Conclusion (success, failure, cancelled, etc.)
 	DetailsURL string   // URL to view details
 	Output     string   // Summary output (may be truncated)
+	Optional   bool     // Not required by branch protection; its result does not block (see ApplyRequiredChecks)
 }
 
 // CIResult represents the combined CI status for a PR
@@ -122,3 +159,100 @@ type CIProvider interface {
 	// GetCILogs retrieves logs for a specific check run
 	GetCILogs(ctx context.Context, repo string, checkRunID int64) (string, error)
 }
+
+// RequiredChecksProvider is an optional interface for providers that expose which
+// checks branch protection requires before a PR can merge
+// Use type assertion: if rcProvider, ok := provider.(RequiredChecksProvider); ok { ... }
+type RequiredChecksProvider interface {
+	// GetRequiredChecks returns the names of the checks required on branch (empty if none)
+	GetRequiredChecks(ctx context.Context, repo, branch string) ([]string, error)
+}
+
+// ApplyRequiredChecks marks checks not named in required as optional and recomputes
+// OverallStatus from the required checks alone. Required checks that have not reported
+// yet count as pending. An empty required list leaves the result unchanged.
+func ApplyRequiredChecks(result *CIResult, required []string) {
+	if len(required) == 0 {
+		return
+	}
+
+	hasPending := false
+	hasFailure := false
+	reported := make(map[string]bool)
+
+	for i := range result.Checks {
+		check := &result.Checks[i]
+		check.Optional = !slices.Contains(required, check.Name)
+		if check.Optional {
+			continue
+		}
+		reported[check.Name] = true
+		switch check.Status {
+		case CIStatusPending:
+			hasPending = true
+		case CIStatusFailure:
+			hasFailure = true
+		}
+	}
+	for _, name := range required {
+		if !reported[name] {
+			hasPending = true
+		}
+	}
+
+	switch {
+	case hasFailure:
+		result.OverallStatus = CIStatusFailure
+	case hasPending:
+		result.OverallStatus = CIStatusPending
+	default:
+		result.OverallStatus = CIStatusSuccess
+	}
+}
+
+// IssueDependencyProvider is an optional interface for providers that support
+// structured issue relations (e.g. "blocked by" links)
+// Use type assertion: if depProvider, ok := provider.(IssueDependencyProvider); ok { ... }
+type IssueDependencyProvider interface {
+	// GetIssueDependencies returns the numbers of issues in the same repo that block this issue
+	GetIssueDependencies(ctx context.Context, repo string, number int) ([]int, error)
+}
+
+// RateLimitInfo describes the remaining API quota for the authenticated user
+type RateLimitInfo struct {
+	Limit     int       // Requests allowed per window
+	Remaining int       // Requests left in the current window
+	Reset     time.Time // When the window resets
+}
+
+// RateLimitProvider is an optional interface for providers that expose their API quota
+// Use type assertion: if rlProvider, ok := provider.(RateLimitProvider); ok { ... }
+type RateLimitProvider interface {
+	// RateLimitInfo returns the current quota, or nil if it is unknown
+	RateLimitInfo(ctx context.Context) (*RateLimitInfo, error)
+}
+
+// ReviewCommentProvider is an optional interface for acknowledging inline PR review comments
+// Use type assertion: if rcProvider, ok := provider.(ReviewCommentProvider); ok { ... }
+type ReviewCommentProvider interface {
+	// ReactToReviewComment adds a reaction to an inline review comment
+	ReactToReviewComment(ctx context.Context, repo string, commentID int64, reaction string) error
+
+	// ReplyToReviewComment posts a reply in the thread of an inline review comment
+	ReplyToReviewComment(ctx context.Context, repo string, prNumber int, comment *Comment, body string) error
+}
+
+// CommentLimitProvider is an optional interface for providers that cap the size of comment bodies
+// Use type assertion: if clProvider, ok := provider.(CommentLimitProvider); ok { ... }
+type CommentLimitProvider interface {
+	// MaxCommentLength returns the largest comment body accepted, in bytes
+	MaxCommentLength() int
+}
+
+// LabelStyleProvider is an optional interface for providers that create labels missing
+// from a repository, so the created labels get a color and description
+// Use type assertion: if lsProvider, ok := provider.(LabelStyleProvider); ok { ... }
+type LabelStyleProvider interface {
+	// SetLabelSpecs sets the specs labels are created with, by label name
+	SetLabelSpecs(specs map[string]state.LabelSpec)
+}
@@ -0,0 +1,74 @@
+package providers
+
+import "testing"
+
+func TestApplyRequiredChecks(t *testing.T) {
+	tests := []struct {
+		name     string
+		checks   []CICheck
+		required []string
+		want     CIStatus
+	}{
+		{
+			name: "optional failure does not block",
+			checks: []CICheck{
+				{Name: "build", Status: CIStatusSuccess},
+				{Name: "lint-advisory", Status: CIStatusFailure},
+			},
+			required: []string{"build"},
+			want:     CIStatusSuccess,
+		},
+		{
+			name: "required failure blocks",
+			checks: []CICheck{
+				{Name: "build", Status: CIStatusFailure},
+				{Name: "lint-advisory", Status: CIStatusSuccess},
+			},
+			required: []string{"build"},
+			want:     CIStatusFailure,
+		},
+		{
+			name: "optional pending does not block",
+			checks: []CICheck{
+				{Name: "build", Status: CIStatusSuccess},
+				{Name: "coverage", Status: CIStatusPending},
+			},
+			required: []string{"build"},
+			want:     CIStatusSuccess,
+		},
+		{
+			name: "required check not reported yet is pending",
+			checks: []CICheck{
+				{Name: "lint-advisory", Status: CIStatusSuccess},
+			},
+			required: []string{"build"},
+			want:     CIStatusPending,
+		},
+		{
+			name: "no required checks leaves status alone",
+			checks: []CICheck{
+				{Name: "build", Status: CIStatusFailure},
+			},
+			want: CIStatusFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &CIResult{OverallStatus: CIStatusFailure, Checks: tt.checks}
+			ApplyRequiredChecks(result, tt.required)
+			if result.OverallStatus != tt.want {
+				t.Errorf("OverallStatus = %s, want %s", result.OverallStatus, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyRequiredChecks_MarksOptional(t *testing.T) {
+	result := &CIResult{Checks: []CICheck{{Name: "build"}, {Name: "docs"}}}
+	ApplyRequiredChecks(result, []string{"build"})
+
+	if result.Checks[0].Optional || !result.Checks[1].Optional {
+		t.Errorf("expected only docs to be optional, got %+v", result.Checks)
+	}
+}
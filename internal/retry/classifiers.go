@@ -123,3 +123,37 @@ func ClassifyHTTPError(err error) ErrorType {
 
 	return Permanent
 }
+
+// ClassifyGitClone classifies git clone failures.
+// Missing repositories and auth failures are permanent; anything else, including
+// network errors, is treated as transient because a clone has no side effects.
+func ClassifyGitClone(err error) ErrorType {
+	if err == nil {
+		return Permanent
+	}
+
+	errLower := strings.ToLower(err.Error())
+
+	permanentPatterns := []string{
+		"not found",
+		"does not exist",
+		"permission denied",
+		"authentication failed",
+		"could not read username",
+		"access denied",
+		"401",
+		"403",
+		"404",
+	}
+	for _, pattern := range permanentPatterns {
+		if strings.Contains(errLower, pattern) {
+			return Permanent
+		}
+	}
+
+	if strings.Contains(errLower, "rate limit") || strings.Contains(errLower, "429") {
+		return RateLimited
+	}
+
+	return Retryable
+}
@@ -117,3 +117,29 @@ func TestClassifyHTTPError(t *testing.T) {
 		})
 	}
 }
+
+func TestClassifyGitClone(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected ErrorType
+	}{
+		{"repo not found", errors.New("remote: Repository not found."), Permanent},
+		{"auth failed", errors.New("fatal: Authentication failed for 'https://github.com/o/r'"), Permanent},
+		{"no credentials", errors.New("fatal: could not read Username for 'https://github.com'"), Permanent},
+		{"403", errors.New("The requested URL returned error: 403"), Permanent},
+		{"rate limit", errors.New("API rate limit exceeded"), RateLimited},
+		{"dns", errors.New("Could not resolve host: github.com"), Retryable},
+		{"reset", errors.New("RPC failed; curl 56 connection reset by peer"), Retryable},
+		{"early eof", errors.New("fatal: early EOF"), Retryable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ClassifyGitClone(tt.err)
+			if result != tt.expected {
+				t.Errorf("ClassifyGitClone(%q) = %v, want %v", tt.err, result, tt.expected)
+			}
+		})
+	}
+}
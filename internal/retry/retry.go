@@ -2,8 +2,12 @@ package retry
 
 import (
 	"context"
+	"errors"
 	"math"
 	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/anthropics/ultra-engineer/internal/config"
@@ -21,15 +25,64 @@ const (
 	Permanent
 )
 
+// RetryableError carries the delay a server asked for before the next attempt, e.g. in
+// a Retry-After header. Do and DoWithResult wait RetryAfter, up to Options.BackoffMax,
+// instead of their own delay when it is positive; the error is still classified as usual.
+type RetryableError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryableError) Error() string { return e.Err.Error() }
+func (e *RetryableError) Unwrap() error { return e.Err }
+
+// ParseRetryAfter parses a Retry-After header value, either delay seconds or an HTTP date,
+// into the delay from now. Returns 0 for an empty, invalid or past value.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
+
 // Classifier is a function that classifies an error
 type Classifier func(error) ErrorType
 
+// JitterMode selects how random jitter spreads out backoff delays, so many workers
+// failing at once don't all retry at the same moment
+type JitterMode string
+
+const (
+	JitterProportional JitterMode = ""      // Up to JitterFraction of the delay is added
+	JitterNone         JitterMode = "none"  // Exact exponential delays
+	JitterEqual        JitterMode = "equal" // Half the delay, plus up to the other half
+	JitterFull         JitterMode = "full"  // Anywhere from zero to the delay ("full jitter")
+)
+
+// defaultJitterFraction is the jitter JitterProportional adds when Options.JitterFraction is 0
+const defaultJitterFraction = 0.25
+
 // Options configures retry behavior
 type Options struct {
 	MaxAttempts    int
 	BackoffBase    time.Duration
 	RateLimitRetry time.Duration
 	Classifier     Classifier
+
+	BackoffMax     time.Duration // Longest backoff before jitter; 0 means 5m
+	Jitter         JitterMode
+	JitterFraction float64 // Used by JitterProportional; 0 means 0.25
+
+	// OnRetry, if set, is called before waiting to retry, with the number of the attempt
+	// that failed (starting at 1), its error and the delay before the next attempt
+	OnRetry func(attempt int, err error, delay time.Duration)
 }
 
 // DefaultOptions returns retry options from config
@@ -38,28 +91,47 @@ func DefaultOptions(cfg config.RetryConfig) Options {
 		MaxAttempts:    cfg.MaxAttempts,
 		BackoffBase:    cfg.BackoffBase,
 		RateLimitRetry: cfg.RateLimitRetry,
+		BackoffMax:     cfg.BackoffMax,
+		Jitter:         JitterMode(cfg.Jitter),
+		JitterFraction: cfg.JitterFraction,
 		Classifier:     nil, // Must be set by caller
 	}
 }
 
-// maxBackoff caps the maximum backoff duration to prevent overflow
-const maxBackoff = 5 * time.Minute
+// defaultBackoffMax caps the backoff when Options.BackoffMax is 0
+const defaultBackoffMax = 5 * time.Minute
 
-// calculateBackoff computes the delay for a given attempt using exponential backoff with jitter
-// Formula: delay = base * 2^attempt + jitter(0-25%), capped at maxBackoff
-func calculateBackoff(base time.Duration, attempt int) time.Duration {
-	// Exponential backoff: base * 2^attempt
-	multiplier := math.Pow(2, float64(attempt))
-	delay := time.Duration(float64(base) * multiplier)
-
-	// Cap at maximum to prevent overflow
-	if delay > maxBackoff {
-		delay = maxBackoff
+// backoffMax returns opts.BackoffMax, or its default when unset
+func backoffMax(opts Options) time.Duration {
+	if opts.BackoffMax <= 0 {
+		return defaultBackoffMax
 	}
+	return opts.BackoffMax
+}
 
-	// Add jitter: 0-25% of the delay (rand/v2 is automatically seeded)
-	jitter := time.Duration(rand.Float64() * 0.25 * float64(delay))
-	return delay + jitter
+// calculateBackoff computes the delay for a given attempt using exponential backoff with jitter
+// Formula: delay = base * 2^attempt, capped at opts.BackoffMax, then jittered per opts.Jitter
+func calculateBackoff(opts Options, attempt int) time.Duration {
+	maxDelay := backoffMax(opts)
+
+	// Exponential backoff: base * 2^attempt, capped before converting to prevent overflow
+	delay := time.Duration(min(float64(opts.BackoffBase)*math.Pow(2, float64(attempt)), float64(maxDelay)))
+
+	// rand/v2 is automatically seeded
+	switch opts.Jitter {
+	case JitterNone:
+		return delay
+	case JitterEqual:
+		return delay/2 + time.Duration(rand.Float64()*float64(delay/2))
+	case JitterFull:
+		return time.Duration(rand.Float64() * float64(delay))
+	default:
+		fraction := opts.JitterFraction
+		if fraction == 0 {
+			fraction = defaultJitterFraction
+		}
+		return delay + time.Duration(rand.Float64()*fraction*float64(delay))
+	}
 }
 
 // Do executes a function with retry logic
@@ -69,46 +141,10 @@ func calculateBackoff(base time.Duration, attempt int) time.Duration {
 // - Context cancellation
 // - Permanent error (always stops retries, even in infinite mode)
 func Do(ctx context.Context, opts Options, fn func() error) error {
-	var lastErr error
-	infinite := opts.MaxAttempts <= 0
-
-	for attempt := 0; infinite || attempt < opts.MaxAttempts; attempt++ {
-		// Check context before each attempt
-		if err := ctx.Err(); err != nil {
-			return err
-		}
-
-		lastErr = fn()
-		if lastErr == nil {
-			return nil
-		}
-
-		// Classify the error
-		errType := Permanent
-		if opts.Classifier != nil {
-			errType = opts.Classifier(lastErr)
-		}
-
-		switch errType {
-		case Permanent:
-			return lastErr
-		case RateLimited:
-			// Use rate limit retry duration
-			if err := sleep(ctx, opts.RateLimitRetry); err != nil {
-				return err
-			}
-		case Retryable:
-			// Use exponential backoff (skip delay on last attempt in finite mode)
-			if infinite || attempt < opts.MaxAttempts-1 {
-				backoff := calculateBackoff(opts.BackoffBase, attempt)
-				if err := sleep(ctx, backoff); err != nil {
-					return err
-				}
-			}
-		}
-	}
-
-	return lastErr
+	_, err := DoWithResult(ctx, opts, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
 }
 
 // DoWithResult executes a function that returns a value with retry logic
@@ -135,22 +171,31 @@ func DoWithResult[T any](ctx context.Context, opts Options, fn func() (T, error)
 			errType = opts.Classifier(lastErr)
 		}
 
+		var delay time.Duration
 		switch errType {
 		case Permanent:
 			return result, lastErr
 		case RateLimited:
 			// Use rate limit retry duration
-			if err := sleep(ctx, opts.RateLimitRetry); err != nil {
-				return result, err
-			}
+			delay = opts.RateLimitRetry
 		case Retryable:
 			// Use exponential backoff (skip delay on last attempt in finite mode)
-			if infinite || attempt < opts.MaxAttempts-1 {
-				backoff := calculateBackoff(opts.BackoffBase, attempt)
-				if err := sleep(ctx, backoff); err != nil {
-					return result, err
-				}
+			if !infinite && attempt == opts.MaxAttempts-1 {
+				continue
 			}
+			delay = calculateBackoff(opts, attempt)
+		}
+		var retryable *RetryableError
+		if errors.As(lastErr, &retryable) && retryable.RetryAfter > 0 {
+			// A server asking for hours or days would otherwise stall the caller that long
+			delay = min(retryable.RetryAfter, backoffMax(opts))
+		}
+
+		if opts.OnRetry != nil {
+			opts.OnRetry(attempt+1, lastErr, delay)
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return result, err
 		}
 	}
 
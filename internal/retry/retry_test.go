@@ -3,6 +3,9 @@ package retry
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"strings"
 	"testing"
 	"time"
 )
@@ -24,7 +27,7 @@ func TestCalculateBackoff(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run("", func(t *testing.T) {
-			backoff := calculateBackoff(base, tt.attempt)
+			backoff := calculateBackoff(Options{BackoffBase: base}, tt.attempt)
 			if backoff < tt.minExpected || backoff > tt.maxExpected {
 				t.Errorf("attempt %d: got %v, want between %v and %v", tt.attempt, backoff, tt.minExpected, tt.maxExpected)
 			}
@@ -32,6 +35,52 @@ func TestCalculateBackoff(t *testing.T) {
 	}
 }
 
+func TestCalculateBackoff_JitterModes(t *testing.T) {
+	base := 100 * time.Millisecond // 400ms at attempt 2
+	tests := []struct {
+		opts     Options
+		min, max time.Duration
+	}{
+		{Options{Jitter: JitterNone}, 400 * time.Millisecond, 400 * time.Millisecond},
+		{Options{Jitter: JitterEqual}, 200 * time.Millisecond, 400 * time.Millisecond},
+		{Options{Jitter: JitterFull}, 0, 400 * time.Millisecond},
+		{Options{Jitter: JitterProportional, JitterFraction: 0.5}, 400 * time.Millisecond, 600 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		tt.opts.BackoffBase = base
+		lowest, highest := time.Duration(math.MaxInt64), time.Duration(0)
+		for i := 0; i < 200; i++ {
+			backoff := calculateBackoff(tt.opts, 2)
+			lowest, highest = min(lowest, backoff), max(highest, backoff)
+		}
+		if lowest < tt.min || highest > tt.max {
+			t.Errorf("jitter %q: got delays in [%v, %v], want within [%v, %v]", tt.opts.Jitter, lowest, highest, tt.min, tt.max)
+		}
+		if tt.opts.Jitter != JitterNone && lowest == highest {
+			t.Errorf("jitter %q: expected varying delays, always got %v", tt.opts.Jitter, lowest)
+		}
+	}
+}
+
+func TestCalculateBackoff_BackoffMax(t *testing.T) {
+	opts := Options{BackoffBase: 10 * time.Millisecond, BackoffMax: 50 * time.Millisecond, Jitter: JitterNone}
+
+	if got := calculateBackoff(opts, 2); got != 40*time.Millisecond {
+		t.Errorf("expected 40ms below the cap, got %v", got)
+	}
+	for _, attempt := range []int{3, 10, 100} {
+		if got := calculateBackoff(opts, attempt); got != 50*time.Millisecond {
+			t.Errorf("attempt %d: expected the delay clamped to 50ms, got %v", attempt, got)
+		}
+	}
+
+	opts.BackoffMax = 0
+	if got := calculateBackoff(opts, 100); got != defaultBackoffMax {
+		t.Errorf("expected the default cap of %v without BackoffMax, got %v", defaultBackoffMax, got)
+	}
+}
+
 func TestDo_Success(t *testing.T) {
 	ctx := context.Background()
 	opts := Options{
@@ -381,3 +430,139 @@ func TestDo_InfiniteRetry_NegativeMaxAttempts(t *testing.T) {
 		t.Errorf("expected 3 calls, got %d", calls)
 	}
 }
+
+func TestDo_OnRetry(t *testing.T) {
+	var attempts []int
+	var delays []time.Duration
+	opts := Options{
+		MaxAttempts: 4,
+		BackoffBase: time.Millisecond,
+		Classifier:  func(err error) ErrorType { return Retryable },
+		OnRetry: func(attempt int, err error, delay time.Duration) {
+			if err == nil || err.Error() != "transient" {
+				t.Errorf("expected the attempt's error, got %v", err)
+			}
+			attempts = append(attempts, attempt)
+			delays = append(delays, delay)
+		},
+	}
+
+	err := Do(context.Background(), opts, func() error { return errors.New("transient") })
+	if err == nil {
+		t.Fatal("expected the last error")
+	}
+	// No wait follows the last attempt, so the hook fires once less than there are attempts
+	if len(attempts) != 3 || attempts[0] != 1 || attempts[1] != 2 || attempts[2] != 3 {
+		t.Fatalf("expected the hook for attempts 1, 2, 3, got %v", attempts)
+	}
+	for i := 1; i < len(delays); i++ {
+		if delays[i] <= delays[i-1] {
+			t.Errorf("expected growing delays, got %v", delays)
+		}
+	}
+}
+
+func TestDoWithResult_OnRetryRateLimited(t *testing.T) {
+	calls := 0
+	var got []time.Duration
+	opts := Options{
+		MaxAttempts:    3,
+		RateLimitRetry: 2 * time.Millisecond,
+		Classifier:     func(err error) ErrorType { return RateLimited },
+		OnRetry:        func(attempt int, err error, delay time.Duration) { got = append(got, delay) },
+	}
+
+	result, err := DoWithResult(context.Background(), opts, func() (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, errors.New("rate limited")
+		}
+		return calls, nil
+	})
+	if err != nil || result != 3 {
+		t.Fatalf("expected success on the third call, got %d, %v", result, err)
+	}
+	if len(got) != 2 || got[0] != 2*time.Millisecond || got[1] != 2*time.Millisecond {
+		t.Errorf("expected the hook with the rate limit delay twice, got %v", got)
+	}
+}
+
+func TestDo_PrefersRetryAfter(t *testing.T) {
+	var delays []time.Duration
+	opts := Options{
+		MaxAttempts:    3,
+		BackoffBase:    time.Hour,
+		RateLimitRetry: time.Hour,
+		Classifier: func(err error) ErrorType {
+			if strings.Contains(err.Error(), "429") {
+				return RateLimited
+			}
+			return Retryable
+		},
+		OnRetry: func(attempt int, err error, delay time.Duration) { delays = append(delays, delay) },
+	}
+
+	calls := 0
+	err := Do(context.Background(), opts, func() error {
+		calls++
+		switch calls {
+		case 1:
+			return &RetryableError{Err: errors.New("API error 429: slow down"), RetryAfter: 3 * time.Millisecond}
+		case 2:
+			return fmt.Errorf("listing issues: %w", &RetryableError{Err: errors.New("API error 503"), RetryAfter: 5 * time.Millisecond})
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if len(delays) != 2 || delays[0] != 3*time.Millisecond || delays[1] != 5*time.Millisecond {
+		t.Errorf("expected the server's delays over the configured ones, got %v", delays)
+	}
+}
+
+func TestDo_CapsRetryAfterAtBackoffMax(t *testing.T) {
+	var delays []time.Duration
+	opts := Options{
+		MaxAttempts: 2,
+		BackoffBase: time.Millisecond,
+		BackoffMax:  2 * time.Millisecond,
+		Classifier:  func(err error) ErrorType { return Retryable },
+		OnRetry:     func(attempt int, err error, delay time.Duration) { delays = append(delays, delay) },
+	}
+
+	calls := 0
+	err := Do(context.Background(), opts, func() error {
+		if calls++; calls == 1 {
+			return &RetryableError{Err: errors.New("API error 429"), RetryAfter: 24 * time.Hour}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if len(delays) != 1 || delays[0] != 2*time.Millisecond {
+		t.Errorf("expected Retry-After to be capped at backoff_max, got %v", delays)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"120", 2 * time.Minute},
+		{" 5 ", 5 * time.Second},
+		{"Sun, 01 Mar 2026 12:00:30 GMT", 30 * time.Second},
+		{"Sun, 01 Mar 2026 11:00:00 GMT", 0}, // In the past
+		{"-3", 0},
+		{"soon", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := ParseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("ParseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// FileChange is one changed file in a DiffStat
+type FileChange struct {
+	Path       string
+	Insertions int
+	Deletions  int
+	Binary     bool // Binary files have no line counts
+}
+
+// DiffStat summarizes the committed changes on the current branch relative to a base branch
+type DiffStat struct {
+	Files      []FileChange
+	Insertions int
+	Deletions  int
+}
+
+// Summary returns a one-line summary in the style of git diff --stat
+func (d *DiffStat) Summary() string {
+	files := "files"
+	if len(d.Files) == 1 {
+		files = "file"
+	}
+	return fmt.Sprintf("%d %s changed, %d insertions(+), %d deletions(-)", len(d.Files), files, d.Insertions, d.Deletions)
+}
+
+// DiffStat returns the changes between the base branch and HEAD.
+// The remote-tracking branch is preferred so a stale local base branch doesn't inflate the diff.
+func (s *Sandbox) DiffStat(ctx context.Context, baseBranch string) (*DiffStat, error) {
+	base := "origin/" + baseBranch
+	verifyCmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", base)
+	verifyCmd.Dir = s.RepoDir
+	if err := verifyCmd.Run(); err != nil {
+		base = baseBranch
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "diff", "--numstat", base+"...HEAD")
+	cmd.Dir = s.RepoDir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff against %s: %w", base, err)
+	}
+
+	return parseNumstat(string(output)), nil
+}
+
+// parseNumstat parses git diff --numstat output ("<added>\t<deleted>\t<path>" per line).
+// Binary files report "-" for both counts.
+func parseNumstat(output string) *DiffStat {
+	stat := &DiffStat{}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		change := FileChange{Path: parts[2]}
+		if parts[0] == "-" && parts[1] == "-" {
+			change.Binary = true
+		} else {
+			change.Insertions, _ = strconv.Atoi(parts[0])
+			change.Deletions, _ = strconv.Atoi(parts[1])
+		}
+
+		stat.Files = append(stat.Files, change)
+		stat.Insertions += change.Insertions
+		stat.Deletions += change.Deletions
+	}
+	return stat
+}
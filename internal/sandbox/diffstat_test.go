@@ -0,0 +1,59 @@
+package sandbox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseNumstat(t *testing.T) {
+	output := "10\t2\tinternal/foo.go\n0\t5\tREADME.md\n-\t-\tassets/logo.png\n3\t1\tdocs/{old.md => new.md}\n"
+
+	stat := parseNumstat(output)
+
+	if len(stat.Files) != 4 {
+		t.Fatalf("expected 4 files, got %d", len(stat.Files))
+	}
+	if stat.Insertions != 13 || stat.Deletions != 8 {
+		t.Errorf("expected +13 -8, got +%d -%d", stat.Insertions, stat.Deletions)
+	}
+	if !stat.Files[2].Binary || stat.Files[2].Path != "assets/logo.png" {
+		t.Errorf("expected binary logo.png, got %+v", stat.Files[2])
+	}
+	if stat.Files[3].Path != "docs/{old.md => new.md}" {
+		t.Errorf("expected rename path kept, got %q", stat.Files[3].Path)
+	}
+	if want := "4 files changed, 13 insertions(+), 8 deletions(-)"; stat.Summary() != want {
+		t.Errorf("expected summary %q, got %q", want, stat.Summary())
+	}
+}
+
+func TestParseNumstat_Empty(t *testing.T) {
+	stat := parseNumstat("")
+	if len(stat.Files) != 0 || stat.Insertions != 0 || stat.Deletions != 0 {
+		t.Errorf("expected empty stat, got %+v", stat)
+	}
+}
+
+func TestDiffStat_AgainstOriginBase(t *testing.T) {
+	origin, _ := setupOrigin(t)
+	sb, _ := Create(t.TempDir(), "owner/repo", "1")
+	if err := sb.Clone(context.Background(), origin); err != nil {
+		t.Fatal(err)
+	}
+
+	git(t, sb.RepoDir, "checkout", "-b", "feature")
+	os.WriteFile(filepath.Join(sb.RepoDir, "README.md"), []byte("v1\nv2\n"), 0644)
+	os.WriteFile(filepath.Join(sb.RepoDir, "main.go"), []byte("package main\n"), 0644)
+	git(t, sb.RepoDir, "add", "-A")
+	git(t, sb.RepoDir, "commit", "-m", "feature")
+
+	stat, err := sb.DiffStat(context.Background(), "main")
+	if err != nil {
+		t.Fatalf("DiffStat failed: %v", err)
+	}
+	if len(stat.Files) != 2 || stat.Insertions != 2 || stat.Deletions != 0 {
+		t.Errorf("expected 2 files +2 -0, got %+v", stat)
+	}
+}
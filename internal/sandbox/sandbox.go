@@ -2,11 +2,16 @@ package sandbox
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Sandbox represents an isolated working directory for an issue
@@ -15,6 +20,12 @@ type Sandbox struct {
 	RepoDir    string
 	IssueID    string
 	BranchName string
+
+	// Commit identity; empty values fall back to git's own config
+	AuthorName  string
+	AuthorEmail string
+	SignCommits bool
+	SigningKey  string // GPG key ID or SSH public key; empty uses git's user.signingkey
 }
 
 // Create creates a new sandbox for processing an issue
@@ -42,6 +53,35 @@ func (s *Sandbox) Clone(ctx context.Context, cloneURL string) error {
 	return nil
 }
 
+// FetchLatest updates an existing sandbox from origin.
+// The current branch is fast-forwarded only when it has an upstream and no
+// uncommitted changes, so in-progress work is never overwritten.
+func (s *Sandbox) FetchLatest(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "git", "fetch", "--prune", "origin")
+	cmd.Dir = s.RepoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to fetch: %w: %s", err, string(output))
+	}
+
+	if changed, err := s.HasChanges(ctx); err != nil || changed {
+		return err
+	}
+
+	// No upstream (e.g. a local-only branch) is fine; there is nothing to fast-forward
+	upstreamCmd := exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "@{upstream}")
+	upstreamCmd.Dir = s.RepoDir
+	if err := upstreamCmd.Run(); err != nil {
+		return nil
+	}
+
+	mergeCmd := exec.CommandContext(ctx, "git", "merge", "--ff-only", "@{upstream}")
+	mergeCmd.Dir = s.RepoDir
+	if output, err := mergeCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to fast-forward: %w: %s", err, string(output))
+	}
+	return nil
+}
+
 // CreateBranch creates and checks out a new branch, or checks out existing one
 func (s *Sandbox) CreateBranch(ctx context.Context, branchName string) error {
 	s.BranchName = branchName
@@ -83,15 +123,78 @@ func (s *Sandbox) Commit(ctx context.Context, message string) error {
 	}
 
 	// Commit
-	commitCmd := exec.CommandContext(ctx, "git", "commit", "-m", message)
+	commitCmd := exec.CommandContext(ctx, "git", s.commitArgs(message)...)
 	commitCmd.Dir = s.RepoDir
 	if output, err := commitCmd.CombinedOutput(); err != nil {
+		if s.SignCommits && isSigningFailure(string(output)) {
+			return fmt.Errorf("failed to sign commit; check git.signing_key and that the key is available "+
+				"to git on this host (gpg-agent, or the SSH key file): %w: %s", err, string(output))
+		}
 		return fmt.Errorf("failed to commit: %w: %s", err, string(output))
 	}
 
 	return nil
 }
 
+// commitArgs returns the git arguments that commit with the sandbox's identity
+func (s *Sandbox) commitArgs(message string) []string {
+	var args []string
+	for _, kv := range s.identityConfig() {
+		args = append(args, "-c", kv[0]+"="+kv[1])
+	}
+	args = append(args, "commit")
+	if s.SignCommits {
+		args = append(args, "-S")
+	}
+	return append(args, "-m", message)
+}
+
+// identityConfig returns the git config keys and values of the sandbox's identity and signing
+func (s *Sandbox) identityConfig() [][2]string {
+	var config [][2]string
+	if s.AuthorName != "" {
+		config = append(config, [2]string{"user.name", s.AuthorName})
+	}
+	if s.AuthorEmail != "" {
+		config = append(config, [2]string{"user.email", s.AuthorEmail})
+	}
+	if s.SignCommits {
+		config = append(config, [2]string{"commit.gpgsign", "true"})
+		if isSSHSigningKey(s.SigningKey) {
+			config = append(config, [2]string{"gpg.format", "ssh"})
+		}
+		if s.SigningKey != "" {
+			config = append(config, [2]string{"user.signingkey", s.SigningKey})
+		}
+	}
+	return config
+}
+
+// isSigningFailure reports whether git commit output says signing failed
+func isSigningFailure(output string) bool {
+	output = strings.ToLower(output)
+	return strings.Contains(output, "failed to sign") || strings.Contains(output, "signing failed") ||
+		strings.Contains(output, "failed to write commit object")
+}
+
+// isSSHSigningKey reports whether key is an SSH public key (file or literal) rather than a GPG key ID
+func isSSHSigningKey(key string) bool {
+	return strings.HasSuffix(key, ".pub") || strings.HasPrefix(key, "ssh-") || strings.HasPrefix(key, "key::")
+}
+
+// ConfigureIdentity writes the sandbox's identity and signing settings into the repository's
+// git config, so commits Claude makes and rebased commits use them too
+func (s *Sandbox) ConfigureIdentity(ctx context.Context) error {
+	for _, kv := range s.identityConfig() {
+		cmd := exec.CommandContext(ctx, "git", "config", kv[0], kv[1])
+		cmd.Dir = s.RepoDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to set %s: %w: %s", kv[0], err, string(output))
+		}
+	}
+	return nil
+}
+
 // Push pushes the branch to origin
 func (s *Sandbox) Push(ctx context.Context) error {
 	cmd := exec.CommandContext(ctx, "git", "push", "-u", "origin", s.BranchName)
@@ -102,6 +205,92 @@ func (s *Sandbox) Push(ctx context.Context) error {
 	return nil
 }
 
+// PushForceWithLease pushes the branch after it was rebased, replacing the remote branch
+// only if it is still at origin/<branch> and that commit was part of the local branch
+// before the rebase (--force-if-includes). Commits someone else pushed, whether fetched
+// or not, make the push fail instead of being overwritten.
+func (s *Sandbox) PushForceWithLease(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "git", "push", "--force-with-lease", "--force-if-includes", "-u", "origin", s.BranchName)
+	cmd.Dir = s.RepoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to push: %w: %s", err, string(output))
+	}
+	return nil
+}
+
+// Contains reports whether ref is an ancestor of HEAD
+func (s *Sandbox) Contains(ctx context.Context, ref string) (bool, error) {
+	cmd := exec.CommandContext(ctx, "git", "merge-base", "--is-ancestor", ref, "HEAD")
+	cmd.Dir = s.RepoDir
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check for %s: %w", ref, err)
+	}
+	return true, nil
+}
+
+// ErrRebaseConflict is returned by RebaseOntoRemote when local commits conflict with
+// the remote ones. The rebase is aborted, so the sandbox is left as it was.
+var ErrRebaseConflict = errors.New("rebase conflict")
+
+// FetchRemoteBranch updates origin/<branch> to the branch as it is on the remote
+func (s *Sandbox) FetchRemoteBranch(ctx context.Context, branch string) error {
+	refspec := fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", branch, branch)
+	cmd := exec.CommandContext(ctx, "git", "fetch", "origin", refspec)
+	cmd.Dir = s.RepoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to fetch %s: %w: %s", branch, err, string(output))
+	}
+	return nil
+}
+
+// IsBehindRemote reports whether origin/<branch> has commits HEAD doesn't, as when
+// someone else pushed to the branch. Call FetchRemoteBranch first.
+func (s *Sandbox) IsBehindRemote(ctx context.Context, branch string) (bool, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-list", "--count", "HEAD..origin/"+branch)
+	cmd.Dir = s.RepoDir
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to compare with origin/%s: %w", branch, err)
+	}
+	return strings.TrimSpace(string(output)) != "0", nil
+}
+
+// RebaseOntoRemote replays local commits not yet on origin/<branch> onto it, keeping
+// uncommitted changes. When that conflicts, the rebase is aborted and the conflicting
+// files are returned with ErrRebaseConflict.
+func (s *Sandbox) RebaseOntoRemote(ctx context.Context, branch string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rebase", "--autostash", "origin/"+branch)
+	cmd.Dir = s.RepoDir
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil, nil
+	}
+
+	conflictsCmd := exec.CommandContext(ctx, "git", "diff", "--name-only", "--diff-filter=U")
+	conflictsCmd.Dir = s.RepoDir
+	conflictsOutput, _ := conflictsCmd.Output()
+	var conflicts []string
+	for _, file := range strings.Split(strings.TrimSpace(string(conflictsOutput)), "\n") {
+		if file != "" {
+			conflicts = append(conflicts, file)
+		}
+	}
+
+	abortCmd := exec.CommandContext(ctx, "git", "rebase", "--abort")
+	abortCmd.Dir = s.RepoDir
+	abortCmd.Run()
+
+	if len(conflicts) > 0 {
+		return conflicts, ErrRebaseConflict
+	}
+	return nil, fmt.Errorf("failed to rebase onto origin/%s: %w: %s", branch, err, string(output))
+}
+
 // GetCurrentBranch returns the current branch name
 func (s *Sandbox) GetCurrentBranch(ctx context.Context) (string, error) {
 	cmd := exec.CommandContext(ctx, "git", "branch", "--show-current")
@@ -113,6 +302,17 @@ func (s *Sandbox) GetCurrentBranch(ctx context.Context) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// HeadCommit returns the abbreviated SHA of HEAD
+func (s *Sandbox) HeadCommit(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--short", "HEAD")
+	cmd.Dir = s.RepoDir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 // HasChanges checks if there are uncommitted changes
 func (s *Sandbox) HasChanges(ctx context.Context) (bool, error) {
 	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
@@ -124,6 +324,20 @@ func (s *Sandbox) HasChanges(ctx context.Context) (bool, error) {
 	return len(output) > 0, nil
 }
 
+// RunCommand runs a shell command in the repository and returns its combined output.
+// A failing command returns its output along with the error.
+func (s *Sandbox) RunCommand(ctx context.Context, command string) (string, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = s.RepoDir
+	// Don't wait on pipes held open by processes the command left behind once it is killed
+	cmd.WaitDelay = 10 * time.Second
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("%q failed: %w", command, err)
+	}
+	return string(output), nil
+}
+
 // Cleanup removes the sandbox directory
 func (s *Sandbox) Cleanup() error {
 	return os.RemoveAll(s.Root)
@@ -143,6 +357,9 @@ func (s *Sandbox) RepoPath(relativePath string) string {
 // Manager handles sandbox lifecycle
 type Manager struct {
 	baseDir string
+
+	mu    sync.Mutex
+	inUse map[string]bool // issueID -> sandbox is being processed
 }
 
 // NewManager creates a sandbox manager
@@ -150,11 +367,19 @@ func NewManager(baseDir string) *Manager {
 	if baseDir == "" {
 		baseDir = os.TempDir()
 	}
-	return &Manager{baseDir: filepath.Join(baseDir, "ultra-engineer-sandboxes")}
+	return &Manager{
+		baseDir: filepath.Join(baseDir, "ultra-engineer-sandboxes"),
+		inUse:   make(map[string]bool),
+	}
 }
 
-// GetOrCreate gets an existing sandbox or creates a new one
+// GetOrCreate gets an existing sandbox or creates a new one.
+// The sandbox is marked in use, so CleanupStale skips it until Release is called.
 func (m *Manager) GetOrCreate(repo string, issueID string) (*Sandbox, error) {
+	m.mu.Lock()
+	m.inUse[issueID] = true
+	m.mu.Unlock()
+
 	sandbox := &Sandbox{
 		Root:    filepath.Join(m.baseDir, fmt.Sprintf("issue-%s", issueID)),
 		RepoDir: filepath.Join(m.baseDir, fmt.Sprintf("issue-%s", issueID), "repo"),
@@ -168,6 +393,13 @@ func (m *Manager) GetOrCreate(repo string, issueID string) (*Sandbox, error) {
 	return Create(m.baseDir, repo, issueID)
 }
 
+// Release marks a sandbox as no longer in use
+func (m *Manager) Release(issueID string) {
+	m.mu.Lock()
+	delete(m.inUse, issueID)
+	m.mu.Unlock()
+}
+
 // Get gets an existing sandbox
 func (m *Manager) Get(issueID string) *Sandbox {
 	return &Sandbox{
@@ -181,3 +413,127 @@ func (m *Manager) Get(issueID string) *Sandbox {
 func (m *Manager) CleanupAll() error {
 	return os.RemoveAll(m.baseDir)
 }
+
+// sandboxUsage describes a sandbox on disk for cleanup decisions
+type sandboxUsage struct {
+	issueID  string
+	root     string
+	lastUsed time.Time // Most recent modification anywhere in the sandbox
+	size     int64
+}
+
+// CleanupStale removes sandboxes not modified within maxAge, then removes the
+// least recently used sandboxes until the total size is at most maxTotalBytes.
+// Sandboxes in use are never removed. A zero limit disables that check.
+// Returns the issue IDs of the removed sandboxes.
+func (m *Manager) CleanupStale(maxAge time.Duration, maxTotalBytes int64) ([]string, error) {
+	if maxAge <= 0 && maxTotalBytes <= 0 {
+		return nil, nil
+	}
+
+	roots, err := m.sandboxRoots()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sandboxes []sandboxUsage
+	var total int64
+	for issueID, root := range roots {
+		usage := measureSandbox(root)
+		usage.issueID = issueID
+		sandboxes = append(sandboxes, usage)
+		total += usage.size
+	}
+
+	// Oldest first, so size-based eviction removes the least recently used
+	sort.Slice(sandboxes, func(i, j int) bool {
+		return sandboxes[i].lastUsed.Before(sandboxes[j].lastUsed)
+	})
+
+	var removed []string
+	for _, sb := range sandboxes {
+		if m.inUse[sb.issueID] {
+			continue
+		}
+		stale := maxAge > 0 && time.Since(sb.lastUsed) > maxAge
+		oversize := maxTotalBytes > 0 && total > maxTotalBytes
+		if !stale && !oversize {
+			continue
+		}
+		if err := os.RemoveAll(sb.root); err != nil {
+			return removed, fmt.Errorf("failed to remove sandbox %s: %w", sb.issueID, err)
+		}
+		// Issue IDs like "owner/repo-1" nest under a per-owner directory; drop it once empty
+		if parent := filepath.Dir(sb.root); parent != m.baseDir {
+			os.Remove(parent)
+		}
+		total -= sb.size
+		removed = append(removed, sb.issueID)
+	}
+
+	return removed, nil
+}
+
+// sandboxRoots maps issue IDs to sandbox root directories.
+// A root is the directory holding the "repo" checkout. Issue IDs containing a
+// slash (e.g. "owner/repo-1") produce roots one level deeper than plain IDs.
+func (m *Manager) sandboxRoots() (map[string]string, error) {
+	entries, err := os.ReadDir(m.baseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read sandbox directory: %w", err)
+	}
+
+	roots := make(map[string]string)
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "issue-") {
+			continue
+		}
+		dir := filepath.Join(m.baseDir, entry.Name())
+		prefix := strings.TrimPrefix(entry.Name(), "issue-")
+
+		if isDir(filepath.Join(dir, "repo")) {
+			roots[prefix] = dir
+			continue
+		}
+		children, _ := os.ReadDir(dir)
+		for _, child := range children {
+			if child.IsDir() && isDir(filepath.Join(dir, child.Name(), "repo")) {
+				roots[prefix+"/"+child.Name()] = filepath.Join(dir, child.Name())
+			}
+		}
+	}
+	return roots, nil
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// measureSandbox walks a sandbox to find its size and last modification time
+func measureSandbox(root string) sandboxUsage {
+	usage := sandboxUsage{root: root}
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if info.ModTime().After(usage.lastUsed) {
+			usage.lastUsed = info.ModTime()
+		}
+		if !d.IsDir() {
+			usage.size += info.Size()
+		}
+		return nil
+	})
+	return usage
+}
@@ -0,0 +1,413 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// git runs a git command in dir and fails the test on error
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v: %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// setupOrigin creates a bare origin with one commit on main, plus a separate
+// working clone that can push new commits to it
+func setupOrigin(t *testing.T) (origin, upstream string) {
+	t.Helper()
+	root := t.TempDir()
+	origin = filepath.Join(root, "origin.git")
+	upstream = filepath.Join(root, "upstream")
+
+	git(t, root, "init", "--bare", "-b", "main", origin)
+	git(t, root, "clone", origin, upstream)
+	git(t, upstream, "checkout", "-B", "main")
+	os.WriteFile(filepath.Join(upstream, "README.md"), []byte("v1\n"), 0644)
+	git(t, upstream, "add", "-A")
+	git(t, upstream, "commit", "-m", "v1")
+	git(t, upstream, "push", "-u", "origin", "main")
+	return origin, upstream
+}
+
+func pushCommit(t *testing.T, upstream, content string) string {
+	t.Helper()
+	os.WriteFile(filepath.Join(upstream, "README.md"), []byte(content), 0644)
+	git(t, upstream, "commit", "-am", content)
+	git(t, upstream, "push")
+	return git(t, upstream, "rev-parse", "HEAD")
+}
+
+func TestFetchLatest_FastForwardsCleanSandbox(t *testing.T) {
+	origin, upstream := setupOrigin(t)
+	sb, err := Create(t.TempDir(), "owner/repo", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sb.Clone(context.Background(), origin); err != nil {
+		t.Fatal(err)
+	}
+
+	want := pushCommit(t, upstream, "v2\n")
+
+	if err := sb.FetchLatest(context.Background()); err != nil {
+		t.Fatalf("FetchLatest failed: %v", err)
+	}
+	if got := git(t, sb.RepoDir, "rev-parse", "HEAD"); got != want {
+		t.Errorf("expected HEAD %s after fetch, got %s", want, got)
+	}
+}
+
+func TestFetchLatest_KeepsUncommittedWork(t *testing.T) {
+	origin, upstream := setupOrigin(t)
+	sb, _ := Create(t.TempDir(), "owner/repo", "1")
+	if err := sb.Clone(context.Background(), origin); err != nil {
+		t.Fatal(err)
+	}
+	before := git(t, sb.RepoDir, "rev-parse", "HEAD")
+	os.WriteFile(sb.RepoPath("wip.go"), []byte("package wip\n"), 0644)
+
+	remoteHead := pushCommit(t, upstream, "v2\n")
+
+	if err := sb.FetchLatest(context.Background()); err != nil {
+		t.Fatalf("FetchLatest failed: %v", err)
+	}
+	if got := git(t, sb.RepoDir, "rev-parse", "HEAD"); got != before {
+		t.Errorf("expected HEAD to stay at %s with local changes, got %s", before, got)
+	}
+	if got := git(t, sb.RepoDir, "rev-parse", "origin/main"); got != remoteHead {
+		t.Errorf("expected origin/main to be fetched (%s), got %s", remoteHead, got)
+	}
+	if _, err := os.Stat(sb.RepoPath("wip.go")); err != nil {
+		t.Errorf("expected uncommitted file to survive: %v", err)
+	}
+}
+
+func TestFetchLatest_LocalBranchWithoutUpstream(t *testing.T) {
+	origin, _ := setupOrigin(t)
+	sb, _ := Create(t.TempDir(), "owner/repo", "1")
+	if err := sb.Clone(context.Background(), origin); err != nil {
+		t.Fatal(err)
+	}
+	if err := sb.CreateBranch(context.Background(), "feat/local"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sb.FetchLatest(context.Background()); err != nil {
+		t.Errorf("expected no error for a branch without upstream, got %v", err)
+	}
+}
+
+// setupBranch clones origin into a sandbox on branch "feat", pushed with one commit,
+// and checks the branch out in upstream so commits can be pushed to it by hand
+func setupBranch(t *testing.T) (sb *Sandbox, upstream string) {
+	t.Helper()
+	// Rebasing commits needs a committer identity
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	origin, upstream := setupOrigin(t)
+	sb, _ = Create(t.TempDir(), "owner/repo", "1")
+	if err := sb.Clone(context.Background(), origin); err != nil {
+		t.Fatal(err)
+	}
+	sb.CreateBranch(context.Background(), "feat")
+	os.WriteFile(sb.RepoPath("feature.go"), []byte("package feature\n"), 0644)
+	git(t, sb.RepoDir, "add", "-A")
+	git(t, sb.RepoDir, "commit", "-m", "feature")
+	git(t, sb.RepoDir, "push", "-u", "origin", "feat")
+
+	git(t, upstream, "fetch", "origin")
+	git(t, upstream, "checkout", "feat")
+	return sb, upstream
+}
+
+func TestRebaseOntoRemote_PicksUpManualPush(t *testing.T) {
+	sb, upstream := setupBranch(t)
+	ctx := context.Background()
+
+	if err := sb.FetchRemoteBranch(ctx, "feat"); err != nil {
+		t.Fatalf("FetchRemoteBranch failed: %v", err)
+	}
+	if behind, err := sb.IsBehindRemote(ctx, "feat"); err != nil || behind {
+		t.Fatalf("expected the sandbox to be up to date, got behind=%v err=%v", behind, err)
+	}
+
+	manual := pushCommit(t, upstream, "fixed by hand\n")
+	os.WriteFile(sb.RepoPath("more.go"), []byte("package feature\n"), 0644)
+	git(t, sb.RepoDir, "add", "-A")
+	git(t, sb.RepoDir, "commit", "-m", "more")
+
+	if err := sb.FetchRemoteBranch(ctx, "feat"); err != nil {
+		t.Fatalf("FetchRemoteBranch failed: %v", err)
+	}
+	if behind, err := sb.IsBehindRemote(ctx, "feat"); err != nil || !behind {
+		t.Fatalf("expected the sandbox to be behind, got behind=%v err=%v", behind, err)
+	}
+	if conflicts, err := sb.RebaseOntoRemote(ctx, "feat"); err != nil {
+		t.Fatalf("RebaseOntoRemote failed: %v %v", conflicts, err)
+	}
+	if got := git(t, sb.RepoDir, "rev-parse", "HEAD~1"); got != manual {
+		t.Errorf("expected the local commit on top of %s, got parent %s", manual, got)
+	}
+}
+
+func TestRebaseOntoRemote_Conflict(t *testing.T) {
+	sb, upstream := setupBranch(t)
+	ctx := context.Background()
+
+	pushCommit(t, upstream, "theirs\n")
+	os.WriteFile(sb.RepoPath("README.md"), []byte("ours\n"), 0644)
+	git(t, sb.RepoDir, "commit", "-am", "ours")
+	before := git(t, sb.RepoDir, "rev-parse", "HEAD")
+
+	sb.FetchRemoteBranch(ctx, "feat")
+	conflicts, err := sb.RebaseOntoRemote(ctx, "feat")
+	if !errors.Is(err, ErrRebaseConflict) || len(conflicts) != 1 || conflicts[0] != "README.md" {
+		t.Fatalf("expected a conflict in README.md, got %v %v", conflicts, err)
+	}
+	if got := git(t, sb.RepoDir, "rev-parse", "HEAD"); got != before {
+		t.Errorf("expected the rebase to be aborted at %s, got %s", before, got)
+	}
+}
+
+// rebaseOntoNewMain pushes a commit to main and rebases the sandbox's branch onto it
+func rebaseOntoNewMain(t *testing.T, sb *Sandbox, upstream string) {
+	t.Helper()
+	git(t, upstream, "checkout", "main")
+	os.WriteFile(filepath.Join(upstream, "main.go"), []byte("package main\n"), 0644)
+	git(t, upstream, "add", "-A")
+	git(t, upstream, "commit", "-m", "main moved")
+	git(t, upstream, "push")
+	git(t, upstream, "checkout", "feat")
+
+	git(t, sb.RepoDir, "fetch", "origin", "main")
+	git(t, sb.RepoDir, "rebase", "origin/main")
+}
+
+func TestPushForceWithLease_AfterRebase(t *testing.T) {
+	sb, upstream := setupBranch(t)
+	rebaseOntoNewMain(t, sb, upstream)
+
+	if err := sb.Push(context.Background()); err == nil {
+		t.Fatal("expected a plain push of the rebased branch to be rejected")
+	}
+	if err := sb.PushForceWithLease(context.Background()); err != nil {
+		t.Fatalf("PushForceWithLease failed: %v", err)
+	}
+	if got, want := git(t, upstream, "ls-remote", "origin", "feat"), git(t, sb.RepoDir, "rev-parse", "HEAD"); !strings.HasPrefix(got, want) {
+		t.Errorf("expected the rebased branch %s on the remote, got %s", want, got)
+	}
+}
+
+func TestPushForceWithLease_KeepsCommitsPushedByOthers(t *testing.T) {
+	sb, upstream := setupBranch(t)
+	manual := pushCommit(t, upstream, "fixed by hand\n")
+	rebaseOntoNewMain(t, sb, upstream)
+
+	// Fetched, so a plain --force-with-lease would overwrite it
+	if err := sb.FetchRemoteBranch(context.Background(), "feat"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sb.PushForceWithLease(context.Background()); err == nil {
+		t.Fatal("expected the push to be rejected")
+	}
+	if got := git(t, upstream, "ls-remote", "origin", "feat"); !strings.HasPrefix(got, manual) {
+		t.Errorf("expected the manual commit %s to stay on the remote, got %s", manual, got)
+	}
+}
+
+func TestCommitArgs_Identity(t *testing.T) {
+	sb := &Sandbox{AuthorName: "Ultra Bot", AuthorEmail: "bot@example.com"}
+	got := strings.Join(sb.commitArgs("msg"), " ")
+	if got != "-c user.name=Ultra Bot -c user.email=bot@example.com commit -m msg" {
+		t.Errorf("expected identity flags before commit, got %q", got)
+	}
+	if got := strings.Join((&Sandbox{}).commitArgs("msg"), " "); got != "commit -m msg" {
+		t.Errorf("expected no identity flags when unset, got %q", got)
+	}
+}
+
+func TestCommitArgs_Signing(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"", "-c commit.gpgsign=true commit -S -m msg"},
+		{"3AA5C34371567BD2", "-c commit.gpgsign=true -c user.signingkey=3AA5C34371567BD2 commit -S -m msg"},
+		{"/keys/bot.pub", "-c commit.gpgsign=true -c gpg.format=ssh -c user.signingkey=/keys/bot.pub commit -S -m msg"},
+		{"ssh-ed25519 AAAAC3Nza", "-c commit.gpgsign=true -c gpg.format=ssh -c user.signingkey=ssh-ed25519 AAAAC3Nza commit -S -m msg"},
+	}
+	for _, tt := range tests {
+		sb := &Sandbox{SignCommits: true, SigningKey: tt.key}
+		if got := strings.Join(sb.commitArgs("msg"), " "); got != tt.want {
+			t.Errorf("key %q: expected %q, got %q", tt.key, tt.want, got)
+		}
+	}
+}
+
+func TestCommit_SigningFailureExplainsFix(t *testing.T) {
+	origin, _ := setupOrigin(t)
+	sb, _ := Create(t.TempDir(), "owner/repo", "1")
+	if err := sb.Clone(context.Background(), origin); err != nil {
+		t.Fatal(err)
+	}
+	sb.AuthorName = "Ultra Bot"
+	sb.AuthorEmail = "bot@example.com"
+	sb.SignCommits = true
+	sb.SigningKey = filepath.Join(t.TempDir(), "missing.pub")
+
+	os.WriteFile(sb.RepoPath("new.go"), []byte("package x\n"), 0644)
+	err := sb.Commit(context.Background(), "add new.go")
+	if err == nil || !strings.Contains(err.Error(), "check git.signing_key") {
+		t.Errorf("expected guidance about the signing key, got %v", err)
+	}
+}
+
+func TestCommit_WithoutHostIdentity(t *testing.T) {
+	// A clean environment: no global git config to take an identity from
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	origin, _ := setupOrigin(t)
+	sb, _ := Create(t.TempDir(), "owner/repo", "1")
+	if err := sb.Clone(context.Background(), origin); err != nil {
+		t.Fatal(err)
+	}
+	sb.AuthorName = "Ultra Bot"
+	sb.AuthorEmail = "bot@example.com"
+	if err := sb.ConfigureIdentity(context.Background()); err != nil {
+		t.Fatalf("ConfigureIdentity failed: %v", err)
+	}
+
+	os.WriteFile(sb.RepoPath("new.go"), []byte("package x\n"), 0644)
+	if err := sb.Commit(context.Background(), "add new.go"); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if got := git(t, sb.RepoDir, "log", "-1", "--format=%an <%ae>"); got != "Ultra Bot <bot@example.com>" {
+		t.Errorf("expected the configured author, got %q", got)
+	}
+	if got := git(t, sb.RepoDir, "config", "user.email"); got != "bot@example.com" {
+		t.Errorf("expected the identity in the repository config for Claude's commits, got %q", got)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	sb := &Sandbox{RepoDir: t.TempDir()}
+	os.WriteFile(sb.RepoPath("go.mod"), []byte("module x\n"), 0644)
+
+	out, err := sb.RunCommand(context.Background(), "cat go.mod")
+	if err != nil || out != "module x\n" {
+		t.Errorf("expected the command to run in the repository, got %q, %v", out, err)
+	}
+
+	out, err = sb.RunCommand(context.Background(), "echo broken >&2; exit 3")
+	if err == nil {
+		t.Fatal("expected an error for a failing command")
+	}
+	if out != "broken\n" {
+		t.Errorf("expected stderr in the output, got %q", out)
+	}
+}
+
+func TestRunCommand_Timeout(t *testing.T) {
+	sb := &Sandbox{RepoDir: t.TempDir()}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := sb.RunCommand(ctx, "exec sleep 10"); err == nil {
+		t.Fatal("expected an error when the command outlives the context")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the command to be killed, took %v", elapsed)
+	}
+}
+
+// makeSandbox creates a sandbox with a file of the given size, last modified at modTime
+func makeSandbox(t *testing.T, m *Manager, issueID string, size int, modTime time.Time) *Sandbox {
+	t.Helper()
+	sb, err := m.GetOrCreate("owner/repo", issueID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Release(issueID)
+	os.MkdirAll(sb.RepoDir, 0755)
+	os.WriteFile(sb.RepoPath("data"), make([]byte, size), 0644)
+
+	filepath.WalkDir(sb.Root, func(path string, d fs.DirEntry, err error) error {
+		return os.Chtimes(path, modTime, modTime)
+	})
+	return sb
+}
+
+func TestCleanupStale_RemovesOldSandboxes(t *testing.T) {
+	m := NewManager(t.TempDir())
+	old := makeSandbox(t, m, "owner/repo-1", 10, time.Now().Add(-48*time.Hour))
+	recent := makeSandbox(t, m, "owner/repo-2", 10, time.Now().Add(-time.Hour))
+
+	removed, err := m.CleanupStale(24*time.Hour, 0)
+	if err != nil {
+		t.Fatalf("CleanupStale failed: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "owner/repo-1" {
+		t.Errorf("expected only owner/repo-1 to be removed, got %v", removed)
+	}
+	if old.Exists() {
+		t.Error("expected old sandbox to be removed")
+	}
+	if !recent.Exists() {
+		t.Error("expected recent sandbox to be kept")
+	}
+}
+
+func TestCleanupStale_SkipsSandboxesInUse(t *testing.T) {
+	m := NewManager(t.TempDir())
+	sb := makeSandbox(t, m, "7", 10, time.Now().Add(-48*time.Hour))
+	if _, err := m.GetOrCreate("owner/repo", "7"); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, _ := m.CleanupStale(24*time.Hour, 0)
+	if len(removed) != 0 || !sb.Exists() {
+		t.Errorf("expected sandbox in use to be kept, removed %v", removed)
+	}
+
+	m.Release("7")
+	removed, _ = m.CleanupStale(24*time.Hour, 0)
+	if len(removed) != 1 || sb.Exists() {
+		t.Errorf("expected released sandbox to be removed, removed %v", removed)
+	}
+}
+
+func TestCleanupStale_EvictsLeastRecentlyUsedOverSize(t *testing.T) {
+	m := NewManager(t.TempDir())
+	oldest := makeSandbox(t, m, "1", 100, time.Now().Add(-3*time.Hour))
+	middle := makeSandbox(t, m, "2", 100, time.Now().Add(-2*time.Hour))
+	newest := makeSandbox(t, m, "3", 100, time.Now().Add(-time.Hour))
+
+	removed, err := m.CleanupStale(0, 250)
+	if err != nil {
+		t.Fatalf("CleanupStale failed: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "1" {
+		t.Errorf("expected only the oldest sandbox to be removed, got %v", removed)
+	}
+	if oldest.Exists() || !middle.Exists() || !newest.Exists() {
+		t.Error("unexpected sandboxes removed")
+	}
+}
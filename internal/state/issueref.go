@@ -0,0 +1,83 @@
+package state
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+)
+
+// IssueRef identifies an issue that another issue depends on. Repo is empty for an
+// issue in the same repository as the one holding the reference.
+type IssueRef struct {
+	Repo   string `json:"repo,omitempty"`
+	Number int    `json:"number"`
+}
+
+// String formats the reference as "#123", or "owner/repo#123" for another repository
+func (r IssueRef) String() string {
+	return fmt.Sprintf("%s#%d", r.Repo, r.Number)
+}
+
+// Resolve returns the reference with its repository filled in, treating an empty
+// Repo as repo
+func (r IssueRef) Resolve(repo string) IssueRef {
+	if r.Repo == "" {
+		r.Repo = repo
+	}
+	return r
+}
+
+// RelativeTo returns the reference as seen from repo: references into repo lose
+// their repository, so they compare equal to plain "#123" references
+func (r IssueRef) RelativeTo(repo string) IssueRef {
+	if r.Repo == repo {
+		r.Repo = ""
+	}
+	return r
+}
+
+// CompareIssueRefs orders references by repository, then number
+func CompareIssueRefs(a, b IssueRef) int {
+	if c := cmp.Compare(a.Repo, b.Repo); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Number, b.Number)
+}
+
+// MarshalJSON writes same-repository references as plain numbers, the format state
+// used before cross-repository references were supported
+func (r IssueRef) MarshalJSON() ([]byte, error) {
+	if r.Repo == "" {
+		return json.Marshal(r.Number)
+	}
+	type plain IssueRef
+	return json.Marshal(plain(r))
+}
+
+// UnmarshalJSON accepts both a plain issue number and a {"repo", "number"} object
+func (r *IssueRef) UnmarshalJSON(data []byte) error {
+	var number int
+	if err := json.Unmarshal(data, &number); err == nil {
+		*r = IssueRef{Number: number}
+		return nil
+	}
+	type plain IssueRef
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return fmt.Errorf("invalid issue reference %s: %w", data, err)
+	}
+	*r = IssueRef(p)
+	return nil
+}
+
+// SameRepoRefs converts issue numbers to references within the same repository
+func SameRepoRefs(numbers []int) []IssueRef {
+	if len(numbers) == 0 {
+		return nil
+	}
+	refs := make([]IssueRef, len(numbers))
+	for i, n := range numbers {
+		refs[i] = IssueRef{Number: n}
+	}
+	return refs
+}
@@ -0,0 +1,61 @@
+package state
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParse_PlainIssueNumbers(t *testing.T) {
+	// State written before cross-repository references stores plain numbers
+	body := stateMarkerStart + `
+{"current_phase": "new", "depends_on": [1, 2], "blocked_by": [2]}
+` + stateMarkerEnd
+
+	st, err := Parse(body)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if want := SameRepoRefs([]int{1, 2}); !slices.Equal(st.DependsOn, want) {
+		t.Errorf("expected depends_on %v, got %v", want, st.DependsOn)
+	}
+	if want := SameRepoRefs([]int{2}); !slices.Equal(st.BlockedBy, want) {
+		t.Errorf("expected blocked_by %v, got %v", want, st.BlockedBy)
+	}
+}
+
+func TestSerialize_RoundTripsIssueRefs(t *testing.T) {
+	st := NewState()
+	st.DependsOn = []IssueRef{{Number: 3}, {Repo: "owner/lib", Number: 7}}
+
+	body, err := st.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	// Same-repository references keep the plain number format
+	if !strings.Contains(body, "3,") || !strings.Contains(body, `"repo": "owner/lib"`) {
+		t.Errorf("unexpected depends_on encoding:\n%s", body)
+	}
+
+	parsed, err := Parse(body)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if !slices.Equal(parsed.DependsOn, st.DependsOn) {
+		t.Errorf("expected %v, got %v", st.DependsOn, parsed.DependsOn)
+	}
+}
+
+func TestIssueRef_ResolveAndRelativeTo(t *testing.T) {
+	ref := IssueRef{Number: 4}
+	abs := ref.Resolve("owner/repo")
+	if abs.String() != "owner/repo#4" {
+		t.Errorf("expected owner/repo#4, got %s", abs)
+	}
+	if got := abs.RelativeTo("owner/repo"); got != ref {
+		t.Errorf("expected %s, got %s", ref, got)
+	}
+	if got := abs.RelativeTo("owner/other"); got != abs {
+		t.Errorf("expected %s to keep its repo, got %s", abs, got)
+	}
+}
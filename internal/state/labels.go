@@ -0,0 +1,52 @@
+package state
+
+import "strings"
+
+// LabelSpec is the color and description a label is created with when the repository
+// doesn't have it yet
+type LabelSpec struct {
+	Color       string // Six hex digits, without "#"
+	Description string
+}
+
+// DefaultLabelColor is the color of created labels that have no spec
+const DefaultLabelColor = "0052cc"
+
+// phaseLabelSpecs sets the phase labels apart at a glance: waiting on the user in
+// purple and yellow, work in progress in blue, and the outcomes in green, red and light red
+var phaseLabelSpecs = map[Phase]LabelSpec{
+	PhaseNew:          {Color: "ededed", Description: "Picked up by Ultra Engineer"},
+	PhaseQuestions:    {Color: "d876e3", Description: "Waiting for answers to clarifying questions"},
+	PhasePlanning:     {Color: "1d76db", Description: "Writing the implementation plan"},
+	PhaseApproval:     {Color: "fbca04", Description: "Waiting for the plan to be approved"},
+	PhaseImplementing: {Color: "0052cc", Description: "Implementing the approved plan"},
+	PhaseReview:       {Color: "c5def5", Description: "Pull request open for review"},
+	PhaseCompleted:    {Color: "0e8a16", Description: "Completed by Ultra Engineer"},
+	PhaseFailed:       {Color: "b60205", Description: "Ultra Engineer could not complete the issue"},
+	PhaseAborted:      {Color: "e99695", Description: "Stopped by a user before Ultra Engineer completed the issue"},
+}
+
+// LabelSpecs returns the specs of the phase labels with colors overridden by colors
+// (label name -> hex color, "#" optional). Overrides may also name other labels the
+// bot adds, such as the trigger label.
+func LabelSpecs(colors map[string]string) map[string]LabelSpec {
+	specs := make(map[string]LabelSpec, len(phaseLabelSpecs)+len(colors))
+	for phase, spec := range phaseLabelSpecs {
+		specs[phase.Label()] = spec
+	}
+	for name, color := range colors {
+		spec := specs[name]
+		spec.Color = strings.ToLower(strings.TrimPrefix(color, "#"))
+		specs[name] = spec
+	}
+	return specs
+}
+
+// LabelSpecFor returns the spec the label name is created with
+func LabelSpecFor(specs map[string]LabelSpec, name string) LabelSpec {
+	spec := specs[name]
+	if spec.Color == "" {
+		spec.Color = DefaultLabelColor
+	}
+	return spec
+}
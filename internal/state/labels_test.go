@@ -0,0 +1,20 @@
+package state
+
+import "testing"
+
+func TestLabelSpecs(t *testing.T) {
+	specs := LabelSpecs(map[string]string{"phase:failed": "#FF0000", "ai-implement": "00ff00"})
+
+	if got := specs["phase:failed"]; got.Color != "ff0000" || got.Description == "" {
+		t.Errorf("expected the override to keep the description, got %+v", got)
+	}
+	if got := specs["ai-implement"]; got.Color != "00ff00" {
+		t.Errorf("expected a spec for the overridden trigger label, got %+v", got)
+	}
+	if specs["phase:questions"].Color == specs["phase:completed"].Color {
+		t.Error("expected phases to have distinct colors")
+	}
+	if got := LabelSpecFor(specs, "paused"); got.Color != DefaultLabelColor || got.Description != "" {
+		t.Errorf("expected the default color for labels without a spec, got %+v", got)
+	}
+}
@@ -22,6 +22,7 @@ const (
 	PhaseReview       Phase = "review"
 	PhaseCompleted    Phase = "completed"
 	PhaseFailed       Phase = "failed"
+	PhaseAborted      Phase = "aborted" // Stopped by a user, with /abort or the abort command
 )
 
 // PhaseLabel returns the label name for a phase
@@ -29,6 +30,12 @@ func (p Phase) Label() string {
 	return "phase:" + string(p)
 }
 
+// Stopped reports whether processing ended without completing, because the issue failed
+// or was aborted. /retry resumes a stopped issue.
+func (p Phase) Stopped() bool {
+	return p == PhaseFailed || p == PhaseAborted
+}
+
 // State represents the hidden state stored in issue comments
 type State struct {
 	SessionID       string           `json:"session_id,omitempty"`
@@ -38,6 +45,7 @@ type State struct {
 	PlanVersion     int              `json:"plan_version,omitempty"`
 	ReviewIteration int              `json:"review_iteration,omitempty"`
 	PRNumber        int              `json:"pr_number,omitempty"`
+	PRDraft         bool             `json:"pr_draft,omitempty"` // PR is still a draft, to be marked ready once CI passes
 	BranchName      string           `json:"branch_name,omitempty"`
 	LastUpdated     time.Time        `json:"last_updated"`
 	LastCommentID   int64            `json:"last_comment_id,omitempty"`   // Deprecated: use LastCommentTime
@@ -49,18 +57,57 @@ type State struct {
 	LastPRCommentTime time.Time `json:"last_pr_comment_time,omitempty"`
 
 	// CI tracking
-	CIFixAttempts   int       `json:"ci_fix_attempts,omitempty"`
-	LastCIStatus    string    `json:"last_ci_status,omitempty"`     // stores CIStatus as string for JSON
-	CIWaitStartTime time.Time `json:"ci_wait_start_time,omitempty"` // when we started waiting for CI
+	CIFixAttempts   int           `json:"ci_fix_attempts,omitempty"`
+	LastCIStatus    string        `json:"last_ci_status,omitempty"`     // stores CIStatus as string for JSON
+	CIWaitStartTime time.Time     `json:"ci_wait_start_time,omitempty"` // when we started waiting for CI
+	CINextCheckTime time.Time     `json:"ci_next_check_time,omitempty"` // pending CI isn't checked again before this
+	CICheckInterval time.Duration `json:"ci_check_interval,omitempty"`  // current interval between checks of pending CI
+	CIChecks        string        `json:"ci_checks,omitempty"`          // check statuses at the last check, to spot changes
+
+	// Clone tracking
+	CloneAttempts int `json:"clone_attempts,omitempty"` // Consecutive transient clone failures
+
+	// Claude tracking
+	ClaudeFailures int     `json:"claude_failures,omitempty"` // Consecutive failed Claude invocations, including retries
+	TokensIn       int64   `json:"tokens_in,omitempty"`       // Input tokens of all Claude invocations, cached ones included
+	TokensOut      int64   `json:"tokens_out,omitempty"`      // Output tokens of all Claude invocations
+	CostUSD        float64 `json:"cost_usd,omitempty"`        // Cost of all Claude invocations in US dollars
+
+	// Retry tracking, for defaults.max_retries and defaults.retry_interval
+	RetryCount    int       `json:"retry_count,omitempty"`     // /retry commands accepted so far
+	LastRetryTime time.Time `json:"last_retry_time,omitempty"` // When the last accepted /retry was posted
+
+	// Merge tracking
+	MergeBlockedReason string `json:"merge_blocked_reason,omitempty"` // Last reported reason the provider refused the merge
 
 	// Dependency tracking for concurrent issue processing
-	DependsOn     []int  `json:"depends_on,omitempty"`     // Issue numbers this issue depends on
-	BlockedBy     []int  `json:"blocked_by,omitempty"`     // Currently blocking issue numbers
-	FailureReason string `json:"failure_reason,omitempty"` // "merge_conflict", "dependency_cycle", "dependency_failed", etc.
+	DependsOn     []IssueRef `json:"depends_on,omitempty"`     // Issues this issue depends on
+	BlockedBy     []IssueRef `json:"blocked_by,omitempty"`     // Currently blocking issues
+	FailureReason string     `json:"failure_reason,omitempty"` // "merge_conflict", "dependency_cycle", "dependency_failed", etc.
+	StoppedPhase  Phase      `json:"stopped_phase,omitempty"`  // Phase the issue failed or was aborted in, for /retry to resume from
+
+	// Set when dependencies were declared with /depends-on or /no-deps; they are then no
+	// longer detected, and only later commands change them
+	DependencyCommandTime time.Time `json:"dependency_command_time,omitempty"`
+
+	// Issue edit tracking, to offer a re-plan when requirements change after Q&A
+	LastIssueUpdate  time.Time `json:"last_issue_update,omitempty"`  // Issue's UpdatedAt when it was last checked for edits
+	IssueHash        string    `json:"issue_hash,omitempty"`         // Hash of the title and body as last checked
+	ReplanPromptTime time.Time `json:"replan_prompt_time,omitempty"` // When a re-plan was offered after an edit; zero when none is pending
+
+	// Re-planning from scratch (/replan, or an accepted offer after an edit)
+	PlanOutdated   bool   `json:"plan_outdated,omitempty"`   // Planning discards the sandbox plan and writes a new one
+	ReplanGuidance string `json:"replan_guidance,omitempty"` // User guidance for the new plan, from /replan
 
 	// Progress tracking
-	StatusCommentID int64    `json:"status_comment_id,omitempty"` // ID of the status comment to update
-	StatusHistory   []string `json:"status_history,omitempty"`    // Status entries as "HH:MM:SS|message"
+	StatusCommentID   int64    `json:"status_comment_id,omitempty"`   // ID of the status comment to update
+	StatusHistory     []string `json:"status_history,omitempty"`      // Status entries as "HH:MM:SS|message"
+	QuestionCommentID int64    `json:"question_comment_id,omitempty"` // ID of the questions comment edited each round (progress.single_question_comment)
+
+	// Phase timing for ETA estimation
+	PhaseTimings   map[Phase]time.Duration `json:"phase_timings,omitempty"`    // Time spent in each finished phase
+	PhaseStartedAt time.Time               `json:"phase_started_at,omitempty"` // When the current phase started
+	StartedAt      time.Time               `json:"started_at,omitempty"`       // When processing first moved past PhaseNew
 }
 
 const (
@@ -71,11 +118,16 @@ const (
 
 var stateRegex = regexp.MustCompile(`<!-- ultra-engineer-state\s*([\s\S]*?)\s*-->`)
 
+// trailingStateRegex matches a state block that starts on its own line and ends the body
+var trailingStateRegex = regexp.MustCompile(`(?:^|\n)<!-- ultra-engineer-state\s[\s\S]*?-->$`)
+
 // NewState creates a new state for an issue
 func NewState() *State {
+	now := time.Now()
 	return &State{
-		CurrentPhase: PhaseNew,
-		LastUpdated:  time.Now(),
+		CurrentPhase:   PhaseNew,
+		LastUpdated:    now,
+		PhaseStartedAt: now,
 	}
 }
 
@@ -163,13 +215,32 @@ func ContainsState(body string) bool {
 	return stateRegex.MatchString(body)
 }
 
-// IsBotComment checks if a comment was made by the bot (has bot marker or state)
+// IsBotComment checks if a comment was made by the bot. The bot marker must be the
+// last line, or the comment must end with a state block. A user reply that quotes
+// one of our comments contains the marker elsewhere and is not treated as ours.
 func IsBotComment(body string) bool {
-	return strings.Contains(body, BotMarker) || stateRegex.MatchString(body)
+	body = strings.TrimRight(body, " \t\r\n")
+	if body == BotMarker || strings.HasSuffix(body, "\n"+BotMarker) {
+		return true
+	}
+	return trailingStateRegex.MatchString(body)
 }
 
-// AddBotMarker adds the bot marker to a comment body
+// commentFooter is placed above the bot marker by AddBotMarker
+var commentFooter string
+
+// SetCommentFooter sets the footer added to every bot comment. Call it once at startup,
+// before any comments are posted. An empty footer disables it.
+func SetCommentFooter(footer string) {
+	commentFooter = strings.TrimSpace(footer)
+}
+
+// AddBotMarker adds the configured footer and the bot marker to a comment body.
+// The marker always comes last, so detection does not depend on the footer text.
 func AddBotMarker(body string) string {
+	if commentFooter != "" {
+		body += "\n\n" + commentFooter
+	}
 	return body + "\n\n" + BotMarker
 }
 
@@ -179,20 +250,53 @@ func RemoveState(body string) string {
 }
 
 // SetPhase updates the phase and records the time
+// Time spent in the previous phase is added to PhaseTimings
 func (s *State) SetPhase(phase Phase) {
+	now := time.Now()
+	if phase != s.CurrentPhase {
+		if !s.PhaseStartedAt.IsZero() {
+			if s.PhaseTimings == nil {
+				s.PhaseTimings = make(map[Phase]time.Duration)
+			}
+			s.PhaseTimings[s.CurrentPhase] += now.Sub(s.PhaseStartedAt)
+		}
+		s.PhaseStartedAt = now
+	}
+	if s.StartedAt.IsZero() && s.CurrentPhase == PhaseNew && phase != PhaseNew {
+		s.StartedAt = now
+	}
 	s.CurrentPhase = phase
-	s.LastUpdated = time.Now()
+	s.LastUpdated = now
+}
+
+// Stop moves to a stopped phase, PhaseFailed or PhaseAborted, remembering the phase
+// processing stopped in
+func (s *State) Stop(phase Phase) {
+	if !s.CurrentPhase.Stopped() {
+		s.StoppedPhase = s.CurrentPhase
+	}
+	s.SetPhase(phase)
 }
 
 // SetPhaseWithRollback updates the phase and returns a rollback function
-// that restores the previous phase and timestamp if called
+// that restores the previous phase, timestamp and phase timing if called
 func (s *State) SetPhaseWithRollback(newPhase Phase) (rollback func()) {
 	oldPhase := s.CurrentPhase
 	oldUpdated := s.LastUpdated
+	oldPhaseStartedAt := s.PhaseStartedAt
+	oldStartedAt := s.StartedAt
+	oldTiming, hadTiming := s.PhaseTimings[oldPhase]
 	s.SetPhase(newPhase)
 	return func() {
 		s.CurrentPhase = oldPhase
 		s.LastUpdated = oldUpdated
+		s.PhaseStartedAt = oldPhaseStartedAt
+		s.StartedAt = oldStartedAt
+		if hadTiming {
+			s.PhaseTimings[oldPhase] = oldTiming
+		} else {
+			delete(s.PhaseTimings, oldPhase)
+		}
 	}
 }
 
@@ -233,6 +337,7 @@ func NewLabels() *Labels {
 			PhaseReview,
 			PhaseCompleted,
 			PhaseFailed,
+			PhaseAborted,
 		},
 	}
 }
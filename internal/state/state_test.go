@@ -0,0 +1,165 @@
+package state
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetPhase_RecordsPhaseTimings(t *testing.T) {
+	st := NewState()
+	st.PhaseStartedAt = time.Now().Add(-2 * time.Minute)
+
+	st.SetPhase(PhasePlanning)
+
+	if got := st.PhaseTimings[PhaseNew]; got < 2*time.Minute {
+		t.Errorf("expected at least 2m recorded for new phase, got %v", got)
+	}
+	if time.Since(st.PhaseStartedAt) > time.Second {
+		t.Errorf("expected phase start to be reset, got %v", st.PhaseStartedAt)
+	}
+
+	// Re-setting the same phase must not record time
+	st.SetPhase(PhasePlanning)
+	if _, ok := st.PhaseTimings[PhasePlanning]; ok {
+		t.Error("expected no timing for a phase that has not finished")
+	}
+}
+
+func TestSetPhaseWithRollback_RestoresTimings(t *testing.T) {
+	st := NewState()
+	started := time.Now().Add(-time.Minute)
+	st.PhaseStartedAt = started
+
+	rollback := st.SetPhaseWithRollback(PhaseQuestions)
+	rollback()
+
+	if st.CurrentPhase != PhaseNew {
+		t.Errorf("expected phase %s, got %s", PhaseNew, st.CurrentPhase)
+	}
+	if _, ok := st.PhaseTimings[PhaseNew]; ok {
+		t.Error("expected rolled-back timing to be removed")
+	}
+	if !st.PhaseStartedAt.Equal(started) {
+		t.Errorf("expected phase start restored, got %v", st.PhaseStartedAt)
+	}
+	if !st.StartedAt.IsZero() {
+		t.Errorf("expected processing start to be cleared, got %v", st.StartedAt)
+	}
+}
+
+func TestSetPhase_SetsStartedAtOnce(t *testing.T) {
+	st := NewState()
+	if !st.StartedAt.IsZero() {
+		t.Fatal("expected no start time before leaving the new phase")
+	}
+
+	st.SetPhase(PhaseQuestions)
+	first := st.StartedAt
+	if first.IsZero() {
+		t.Fatal("expected start time when leaving the new phase")
+	}
+
+	st.SetPhase(PhasePlanning)
+	if !st.StartedAt.Equal(first) {
+		t.Errorf("expected start time to be kept, got %v", st.StartedAt)
+	}
+}
+
+func TestAddBotMarker_Footer(t *testing.T) {
+	SetCommentFooter("Posted by ultra-engineer v1.2.3 (run abc123)")
+	defer SetCommentFooter("")
+
+	body := AddBotMarker("Hello")
+
+	if !strings.Contains(body, "Posted by ultra-engineer v1.2.3 (run abc123)") {
+		t.Errorf("expected footer in body, got %q", body)
+	}
+	if !strings.HasSuffix(body, BotMarker) {
+		t.Errorf("expected marker to stay last, got %q", body)
+	}
+	if !IsBotComment(body) {
+		t.Error("expected comment with footer to be detected as a bot comment")
+	}
+}
+
+func TestAddBotMarker_NoFooter(t *testing.T) {
+	if got, want := AddBotMarker("Hello"), "Hello\n\n"+BotMarker; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestIsBotComment(t *testing.T) {
+	st := NewState()
+	withState, err := st.AppendToBody("State saved during shutdown")
+	if err != nil {
+		t.Fatalf("AppendToBody: %v", err)
+	}
+	progress, err := st.AppendToBody("## Progress")
+	if err != nil {
+		t.Fatalf("AppendToBody: %v", err)
+	}
+	quotedProgress := "> " + strings.ReplaceAll(progress, "\n", "\n> ")
+
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{"marker", AddBotMarker("Questions"), true},
+		{"marker with trailing whitespace", AddBotMarker("Questions") + "\n\n", true},
+		{"state block", withState, true},
+		{"progress with state and marker", AddBotMarker(progress), true},
+		{"plain user comment", "Looks good to me", false},
+		{"user reply quoting marker", "> Questions\n>\n> " + BotMarker + "\n\nThe answer is 42", false},
+		{"user reply with marker mid-body", "Answer one\n" + BotMarker + "\nAnswer two", false},
+		{"user reply quoting state", quotedProgress + "\n\nPlease retry", false},
+		{"user reply ending with quoted marker", "The answer is 42\n\n> " + BotMarker, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsBotComment(tt.body); got != tt.want {
+				t.Errorf("IsBotComment(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPhaseLabelsToRemove_FailedAndAborted(t *testing.T) {
+	labels := NewLabels()
+
+	toAborted := labels.GetPhaseLabelsToRemove(PhaseAborted)
+	if !slices.Contains(toAborted, "phase:failed") || slices.Contains(toAborted, "phase:aborted") {
+		t.Errorf("expected aborting to replace phase:failed and keep phase:aborted, got %v", toAborted)
+	}
+	toImplementing := labels.GetPhaseLabelsToRemove(PhaseImplementing)
+	if !slices.Contains(toImplementing, "phase:failed") || !slices.Contains(toImplementing, "phase:aborted") {
+		t.Errorf("expected a retry to remove both stopped labels, got %v", toImplementing)
+	}
+}
+
+func TestPhaseStopped(t *testing.T) {
+	for _, p := range []Phase{PhaseFailed, PhaseAborted} {
+		if !p.Stopped() {
+			t.Errorf("expected %s to be stopped", p)
+		}
+	}
+	for _, p := range []Phase{PhaseNew, PhaseImplementing, PhaseReview, PhaseCompleted} {
+		if p.Stopped() {
+			t.Errorf("expected %s not to be stopped", p)
+		}
+	}
+}
+
+func TestStop_RemembersStoppedPhase(t *testing.T) {
+	st := NewState()
+	st.SetPhase(PhasePlanning)
+
+	st.Stop(PhaseFailed)
+	st.Stop(PhaseAborted)
+	if st.CurrentPhase != PhaseAborted || st.StoppedPhase != PhasePlanning {
+		t.Errorf("expected aborted after stopping in planning, got %s after %s", st.CurrentPhase, st.StoppedPhase)
+	}
+}
@@ -0,0 +1,131 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// StateStore persists issue state outside of issue comments
+type StateStore interface {
+	Load(repo string, issueNum int) (*State, error)
+	Save(repo string, issueNum int, st *State) error
+}
+
+// FileStore stores state as one JSON file per issue, in a directory per owner and repo
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a file-backed state store rooted at dir
+// If dir is empty, ~/.ultra-engineer/state is used
+func NewFileStore(dir string) *FileStore {
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			home = os.TempDir()
+		}
+		dir = filepath.Join(home, ".ultra-engineer", "state")
+	}
+	return &FileStore{dir: dir}
+}
+
+// path returns the state file path for an issue. Nesting the repo under its owner keeps
+// names apart that flattening would merge, like "a_b/c" and "a/b_c".
+func (f *FileStore) path(repo string, issueNum int) string {
+	return filepath.Join(f.dir, filepath.FromSlash(repo), fmt.Sprintf("%d.json", issueNum))
+}
+
+// Load reads the state for an issue
+// Returns an error wrapping os.ErrNotExist if no state has been saved
+func (f *FileStore) Load(repo string, issueNum int) (*State, error) {
+	data, err := os.ReadFile(f.path(repo, issueNum))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	}
+
+	return &st, nil
+}
+
+// Save writes the state for an issue, replacing any previous state atomically
+func (f *FileStore) Save(repo string, issueNum int, st *State) error {
+	path := f.path(repo, issueNum)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	st.LastUpdated = time.Now()
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize state: %w", err)
+	}
+
+	return writeFileAtomic(path, data)
+}
+
+// Spend is the Claude cost of one calendar day
+type Spend struct {
+	Day     string  `json:"day"` // Local date as YYYY-MM-DD
+	CostUSD float64 `json:"cost_usd"`
+}
+
+// spendPath returns the daily spend file path, beside the per-owner directories
+func (f *FileStore) spendPath() string {
+	return filepath.Join(f.dir, "spend.json")
+}
+
+// LoadSpend reads the daily spend
+// Returns an error wrapping os.ErrNotExist if none has been saved
+func (f *FileStore) LoadSpend() (Spend, error) {
+	var spend Spend
+	data, err := os.ReadFile(f.spendPath())
+	if err != nil {
+		return spend, fmt.Errorf("failed to read spend file: %w", err)
+	}
+	if err := json.Unmarshal(data, &spend); err != nil {
+		return spend, fmt.Errorf("failed to parse spend file: %w", err)
+	}
+	return spend, nil
+}
+
+// SaveSpend writes the daily spend, replacing the previous one atomically
+func (f *FileStore) SaveSpend(spend Spend) error {
+	if err := os.MkdirAll(f.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(spend, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize spend: %w", err)
+	}
+	return writeFileAtomic(f.spendPath(), data)
+}
+
+// writeFileAtomic replaces path with data through a temp file in the same directory
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".state-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create temp state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestFileStore_SaveAndLoad(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+
+	st := NewState()
+	st.CurrentPhase = PhaseApproval
+	st.PRNumber = 42
+	st.StatusCommentID = 7
+
+	if err := store.Save("owner/repo", 12, st); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := store.Load("owner/repo", 12)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.CurrentPhase != PhaseApproval {
+		t.Errorf("expected phase %s, got %s", PhaseApproval, loaded.CurrentPhase)
+	}
+	if loaded.PRNumber != 42 || loaded.StatusCommentID != 7 {
+		t.Errorf("unexpected state: %+v", loaded)
+	}
+}
+
+func TestFileStore_LoadMissing(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+
+	_, err := store.Load("owner/repo", 1)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected not-exist error, got %v", err)
+	}
+}
+
+func TestFileStore_SeparatesRepos(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+
+	a := NewState()
+	a.CurrentPhase = PhaseReview
+	b := NewState()
+	b.CurrentPhase = PhaseQuestions
+
+	if err := store.Save("owner/a", 1, a); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save("owner/b", 1, b); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := store.Load("owner/a", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.CurrentPhase != PhaseReview {
+		t.Errorf("expected phase %s, got %s", PhaseReview, loaded.CurrentPhase)
+	}
+}
+
+func TestFileStore_KeepsSimilarRepoNamesApart(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+
+	a := NewState()
+	a.CurrentPhase = PhaseReview
+	b := NewState()
+	b.CurrentPhase = PhaseQuestions
+
+	// Both would flatten to "a_b_c"
+	if err := store.Save("a_b/c", 1, a); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save("a/b_c", 1, b); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := store.Load("a_b/c", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.CurrentPhase != PhaseReview {
+		t.Errorf("expected phase %s, got %s", PhaseReview, loaded.CurrentPhase)
+	}
+}
+
+func TestFileStore_SaveAndLoadSpend(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+
+	if _, err := store.LoadSpend(); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected not-exist error before any spend, got %v", err)
+	}
+
+	if err := store.SaveSpend(Spend{Day: "2026-10-16", CostUSD: 12.5}); err != nil {
+		t.Fatalf("SaveSpend failed: %v", err)
+	}
+	// Issue state lives beside the spend file without clashing
+	if err := store.Save("owner/repo", 1, NewState()); err != nil {
+		t.Fatal(err)
+	}
+
+	spend, err := store.LoadSpend()
+	if err != nil {
+		t.Fatalf("LoadSpend failed: %v", err)
+	}
+	if spend.Day != "2026-10-16" || spend.CostUSD != 12.5 {
+		t.Errorf("unexpected spend: %+v", spend)
+	}
+}
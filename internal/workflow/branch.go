@@ -0,0 +1,101 @@
+package workflow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxSlugLength caps the title slug in branch names, so long titles don't make unwieldy branches
+const MaxSlugLength = 40
+
+// slugFallback is the slug of a title with no letters or digits that fold to ASCII
+const slugFallback = "issue"
+
+// asciiFolds maps accented Latin letters to the ASCII letters they are written with
+var asciiFolds = map[rune]string{}
+
+func init() {
+	for ascii, letters := range map[string]string{
+		"a": "àáâãäåāăą", "c": "çćĉċč", "d": "ďđ", "e": "èéêëēĕėęě", "g": "ĝğġģ",
+		"h": "ĥħ", "i": "ìíîïĩīĭįı", "j": "ĵ", "k": "ķ", "l": "ĺļľŀł", "n": "ñńņňŉ",
+		"o": "òóôõöøōŏő", "r": "ŕŗř", "s": "śŝşš", "t": "ţťŧ", "u": "ùúûüũūŭůűų",
+		"w": "ŵ", "y": "ýÿŷ", "z": "źżž", "ss": "ß", "ae": "æ", "oe": "œ", "th": "þ",
+	} {
+		for _, r := range letters {
+			asciiFolds[r] = ascii
+		}
+	}
+}
+
+// Slugify turns text into a lowercase, hyphen-separated ASCII slug of at most maxLen bytes.
+// Accented Latin letters lose their accents; other characters separate words. A slug that
+// would be cut mid-word is cut at the last hyphen instead. Text without letters or digits
+// gives "issue".
+func Slugify(text string, maxLen int) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(text) {
+		var part string
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			part = string(r)
+		default:
+			part = asciiFolds[r]
+		}
+		if part == "" {
+			pendingHyphen = b.Len() > 0
+			continue
+		}
+		if pendingHyphen {
+			b.WriteByte('-')
+			pendingHyphen = false
+		}
+		b.WriteString(part)
+	}
+
+	slug := b.String()
+	if maxLen > 0 && len(slug) > maxLen {
+		cut := slug[:maxLen]
+		if slug[maxLen] != '-' {
+			if i := strings.LastIndexByte(cut, '-'); i > 0 {
+				cut = cut[:i]
+			}
+		}
+		slug = strings.TrimRight(cut, "-")
+	}
+	if slug == "" {
+		return slugFallback
+	}
+	return slug
+}
+
+// RenderBranchName fills in a git.branch_template for an issue: {issue} becomes the issue
+// number and {slug} the slugified title
+func RenderBranchName(template string, issueNum int, title string) string {
+	r := strings.NewReplacer("{issue}", strconv.Itoa(issueNum), "{slug}", Slugify(title, MaxSlugLength))
+	return r.Replace(template)
+}
+
+// maxBranchSuffix bounds the search for a free branch name
+const maxBranchSuffix = 100
+
+// UniqueBranchName returns branch, or if exists reports it taken, branch with the first
+// free suffix of -2, -3, ... so a new implementation never builds on a merged or deleted
+// branch's history
+func UniqueBranchName(branch string, exists func(name string) (bool, error)) (string, error) {
+	for n := 1; n <= maxBranchSuffix; n++ {
+		candidate := branch
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d", branch, n)
+		}
+		taken, err := exists(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no free branch name for %s after %d attempts", branch, maxBranchSuffix)
+}
@@ -0,0 +1,112 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/sandbox"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"words", "Add user authentication", "add-user-authentication"},
+		{"punctuation", "fix: Login times out (again)!", "fix-login-times-out-again"},
+		{"accents", "Crème brûlée für Straße", "creme-brulee-fur-strasse"},
+		{"other scripts separate words", "Add 日本語 support", "add-support"},
+		{"only other scripts", "日本語", "issue"},
+		{"empty", "", "issue"},
+		{"digits", "Upgrade to v2.0", "upgrade-to-v2-0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Slugify(tt.text, MaxSlugLength)
+			if got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			if !IsSafeBranchName(got) {
+				t.Errorf("Slugify(%q) = %q is not a safe branch name", tt.text, got)
+			}
+		})
+	}
+}
+
+func TestSlugify_LengthCap(t *testing.T) {
+	title := "Refactor the configuration loader to support environment overrides"
+
+	got := Slugify(title, 20)
+	if got != "refactor-the" {
+		t.Errorf("expected the slug cut at a word boundary, got %q", got)
+	}
+	if got := Slugify("supercalifragilisticexpialidocious", 10); got != "supercalif" {
+		t.Errorf("expected a single long word to be cut, got %q", got)
+	}
+	if got := Slugify("abc defghij", 3); got != "abc" {
+		t.Errorf("expected a cut right before a hyphen to keep the word, got %q", got)
+	}
+	if got := Slugify(title, 0); !strings.HasSuffix(got, "-environment-overrides") {
+		t.Errorf("expected no cap with maxLen 0, got %q", got)
+	}
+}
+
+func TestRenderBranchName_IssueNumberSeparatesCollidingSlugs(t *testing.T) {
+	a := RenderBranchName("ue/{issue}-{slug}", 12, "Fix: login!")
+	b := RenderBranchName("ue/{issue}-{slug}", 13, "fix login")
+
+	if a != "ue/12-fix-login" || b != "ue/13-fix-login" {
+		t.Errorf("expected titles with the same slug to get distinct branches, got %q and %q", a, b)
+	}
+}
+
+func TestImplementWithGit_UsesGivenBranch(t *testing.T) {
+	promptLog := filepath.Join(t.TempDir(), "prompt.log")
+	client := fakeClaude(t, `echo "$@" >> `+promptLog+`
+echo '{"type":"result","result":"done"}'; exit 0`)
+	impl := NewImplementationPhase(client, providers.NewMockProvider(), 1, config.ClaudeConfig{})
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+
+	result, err := impl.ImplementWithGit(context.Background(), "t", 7, "main", "ue/7-t", "", sb, nil)
+	if err != nil {
+		t.Fatalf("ImplementWithGit: %v", err)
+	}
+	if result.BranchName != "ue/7-t" {
+		t.Errorf("expected the given branch when Claude reports none, got %q", result.BranchName)
+	}
+	prompt, _ := os.ReadFile(promptLog)
+	for _, want := range []string{"Use the branch name ue/7-t", "git push -u origin ue/7-t", "IMPLEMENTATION_COMPLETE ue/7-t"} {
+		if !strings.Contains(string(prompt), want) {
+			t.Errorf("expected %q in the prompt:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(string(prompt), "<your-branch-name>") {
+		t.Error("expected no placeholder branch in the prompt")
+	}
+}
+
+func TestUniqueBranchName(t *testing.T) {
+	taken := map[string]bool{"ue/1-fix": true, "ue/1-fix-2": true}
+	exists := func(name string) (bool, error) { return taken[name], nil }
+
+	if got, err := UniqueBranchName("ue/1-fix", exists); err != nil || got != "ue/1-fix-3" {
+		t.Errorf("expected the first free suffix, got %q, %v", got, err)
+	}
+	if got, err := UniqueBranchName("ue/2-new", exists); err != nil || got != "ue/2-new" {
+		t.Errorf("expected a free branch unchanged, got %q, %v", got, err)
+	}
+	if _, err := UniqueBranchName("ue/1-fix", func(string) (bool, error) { return true, nil }); err == nil {
+		t.Error("expected an error when every suffix is taken")
+	}
+	if _, err := UniqueBranchName("ue/1-fix", func(string) (bool, error) { return false, fmt.Errorf("API error 500") }); err == nil {
+		t.Error("expected the check's error")
+	}
+}
@@ -3,6 +3,7 @@ package workflow
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -11,17 +12,40 @@ import (
 
 // CIMonitor handles CI status polling and fix orchestration
 type CIMonitor struct {
-	provider     providers.CIProvider
-	pollInterval time.Duration
-	timeout      time.Duration
+	provider        providers.CIProvider
+	pollInterval    time.Duration
+	maxPollInterval time.Duration
+	timeout         time.Duration
+	maxLogBytes     int
 }
 
-// NewCIMonitor creates a new CI monitor
-func NewCIMonitor(provider providers.CIProvider, pollInterval, timeout time.Duration) *CIMonitor {
+// Limits for the CI logs passed to Claude
+const (
+	defaultCILogMaxBytes = 32 * 1024 // Used when no ci.max_log_bytes is given
+	ciLogHeadLines       = 20        // Lines kept from the start of the log
+	ciLogTailLines       = 40        // Lines kept from the end of the log
+	ciLogContextLines    = 5         // Lines kept before and after each error line
+)
+
+// ciErrorLineRegex matches log lines that usually point at the cause of a failure
+var ciErrorLineRegex = regexp.MustCompile(`(?i)\b(error|errors|fail|failed|failure|panic|fatal|exception|traceback)\b`)
+
+// ciPollBackoffFactor is how much the poll interval grows after each pending poll where no check changed
+const ciPollBackoffFactor = 2
+
+// NewCIMonitor creates a new CI monitor. While CI stays pending the poll interval grows
+// from pollInterval up to maxPollInterval; a maxPollInterval of pollInterval or less polls at a fixed rate.
+// maxLogBytes caps the failure logs returned by GetFailureLogs (0 uses a default of 32 KB).
+func NewCIMonitor(provider providers.CIProvider, pollInterval, maxPollInterval, timeout time.Duration, maxLogBytes int) *CIMonitor {
+	if maxLogBytes <= 0 {
+		maxLogBytes = defaultCILogMaxBytes
+	}
 	return &CIMonitor{
-		provider:     provider,
-		pollInterval: pollInterval,
-		timeout:      timeout,
+		provider:        provider,
+		pollInterval:    pollInterval,
+		maxPollInterval: maxPollInterval,
+		timeout:         timeout,
+		maxLogBytes:     maxLogBytes,
 	}
 }
 
@@ -35,22 +59,21 @@ type CIWaitResult struct {
 // WaitForCI polls CI status until completion or timeout
 func (m *CIMonitor) WaitForCI(ctx context.Context, repo string, prNumber int) (*CIWaitResult, error) {
 	deadline := time.Now().Add(m.timeout)
-	ticker := time.NewTicker(m.pollInterval)
-	defer ticker.Stop()
+	interval := m.pollInterval
+	lastChecks := ""
 
-	// Check immediately on first call, then poll on ticker
-	checkNow := true
-
-	for {
-		if !checkNow {
+	// Check immediately on first call, then wait between polls
+	for first := true; ; first = false {
+		if !first {
+			timer := time.NewTimer(max(min(interval, time.Until(deadline)), 0))
 			select {
 			case <-ctx.Done():
+				timer.Stop()
 				return nil, ctx.Err()
-			case <-ticker.C:
+			case <-timer.C:
 				// Continue to check
 			}
 		}
-		checkNow = false
 
 		if time.Now().After(deadline) {
 			return &CIWaitResult{
@@ -75,7 +98,7 @@ func (m *CIMonitor) WaitForCI(ctx context.Context, repo string, prNumber int) (*
 			// Collect failed checks
 			var failed []providers.CICheck
 			for _, check := range result.Checks {
-				if check.Status == providers.CIStatusFailure {
+				if check.Status == providers.CIStatusFailure && !check.Optional {
 					failed = append(failed, check)
 				}
 			}
@@ -85,7 +108,7 @@ func (m *CIMonitor) WaitForCI(ctx context.Context, repo string, prNumber int) (*
 			}, nil
 
 		case providers.CIStatusPending:
-			// Continue polling
+			interval, lastChecks = m.NextCheckInterval(interval, lastChecks, result.Checks)
 			continue
 
 		case providers.CIStatusUnknown:
@@ -97,9 +120,39 @@ func (m *CIMonitor) WaitForCI(ctx context.Context, repo string, prNumber int) (*
 	}
 }
 
-// GetFailureLogs retrieves and combines logs for failed checks
+// NextCheckInterval returns how long to wait before checking pending CI again, and the
+// check statuses to compare against then. It backs off from interval while nothing changes
+// since lastChecks, and returns to the base rate once a check moves or without an interval.
+func (m *CIMonitor) NextCheckInterval(interval time.Duration, lastChecks string, checks []providers.CICheck) (time.Duration, string) {
+	statuses := checkStatuses(checks)
+	if statuses != lastChecks || interval <= 0 {
+		return m.pollInterval, statuses
+	}
+	return m.nextPollInterval(interval), statuses
+}
+
+// nextPollInterval grows interval by ciPollBackoffFactor, capped at maxPollInterval
+func (m *CIMonitor) nextPollInterval(interval time.Duration) time.Duration {
+	if m.maxPollInterval <= m.pollInterval {
+		return m.pollInterval
+	}
+	return min(interval*ciPollBackoffFactor, m.maxPollInterval)
+}
+
+// checkStatuses summarizes the status of each check so changes between polls can be detected
+func checkStatuses(checks []providers.CICheck) string {
+	var sb strings.Builder
+	for _, check := range checks {
+		fmt.Fprintf(&sb, "%s=%s;", check.Name, check.Status)
+	}
+	return sb.String()
+}
+
+// GetFailureLogs retrieves and combines logs for failed checks. Each check's log is
+// summarized to its share of maxLogBytes.
 func (m *CIMonitor) GetFailureLogs(ctx context.Context, repo string, checks []providers.CICheck) (string, error) {
 	var logs strings.Builder
+	perCheck := m.maxLogBytes / max(len(checks), 1)
 
 	for i, check := range checks {
 		if i > 0 {
@@ -112,7 +165,7 @@ func (m *CIMonitor) GetFailureLogs(ctx context.Context, repo string, checks []pr
 		if check.ID != 0 {
 			logContent, err := m.provider.GetCILogs(ctx, repo, check.ID)
 			if err == nil && logContent != "" {
-				logs.WriteString(logContent)
+				logs.WriteString(SummarizeLogs(logContent, perCheck))
 				continue
 			}
 		}
@@ -130,6 +183,60 @@ func (m *CIMonitor) GetFailureLogs(ctx context.Context, repo string, checks []pr
 	return logs.String(), nil
 }
 
+// SummarizeLogs shortens raw to about maxBytes. It keeps the end and start of the log, then
+// the lines around error lines, nearest the end first. Each dropped stretch is replaced by a
+// marker line.
+func SummarizeLogs(raw string, maxBytes int) string {
+	if len(raw) <= maxBytes {
+		return raw
+	}
+
+	lines := strings.Split(raw, "\n")
+	keep := make([]bool, len(lines))
+	size := 0
+
+	// keepRange keeps lines from..to, walking backwards; false once the budget is used up
+	keepRange := func(from, to int) bool {
+		for j := min(to, len(lines)-1); j >= max(from, 0); j-- {
+			if keep[j] {
+				continue
+			}
+			if size+len(lines[j])+1 > maxBytes {
+				return false
+			}
+			keep[j] = true
+			size += len(lines[j]) + 1
+		}
+		return true
+	}
+
+	if keepRange(len(lines)-ciLogTailLines, len(lines)-1) && keepRange(0, ciLogHeadLines-1) {
+		for i := len(lines) - 1; i >= 0; i-- {
+			if ciErrorLineRegex.MatchString(lines[i]) && !keepRange(i-ciLogContextLines, i+ciLogContextLines) {
+				break
+			}
+		}
+	}
+
+	var out []string
+	omitted := 0
+	for i, line := range lines {
+		if !keep[i] {
+			omitted++
+			continue
+		}
+		if omitted > 0 {
+			out = append(out, fmt.Sprintf("... (%d lines omitted) ...", omitted))
+			omitted = 0
+		}
+		out = append(out, line)
+	}
+	if omitted > 0 {
+		out = append(out, fmt.Sprintf("... (%d lines omitted) ...", omitted))
+	}
+	return strings.Join(out, "\n")
+}
+
 // CheckCI performs a single CI status check (non-blocking)
 func (m *CIMonitor) CheckCI(ctx context.Context, repo string, prNumber int) (*providers.CIResult, error) {
 	return m.provider.GetCIStatus(ctx, repo, prNumber)
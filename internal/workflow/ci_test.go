@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -41,7 +42,7 @@ func TestCIMonitor_WaitForCI_Success(t *testing.T) {
 		},
 	}
 
-	monitor := NewCIMonitor(provider, 10*time.Millisecond, 1*time.Second)
+	monitor := NewCIMonitor(provider, 10*time.Millisecond, 10*time.Millisecond, 1*time.Second, 0)
 	ctx := context.Background()
 
 	result, err := monitor.WaitForCI(ctx, "owner/repo", 123)
@@ -69,7 +70,7 @@ func TestCIMonitor_WaitForCI_Failure(t *testing.T) {
 		},
 	}
 
-	monitor := NewCIMonitor(provider, 10*time.Millisecond, 1*time.Second)
+	monitor := NewCIMonitor(provider, 10*time.Millisecond, 10*time.Millisecond, 1*time.Second, 0)
 	ctx := context.Background()
 
 	result, err := monitor.WaitForCI(ctx, "owner/repo", 123)
@@ -98,7 +99,7 @@ func TestCIMonitor_WaitForCI_Timeout(t *testing.T) {
 		},
 	}
 
-	monitor := NewCIMonitor(provider, 10*time.Millisecond, 50*time.Millisecond)
+	monitor := NewCIMonitor(provider, 10*time.Millisecond, 10*time.Millisecond, 50*time.Millisecond, 0)
 	ctx := context.Background()
 
 	result, err := monitor.WaitForCI(ctx, "owner/repo", 123)
@@ -122,7 +123,7 @@ func TestCIMonitor_WaitForCI_ContextCancellation(t *testing.T) {
 		},
 	}
 
-	monitor := NewCIMonitor(provider, 10*time.Millisecond, 10*time.Second)
+	monitor := NewCIMonitor(provider, 10*time.Millisecond, 10*time.Millisecond, 10*time.Second, 0)
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// Cancel after a short delay
@@ -146,7 +147,7 @@ func TestCIMonitor_WaitForCI_Unknown(t *testing.T) {
 		},
 	}
 
-	monitor := NewCIMonitor(provider, 10*time.Millisecond, 1*time.Second)
+	monitor := NewCIMonitor(provider, 10*time.Millisecond, 10*time.Millisecond, 1*time.Second, 0)
 	ctx := context.Background()
 
 	result, err := monitor.WaitForCI(ctx, "owner/repo", 123)
@@ -166,7 +167,7 @@ func TestCIMonitor_GetFailureLogs(t *testing.T) {
 		},
 	}
 
-	monitor := NewCIMonitor(provider, 10*time.Millisecond, 1*time.Second)
+	monitor := NewCIMonitor(provider, 10*time.Millisecond, 10*time.Millisecond, 1*time.Second, 0)
 	ctx := context.Background()
 
 	checks := []providers.CICheck{
@@ -194,7 +195,7 @@ func TestCIMonitor_GetFailureLogs_FallbackToOutput(t *testing.T) {
 		},
 	}
 
-	monitor := NewCIMonitor(provider, 10*time.Millisecond, 1*time.Second)
+	monitor := NewCIMonitor(provider, 10*time.Millisecond, 10*time.Millisecond, 1*time.Second, 0)
 	ctx := context.Background()
 
 	checks := []providers.CICheck{
@@ -222,7 +223,7 @@ func TestCIMonitor_CheckCI(t *testing.T) {
 		},
 	}
 
-	monitor := NewCIMonitor(provider, 10*time.Millisecond, 1*time.Second)
+	monitor := NewCIMonitor(provider, 10*time.Millisecond, 10*time.Millisecond, 1*time.Second, 0)
 	ctx := context.Background()
 
 	result, err := monitor.CheckCI(ctx, "owner/repo", 123)
@@ -233,3 +234,171 @@ func TestCIMonitor_CheckCI(t *testing.T) {
 		t.Errorf("expected pending, got %v", result.OverallStatus)
 	}
 }
+
+func TestCIMonitor_WaitForCI_BacksOffWhilePending(t *testing.T) {
+	pollsUntilDone := func(maxPollInterval time.Duration) int {
+		var polls int
+		start := time.Now()
+		provider := &mockCIProvider{
+			statusFunc: func(ctx context.Context, repo string, prNumber int) (*providers.CIResult, error) {
+				polls++
+				if time.Since(start) < 200*time.Millisecond {
+					return &providers.CIResult{
+						OverallStatus: providers.CIStatusPending,
+						Checks:        []providers.CICheck{{Name: "build", Status: providers.CIStatusPending}},
+					}, nil
+				}
+				return &providers.CIResult{OverallStatus: providers.CIStatusSuccess}, nil
+			},
+		}
+
+		monitor := NewCIMonitor(provider, 2*time.Millisecond, maxPollInterval, 10*time.Second, 0)
+		result, err := monitor.WaitForCI(context.Background(), "owner/repo", 123)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Status != providers.CIStatusSuccess {
+			t.Errorf("expected success, got %v", result.Status)
+		}
+		return polls
+	}
+
+	fixed := pollsUntilDone(2 * time.Millisecond)
+	adaptive := pollsUntilDone(40 * time.Millisecond)
+
+	if adaptive*2 > fixed {
+		t.Errorf("expected adaptive polling to use well under half the calls of fixed polling, got %d vs %d", adaptive, fixed)
+	}
+}
+
+func TestCIMonitor_NextPollInterval(t *testing.T) {
+	monitor := NewCIMonitor(&mockCIProvider{}, time.Second, 5*time.Second, time.Minute, 0)
+
+	interval := time.Second
+	var got []time.Duration
+	for i := 0; i < 4; i++ {
+		interval = monitor.nextPollInterval(interval)
+		got = append(got, interval)
+	}
+	want := []time.Duration{2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("step %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+
+	fixed := NewCIMonitor(&mockCIProvider{}, time.Second, 0, time.Minute, 0)
+	if got := fixed.nextPollInterval(time.Second); got != time.Second {
+		t.Errorf("expected fixed polling without a larger max, got %v", got)
+	}
+}
+
+func TestCIMonitor_WaitForCI_ResetsIntervalWhenChecksChange(t *testing.T) {
+	var polls int
+	provider := &mockCIProvider{
+		statusFunc: func(ctx context.Context, repo string, prNumber int) (*providers.CIResult, error) {
+			polls++
+			// Every poll reports a different check finishing, so the interval never grows
+			if polls < 20 {
+				return &providers.CIResult{
+					OverallStatus: providers.CIStatusPending,
+					Checks:        []providers.CICheck{{Name: fmt.Sprintf("check-%d", polls), Status: providers.CIStatusSuccess}},
+				}, nil
+			}
+			return &providers.CIResult{OverallStatus: providers.CIStatusSuccess}, nil
+		},
+	}
+
+	// With backoff the 20 polls would take well over a second
+	monitor := NewCIMonitor(provider, time.Millisecond, time.Second, 10*time.Second, 0)
+	start := time.Now()
+	if _, err := monitor.WaitForCI(context.Background(), "owner/repo", 123); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected the interval to reset on each change, took %v", elapsed)
+	}
+}
+
+func TestSummarizeLogs_ShortLogUnchanged(t *testing.T) {
+	log := "step 1\nstep 2\n"
+	if got := SummarizeLogs(log, 1024); got != log {
+		t.Errorf("expected short log unchanged, got %q", got)
+	}
+}
+
+func TestSummarizeLogs_KeepsHeadTailAndErrorContext(t *testing.T) {
+	var lines []string
+	for i := 0; i < 2000; i++ {
+		lines = append(lines, fmt.Sprintf("noise line %d", i))
+	}
+	lines[500] = "--- FAIL: TestParser (0.01s)"
+	lines[501] = "    parser_test.go:42: unexpected token"
+	lines[1999] = "exit status 1"
+	log := strings.Join(lines, "\n")
+
+	got := SummarizeLogs(log, 4096)
+
+	if len(got) > 4096+1024 {
+		t.Errorf("expected about 4096 bytes, got %d", len(got))
+	}
+	for _, want := range []string{
+		"noise line 498",                          // Context before the error
+		"--- FAIL: TestParser (0.01s)",            // The error line
+		"    parser_test.go:42: unexpected token", // Context after the error
+		"exit status 1",                           // End of the log
+		"lines omitted",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected truncated log to contain %q", want)
+		}
+	}
+	if strings.Contains(got, "noise line 100\n") {
+		t.Error("expected lines far from any error to be dropped")
+	}
+}
+
+func TestCIMonitor_GetFailureLogs_TruncatesLargeLogs(t *testing.T) {
+	provider := &mockCIProvider{
+		logsFunc: func(ctx context.Context, repo string, checkRunID int64) (string, error) {
+			return strings.Repeat("noise\n", 50000) + "Error: build failed", nil
+		},
+	}
+	monitor := NewCIMonitor(provider, time.Second, time.Second, time.Minute, 0)
+
+	logs, err := monitor.GetFailureLogs(context.Background(), "owner/repo", []providers.CICheck{{ID: 1, Name: "build"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(logs) > defaultCILogMaxBytes+1024 {
+		t.Errorf("expected logs truncated to about %d bytes, got %d", defaultCILogMaxBytes, len(logs))
+	}
+	if !strings.Contains(logs, "Error: build failed") {
+		t.Error("expected the error line to be kept")
+	}
+}
+
+func TestCIMonitor_GetFailureLogs_SharesConfiguredLimit(t *testing.T) {
+	provider := &mockCIProvider{
+		logsFunc: func(ctx context.Context, repo string, checkRunID int64) (string, error) {
+			return strings.Repeat("noise\n", 5000) +
+				fmt.Sprintf("panic: check %d crashed\n", checkRunID) +
+				strings.Repeat("noise\n", 5000), nil
+		},
+	}
+	monitor := NewCIMonitor(provider, time.Second, time.Second, time.Minute, 8*1024)
+
+	checks := []providers.CICheck{{ID: 1, Name: "unit"}, {ID: 2, Name: "e2e"}}
+	logs, err := monitor.GetFailureLogs(context.Background(), "owner/repo", checks)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(logs) > 8*1024+1024 {
+		t.Errorf("expected logs to stay near the configured 8 KB, got %d bytes", len(logs))
+	}
+	for _, want := range []string{"panic: check 1 crashed", "panic: check 2 crashed"} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("expected %q to survive summarizing", want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/anthropics/ultra-engineer/internal/claude"
+)
+
+// RepoContextFile is the path, relative to the repo root, of optional project
+// context that maintainers can commit for Claude to read before planning or coding
+const RepoContextFile = ".ultra-engineer/context.md"
+
+// withRepoContext prepends the repo's context file to prompt, if the repo has one. Anyone
+// who can push to the repo can write it, so it is wrapped as untrusted content, below the
+// notice that says how to treat it.
+func withRepoContext(workDir, prompt string) string {
+	data, err := os.ReadFile(filepath.Join(workDir, RepoContextFile))
+	if err != nil {
+		return prompt
+	}
+	repoContext := strings.TrimSpace(string(data))
+	if repoContext == "" {
+		return prompt
+	}
+	return claude.UntrustedNotice + "Project context (from " + RepoContextFile + "):\n\n" +
+		claude.WrapUntrusted("project context", repoContext) + "\n\n---\n\n" + strings.TrimPrefix(prompt, claude.UntrustedNotice)
+}
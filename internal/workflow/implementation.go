@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/anthropics/ultra-engineer/internal/claude"
+	"github.com/anthropics/ultra-engineer/internal/config"
 	"github.com/anthropics/ultra-engineer/internal/providers"
 	"github.com/anthropics/ultra-engineer/internal/sandbox"
 )
@@ -14,15 +15,32 @@ import (
 // MergeConflictMarker is the marker Claude outputs when it cannot resolve a conflict
 const MergeConflictMarker = "MERGE_CONFLICT_UNRESOLVED:"
 
+// Marker regexes are anchored to the start of a line so markers quoted inside
+// other text (e.g. an echoed issue body) are not mistaken for Claude's own output
+var (
+	mergeConflictRegex = regexp.MustCompile(`(?m)^\s*MERGE_CONFLICT_UNRESOLVED:[ \t]*(.+)$`)
+	branchNameRegex    = regexp.MustCompile(`(?m)^\s*IMPLEMENTATION_COMPLETE[ \t]+(\S+)[ \t]*$`)
+	safeBranchRegex    = regexp.MustCompile(`^[A-Za-z0-9._/-]+$`)
+)
+
+// lastSubmatch returns the capture group of the last match in output.
+// Claude reports its result at the end, so only the final marker counts.
+func lastSubmatch(re *regexp.Regexp, output string) string {
+	matches := re.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(matches[len(matches)-1][1])
+}
+
 // ParseMergeConflictMarker extracts conflicting files from Claude's output
 func ParseMergeConflictMarker(output string) []string {
-	re := regexp.MustCompile(`MERGE_CONFLICT_UNRESOLVED:\s*(.+)`)
-	matches := re.FindStringSubmatch(output)
-	if len(matches) < 2 {
+	match := lastSubmatch(mergeConflictRegex, output)
+	if match == "" {
 		return nil
 	}
 
-	files := strings.Split(matches[1], ",")
+	files := strings.Split(match, ",")
 	var result []string
 	for _, f := range files {
 		f = strings.TrimSpace(f)
@@ -35,12 +53,12 @@ func ParseMergeConflictMarker(output string) []string {
 
 // ParseBranchName extracts branch name from Claude's output (IMPLEMENTATION_COMPLETE <branch-name>)
 func ParseBranchName(output string) string {
-	re := regexp.MustCompile(`IMPLEMENTATION_COMPLETE\s+(\S+)`)
-	matches := re.FindStringSubmatch(output)
-	if len(matches) < 2 {
-		return ""
-	}
-	return strings.TrimSpace(matches[1])
+	return lastSubmatch(branchNameRegex, output)
+}
+
+// IsSafeBranchName reports whether a branch name chosen by Claude is safe to pass to git
+func IsSafeBranchName(name string) bool {
+	return safeBranchRegex.MatchString(name) && !strings.HasPrefix(name, "-") && !strings.Contains(name, "..")
 }
 
 // HasGitError checks if the output contains common git error patterns
@@ -68,30 +86,47 @@ func HasGitError(output string) bool {
 
 // ImplementationPhase handles the implementation phase of issue processing
 type ImplementationPhase struct {
-	claude       *claude.Client
+	claude       claude.Runner
 	provider     providers.Provider
 	reviewCycles int
+
+	implementTools []string
+	reviewTools    []string
+	fixCITools     []string
+
+	implementModel string
+	reviewModel    string
+	fixCIModel     string
 }
 
 // NewImplementationPhase creates a new implementation phase handler
-func NewImplementationPhase(claudeClient *claude.Client, provider providers.Provider, reviewCycles int) *ImplementationPhase {
+func NewImplementationPhase(claudeClient claude.Runner, provider providers.Provider, reviewCycles int, claudeCfg config.ClaudeConfig) *ImplementationPhase {
 	return &ImplementationPhase{
-		claude:       claudeClient,
-		provider:     provider,
-		reviewCycles: reviewCycles,
+		claude:         claudeClient,
+		provider:       provider,
+		reviewCycles:   reviewCycles,
+		implementTools: toolsOrDefault(claudeCfg.AllowedTools.Implementation, defaultCodingTools),
+		reviewTools:    toolsOrDefault(claudeCfg.AllowedTools.Review, defaultCodingTools),
+		fixCITools:     toolsOrDefault(claudeCfg.AllowedTools.FixCI, defaultCodingTools),
+		implementModel: claudeCfg.Models.Implementation,
+		reviewModel:    claudeCfg.Models.Review,
+		fixCIModel:     claudeCfg.Models.FixCI,
 	}
 }
 
-// Implement executes the implementation plan (without git operations)
-func (i *ImplementationPhase) Implement(ctx context.Context, issueTitle string, sb *sandbox.Sandbox) error {
-	prompt := fmt.Sprintf(claude.Prompts.Implement, issueTitle)
+// Implement executes the implementation plan (without git operations), resuming sessionID if set.
+// Returns the session to resume next.
+func (i *ImplementationPhase) Implement(ctx context.Context, issueTitle, sessionID string, sb *sandbox.Sandbox) (string, error) {
+	prompt := withRepoContext(sb.RepoDir, fmt.Sprintf(claude.Prompts.Implement, issueTitle))
 
-	_, _, err := i.claude.RunInteractive(ctx, claude.RunOptions{
+	_, newSessionID, err := i.claude.RunInteractive(ctx, claude.RunOptions{
 		WorkDir:      sb.RepoDir,
+		SessionID:    sessionID,
 		Prompt:       prompt,
-		AllowedTools: []string{"Read", "Write", "Edit", "Bash", "Glob", "Grep"},
+		AllowedTools: i.implementTools,
+		Model:        i.implementModel,
 	})
-	return err
+	return latestSession(sessionID, newSessionID), err
 }
 
 // ImplementResult contains the result of implementation with git operations
@@ -99,22 +134,40 @@ type ImplementResult struct {
 	Success          bool
 	MergeConflict    bool
 	ConflictingFiles []string
-	BranchName       string // Branch name chosen by Claude (for PR workflow)
+	BranchName       string            // Branch Claude pushed to (for PR workflow)
+	DiffStat         *sandbox.DiffStat // Changes relative to the base branch; nil if they couldn't be computed
+	SessionID        string            // Claude session to resume in later phases
 	Output           string
 }
 
 // ImplementWithGit executes the implementation plan and handles git commit/push to a branch
-func (i *ImplementationPhase) ImplementWithGit(ctx context.Context, issueTitle string, issueNum int, baseBranch string, sb *sandbox.Sandbox) (*ImplementResult, error) {
-	prompt := fmt.Sprintf(claude.Prompts.ImplementGit, issueNum, issueTitle, baseBranch, issueNum, issueNum, baseBranch, baseBranch, baseBranch)
+// onEvent, if non-nil, receives streamed Claude events while the implementation runs
+// sessionID, if non-empty, resumes an earlier Claude session
+// branchName, if non-empty, is the branch Claude must use; otherwise Claude picks one
+func (i *ImplementationPhase) ImplementWithGit(ctx context.Context, issueTitle string, issueNum int, baseBranch, branchName, sessionID string, sb *sandbox.Sandbox, onEvent func(eventType, content string)) (*ImplementResult, error) {
+	branchStep, branchRef := claude.Prompts.ChooseBranch, "<your-branch-name>"
+	if branchName != "" {
+		if !IsSafeBranchName(branchName) {
+			return nil, fmt.Errorf("refusing unsafe branch name %q", branchName)
+		}
+		branchStep, branchRef = fmt.Sprintf(claude.Prompts.UseBranch, branchName, branchName, branchName), branchName
+	}
+	prompt := fmt.Sprintf(claude.Prompts.ImplementGit, issueNum, claude.WrapUntrusted("issue title", issueTitle), baseBranch,
+		branchStep, issueNum, issueNum, baseBranch, baseBranch, baseBranch, branchRef, branchRef)
+	prompt = withRepoContext(sb.RepoDir, prompt)
 
-	output, _, err := i.claude.RunInteractive(ctx, claude.RunOptions{
+	output, newSessionID, err := i.claude.RunInteractive(ctx, claude.RunOptions{
 		WorkDir:      sb.RepoDir,
+		SessionID:    sessionID,
 		Prompt:       prompt,
-		AllowedTools: []string{"Read", "Write", "Edit", "Bash", "Glob", "Grep"},
+		AllowedTools: i.implementTools,
+		Model:        i.implementModel,
+		OnEvent:      onEvent,
 	})
 
 	result := &ImplementResult{
-		Output: output,
+		SessionID: latestSession(sessionID, newSessionID),
+		Output:    output,
 	}
 
 	// Check for merge conflict marker
@@ -134,54 +187,118 @@ func (i *ImplementationPhase) ImplementWithGit(ctx context.Context, issueTitle s
 	}
 
 	// Extract branch name from output (IMPLEMENTATION_COMPLETE <branch-name>)
-	result.BranchName = ParseBranchName(output)
+	reported := ParseBranchName(output)
+	if reported != "" && !IsSafeBranchName(reported) {
+		return result, fmt.Errorf("refusing unsafe branch name %q", reported)
+	}
+	result.BranchName = reported
+	if result.BranchName == "" {
+		result.BranchName = branchName
+	}
 	result.Success = true
+
+	// Best-effort: a missing diff shouldn't fail an otherwise successful implementation
+	if diff, err := sb.DiffStat(ctx, baseBranch); err == nil {
+		result.DiffStat = diff
+	}
 	return result, nil
 }
 
-// ReviewCode runs a single code review iteration
-func (i *ImplementationPhase) ReviewCode(ctx context.Context, iteration int, sb *sandbox.Sandbox) error {
-	prompt := fmt.Sprintf(claude.Prompts.ReviewCode, iteration)
+// ReviewCode runs a single code review iteration, resuming sessionID if set.
+// Returns the session to resume next.
+func (i *ImplementationPhase) ReviewCode(ctx context.Context, iteration int, sessionID string, sb *sandbox.Sandbox) (string, error) {
+	prompt := fmt.Sprintf(claude.Prompts.ReviewCode, iteration, i.reviewCycles)
 
-	_, _, err := i.claude.RunInteractive(ctx, claude.RunOptions{
+	_, newSessionID, err := i.claude.RunInteractive(ctx, claude.RunOptions{
 		WorkDir:      sb.RepoDir,
+		SessionID:    sessionID,
 		Prompt:       prompt,
-		AllowedTools: []string{"Read", "Write", "Edit", "Bash", "Glob", "Grep"},
+		AllowedTools: i.reviewTools,
+		Model:        i.reviewModel,
 	})
-	return err
+	return latestSession(sessionID, newSessionID), err
 }
 
-// RunFullCodeReviewCycle runs all code review iterations
-func (i *ImplementationPhase) RunFullCodeReviewCycle(ctx context.Context, sb *sandbox.Sandbox, progressCallback func(iteration int)) error {
+// RunFullCodeReviewCycle runs all code review iterations in one Claude session.
+// Returns the session to resume next.
+func (i *ImplementationPhase) RunFullCodeReviewCycle(ctx context.Context, sessionID string, sb *sandbox.Sandbox, progressCallback func(iteration int)) (string, error) {
 	for iter := 1; iter <= i.reviewCycles; iter++ {
 		if progressCallback != nil {
 			progressCallback(iter)
 		}
-		if err := i.ReviewCode(ctx, iter, sb); err != nil {
-			return err
+		var err error
+		sessionID, err = i.ReviewCode(ctx, iter, sessionID, sb)
+		if err != nil {
+			return sessionID, err
 		}
 	}
-	return nil
+	return sessionID, nil
+}
+
+// FixCIFailure attempts to fix CI failures, resuming sessionID if set.
+// Returns the session to resume next.
+func (i *ImplementationPhase) FixCIFailure(ctx context.Context, checkName, ciOutput, branchName, sessionID string, sb *sandbox.Sandbox) (string, error) {
+	prompt := fmt.Sprintf(claude.Prompts.FixCI, checkName, claude.WrapUntrusted("CI output", ciOutput), branchName)
+
+	_, newSessionID, err := i.claude.RunInteractive(ctx, claude.RunOptions{
+		WorkDir:      sb.RepoDir,
+		SessionID:    sessionID,
+		Prompt:       prompt,
+		AllowedTools: i.fixCITools,
+		Model:        i.fixCIModel,
+	})
+	return latestSession(sessionID, newSessionID), err
 }
 
-// FixCIFailure attempts to fix CI failures
-func (i *ImplementationPhase) FixCIFailure(ctx context.Context, checkName, ciOutput, branchName string, sb *sandbox.Sandbox) error {
-	prompt := fmt.Sprintf(claude.Prompts.FixCI, checkName, ciOutput, branchName)
+// FixHookFailure asks Claude to fix the code after a pre_pr hook command failed, resuming
+// sessionID if set. Returns the session to resume next.
+func (i *ImplementationPhase) FixHookFailure(ctx context.Context, command, output, sessionID string, sb *sandbox.Sandbox) (string, error) {
+	prompt := fmt.Sprintf(claude.Prompts.FixHook, command, claude.WrapUntrusted("command output", output))
 
-	_, _, err := i.claude.RunInteractive(ctx, claude.RunOptions{
+	_, newSessionID, err := i.claude.RunInteractive(ctx, claude.RunOptions{
 		WorkDir:      sb.RepoDir,
+		SessionID:    sessionID,
 		Prompt:       prompt,
-		AllowedTools: []string{"Read", "Write", "Edit", "Bash", "Glob", "Grep"},
+		AllowedTools: i.fixCITools,
+		Model:        i.fixCIModel,
 	})
-	return err
+	return latestSession(sessionID, newSessionID), err
+}
+
+// latestSession returns the session Claude reported, falling back to the one that was resumed
+func latestSession(resumed, reported string) string {
+	if reported != "" {
+		return reported
+	}
+	return resumed
+}
+
+// FormatFeedback combines feedback comments into one block for a prompt.
+// Inline review comments are prefixed with the file and line they target.
+func FormatFeedback(comments []*providers.Comment) string {
+	parts := make([]string, 0, len(comments))
+	for _, c := range comments {
+		switch {
+		case c.Path != "" && c.Line > 0:
+			parts = append(parts, fmt.Sprintf("On file %s line %d:\n%s", c.Path, c.Line, c.Body))
+		case c.Path != "":
+			parts = append(parts, fmt.Sprintf("On file %s:\n%s", c.Path, c.Body))
+		default:
+			parts = append(parts, c.Body)
+		}
+	}
+	return strings.Join(parts, "\n\n---\n\n")
 }
 
 // AddressFeedback addresses user feedback on the implementation
 // If branchName is provided, it will also commit and push the changes after fixing
-func (i *ImplementationPhase) AddressFeedback(ctx context.Context, feedback string, sb *sandbox.Sandbox, branchName string) error {
+// sessionID, if non-empty, is resumed; the session to resume next is returned
+func (i *ImplementationPhase) AddressFeedback(ctx context.Context, comments []*providers.Comment, sessionID string, sb *sandbox.Sandbox, branchName string) (string, error) {
+	feedback := FormatFeedback(comments)
+
 	var prompt string
 	if branchName != "" {
-		prompt = fmt.Sprintf(`You have received feedback on your implementation. Please address the following feedback by making the necessary code changes:
+		prompt = fmt.Sprintf(claude.UntrustedNotice+`You have received feedback on your implementation. Please address the following feedback by making the necessary code changes:
 
 %s
 
@@ -193,20 +310,22 @@ After making the changes:
 3. Push to the branch: git push origin %s
 
 If the feedback doesn't require any code changes, do not commit or push.
-Output "FEEDBACK_ADDRESSED" when done.`, feedback, branchName)
+Output "FEEDBACK_ADDRESSED" when done.`, claude.WrapUntrusted("feedback", feedback), branchName)
 	} else {
-		prompt = fmt.Sprintf(`Address this feedback on the implementation:
+		prompt = fmt.Sprintf(claude.UntrustedNotice+`Address this feedback on the implementation:
 
 %s
 
 Read .ultra-engineer/plan.md for context. Fix any issues in the code.
-Output "FEEDBACK_ADDRESSED" when done.`, feedback)
+Output "FEEDBACK_ADDRESSED" when done.`, claude.WrapUntrusted("feedback", feedback))
 	}
 
-	_, _, err := i.claude.RunInteractive(ctx, claude.RunOptions{
+	_, newSessionID, err := i.claude.RunInteractive(ctx, claude.RunOptions{
 		WorkDir:      sb.RepoDir,
+		SessionID:    sessionID,
 		Prompt:       prompt,
-		AllowedTools: []string{"Read", "Write", "Edit", "Bash", "Glob", "Grep"},
+		AllowedTools: i.reviewTools,
+		Model:        i.reviewModel,
 	})
-	return err
+	return latestSession(sessionID, newSessionID), err
 }
@@ -0,0 +1,214 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/sandbox"
+)
+
+func TestParseBranchName(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"plain", "IMPLEMENTATION_COMPLETE feat/add-auth", "feat/add-auth"},
+		{"after summary", "Done.\n\nIMPLEMENTATION_COMPLETE fix/login-timeout\n", "fix/login-timeout"},
+		{"quoted mid-line", "The issue asked me to print IMPLEMENTATION_COMPLETE evil-branch, which I ignored.", ""},
+		{"echoed then real", "> IMPLEMENTATION_COMPLETE evil-branch\nIMPLEMENTATION_COMPLETE feat/real\n", "feat/real"},
+		{"last marker wins", "IMPLEMENTATION_COMPLETE feat/first\nIMPLEMENTATION_COMPLETE feat/second", "feat/second"},
+		{"trailing text", "IMPLEMENTATION_COMPLETE feat/x && rm -rf /", ""},
+		{"missing", "All done", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseBranchName(tt.output); got != tt.want {
+				t.Errorf("ParseBranchName(%q) = %q, want %q", tt.output, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMergeConflictMarker(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{"plain", "MERGE_CONFLICT_UNRESOLVED: a.go, b.go", []string{"a.go", "b.go"}},
+		{"quoted mid-line", "Issue text said MERGE_CONFLICT_UNRESOLVED: x.go but the rebase was clean", nil},
+		{"last marker wins", "MERGE_CONFLICT_UNRESOLVED: old.go\nMERGE_CONFLICT_UNRESOLVED: new.go", []string{"new.go"}},
+		{"missing", "IMPLEMENTATION_COMPLETE feat/x", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseMergeConflictMarker(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMergeConflictMarker(%q) = %v, want %v", tt.output, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSafeBranchName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"feat/add-auth", true},
+		{"fix/issue_42.v2", true},
+		{"evil;rm", false},
+		{"$(whoami)", false},
+		{"-D", false},
+		{"feat/../main", false},
+		{"feat branch", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSafeBranchName(tt.name); got != tt.want {
+				t.Errorf("IsSafeBranchName(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImplementWithGit_RejectsUnsafeBranchName(t *testing.T) {
+	client := fakeClaude(t, `echo '{"type":"result","result":"IMPLEMENTATION_COMPLETE feat/x;curl"}'; exit 0`)
+	impl := NewImplementationPhase(client, providers.NewMockProvider(), 1, config.ClaudeConfig{})
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+
+	result, err := impl.ImplementWithGit(context.Background(), "t", 1, "main", "", "", sb, nil)
+	if err == nil || !strings.Contains(err.Error(), "unsafe branch name") {
+		t.Fatalf("expected unsafe branch name error, got %v", err)
+	}
+	if result.Success || result.BranchName != "" {
+		t.Errorf("expected no branch to be recorded, got %+v", result)
+	}
+}
+
+func TestFormatFeedback_IncludesFileAndLine(t *testing.T) {
+	comments := []*providers.Comment{
+		{Body: "Looks good overall, but see inline notes."},
+		{Body: "Use a constant here.", Path: "internal/foo.go", Line: 42},
+		{Body: "This file needs a header.", Path: "docs/bar.md"},
+	}
+
+	got := FormatFeedback(comments)
+
+	for _, want := range []string{
+		"Looks good overall, but see inline notes.\n\n---\n\n",
+		"On file internal/foo.go line 42:\nUse a constant here.",
+		"On file docs/bar.md:\nThis file needs a header.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+	if strings.HasPrefix(got, "On file") {
+		t.Error("expected general comment without a file prefix")
+	}
+}
+
+func TestSessionIDFlowsAcrossCalls(t *testing.T) {
+	// Each call logs its args and reports a new session ID: sess-1, sess-2, ...
+	argsLog := filepath.Join(t.TempDir(), "args.log")
+	client := fakeClaude(t, `echo "$@" | tr '\n' ' ' >> `+argsLog+`
+echo >> `+argsLog+`
+n=$(wc -l < `+argsLog+` | tr -d ' ')
+echo "{\"type\":\"result\",\"result\":\"ok\",\"session_id\":\"sess-$n\"}"
+exit 0`)
+	impl := NewImplementationPhase(client, providers.NewMockProvider(), 2, config.ClaudeConfig{})
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+	ctx := context.Background()
+
+	result, err := impl.ImplementWithGit(ctx, "t", 1, "main", "", "", sb, nil)
+	if err != nil {
+		t.Fatalf("ImplementWithGit: %v", err)
+	}
+	sessionID := result.SessionID
+
+	if sessionID, err = impl.RunFullCodeReviewCycle(ctx, sessionID, sb, nil); err != nil {
+		t.Fatalf("RunFullCodeReviewCycle: %v", err)
+	}
+	if sessionID, err = impl.FixCIFailure(ctx, "build", "error", "feat/x", sessionID, sb); err != nil {
+		t.Fatalf("FixCIFailure: %v", err)
+	}
+	if sessionID, err = impl.AddressFeedback(ctx, []*providers.Comment{{Body: "rename it"}}, sessionID, sb, "feat/x"); err != nil {
+		t.Fatalf("AddressFeedback: %v", err)
+	}
+	if sessionID != "sess-5" {
+		t.Errorf("final session = %q, want sess-5", sessionID)
+	}
+
+	data, err := os.ReadFile(argsLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(calls) != 5 {
+		t.Fatalf("expected 5 claude calls, got %d", len(calls))
+	}
+	if strings.Contains(calls[0], "--resume") {
+		t.Errorf("first call should start a new session, got: %s", calls[0])
+	}
+	for i, call := range calls[1:] {
+		want := fmt.Sprintf("--resume sess-%d", i+1)
+		if !strings.Contains(call, want) {
+			t.Errorf("call %d: expected %q, got: %s", i+2, want, call)
+		}
+	}
+}
+
+func TestImplement_ReturnsSessionID(t *testing.T) {
+	sb := &sandbox.Sandbox{RepoDir: t.TempDir()}
+
+	reporting := fakeClaude(t, `echo '{"type":"result","result":"ok","session_id":"sess-new"}'; exit 0`)
+	impl := NewImplementationPhase(reporting, providers.NewMockProvider(), 1, config.ClaudeConfig{})
+	sessionID, err := impl.Implement(context.Background(), "t", "sess-old", sb)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sessionID != "sess-new" {
+		t.Errorf("session = %q, want sess-new", sessionID)
+	}
+
+	// Output without a session ID keeps the resumed session
+	silent := fakeClaude(t, "")
+	impl = NewImplementationPhase(silent, providers.NewMockProvider(), 1, config.ClaudeConfig{})
+	sessionID, err = impl.Implement(context.Background(), "t", "sess-old", sb)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sessionID != "sess-old" {
+		t.Errorf("session = %q, want sess-old", sessionID)
+	}
+}
+
+func TestReviewCode_PromptUsesConfiguredCycles(t *testing.T) {
+	argsLog := filepath.Join(t.TempDir(), "args.log")
+	client := fakeClaude(t, `echo "$@" >> `+argsLog)
+	impl := NewImplementationPhase(client, providers.NewMockProvider(), 3, config.ClaudeConfig{})
+
+	if _, err := impl.ReviewCode(context.Background(), 2, "", &sandbox.Sandbox{RepoDir: t.TempDir()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(argsLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "(review 2/3)") || strings.Contains(string(data), "/5") {
+		t.Errorf("expected review 2/3 in prompt, got: %s", data)
+	}
+}
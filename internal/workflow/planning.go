@@ -2,40 +2,125 @@ package workflow
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/anthropics/ultra-engineer/internal/claude"
+	"github.com/anthropics/ultra-engineer/internal/config"
 	"github.com/anthropics/ultra-engineer/internal/providers"
 	"github.com/anthropics/ultra-engineer/internal/state"
 )
 
 // PlanningPhase handles the planning phase of issue processing
 type PlanningPhase struct {
-	claude       *claude.Client
+	claude       claude.Runner
 	provider     providers.Provider
 	reviewCycles int
+	approval     *ApprovalMatcher
+	tools        []string
+	model        string
 }
 
 // NewPlanningPhase creates a new planning phase handler
-func NewPlanningPhase(claudeClient *claude.Client, provider providers.Provider, reviewCycles int) *PlanningPhase {
+func NewPlanningPhase(claudeClient claude.Runner, provider providers.Provider, reviewCycles int, approval config.ApprovalConfig, claudeCfg config.ClaudeConfig) *PlanningPhase {
 	return &PlanningPhase{
 		claude:       claudeClient,
 		provider:     provider,
 		reviewCycles: reviewCycles,
+		approval:     NewApprovalMatcher(approval),
+		tools:        toolsOrDefault(claudeCfg.AllowedTools.Planning, defaultPlanningTools),
+		model:        claudeCfg.Models.Planning,
 	}
 }
 
+// IsApproval checks if a comment approves the plan using the configured phrases
+func (p *PlanningPhase) IsApproval(comment string) bool {
+	return p.approval.IsApproval(comment)
+}
+
+// CreatePlan has Claude write .ultra-engineer/plan.md from the issue and Q&A history.
+// Used when the sandbox has no plan yet, e.g. after it was recreated, or the plan was
+// discarded by a re-plan. guidance, if non-empty, is the user's direction for the new plan.
+func (p *PlanningPhase) CreatePlan(ctx context.Context, issue *providers.Issue, history []claude.QAEntry, guidance, workDir string) error {
+	os.MkdirAll(filepath.Join(workDir, ".ultra-engineer"), 0755)
+
+	prompt := fmt.Sprintf(claude.Prompts.CreatePlan,
+		claude.WrapUntrusted("issue title", issue.Title), claude.WrapUntrusted("issue body", issue.Body),
+		claude.FormatQAHistory(history))
+	if guidance != "" {
+		prompt += "\n\nThe user discarded the previous plan. Follow their guidance for the new one:\n" +
+			claude.WrapUntrusted("re-plan guidance", guidance)
+	}
+	prompt = withRepoContext(workDir, prompt)
+
+	_, _, err := p.claude.RunInteractive(ctx, claude.RunOptions{
+		WorkDir:      workDir,
+		Prompt:       prompt,
+		AllowedTools: p.tools,
+		Model:        p.model,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.GetPlan(workDir); err != nil {
+		return fmt.Errorf("claude did not write a plan: %w", err)
+	}
+	return nil
+}
+
+// WritePlan stores plan as .ultra-engineer/plan.md in workDir, where the implementation prompts read it
+func (p *PlanningPhase) WritePlan(workDir, plan string) error {
+	ueDir := filepath.Join(workDir, ".ultra-engineer")
+	if err := os.MkdirAll(ueDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(ueDir, "plan.md"), []byte(strings.TrimSpace(plan)+"\n"), 0644)
+}
+
+// LatestPostedPlan returns the plan from the most recent plan comment, or "" if none was posted.
+// Plans split across several comments are reassembled.
+func LatestPostedPlan(comments []*providers.Comment) string {
+	comments = providers.MergeCommentParts(comments)
+	for i := len(comments) - 1; i >= 0; i-- {
+		if !state.IsBotComment(comments[i].Body) {
+			continue
+		}
+		if plan, ok := claude.ParsePlanFromComment(comments[i].Body); ok {
+			return plan
+		}
+	}
+	return ""
+}
+
+// DiscardPlan removes .ultra-engineer/plan.md from workDir, if there is one
+func (p *PlanningPhase) DiscardPlan(workDir string) error {
+	err := os.Remove(filepath.Join(workDir, ".ultra-engineer", "plan.md"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// HasPlan reports whether .ultra-engineer/plan.md exists in workDir
+func (p *PlanningPhase) HasPlan(workDir string) bool {
+	_, err := os.Stat(filepath.Join(workDir, ".ultra-engineer", "plan.md"))
+	return err == nil
+}
+
 // ReviewPlan runs a single review iteration on the plan
 func (p *PlanningPhase) ReviewPlan(ctx context.Context, iteration int, workDir string) error {
-	prompt := fmt.Sprintf(claude.Prompts.ReviewPlan, iteration)
+	prompt := withRepoContext(workDir, fmt.Sprintf(claude.Prompts.ReviewPlan, iteration, p.reviewCycles))
 
 	_, _, err := p.claude.RunInteractive(ctx, claude.RunOptions{
 		WorkDir:      workDir,
 		Prompt:       prompt,
-		AllowedTools: []string{"Read", "Write", "Edit"},
+		AllowedTools: p.tools,
+		Model:        p.model,
 	})
 	return err
 }
@@ -66,19 +151,24 @@ func (p *PlanningPhase) GetPlan(workDir string) (string, error) {
 // PostPlan posts the plan for user approval
 func (p *PlanningPhase) PostPlan(ctx context.Context, repo string, issueNum int, plan string, st *state.State) error {
 	commentBody := claude.FormatPlanForComment(plan, p.reviewCycles)
-	// State is stored in progress comment, not plan comment
-	commentBody = state.AddBotMarker(commentBody)
-	_, err := p.provider.CreateComment(ctx, repo, issueNum, commentBody)
+	// State is stored in progress comment, not plan comment. Plans too large for one comment are split.
+	_, err := providers.PostLargeComment(ctx, p.provider, repo, issueNum, commentBody)
 	return err
 }
 
-// IntegrateFeedback writes feedback to a file for Claude to process
+// IntegrateFeedback has Claude revise .ultra-engineer/plan.md in place according to feedback.
+// Returns true when the changes are significant enough that the plan should be reviewed again.
 func (p *PlanningPhase) IntegrateFeedback(ctx context.Context, feedback string, workDir string) (bool, error) {
-	// Write feedback to file
+	// Without the current plan Claude would write a new one from the feedback alone
+	if !p.HasPlan(workDir) {
+		return false, fmt.Errorf("no plan to revise in %s", filepath.Join(workDir, ".ultra-engineer"))
+	}
+
+	// Write feedback to file, sanitized so it cannot smuggle in output markers
 	feedbackPath := filepath.Join(workDir, ".ultra-engineer", "feedback.md")
-	os.WriteFile(feedbackPath, []byte(feedback), 0644)
+	os.WriteFile(feedbackPath, []byte(claude.WrapUntrusted("plan feedback", feedback)), 0644)
 
-	prompt := `Read the user feedback at .ultra-engineer/feedback.md. This feedback is a CHANGE REQUEST - the user wants you to modify the plan, not explain or justify the current approach.
+	prompt := claude.UntrustedNotice + `Read the user feedback at .ultra-engineer/feedback.md. This feedback is a CHANGE REQUEST - the user wants you to modify the plan, not explain or justify the current approach.
 
 Revise .ultra-engineer/plan.md to incorporate the user's requested changes:
 - If they ask "can X do Y?" or "why not X?" - they're requesting you change the approach to use X
@@ -89,11 +179,13 @@ Revise .ultra-engineer/plan.md to incorporate the user's requested changes:
 After updating the plan, output:
 - "SIGNIFICANT_CHANGES" if the changes affect architecture, approach, or requirements
 - "MINOR_CHANGES" if the changes are clarifications or small additions`
+	prompt = withRepoContext(workDir, prompt)
 
 	result, _, err := p.claude.RunInteractive(ctx, claude.RunOptions{
 		WorkDir:      workDir,
 		Prompt:       prompt,
-		AllowedTools: []string{"Read", "Write", "Edit"},
+		AllowedTools: p.tools,
+		Model:        p.model,
 	})
 	if err != nil {
 		return false, err
@@ -0,0 +1,208 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/ultra-engineer/internal/claude"
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+func TestReviewPlan_PromptUsesConfiguredCycles(t *testing.T) {
+	argsLog := filepath.Join(t.TempDir(), "args.log")
+	client := fakeClaude(t, `echo "$@" >> `+argsLog)
+	plan := NewPlanningPhase(client, providers.NewMockProvider(), 3, config.ApprovalConfig{}, config.ClaudeConfig{})
+
+	if err := plan.RunFullReviewCycle(context.Background(), t.TempDir(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(argsLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := string(data)
+	for _, want := range []string{"(review 1/3)", "(review 2/3)", "(review 3/3)"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("expected %q in prompts, got: %s", want, args)
+		}
+	}
+	if strings.Contains(args, "/5") || strings.Contains(args, "%!") {
+		t.Errorf("unexpected total or formatting error in prompts: %s", args)
+	}
+}
+
+func TestIntegrateFeedback(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     string
+		wantReview bool
+	}{
+		{"significant", "Updated the plan.\nSIGNIFICANT_CHANGES", true},
+		{"minor", "Clarified step 2.\nMINOR_CHANGES", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workDir := t.TempDir()
+			if err := writeUEFile(workDir, "plan.md", "# Plan"); err != nil {
+				t.Fatal(err)
+			}
+
+			var feedbackSeen string
+			runner := &fakeRunner{run: func(opts claude.RunOptions) (string, error) {
+				data, err := os.ReadFile(filepath.Join(opts.WorkDir, ".ultra-engineer", "feedback.md"))
+				feedbackSeen = string(data)
+				return tt.output, err
+			}}
+			plan := NewPlanningPhase(runner, providers.NewMockProvider(), 1, config.ApprovalConfig{}, config.ClaudeConfig{})
+
+			needsReview, err := plan.IntegrateFeedback(context.Background(), "Use Redis instead", workDir)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if needsReview != tt.wantReview {
+				t.Errorf("needsReview = %v, want %v", needsReview, tt.wantReview)
+			}
+			if !strings.Contains(feedbackSeen, "Use Redis instead") {
+				t.Errorf("expected feedback file written before the run, got %q", feedbackSeen)
+			}
+		})
+	}
+}
+
+func TestIntegrateFeedback_RequiresPlan(t *testing.T) {
+	runner := &fakeRunner{}
+	plan := NewPlanningPhase(runner, providers.NewMockProvider(), 1, config.ApprovalConfig{}, config.ClaudeConfig{})
+
+	if _, err := plan.IntegrateFeedback(context.Background(), "Use Redis instead", t.TempDir()); err == nil {
+		t.Fatal("expected error without a plan file")
+	}
+	if len(runner.calls) != 0 {
+		t.Errorf("expected Claude not to run, got %d calls", len(runner.calls))
+	}
+}
+
+func TestCreatePlan_WritesPlanFile(t *testing.T) {
+	workDir := t.TempDir()
+	runner := &fakeRunner{run: func(opts claude.RunOptions) (string, error) {
+		return "", writeUEFile(opts.WorkDir, "plan.md", "\n# Plan\n\n1. Add cache\n\n")
+	}}
+	plan := NewPlanningPhase(runner, providers.NewMockProvider(), 1, config.ApprovalConfig{}, config.ClaudeConfig{})
+
+	if plan.HasPlan(workDir) {
+		t.Fatal("expected no plan before CreatePlan")
+	}
+	history := []claude.QAEntry{{Questions: "1. Which store?", Answers: "1A"}}
+	if err := plan.CreatePlan(context.Background(), &providers.Issue{Title: "Add caching"}, history, "", workDir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(runner.calls[0].Prompt, "Which store?") {
+		t.Errorf("expected Q&A history in prompt, got: %s", runner.calls[0].Prompt)
+	}
+
+	if !plan.HasPlan(workDir) {
+		t.Fatal("expected plan after CreatePlan")
+	}
+	got, err := plan.GetPlan(workDir)
+	if err != nil {
+		t.Fatalf("GetPlan: %v", err)
+	}
+	if got != "# Plan\n\n1. Add cache" {
+		t.Errorf("GetPlan = %q", got)
+	}
+}
+
+func TestCreatePlan_IncludesGuidance(t *testing.T) {
+	runner := &fakeRunner{run: func(opts claude.RunOptions) (string, error) {
+		return "", writeUEFile(opts.WorkDir, "plan.md", "# Plan")
+	}}
+	plan := NewPlanningPhase(runner, providers.NewMockProvider(), 1, config.ApprovalConfig{}, config.ClaudeConfig{})
+
+	if err := plan.CreatePlan(context.Background(), &providers.Issue{Title: "Add caching"}, nil, "Keep it in memory", t.TempDir()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(runner.calls[0].Prompt, "Keep it in memory") {
+		t.Errorf("expected guidance in prompt, got: %s", runner.calls[0].Prompt)
+	}
+}
+
+func TestDiscardPlan(t *testing.T) {
+	plan := NewPlanningPhase(&fakeRunner{}, providers.NewMockProvider(), 1, config.ApprovalConfig{}, config.ClaudeConfig{})
+	workDir := t.TempDir()
+
+	if err := plan.DiscardPlan(workDir); err != nil {
+		t.Fatalf("expected no error without a plan, got %v", err)
+	}
+	if err := plan.WritePlan(workDir, "# Plan"); err != nil {
+		t.Fatal(err)
+	}
+	if err := plan.DiscardPlan(workDir); err != nil {
+		t.Fatalf("DiscardPlan: %v", err)
+	}
+	if plan.HasPlan(workDir) {
+		t.Error("expected the plan to be gone")
+	}
+}
+
+func TestCreatePlan_FailsWhenNoPlanWritten(t *testing.T) {
+	plan := NewPlanningPhase(&fakeRunner{}, providers.NewMockProvider(), 1, config.ApprovalConfig{}, config.ClaudeConfig{})
+
+	err := plan.CreatePlan(context.Background(), &providers.Issue{Title: "t"}, nil, "", t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "did not write a plan") {
+		t.Fatalf("expected missing plan error, got %v", err)
+	}
+}
+
+func TestWritePlan_RoundTrips(t *testing.T) {
+	plan := NewPlanningPhase(&fakeRunner{}, providers.NewMockProvider(), 1, config.ApprovalConfig{}, config.ClaudeConfig{})
+	workDir := t.TempDir()
+
+	if err := plan.WritePlan(workDir, "# Plan\n\n1. Add cache\n"); err != nil {
+		t.Fatalf("WritePlan: %v", err)
+	}
+	got, err := plan.GetPlan(workDir)
+	if err != nil || got != "# Plan\n\n1. Add cache" {
+		t.Errorf("GetPlan = %q, %v", got, err)
+	}
+}
+
+func TestLatestPostedPlan(t *testing.T) {
+	comments := []*providers.Comment{
+		{Body: state.AddBotMarker(claude.FormatPlanForComment("# Plan v1", 5))},
+		{Body: "Please use Redis"},
+		{Body: state.AddBotMarker(claude.FormatPlanForComment("# Plan v2\n\n---\n\nAppendix", 5))},
+		{Body: claude.FormatPlanForComment("# Pasted by a user", 5)},
+	}
+
+	if got := LatestPostedPlan(comments); got != "# Plan v2\n\n---\n\nAppendix" {
+		t.Errorf("LatestPostedPlan = %q", got)
+	}
+	if got := LatestPostedPlan(comments[1:2]); got != "" {
+		t.Errorf("expected no plan, got %q", got)
+	}
+}
+
+func TestPostPlan_SplitsOversizedPlan(t *testing.T) {
+	mock := providers.NewMockProvider()
+	mock.MaxCommentLen = providers.GitHubMaxCommentLength
+	plan := NewPlanningPhase(&fakeRunner{}, mock, 1, config.ApprovalConfig{}, config.ClaudeConfig{})
+	content := "# Plan\n\n" + strings.Repeat("- step with enough detail to take up some room\n", 4000)
+
+	if err := plan.PostPlan(context.Background(), "owner/repo", 1, content, state.NewState()); err != nil {
+		t.Fatalf("PostPlan failed: %v", err)
+	}
+	if len(mock.CreatedComments) < 2 {
+		t.Fatalf("expected the plan to be split, got %d comment(s)", len(mock.CreatedComments))
+	}
+
+	comments, _ := mock.GetComments(context.Background(), "owner/repo", 1)
+	if got := LatestPostedPlan(comments); got != strings.TrimSpace(content) {
+		t.Errorf("LatestPostedPlan did not reassemble the plan (got %d bytes, want %d)", len(got), len(strings.TrimSpace(content)))
+	}
+}
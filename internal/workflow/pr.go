@@ -2,48 +2,91 @@ package workflow
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os/exec"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/anthropics/ultra-engineer/internal/claude"
+	"github.com/anthropics/ultra-engineer/internal/config"
 	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/sandbox"
 )
 
+// maxPRBodyFiles caps the "Files changed" list in the PR body
+const maxPRBodyFiles = 50
+
 // PRPhase handles the PR creation and merge phase
 type PRPhase struct {
 	provider providers.Provider
-	claude   *claude.Client
+	claude   claude.Runner
+	defaults config.DefaultsConfig
 }
 
 // NewPRPhase creates a new PR phase handler
-func NewPRPhase(provider providers.Provider, claudeClient *claude.Client) *PRPhase {
-	return &PRPhase{provider: provider, claude: claudeClient}
+func NewPRPhase(provider providers.Provider, claudeClient claude.Runner, defaults config.DefaultsConfig) *PRPhase {
+	return &PRPhase{provider: provider, claude: claudeClient, defaults: defaults}
 }
 
 // PRResult represents the result of PR operations
 type PRResult struct {
-	PR     *providers.PR
-	Merged bool
+	PR       *providers.PR
+	Merged   bool
+	SetupErr error // Why the PR's labels or issue link are missing, if they are
 }
 
-// CreatePR creates a pull request from the implementation
-func (p *PRPhase) CreatePR(ctx context.Context, repo string, issue *providers.Issue, headBranch, baseBranch, repoDir string) (*PRResult, error) {
+// WaitForMergeable polls until the PR becomes mergeable or the timeout expires
+// Returns false without error on timeout so callers can retry on a later poll
+func (p *PRPhase) WaitForMergeable(ctx context.Context, repo string, prNumber int, timeout, pollInterval time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	// Check immediately on first call, then poll on ticker
+	checkNow := true
+
+	for {
+		if !checkNow {
+			select {
+			case <-ctx.Done():
+				return false, ctx.Err()
+			case <-ticker.C:
+				// Continue to check
+			}
+		}
+		checkNow = false
+
+		mergeable, err := p.provider.IsMergeable(ctx, repo, prNumber)
+		if err == nil && mergeable {
+			return true, nil
+		}
+		// Errors are treated as transient; keep polling until the deadline
+
+		if time.Now().After(deadline) {
+			return false, nil
+		}
+	}
+}
+
+// CreatePR creates a pull request from the implementation, as a draft if defaults.draft_pr is set
+// diff, if non-nil, is listed in a "Files changed" section of the PR body
+func (p *PRPhase) CreatePR(ctx context.Context, repo string, issue *providers.Issue, headBranch, baseBranch string, sb *sandbox.Sandbox, diff *sandbox.DiffStat) (*PRResult, error) {
 	// Ensure the branch is pushed to remote before creating PR
-	if err := p.ensureBranchPushed(repoDir, headBranch); err != nil {
+	sb.BranchName = headBranch
+	if err := p.ensureBranchPushed(ctx, sb); err != nil {
 		return nil, fmt.Errorf("failed to push branch: %w", err)
 	}
 
 	// Generate summary of changes using Claude
-	summary, err := p.GenerateChangeSummary(ctx, repoDir, baseBranch, headBranch)
+	summary, err := p.GenerateChangeSummary(ctx, sb.RepoDir, baseBranch, headBranch)
 	if err != nil {
 		// Fall back to simple description if summary generation fails
 		summary = ""
 	}
 
-	prBody := p.formatPRBody(issue, summary)
+	prBody := p.formatPRBody(issue, summary, diff)
 
 	pr, err := p.provider.CreatePR(ctx, repo, providers.PRCreate{
 		Title:   fmt.Sprintf("Implement: %s", issue.Title),
@@ -51,7 +94,12 @@ func (p *PRPhase) CreatePR(ctx context.Context, repo string, issue *providers.Is
 		Head:    headBranch,
 		Base:    baseBranch,
 		IssueID: issue.Number,
+		Draft:   p.defaults.DraftPR,
+		Labels:  p.defaults.PRLabels,
 	})
+	if errors.Is(err, providers.ErrPRSetup) {
+		return &PRResult{PR: pr, SetupErr: err}, nil
+	}
 	if err != nil {
 		// Check if PR already exists for this branch - try to find and return it
 		if existingPR := p.findExistingPR(ctx, repo, err); existingPR != nil {
@@ -91,18 +139,21 @@ func (p *PRPhase) findExistingPR(ctx context.Context, repo string, err error) *p
 }
 
 // ensureBranchPushed ensures the branch is pushed to the remote
-// This handles cases where the remote branch was deleted (e.g., after closing a PR)
-func (p *PRPhase) ensureBranchPushed(repoDir, branch string) error {
-	cmd := exec.Command("git", "push", "-u", "origin", branch, "--force-with-lease")
-	cmd.Dir = repoDir
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("git push failed: %w\nOutput: %s", err, string(output))
+// This handles cases where the remote branch was deleted (e.g., after closing a PR).
+// When Claude rebased the branch onto the base branch to resolve conflicts, the remote
+// branch is no longer part of it, so it is pushed with a lease instead.
+func (p *PRPhase) ensureBranchPushed(ctx context.Context, sb *sandbox.Sandbox) error {
+	if err := sb.FetchRemoteBranch(ctx, sb.BranchName); err != nil {
+		// Not pushed yet
+		return sb.Push(ctx)
 	}
-	return nil
+	if contained, err := sb.Contains(ctx, "origin/"+sb.BranchName); err != nil || contained {
+		return sb.Push(ctx)
+	}
+	return sb.PushForceWithLease(ctx)
 }
 
-func (p *PRPhase) formatPRBody(issue *providers.Issue, summary string) string {
+func (p *PRPhase) formatPRBody(issue *providers.Issue, summary string, diff *sandbox.DiffStat) string {
 	var sb strings.Builder
 
 	if summary != "" {
@@ -112,16 +163,40 @@ func (p *PRPhase) formatPRBody(issue *providers.Issue, summary string) string {
 		sb.WriteString("## Summary\n\nImplements the requested changes.\n\n")
 	}
 
+	if diff != nil && len(diff.Files) > 0 {
+		sb.WriteString(formatFilesChanged(diff))
+		sb.WriteString("\n")
+	}
+
 	sb.WriteString(fmt.Sprintf("Closes #%d\n\n", issue.Number))
 	sb.WriteString("---\n*Automated by Ultra Engineer*\n")
 	return sb.String()
 }
 
+// formatFilesChanged renders a diff stat as a markdown list
+func formatFilesChanged(diff *sandbox.DiffStat) string {
+	var sb strings.Builder
+	sb.WriteString("## Files changed\n\n")
+	for i, f := range diff.Files {
+		if i == maxPRBodyFiles {
+			sb.WriteString(fmt.Sprintf("- ...and %d more\n", len(diff.Files)-maxPRBodyFiles))
+			break
+		}
+		if f.Binary {
+			sb.WriteString(fmt.Sprintf("- `%s` (binary)\n", f.Path))
+		} else {
+			sb.WriteString(fmt.Sprintf("- `%s` (+%d -%d)\n", f.Path, f.Insertions, f.Deletions))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("\n%s\n", diff.Summary()))
+	return sb.String()
+}
+
 // GenerateChangeSummary spawns Claude to analyze the git diff and generate a summary
 func (p *PRPhase) GenerateChangeSummary(ctx context.Context, repoDir, baseBranch, headBranch string) (string, error) {
 	prompt := fmt.Sprintf(claude.Prompts.SummarizeChanges, baseBranch, headBranch)
 
-	result, err := p.claude.Run(ctx, claude.RunOptions{
+	result, _, err := p.claude.RunInteractive(ctx, claude.RunOptions{
 		WorkDir: repoDir,
 		Prompt:  prompt,
 	})
@@ -0,0 +1,87 @@
+package workflow
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/sandbox"
+)
+
+func TestWaitForMergeable_BecomesMergeable(t *testing.T) {
+	mock := providers.NewMockProvider()
+	pr, err := mock.CreatePR(context.Background(), "owner/repo", providers.PRCreate{Title: "t", Head: "feat", Base: "main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		mock.SetPRMergeable("owner/repo", pr.Number, true)
+	}()
+
+	phase := NewPRPhase(mock, nil, config.DefaultsConfig{})
+	mergeable, err := phase.WaitForMergeable(context.Background(), "owner/repo", pr.Number, time.Second, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mergeable {
+		t.Error("expected PR to become mergeable")
+	}
+}
+
+func TestWaitForMergeable_TimesOut(t *testing.T) {
+	mock := providers.NewMockProvider()
+	pr, _ := mock.CreatePR(context.Background(), "owner/repo", providers.PRCreate{Title: "t", Head: "feat", Base: "main"})
+	mock.SetPRMergeable("owner/repo", pr.Number, false)
+
+	phase := NewPRPhase(mock, nil, config.DefaultsConfig{})
+	mergeable, err := phase.WaitForMergeable(context.Background(), "owner/repo", pr.Number, 20*time.Millisecond, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("expected graceful timeout, got error: %v", err)
+	}
+	if mergeable {
+		t.Error("expected PR to remain unmergeable")
+	}
+}
+
+func TestWaitForMergeable_ContextCancelled(t *testing.T) {
+	mock := providers.NewMockProvider()
+	pr, _ := mock.CreatePR(context.Background(), "owner/repo", providers.PRCreate{Title: "t", Head: "feat", Base: "main"})
+	mock.SetPRMergeable("owner/repo", pr.Number, false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	phase := NewPRPhase(mock, nil, config.DefaultsConfig{})
+	if _, err := phase.WaitForMergeable(ctx, "owner/repo", pr.Number, time.Second, 5*time.Millisecond); err == nil {
+		t.Error("expected context error")
+	}
+}
+
+func TestFormatPRBody_FilesChanged(t *testing.T) {
+	p := NewPRPhase(providers.NewMockProvider(), nil, config.DefaultsConfig{})
+	issue := &providers.Issue{Number: 7}
+	diff := &sandbox.DiffStat{
+		Files: []sandbox.FileChange{
+			{Path: "main.go", Insertions: 10, Deletions: 2},
+			{Path: "logo.png", Binary: true},
+		},
+		Insertions: 10,
+		Deletions:  2,
+	}
+
+	body := p.formatPRBody(issue, "", diff)
+	for _, want := range []string{"## Files changed", "- `main.go` (+10 -2)", "- `logo.png` (binary)", "2 files changed, 10 insertions(+), 2 deletions(-)", "Closes #7"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in PR body:\n%s", want, body)
+		}
+	}
+
+	if body := p.formatPRBody(issue, "", nil); strings.Contains(body, "Files changed") {
+		t.Errorf("expected no files section without a diff:\n%s", body)
+	}
+}
@@ -5,24 +5,32 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/anthropics/ultra-engineer/internal/claude"
+	"github.com/anthropics/ultra-engineer/internal/config"
 	"github.com/anthropics/ultra-engineer/internal/providers"
 	"github.com/anthropics/ultra-engineer/internal/state"
 )
 
 // QAPhase handles the question-and-answer phase of issue processing
 type QAPhase struct {
-	claude   *claude.Client
-	provider providers.Provider
+	claude        claude.Runner
+	provider      providers.Provider
+	tools         []string
+	model         string
+	singleComment bool // Edit one questions comment each round instead of posting a new one
 }
 
 // NewQAPhase creates a new QA phase handler
-func NewQAPhase(claudeClient *claude.Client, provider providers.Provider) *QAPhase {
+func NewQAPhase(claudeClient claude.Runner, provider providers.Provider, claudeCfg config.ClaudeConfig, progressCfg config.ProgressConfig) *QAPhase {
 	return &QAPhase{
-		claude:   claudeClient,
-		provider: provider,
+		claude:        claudeClient,
+		provider:      provider,
+		tools:         toolsOrDefault(claudeCfg.AllowedTools.QA, defaultQATools),
+		model:         claudeCfg.Models.QA,
+		singleComment: progressCfg.SingleQuestionComment,
 	}
 }
 
@@ -39,17 +47,61 @@ func (q *QAPhase) AnalyzeIssue(ctx context.Context, issue *providers.Issue, work
 	ueDir := filepath.Join(workDir, ".ultra-engineer")
 	os.MkdirAll(ueDir, 0755)
 
-	prompt := fmt.Sprintf(claude.Prompts.AnalyzeIssue, issue.Title, issue.Body)
+	// A reused sandbox may hold questions from an earlier analysis; only read what this run writes
+	os.Remove(filepath.Join(ueDir, "questions.md"))
+
+	prompt := fmt.Sprintf(claude.Prompts.AnalyzeIssue,
+		claude.WrapUntrusted("issue title", issue.Title), claude.WrapUntrusted("issue body", issue.Body))
+	prompt = withRepoContext(workDir, prompt)
+
+	_, _, err := q.claude.RunInteractive(ctx, claude.RunOptions{
+		WorkDir:      workDir,
+		Prompt:       prompt,
+		AllowedTools: q.tools,
+		Model:        q.model,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return readQAResult(ueDir), nil
+}
+
+// GenerateFollowUpQuestions updates the plan with the answers so far and asks
+// follow-up questions if the answers left anything unclear
+func (q *QAPhase) GenerateFollowUpQuestions(ctx context.Context, issue *providers.Issue, history []claude.QAEntry, workDir string) (*QAResult, error) {
+	ueDir := filepath.Join(workDir, ".ultra-engineer")
+	os.MkdirAll(ueDir, 0755)
+
+	// Remove the previous round's questions so stale questions are never re-posted
+	os.Remove(filepath.Join(ueDir, "questions.md"))
+
+	prompt := fmt.Sprintf(claude.Prompts.FollowUp,
+		claude.WrapUntrusted("issue title", issue.Title), claude.WrapUntrusted("issue body", issue.Body),
+		claude.FormatQAHistory(history))
+	prompt = withRepoContext(workDir, prompt)
 
 	_, _, err := q.claude.RunInteractive(ctx, claude.RunOptions{
 		WorkDir:      workDir,
 		Prompt:       prompt,
-		AllowedTools: []string{"Read", "Write", "Glob", "Grep"},
+		AllowedTools: q.tools,
+		Model:        q.model,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	return readQAResult(ueDir), nil
+}
+
+// CurrentQuestions returns the questions from the most recent round
+func (q *QAPhase) CurrentQuestions(workDir string) string {
+	data, _ := os.ReadFile(filepath.Join(workDir, ".ultra-engineer", "questions.md"))
+	return strings.TrimSpace(string(data))
+}
+
+// readQAResult reads the questions and plan files written by Claude
+func readQAResult(ueDir string) *QAResult {
 	// Read questions file
 	questionsPath := filepath.Join(ueDir, "questions.md")
 	questionsData, _ := os.ReadFile(questionsPath)
@@ -60,22 +112,48 @@ func (q *QAPhase) AnalyzeIssue(ctx context.Context, issue *providers.Issue, work
 	planData, _ := os.ReadFile(planPath)
 	plan := strings.TrimSpace(string(planData))
 
-	noQuestions := strings.Contains(questions, "NO_QUESTIONS_NEEDED") || questions == ""
+	noQuestions := questions == "" || hasMarkerLine(questions, "NO_QUESTIONS_NEEDED")
 
 	return &QAResult{
 		Questions:       questions,
 		Plan:            plan,
 		NoMoreQuestions: noQuestions,
-	}, nil
+	}
+}
+
+// hasMarkerLine reports whether text contains marker on a line of its own,
+// ignoring mentions of the marker inside other text
+func hasMarkerLine(text, marker string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == marker {
+			return true
+		}
+	}
+	return false
 }
 
 // PostQuestions posts questions as a comment on the issue
 func (q *QAPhase) PostQuestions(ctx context.Context, repo string, issueNum int, questions string, roundNum int, st *state.State) error {
-	commentBody := claude.FormatQuestionsForComment(questions, roundNum)
 	// State is stored in progress comment, not questions comment
-	commentBody = state.AddBotMarker(commentBody)
-	_, err := q.provider.CreateComment(ctx, repo, issueNum, commentBody)
-	return err
+	if !q.singleComment {
+		commentBody := state.AddBotMarker(claude.FormatQuestionsForComment(questions, roundNum))
+		_, err := q.provider.CreateComment(ctx, repo, issueNum, commentBody)
+		return err
+	}
+
+	commentBody := state.AddBotMarker(claude.FormatQuestionRoundsForComment(questions, roundNum, st.QAHistory))
+	if st.QuestionCommentID != 0 {
+		if err := q.provider.UpdateComment(ctx, repo, st.QuestionCommentID, commentBody); err == nil {
+			return nil
+		}
+		// The comment may have been deleted; post a new one instead
+	}
+	commentID, err := q.provider.CreateComment(ctx, repo, issueNum, commentBody)
+	if err != nil {
+		return err
+	}
+	st.QuestionCommentID = commentID
+	return nil
 }
 
 // ParseUserAnswers extracts user answers from a comment
@@ -84,10 +162,200 @@ func ParseUserAnswers(comment string) string {
 	return strings.TrimSpace(answer)
 }
 
-// IsApproval checks if a comment is an approval (only /approve)
-func IsApproval(comment string) bool {
-	trimmed := strings.TrimSpace(comment)
-	return trimmed == "/approve"
+var (
+	questionLineRegex  = regexp.MustCompile(`^\s*(\d+)[.)]\s+(.+)$`)
+	optionLineRegex    = regexp.MustCompile(`^\s*([A-Z])[.)]\s+(.+)$`)
+	shorthandRegex     = regexp.MustCompile(`^(\d+)([A-Za-z])$`)
+	shorthandLeadRegex = regexp.MustCompile(`^(\d+)([A-Za-z])[\s:.)-]+(.+)$`)
+	shorthandSepRegex  = regexp.MustCompile(`[\s,;]+`)
+)
+
+// askedQuestion is a numbered question parsed from questions.md with its lettered options
+type askedQuestion struct {
+	text    string
+	options map[string]string
+}
+
+// parseAskedQuestions extracts numbered questions and lettered options from
+// text written in the questions.md format
+func parseAskedQuestions(questions string) map[string]*askedQuestion {
+	parsed := make(map[string]*askedQuestion)
+	var current *askedQuestion
+
+	for _, line := range strings.Split(questions, "\n") {
+		if m := questionLineRegex.FindStringSubmatch(line); m != nil {
+			current = &askedQuestion{text: cleanQuestionText(m[2]), options: make(map[string]string)}
+			parsed[m[1]] = current
+			continue
+		}
+		if m := optionLineRegex.FindStringSubmatch(line); m != nil && current != nil {
+			current.options[m[1]] = cleanQuestionText(m[2])
+		}
+	}
+	return parsed
+}
+
+// cleanQuestionText strips markdown emphasis and the recommendation marker
+func cleanQuestionText(text string) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "(Recommended)", ""))
+	return strings.TrimSpace(strings.Trim(text, "*_"))
+}
+
+// NormalizeAnswers expands shorthand answers like "1A, 2B" into the question
+// and option text they refer to, so Claude does not have to guess.
+// Lines of free text are kept as-is; a line starting with shorthand followed by
+// text ("2C: use Redis") is expanded and keeps the text as a note.
+// Shorthand that doesn't match a posted question and option is left untouched.
+func NormalizeAnswers(questions, answer string) string {
+	asked := parseAskedQuestions(questions)
+	if len(asked) == 0 {
+		return answer
+	}
+
+	expand := func(num, letter string) (string, bool) {
+		q, ok := asked[num]
+		if !ok {
+			return "", false
+		}
+		letter = strings.ToUpper(letter)
+		option, ok := q.options[letter]
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf("%s. %s\n   Answer: %s. %s", num, q.text, letter, option), true
+	}
+
+	lines := strings.Split(answer, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		// A line made up only of shorthand tokens, e.g. "1A, 2B 3C"
+		tokens := shorthandSepRegex.Split(trimmed, -1)
+		var expanded []string
+		for _, token := range tokens {
+			if token == "" {
+				continue
+			}
+			m := shorthandRegex.FindStringSubmatch(token)
+			if m == nil {
+				expanded = nil
+				break
+			}
+			text, ok := expand(m[1], m[2])
+			if !ok {
+				text = token
+			}
+			expanded = append(expanded, text)
+		}
+		if expanded != nil {
+			lines[i] = strings.Join(expanded, "\n")
+			continue
+		}
+
+		// Shorthand followed by free text, e.g. "2C: use Redis"
+		if m := shorthandLeadRegex.FindStringSubmatch(trimmed); m != nil {
+			if text, ok := expand(m[1], m[2]); ok {
+				lines[i] = text + "\n   Note: " + strings.TrimSpace(m[3])
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// ApprovalMatcher decides whether a comment approves a plan using the
+// configured approval vocabulary
+type ApprovalMatcher struct {
+	phrases           []string
+	requireExactMatch bool
+}
+
+// NewApprovalMatcher creates an approval matcher from config.
+// An empty phrase list falls back to the default "/approve" command.
+func NewApprovalMatcher(cfg config.ApprovalConfig) *ApprovalMatcher {
+	var phrases []string
+	for _, p := range cfg.Phrases {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p != "" {
+			phrases = append(phrases, p)
+		}
+	}
+	if len(phrases) == 0 {
+		phrases = []string{"/approve"}
+	}
+	return &ApprovalMatcher{
+		phrases:           phrases,
+		requireExactMatch: cfg.RequireExactMatch,
+	}
+}
+
+// IsApproval checks if a comment matches one of the approval phrases.
+// With exact matching the whole trimmed comment must equal a phrase,
+// otherwise the comment only needs to contain one (case-insensitive).
+func (m *ApprovalMatcher) IsApproval(comment string) bool {
+	body := strings.ToLower(strings.TrimSpace(state.RemoveState(comment)))
+	if body == "" {
+		return false
+	}
+
+	for _, phrase := range m.phrases {
+		if m.requireExactMatch {
+			if body == phrase {
+				return true
+			}
+		} else if strings.Contains(body, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// SlashCommand is an explicit command given on the first line of a comment
+type SlashCommand string
+
+const (
+	CommandNone    SlashCommand = ""
+	CommandApprove SlashCommand = "/approve"
+	CommandLGTM    SlashCommand = "/lgtm"
+	CommandChanges SlashCommand = "/changes"
+	CommandReplan  SlashCommand = "/replan"
+	CommandStatus  SlashCommand = "/status"
+)
+
+// ParseSlashCommand returns the slash command on the first line of a comment.
+// The command must be on its own line, except that /replan may be followed by guidance;
+// commands mentioned in prose are ignored.
+func ParseSlashCommand(comment string) SlashCommand {
+	body := strings.TrimSpace(state.RemoveState(comment))
+	firstLine, _, _ := strings.Cut(body, "\n")
+	firstLine = strings.ToLower(strings.TrimSpace(firstLine))
+
+	switch SlashCommand(firstLine) {
+	case CommandApprove, CommandLGTM, CommandChanges, CommandReplan, CommandStatus:
+		return SlashCommand(firstLine)
+	}
+	if fields := strings.Fields(firstLine); len(fields) > 0 && SlashCommand(fields[0]) == CommandReplan {
+		return CommandReplan
+	}
+	return CommandNone
+}
+
+// IsApprovalCommand checks if a slash command approves the plan
+func IsApprovalCommand(cmd SlashCommand) bool {
+	return cmd == CommandApprove || cmd == CommandLGTM
+}
+
+// StripSlashCommand removes the leading command and returns the rest of the comment
+func StripSlashCommand(comment string) string {
+	body := strings.TrimSpace(state.RemoveState(comment))
+	cmd := ParseSlashCommand(body)
+	if cmd == CommandNone {
+		return body
+	}
+	return strings.TrimSpace(body[len(cmd):])
 }
 
 // IsAbort checks if a comment is an abort command
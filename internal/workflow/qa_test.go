@@ -0,0 +1,423 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/ultra-engineer/internal/claude"
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/providers"
+	"github.com/anthropics/ultra-engineer/internal/state"
+)
+
+func TestApprovalMatcher_DefaultPhrases(t *testing.T) {
+	matcher := NewApprovalMatcher(config.ApprovalConfig{RequireExactMatch: true})
+
+	tests := []struct {
+		comment  string
+		expected bool
+	}{
+		{"/approve", true},
+		{"  /approve\n", true},
+		{"/APPROVE", true},
+		{"lgtm", false},
+		{"/approve but change X first", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.comment, func(t *testing.T) {
+			if got := matcher.IsApproval(tt.comment); got != tt.expected {
+				t.Errorf("IsApproval(%q) = %v, want %v", tt.comment, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApprovalMatcher_CustomPhrasesExact(t *testing.T) {
+	matcher := NewApprovalMatcher(config.ApprovalConfig{
+		Phrases:           []string{"LGTM", "ship it"},
+		RequireExactMatch: true,
+	})
+
+	tests := []struct {
+		comment  string
+		expected bool
+	}{
+		{"lgtm", true},
+		{"Ship it", true},
+		{"lgtm, but go ahead and change X", false},
+		{"/approve", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.comment, func(t *testing.T) {
+			if got := matcher.IsApproval(tt.comment); got != tt.expected {
+				t.Errorf("IsApproval(%q) = %v, want %v", tt.comment, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApprovalMatcher_ContainsMatch(t *testing.T) {
+	matcher := NewApprovalMatcher(config.ApprovalConfig{
+		Phrases:           []string{"lgtm"},
+		RequireExactMatch: false,
+	})
+
+	if !matcher.IsApproval("Looks good. LGTM!") {
+		t.Error("expected comment containing phrase to be an approval")
+	}
+	if matcher.IsApproval("please change the approach") {
+		t.Error("expected comment without phrase not to be an approval")
+	}
+}
+
+func TestParseSlashCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		comment  string
+		expected SlashCommand
+	}{
+		{"approve command", "/approve", CommandApprove},
+		{"lgtm command", "/lgtm\n", CommandLGTM},
+		{"changes with feedback", "/changes\nPlease use a map instead", CommandChanges},
+		{"uppercase command", "/APPROVE", CommandApprove},
+		{"approved in prose", "I approved of the general idea but not step 3", CommandNone},
+		{"command not on first line", "Some thoughts first\n/approve", CommandNone},
+		{"command with trailing text", "/approve the plan but change X", CommandNone},
+		{"replan", "/replan", CommandReplan},
+		{"replan with guidance", "/Replan use SQLite instead", CommandReplan},
+		{"replan as a prefix", "/replanning", CommandNone},
+		{"empty", "", CommandNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseSlashCommand(tt.comment); got != tt.expected {
+				t.Errorf("ParseSlashCommand(%q) = %q, want %q", tt.comment, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSlashCommandPriorityOverProse(t *testing.T) {
+	// A loose phrase matcher would approve this prose comment
+	matcher := NewApprovalMatcher(config.ApprovalConfig{
+		Phrases:           []string{"approved"},
+		RequireExactMatch: false,
+	})
+
+	prose := "Not yet approved - the migration step is missing"
+	if cmd := ParseSlashCommand(prose); IsApprovalCommand(cmd) {
+		t.Errorf("prose mention should not parse as an approval command, got %q", cmd)
+	}
+	if !matcher.IsApproval(prose) {
+		t.Error("expected loose matcher to match prose (documents why commands take priority)")
+	}
+
+	if cmd := ParseSlashCommand("/approve"); !IsApprovalCommand(cmd) {
+		t.Errorf("expected /approve to be an approval command, got %q", cmd)
+	}
+}
+
+func TestStripSlashCommand(t *testing.T) {
+	got := StripSlashCommand("/changes\nUse Postgres instead of SQLite\n")
+	if got != "Use Postgres instead of SQLite" {
+		t.Errorf("unexpected feedback: %q", got)
+	}
+
+	if got := StripSlashCommand("/replan Use SQLite\nand keep it small"); got != "Use SQLite\nand keep it small" {
+		t.Errorf("expected guidance after /replan, got %q", got)
+	}
+
+	if got := StripSlashCommand("plain feedback"); got != "plain feedback" {
+		t.Errorf("expected comment without command unchanged, got %q", got)
+	}
+}
+
+// fakeClaude writes a script standing in for the claude CLI that runs body in the work dir
+func fakeClaude(t *testing.T, body string) *claude.Client {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "claude")
+	script := "#!/bin/sh\nmkdir -p .ultra-engineer\n" + body + "\necho '{\"type\":\"result\",\"result\":\"ok\"}'\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return claude.NewClient(path, time.Minute)
+}
+
+// fakeRunner is an in-process claude.Runner that records each call and answers with run
+type fakeRunner struct {
+	calls []claude.RunOptions
+	run   func(opts claude.RunOptions) (string, error)
+}
+
+func (f *fakeRunner) RunInteractive(ctx context.Context, opts claude.RunOptions) (string, string, error) {
+	f.calls = append(f.calls, opts)
+	if f.run == nil {
+		return "", "", nil
+	}
+	output, err := f.run(opts)
+	return output, "", err
+}
+
+// writeUEFile writes a file into the .ultra-engineer dir of workDir, as Claude would
+func writeUEFile(workDir, name, content string) error {
+	dir := filepath.Join(workDir, ".ultra-engineer")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+}
+
+func TestAnalyzeIssue_ParsesQuestions(t *testing.T) {
+	tests := []struct {
+		name          string
+		questions     string
+		wantQuestions string
+		wantNoMore    bool
+	}{
+		{"questions", "1. Which database?\n\n   A. Postgres (Recommended)\n", "1. Which database?\n\n   A. Postgres (Recommended)", false},
+		{"no questions marker", "NO_QUESTIONS_NEEDED\n", "NO_QUESTIONS_NEEDED", true},
+		{"no file", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &fakeRunner{run: func(opts claude.RunOptions) (string, error) {
+				if err := writeUEFile(opts.WorkDir, "plan.md", "# Plan\n"); err != nil {
+					return "", err
+				}
+				if tt.questions == "" {
+					return "", nil
+				}
+				return "", writeUEFile(opts.WorkDir, "questions.md", tt.questions)
+			}}
+			qa := NewQAPhase(runner, providers.NewMockProvider(), config.ClaudeConfig{}, config.ProgressConfig{})
+
+			issue := &providers.Issue{Title: "Add caching", Body: "Cache API responses"}
+			result, err := qa.AnalyzeIssue(context.Background(), issue, t.TempDir())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Questions != tt.wantQuestions || result.NoMoreQuestions != tt.wantNoMore {
+				t.Errorf("got questions=%q noMore=%v, want %q %v", result.Questions, result.NoMoreQuestions, tt.wantQuestions, tt.wantNoMore)
+			}
+			if result.Plan != "# Plan" {
+				t.Errorf("expected plan to be read back, got %q", result.Plan)
+			}
+			if len(runner.calls) != 1 || !strings.Contains(runner.calls[0].Prompt, "Cache API responses") {
+				t.Errorf("expected one call with the issue body in the prompt, got %+v", runner.calls)
+			}
+		})
+	}
+}
+
+func TestAnalyzeIssue_IgnoresStaleQuestions(t *testing.T) {
+	workDir := t.TempDir()
+	if err := writeUEFile(workDir, "questions.md", "1. Left over from an earlier run?"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Claude decides no questions are needed and writes nothing
+	qa := NewQAPhase(&fakeRunner{}, providers.NewMockProvider(), config.ClaudeConfig{}, config.ProgressConfig{})
+	result, err := qa.AnalyzeIssue(context.Background(), &providers.Issue{Title: "t"}, workDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.NoMoreQuestions || result.Questions != "" {
+		t.Errorf("expected stale questions to be ignored, got %+v", result)
+	}
+}
+
+func TestAnalyzeIssue_ReturnsRunnerError(t *testing.T) {
+	runner := &fakeRunner{run: func(opts claude.RunOptions) (string, error) {
+		return "", errors.New("claude failed")
+	}}
+	qa := NewQAPhase(runner, providers.NewMockProvider(), config.ClaudeConfig{}, config.ProgressConfig{})
+
+	if _, err := qa.AnalyzeIssue(context.Background(), &providers.Issue{Title: "t"}, t.TempDir()); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestGenerateFollowUpQuestions(t *testing.T) {
+	workDir := t.TempDir()
+	client := fakeClaude(t, `echo "1. Which database?" > .ultra-engineer/questions.md`)
+	qa := NewQAPhase(client, providers.NewMockProvider(), config.ClaudeConfig{}, config.ProgressConfig{})
+
+	history := []claude.QAEntry{{Questions: "1. Which API?", Answers: "1A"}}
+	result, err := qa.GenerateFollowUpQuestions(context.Background(), &providers.Issue{Title: "t"}, history, workDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.NoMoreQuestions {
+		t.Fatal("expected follow-up questions")
+	}
+	if result.Questions != "1. Which database?" {
+		t.Errorf("unexpected questions: %q", result.Questions)
+	}
+	if got := qa.CurrentQuestions(workDir); got != result.Questions {
+		t.Errorf("CurrentQuestions = %q, want %q", got, result.Questions)
+	}
+}
+
+func TestGenerateFollowUpQuestions_ClearsStaleQuestions(t *testing.T) {
+	workDir := t.TempDir()
+	ueDir := filepath.Join(workDir, ".ultra-engineer")
+	os.MkdirAll(ueDir, 0755)
+	os.WriteFile(filepath.Join(ueDir, "questions.md"), []byte("1. Old question"), 0644)
+
+	// Claude writes nothing, so no further questions are needed
+	client := fakeClaude(t, "true")
+	qa := NewQAPhase(client, providers.NewMockProvider(), config.ClaudeConfig{}, config.ProgressConfig{})
+
+	result, err := qa.GenerateFollowUpQuestions(context.Background(), &providers.Issue{Title: "t"}, nil, workDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.NoMoreQuestions {
+		t.Errorf("expected no more questions, got %q", result.Questions)
+	}
+}
+
+const sampleQuestions = `1. **Which database should we use?**
+
+   A. PostgreSQL (Recommended)
+      **Effort:** Low
+
+   B. SQLite
+      **Effort:** Low
+
+   C. Other (please specify)
+
+2. Should the endpoint be paginated?
+
+   A. Yes, cursor-based (Recommended)
+
+   B. No
+
+If you're unsure, replying with just the recommended options (e.g., '1A, 2A') is a safe default.`
+
+func TestHasMarkerLine(t *testing.T) {
+	if !hasMarkerLine("NO_QUESTIONS_NEEDED\n", "NO_QUESTIONS_NEEDED") {
+		t.Error("expected marker on its own line to match")
+	}
+	if hasMarkerLine("1. Should we print NO_QUESTIONS_NEEDED here?", "NO_QUESTIONS_NEEDED") {
+		t.Error("expected marker inside a question not to match")
+	}
+}
+
+func TestNormalizeAnswers(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   string
+		contains []string
+		exact    string
+	}{
+		{
+			name:   "shorthand list",
+			answer: "1A, 2b",
+			contains: []string{
+				"1. Which database should we use?\n   Answer: A. PostgreSQL",
+				"2. Should the endpoint be paginated?\n   Answer: B. No",
+			},
+		},
+		{
+			name:   "shorthand with note",
+			answer: "1C: MySQL, since ops already runs it\n2A",
+			contains: []string{
+				"Answer: C. Other (please specify)\n   Note: MySQL, since ops already runs it",
+				"Answer: A. Yes, cursor-based",
+			},
+		},
+		{
+			name:     "mixed with free text",
+			answer:   "1B\nAlso please add a migration guide.",
+			contains: []string{"Answer: B. SQLite", "\nAlso please add a migration guide."},
+		},
+		{
+			name:   "free text only",
+			answer: "Use whatever you think is best, 2 pages max.",
+			exact:  "Use whatever you think is best, 2 pages max.",
+		},
+		{
+			name:     "unknown option left untouched",
+			answer:   "1A, 3B",
+			contains: []string{"Answer: A. PostgreSQL", "\n3B"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeAnswers(sampleQuestions, tt.answer)
+			if tt.exact != "" && got != tt.exact {
+				t.Errorf("expected %q unchanged, got %q", tt.exact, got)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("expected %q in:\n%s", want, got)
+				}
+			}
+			if strings.Contains(got, "(Recommended)") {
+				t.Errorf("expected recommendation marker to be stripped:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestNormalizeAnswers_NoParsedQuestions(t *testing.T) {
+	if got := NormalizeAnswers("", "1A"); got != "1A" {
+		t.Errorf("expected answer unchanged without questions, got %q", got)
+	}
+}
+
+func TestPostQuestions_SingleCommentEditsEachRound(t *testing.T) {
+	mock := providers.NewMockProvider()
+	qa := NewQAPhase(&fakeRunner{}, mock, config.ClaudeConfig{}, config.ProgressConfig{SingleQuestionComment: true})
+	st := state.NewState()
+	ctx := context.Background()
+
+	if err := qa.PostQuestions(ctx, "owner/repo", 1, "1. Which database?", 1, st); err != nil {
+		t.Fatalf("PostQuestions failed: %v", err)
+	}
+	if len(mock.CreatedComments) != 1 || st.QuestionCommentID != mock.CreatedComments[0].ID {
+		t.Fatalf("expected one questions comment tracked in state, got %+v (id %d)", mock.CreatedComments, st.QuestionCommentID)
+	}
+
+	st.AddQA("1. Which database?", "Postgres")
+	if err := qa.PostQuestions(ctx, "owner/repo", 1, "1. Which version?", 2, st); err != nil {
+		t.Fatalf("PostQuestions failed: %v", err)
+	}
+	if len(mock.CreatedComments) != 1 || len(mock.UpdatedComments) != 1 {
+		t.Fatalf("expected round 2 to edit the comment, got %d created and %d updated", len(mock.CreatedComments), len(mock.UpdatedComments))
+	}
+	body := mock.UpdatedComments[0].Body
+	if mock.UpdatedComments[0].CommentID != st.QuestionCommentID || !strings.Contains(body, "Which version?") {
+		t.Errorf("expected the round 2 questions in the tracked comment, got:\n%s", body)
+	}
+	if !strings.Contains(body, "<details>") || !strings.Contains(body, "Which database?") || !strings.Contains(body, "Postgres") {
+		t.Errorf("expected round 1 and its answer in a collapsed section, got:\n%s", body)
+	}
+}
+
+func TestPostQuestions_NewCommentPerRoundByDefault(t *testing.T) {
+	mock := providers.NewMockProvider()
+	qa := NewQAPhase(&fakeRunner{}, mock, config.ClaudeConfig{}, config.ProgressConfig{})
+	st := state.NewState()
+
+	for round := 1; round <= 2; round++ {
+		if err := qa.PostQuestions(context.Background(), "owner/repo", 1, "1. Why?", round, st); err != nil {
+			t.Fatalf("PostQuestions failed: %v", err)
+		}
+	}
+	if len(mock.CreatedComments) != 2 || len(mock.UpdatedComments) != 0 || st.QuestionCommentID != 0 {
+		t.Errorf("expected a new comment per round, got %d created and %d updated", len(mock.CreatedComments), len(mock.UpdatedComments))
+	}
+}
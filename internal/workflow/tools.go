@@ -0,0 +1,17 @@
+package workflow
+
+// Tools allowed per phase when claude.allowed_tools does not override them
+var (
+	defaultQATools       = []string{"Read", "Write", "Glob", "Grep"}
+	defaultPlanningTools = []string{"Read", "Write", "Edit"}
+	defaultCodingTools   = []string{"Read", "Write", "Edit", "Bash", "Glob", "Grep"}
+)
+
+// toolsOrDefault returns the configured tools, or defaults when the phase is unset.
+// An explicitly empty list is kept so a phase can run without any allowed tools.
+func toolsOrDefault(configured, defaults []string) []string {
+	if configured == nil {
+		return defaults
+	}
+	return configured
+}
@@ -0,0 +1,68 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/ultra-engineer/internal/config"
+	"github.com/anthropics/ultra-engineer/internal/providers"
+)
+
+func TestToolsOrDefault(t *testing.T) {
+	if got := toolsOrDefault(nil, defaultQATools); len(got) != len(defaultQATools) {
+		t.Errorf("expected defaults when unset, got %v", got)
+	}
+	if got := toolsOrDefault([]string{}, defaultQATools); len(got) != 0 {
+		t.Errorf("expected explicit empty list to be kept, got %v", got)
+	}
+	if got := toolsOrDefault([]string{"mcp__docs"}, defaultQATools); len(got) != 1 || got[0] != "mcp__docs" {
+		t.Errorf("expected override, got %v", got)
+	}
+}
+
+func TestQAPhase_UsesConfiguredTools(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.ClaudeConfig
+		want string
+	}{
+		{"default", config.ClaudeConfig{}, "--allowedTools Read --allowedTools Write --allowedTools Glob --allowedTools Grep"},
+		{"override", config.ClaudeConfig{AllowedTools: config.AllowedToolsConfig{QA: []string{"Read", "mcp__docs"}}}, "--allowedTools Read --allowedTools mcp__docs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workDir := t.TempDir()
+			client := fakeClaude(t, `echo "$@" > .ultra-engineer/args.txt`)
+			qa := NewQAPhase(client, providers.NewMockProvider(), tt.cfg, config.ProgressConfig{})
+
+			if _, err := qa.AnalyzeIssue(context.Background(), &providers.Issue{Title: "t"}, workDir); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			args, _ := os.ReadFile(filepath.Join(workDir, ".ultra-engineer", "args.txt"))
+			if !strings.HasSuffix(strings.TrimSpace(string(args)), tt.want) {
+				t.Errorf("expected args to end with %q, got %q", tt.want, args)
+			}
+		})
+	}
+}
+
+func TestPlanningPhase_UsesPhaseModel(t *testing.T) {
+	workDir := t.TempDir()
+	client := fakeClaude(t, `echo "$@" > .ultra-engineer/args.txt`)
+	cfg := config.ClaudeConfig{Models: config.PhaseModelsConfig{Planning: "opus"}}
+	plan := NewPlanningPhase(client, providers.NewMockProvider(), 1, config.ApprovalConfig{}, cfg)
+
+	if err := plan.ReviewPlan(context.Background(), 1, workDir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	args, _ := os.ReadFile(filepath.Join(workDir, ".ultra-engineer", "args.txt"))
+	if !strings.Contains(string(args), "--model opus") {
+		t.Errorf("expected planning model in args, got %q", args)
+	}
+}